@@ -46,17 +46,91 @@ type SchedulerConfig struct {
 // - a list of users specifying limits on the partition
 // - the preemption configuration for the partition
 type PartitionConfig struct {
-	Name           string
-	Queues         []QueueConfig
-	PlacementRules []PlacementRule           `yaml:",omitempty" json:",omitempty"`
-	Limits         []Limit                   `yaml:",omitempty" json:",omitempty"`
-	Preemption     PartitionPreemptionConfig `yaml:",omitempty" json:",omitempty"`
-	NodeSortPolicy NodeSortingPolicy         `yaml:",omitempty" json:",omitempty"`
+	Name             string
+	Queues           []QueueConfig
+	PlacementRules   []PlacementRule            `yaml:",omitempty" json:",omitempty"`
+	Limits           []Limit                    `yaml:",omitempty" json:",omitempty"`
+	Preemption       PartitionPreemptionConfig  `yaml:",omitempty" json:",omitempty"`
+	NodeSortPolicy   NodeSortingPolicy          `yaml:",omitempty" json:",omitempty"`
+	Overcommit       map[string]float64         `yaml:",omitempty" json:",omitempty"`
+	Granularity      map[string]string          `yaml:",omitempty" json:",omitempty"`
+	MaxAllocations   uint64                     `yaml:",omitempty" json:",omitempty"`
+	DefaultSubmitACL string                     `yaml:",omitempty" json:",omitempty"`
+	ParentPlacement  string                     `yaml:",omitempty" json:",omitempty"`
+	SchedulingMode   string                     `yaml:",omitempty" json:",omitempty"`
+	Sandbox          PartitionSandboxConfig     `yaml:",omitempty" json:",omitempty"`
+	ZeroRequest      PartitionZeroRequestConfig `yaml:",omitempty" json:",omitempty"`
+	MinRequest       PartitionMinRequestConfig  `yaml:",omitempty" json:",omitempty"`
+	BurstPool        PartitionBurstPoolConfig   `yaml:",omitempty" json:",omitempty"`
+	NodeOverhead     NodeOverheadConfig         `yaml:",omitempty" json:",omitempty"`
+	Cordon           PartitionCordonConfig      `yaml:",omitempty" json:",omitempty"`
 }
 
 // The partition preemption configuration
+// Preemption is limited to the partition: victims are only selected from the queues and nodes of the partition.
 type PartitionPreemptionConfig struct {
-	Enabled *bool `yaml:",omitempty" json:",omitempty"`
+	Enabled     *bool             `yaml:",omitempty" json:",omitempty"`
+	Cooldown    string            `yaml:",omitempty" json:",omitempty"`
+	GracePeriod string            `yaml:",omitempty" json:",omitempty"`
+	Quota       map[string]string `yaml:",omitempty" json:",omitempty"`
+	QuotaWindow string            `yaml:",omitempty" json:",omitempty"`
+}
+
+// The partition sandbox configuration:
+// applications from resource managers that are not trusted are placed in the sandbox queue.
+// The sandbox is only active when the queue is set.
+type PartitionSandboxConfig struct {
+	TrustedRMs []string `yaml:",omitempty" json:",omitempty"`
+	Queue      string   `yaml:",omitempty" json:",omitempty"`
+}
+
+// The partition zero request configuration:
+// the handling of applications that do not request any resources when they are submitted.
+// The policy is accept (default), reject or route. The queue must be set for, and only for, the route policy.
+// Reject and route decide on the first request of the application: reject an application whose first request does
+// not ask for resources, route parks the application in the queue until its first request arrives.
+type PartitionZeroRequestConfig struct {
+	Policy string `yaml:",omitempty" json:",omitempty"`
+	Queue  string `yaml:",omitempty" json:",omitempty"`
+}
+
+// The partition cordon configuration:
+// the fair share treatment of the capacity of cordoned nodes. The policy is keep (default) or exclude.
+type PartitionCordonConfig struct {
+	FairShare string `yaml:",omitempty" json:",omitempty"`
+}
+
+// The partition minimum request configuration:
+// the minimum per resource type a new request must ask for, requests below it often indicate a bug in the
+// application. The policy is warn (default) or reject.
+type PartitionMinRequestConfig struct {
+	Resources map[string]string `yaml:",omitempty" json:",omitempty"`
+	Policy    string            `yaml:",omitempty" json:",omitempty"`
+}
+
+// The partition burst pool configuration:
+// the tokens shared by all queues to use resources between their quota and burst. Each allocation consumes the part
+// of the allocation that is above the quota of the queue. The pool starts full and is refilled linearly, the refill
+// is the time it takes to refill an empty pool. No refill means consumed tokens are never returned.
+type PartitionBurstPoolConfig struct {
+	Capacity map[string]string `yaml:",omitempty" json:",omitempty"`
+	Refill   string            `yaml:",omitempty" json:",omitempty"`
+}
+
+// The partition node overhead configuration:
+// the resources reserved on each node for system daemons, subtracted from the capacity the node registers with.
+// The reserved resources apply to all nodes unless an override matches the node. An override matches if all its
+// labels are set to the same value in the node attributes, the first matching override replaces the reserved
+// resources for that node.
+type NodeOverheadConfig struct {
+	Reserved  map[string]string      `yaml:",omitempty" json:",omitempty"`
+	Overrides []NodeOverheadOverride `yaml:",omitempty" json:",omitempty"`
+}
+
+// The node overhead override for nodes with matching labels
+type NodeOverheadOverride struct {
+	Labels   map[string]string
+	Reserved map[string]string `yaml:",omitempty" json:",omitempty"`
 }
 
 // The queue object for each queue:
@@ -78,6 +152,12 @@ type QueueConfig struct {
 	ChildTemplate   ChildTemplate     `yaml:",omitempty" json:",omitempty"`
 	Queues          []QueueConfig     `yaml:",omitempty" json:",omitempty"`
 	Limits          []Limit           `yaml:",omitempty" json:",omitempty"`
+	Preemptable     *bool             `yaml:",omitempty" json:",omitempty"` // nil means preemptable
+	Enabled         *bool             `yaml:",omitempty" json:",omitempty"` // nil means enabled, ignored for the root queue
+	Info            QueueInfo         `yaml:",omitempty" json:",omitempty"` // descriptive only, not used for scheduling
+	Tags            map[string]string `yaml:",omitempty" json:",omitempty"` // for grouping queues and for the queuetag placement rule
+	// maximum resources each group can use in the queue
+	MaxResourcesPerGroup map[string]string `yaml:",omitempty" json:",omitempty"`
 }
 
 type ChildTemplate struct {
@@ -86,6 +166,13 @@ type ChildTemplate struct {
 	Resources       Resources         `yaml:",omitempty" json:",omitempty"`
 }
 
+// Descriptive metadata of the queue for the people and tools that manage the queue.
+type QueueInfo struct {
+	Owner       string `yaml:",omitempty" json:",omitempty"`
+	Team        string `yaml:",omitempty" json:",omitempty"`
+	Description string `yaml:",omitempty" json:",omitempty"`
+}
+
 // The resource limits to set on the queue. The definition allows for an unlimited number of types to be used.
 // The mapping to "known" resources is not handled here.
 // - guaranteed resources
@@ -93,6 +180,10 @@ type ChildTemplate struct {
 type Resources struct {
 	Guaranteed map[string]string `yaml:",omitempty" json:",omitempty"`
 	Max        map[string]string `yaml:",omitempty" json:",omitempty"`
+	// usage up to the quota is always allowed, usage between the quota and the burst only while the partition burst
+	// pool has tokens left. Resource types without a quota are not limited by the quota or burst.
+	Quota map[string]string `yaml:",omitempty" json:",omitempty"`
+	Burst map[string]string `yaml:",omitempty" json:",omitempty"`
 }
 
 // The queue placement rule definition
@@ -108,6 +199,20 @@ type PlacementRule struct {
 	Filter Filter         `yaml:",omitempty" json:",omitempty"`
 	Parent *PlacementRule `yaml:",omitempty" json:",omitempty"`
 	Value  string         `yaml:",omitempty" json:",omitempty"`
+	// prefixes and suffixes removed from the tag value by the tag rule, ignored by other rules
+	StripPrefixes []string `yaml:",omitempty" json:",omitempty"`
+	StripSuffixes []string `yaml:",omitempty" json:",omitempty"`
+	// resource threshold and the queues for applications requesting more or less, used by the size rule
+	Threshold  map[string]string `yaml:",omitempty" json:",omitempty"`
+	AboveQueue string            `yaml:",omitempty" json:",omitempty"`
+	BelowQueue string            `yaml:",omitempty" json:",omitempty"`
+	// queue tags a parent queue must carry to be selected by the queuetag rule
+	QueueTags map[string]string `yaml:",omitempty" json:",omitempty"`
+	// rules of the group rule, the first that matches is used, ignored by other rules
+	Rules []PlacementRule `yaml:",omitempty" json:",omitempty"`
+	// static segment added before or after the last queue name in the path resolved by the rule
+	QueuePrefix string `yaml:",omitempty" json:",omitempty"`
+	QueueSuffix string `yaml:",omitempty" json:",omitempty"`
 }
 
 // The user and group filter for a rule.
@@ -158,9 +263,39 @@ func LoadSchedulerConfigFromByteArray(content []byte) (*SchedulerConfig, error)
 	return conf, err
 }
 
+// SetChecksum sets the checksum of the config. The content of referenced ACL files is included in the checksum
+// to make sure a change in an ACL file is picked up on the next reload.
 func SetChecksum(content []byte, conf *SchedulerConfig) {
 	noChecksumContent := GetConfigurationString(content)
-	conf.Checksum = fmt.Sprintf("%X", sha256.Sum256([]byte(noChecksumContent)))
+	var aclContent strings.Builder
+	for _, partition := range conf.Partitions {
+		writeACLFileContent(partition.DefaultSubmitACL, &aclContent)
+		for _, queue := range partition.Queues {
+			getACLFileContent(queue, &aclContent)
+		}
+	}
+	conf.Checksum = fmt.Sprintf("%X", sha256.Sum256([]byte(noChecksumContent+aclContent.String())))
+}
+
+// getACLFileContent adds the content of all ACL files referenced in the queue hierarchy to the builder.
+func getACLFileContent(queue QueueConfig, content *strings.Builder) {
+	writeACLFileContent(queue.AdminACL, content)
+	writeACLFileContent(queue.SubmitACL, content)
+	for _, child := range queue.Queues {
+		getACLFileContent(child, content)
+	}
+}
+
+// writeACLFileContent adds the content of the file to the builder if the ACL references a file.
+func writeACLFileContent(acl string, content *strings.Builder) {
+	if !strings.HasPrefix(strings.TrimSpace(acl), ACLFilePrefix) {
+		return
+	}
+	// the config is validated: errors reading the file are not expected
+	resolved, err := ResolveACL(acl)
+	if err == nil {
+		content.WriteString(resolved)
+	}
 }
 
 func ParseAndValidateConfig(content []byte) (*SchedulerConfig, error) {
@@ -22,6 +22,8 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -456,6 +458,41 @@ partitions:
 	assert.NilError(t, err, "every allowed char queue name should not have failed")
 }
 
+func TestParseQueueInfo(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: described
+            info:
+              owner: alice
+              team: data-platform
+              description: batch jobs of the data platform team
+          - name: plain
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "queue info should not have failed")
+	queues := conf.Partitions[0].Queues[0].Queues
+	assert.Equal(t, queues[0].Name, "described")
+	assert.DeepEqual(t, queues[0].Info, QueueInfo{Owner: "alice", Team: "data-platform", Description: "batch jobs of the data platform team"})
+	assert.DeepEqual(t, queues[1].Info, QueueInfo{})
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: described
+            info:
+              description: ` + strings.Repeat("x", QueueDescriptionMaxLength+1) + `
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "queue described: description is longer than 1024 characters")
+}
+
 func TestParseQueueFail(t *testing.T) {
 	data := `
 partitions:
@@ -609,6 +646,97 @@ partitions:
 	}
 }
 
+func TestParseACLFile(t *testing.T) {
+	dir := t.TempDir()
+	aclFile := filepath.Join(dir, "admin.acl")
+	err := os.WriteFile(aclFile, []byte("user1,user2 group1\n"), 0600)
+	assert.NilError(t, err, "failed to write ACL file")
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        adminacl: "@` + aclFile + `"
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "ACL file reference should have parsed")
+	assert.Equal(t, conf.Partitions[0].Queues[0].AdminACL, "@"+aclFile, "ACL reference should not be replaced in the config")
+
+	// a change in the file changes the checksum
+	first, err := LoadSchedulerConfigFromByteArray([]byte(data))
+	assert.NilError(t, err, "ACL file reference should have loaded")
+	err = os.WriteFile(aclFile, []byte("user1"), 0600)
+	assert.NilError(t, err, "failed to update ACL file")
+	second, err := LoadSchedulerConfigFromByteArray([]byte(data))
+	assert.NilError(t, err, "updated ACL file reference should have loaded")
+	assert.Assert(t, first.Checksum != second.Checksum, "checksum should change when the ACL file changes")
+
+	// content of the file is validated
+	err = os.WriteFile(aclFile, []byte("users groups something_to_fail_it"), 0600)
+	assert.NilError(t, err, "failed to write ACL file")
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "multiple spaces found in ACL")
+
+	// missing file
+	err = os.Remove(aclFile)
+	assert.NilError(t, err, "failed to remove ACL file")
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "unable to read ACL file")
+
+	// reference without a path
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "@"
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "ACL file reference without a path")
+}
+
+func TestParseDefaultSubmitACL(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    defaultsubmitacl: "user1 group1"
+    queues:
+      - name: root
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "default submit ACL should have parsed")
+	assert.Equal(t, conf.Partitions[0].DefaultSubmitACL, "user1 group1")
+
+	data = `
+partitions:
+  - name: default
+    defaultsubmitacl: "user1 group1 something_to_fail_it"
+    queues:
+      - name: root
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "multiple spaces found in ACL")
+
+	// a change in a referenced file changes the checksum
+	aclFile := filepath.Join(t.TempDir(), "submit.acl")
+	err = os.WriteFile(aclFile, []byte("user1"), 0600)
+	assert.NilError(t, err, "failed to write ACL file")
+	data = `
+partitions:
+  - name: default
+    defaultsubmitacl: "@` + aclFile + `"
+    queues:
+      - name: root
+`
+	first, err := LoadSchedulerConfigFromByteArray([]byte(data))
+	assert.NilError(t, err, "ACL file reference should have loaded")
+	err = os.WriteFile(aclFile, []byte("user2"), 0600)
+	assert.NilError(t, err, "failed to update ACL file")
+	second, err := LoadSchedulerConfigFromByteArray([]byte(data))
+	assert.NilError(t, err, "updated ACL file reference should have loaded")
+	assert.Assert(t, first.Checksum != second.Checksum, "checksum should change when the ACL file changes")
+}
+
 func TestPartitionPreemptionParameter(t *testing.T) {
 	data := `
 partitions:
@@ -1893,7 +2021,7 @@ func TestUserLimitsWithHierarchicalQueue(t *testing.T) {
 	// Make sure parent queue user max apps should not less than the child queue max resource
 	// validate the config and check after the update
 	_, err := CreateConfig(prepareUserLimitsConfig(50, "{memory: 10000, vcore: 100}"))
-	assert.ErrorContains(t, err, "user user1 max resource map[memory:10000 vcore:100000] of queue leaf is greater than immediate or ancestor parent maximum resource map[memory:1000 vcore:100000]")
+	assert.ErrorContains(t, err, "user user1 max resource map[vcore:100000 memory:10000] of queue leaf is greater than immediate or ancestor parent maximum resource map[vcore:100000 memory:1000]")
 
 	// Make sure parent queue user max apps should not less than the child queue max apps
 	// validate the config and check after the update
@@ -1904,7 +2032,7 @@ func TestUserLimitsWithHierarchicalQueue(t *testing.T) {
 	// Make sure hierarchical queue user max resource should not less than the child queue max resource
 	// validate the config and check after the update
 	_, err = CreateConfig(prepareUserLimitsWithTwoLevelsConfig(90, "{memory: 100000, vcore: 100}", "{memory: 90000, vcore: 100}"))
-	assert.ErrorContains(t, err, "user user1 max resource map[memory:90000 vcore:100000] of queue leaf is greater than immediate or ancestor parent maximum resource map[memory:10000 vcore:10000000]")
+	assert.ErrorContains(t, err, "user user1 max resource map[vcore:100000 memory:90000] of queue leaf is greater than immediate or ancestor parent maximum resource map[vcore:10000000 memory:10000]")
 
 	// (More than one level check)
 	// Make sure hierarchical queue user max apps should not less than the child queue max apps
@@ -1963,7 +2091,7 @@ partitions:
 `
 	// validate the config and check after the update
 	_, err = CreateConfig(data)
-	assert.ErrorContains(t, err, "user * max resource map[memory:90000 vcore:100000] of queue leaf is greater than immediate or ancestor parent maximum resource map[memory:10000 vcore:10000000]")
+	assert.ErrorContains(t, err, "user * max resource map[vcore:100000 memory:90000] of queue leaf is greater than immediate or ancestor parent maximum resource map[vcore:10000000 memory:10000]")
 }
 
 func prepareGroupLimitsConfig(leafQueueMaxApps uint64, leafQueueMaxResource string) string {
@@ -2096,7 +2224,7 @@ func TestGroupLimitsWithHierarchicalQueue(t *testing.T) {
 	// Make sure parent queue user max apps should not less than the child queue max resource
 	// validate the config and check after the update
 	_, err := CreateConfig(prepareGroupLimitsConfig(50, "{memory: 10000, vcore: 100}"))
-	assert.ErrorContains(t, err, "group group1 max resource map[memory:10000 vcore:100000] of queue leaf is greater than immediate or ancestor parent maximum resource map[memory:1000 vcore:100000]")
+	assert.ErrorContains(t, err, "group group1 max resource map[vcore:100000 memory:10000] of queue leaf is greater than immediate or ancestor parent maximum resource map[vcore:100000 memory:1000]")
 
 	// Make sure parent queue user max apps should not less than the child queue max apps
 	// validate the config and check after the update
@@ -2105,7 +2233,7 @@ func TestGroupLimitsWithHierarchicalQueue(t *testing.T) {
 
 	// validate the config and check after the update
 	_, err = CreateConfig(prepareGroupLimitsWithTwoLevelsConfig(90, "{memory: 100000, vcore: 1000}", "{memory: 90000, vcore: 100}"))
-	assert.ErrorContains(t, err, "group group1 max resource map[memory:90000 vcore:100000] of queue leaf is greater than immediate or ancestor parent maximum resource map[memory:10000 vcore:10000000]")
+	assert.ErrorContains(t, err, "group group1 max resource map[vcore:100000 memory:90000] of queue leaf is greater than immediate or ancestor parent maximum resource map[vcore:10000000 memory:10000]")
 
 	// (More than one level check)
 	// Make sure hierarchical queue user max apps should not less than the child queue max apps
@@ -2179,5 +2307,5 @@ partitions:
 `
 	// validate the config and check after the update
 	_, err = CreateConfig(data)
-	assert.ErrorContains(t, err, "group * max resource map[memory:90000 vcore:100000] of queue leaf is greater than immediate or ancestor parent maximum resource map[memory:10000 vcore:10000000]")
+	assert.ErrorContains(t, err, "group * max resource map[vcore:100000 memory:90000] of queue leaf is greater than immediate or ancestor parent maximum resource map[vcore:10000000 memory:10000]")
 }
@@ -19,19 +19,40 @@
 package configs
 
 import (
+	"strings"
 	"time"
 
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
 	"github.com/apache/yunikorn-core/pkg/locking"
 	"github.com/apache/yunikorn-core/pkg/log"
 )
 
 const (
 	// prefixes
-	PrefixEvent  = "event."
-	PrefixHealth = "health."
+	PrefixACL         = "acl."
+	PrefixApplication = "application."
+	PrefixEvent       = "event."
+	PrefixHealth      = "health."
+	PrefixResources   = "resources."
 
 	HealthCheckInterval = PrefixHealth + "checkInterval"
 
+	// comma separated list of resource types rendered first when a resource is displayed
+	CMResourceDisplayOrder = PrefixResources + "displayOrder"
+
+	// behaviour of the checked resource calculations when a quantity overflows: saturate (default) or error.
+	// Only the queue usage check before an allocation is added to a queue is a checked calculation: the error mode
+	// rejects that allocation. All other resource calculations, like the node and application usage, saturate.
+	CMResourceOverflow = PrefixResources + "overflow"
+
+	// how a wildcard in one field of an ACL affects the other field: any (default) or field
+	CMACLWildcardPolicy = PrefixACL + "wildcardPolicy"
+
+	// scope in which an application ID must be unique: partition (default) or global
+	CMAppIDScope = PrefixApplication + "idScope"
+
 	// events
 	CMEventTrackingEnabled    = PrefixEvent + "trackingEnabled"    // Application Tracking
 	CMEventRequestCapacity    = PrefixEvent + "requestCapacity"    // Request Capacity
@@ -69,6 +90,33 @@ func init() {
 	AddConfigMapCallback("logging", func() {
 		log.UpdateLoggingConfig(GetConfigMap())
 	})
+	// add a callback to reconfigure the resource display order
+	AddConfigMapCallback("resource-display-order", updateResourceDisplayOrder)
+	// add a callback to reconfigure the resource overflow mode
+	AddConfigMapCallback("resource-overflow", updateResourceOverflow)
+}
+
+// updateResourceDisplayOrder sets the resource display order from the config map, an unset value resets the order
+// to the default.
+func updateResourceDisplayOrder() {
+	var order []string
+	if value := GetConfigMap()[CMResourceDisplayOrder]; value != "" {
+		order = strings.Split(value, ",")
+	}
+	resources.SetDisplayOrder(order)
+}
+
+// updateResourceOverflow sets the resource overflow mode from the config map, an unset or unknown value resets the
+// mode to the default.
+func updateResourceOverflow() {
+	value := GetConfigMap()[CMResourceOverflow]
+	mode, err := resources.OverflowModeFromString(value)
+	if err != nil {
+		log.Log(log.Config).Warn("unknown resource overflow mode, using default",
+			zap.String("mode", value),
+			zap.Stringer("default", mode))
+	}
+	resources.SetOverflowMode(mode)
 }
 
 // scheduler config context provides thread-safe access for scheduler configurations
@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
 )
 
 func TestConfigMap(t *testing.T) {
@@ -55,3 +57,28 @@ func TestCallback(t *testing.T) {
 	SetConfigMap(nil)
 	assert.Assert(t, !callbackReceived, "callback still received")
 }
+
+func TestResourceDisplayOrderCallback(t *testing.T) {
+	defer SetConfigMap(nil)
+
+	SetConfigMap(map[string]string{CMResourceDisplayOrder: "gpu, memory"})
+	assert.DeepEqual(t, resources.GetDisplayOrder(), []string{"gpu", "memory"})
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1, "memory": 2, "gpu": 3})
+	assert.Equal(t, res.String(), "map[gpu:3 memory:2 vcore:1]")
+
+	SetConfigMap(nil)
+	assert.DeepEqual(t, resources.GetDisplayOrder(), resources.DefaultDisplayOrder)
+	assert.Equal(t, res.String(), "map[vcore:1 memory:2 gpu:3]")
+}
+
+func TestResourceOverflowCallback(t *testing.T) {
+	defer SetConfigMap(nil)
+
+	SetConfigMap(map[string]string{CMResourceOverflow: "error"})
+	assert.Equal(t, resources.GetOverflowMode(), resources.ErrorOverflow)
+	SetConfigMap(map[string]string{CMResourceOverflow: "unknown"})
+	assert.Equal(t, resources.GetOverflowMode(), resources.SaturateOverflow)
+	SetConfigMap(map[string]string{CMResourceOverflow: "error"})
+	SetConfigMap(nil)
+	assert.Equal(t, resources.GetOverflowMode(), resources.SaturateOverflow)
+}
@@ -21,8 +21,10 @@ package configs
 import (
 	"fmt"
 	"math"
+	"os"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -40,13 +42,32 @@ const (
 	DOT              = "."
 	DotReplace       = "_dot_"
 	DefaultPartition = "default"
+	ACLFilePrefix    = "@"
 
 	ApplicationSortPolicy   = "application.sort.policy"
 	ApplicationSortPriority = "application.sort.priority"
+	ApplicationSortTieBreak = "application.sort.tiebreak"
 	PriorityPolicy          = "priority.policy"
 	PriorityOffset          = "priority.offset"
 	PreemptionPolicy        = "preemption.policy"
 	PreemptionDelay         = "preemption.delay"
+	ACLEnforcement          = "acl.enforcement"
+	CompletedAppRetention   = "application.completed.retention"
+	NodeAffinityWindow      = "application.node.affinity.window"
+	AppAttemptBudget        = "application.attempt.budget"
+	ResourceComparator      = "resource.comparator"
+	NodeSelectionPolicy     = "node.selection.policy"
+	NodeSelectionResource   = "node.selection.resource"
+	AskSizeEnforcement      = "ask.size.enforcement"
+	GroupLimitCharge        = "group.limit.charge"
+	FairShareFloor          = "fairshare.floor"
+	AllocationLogSample     = "allocation.log.sample"
+	SchedulingWeight        = "scheduling.weight"
+	WeightHalfLife          = "scheduling.weight.halflife"
+
+	// queue info length limits
+	QueueInfoMaxLength        = 256
+	QueueDescriptionMaxLength = 1024
 
 	// app sort priority values
 	ApplicationSortPriorityEnabled  = "enabled"
@@ -82,6 +103,18 @@ var GroupRegExp = regexp.MustCompile(`^[_a-zA-Z][a-zA-Z0-9:_.-]*$`)
 // all characters that make a name different from a regexp
 var SpecialRegExp = regexp.MustCompile(`[\^$*+?()\[{}|]`)
 
+// FilterExpDelimiter marks a user or group entry in a placement rule filter as a regular expression: /expression/
+const FilterExpDelimiter = "/"
+
+// GetFilterExp returns the expression and true if the placement rule filter entry is a slash delimited regular
+// expression. The entry is returned unchanged with false for all other entries.
+func GetFilterExp(entry string) (string, bool) {
+	if len(entry) > 2 && strings.HasPrefix(entry, FilterExpDelimiter) && strings.HasSuffix(entry, FilterExpDelimiter) {
+		return entry[1 : len(entry)-1], true
+	}
+	return entry, false
+}
+
 // The rule maps to a go identifier check that regexp only
 var RuleNameRegExp = regexp.MustCompile(`^[_a-zA-Z][a-zA-Z0-9_]*$`)
 
@@ -93,8 +126,31 @@ type placementStaticPath struct {
 	ruleNo         int
 }
 
+// ResolveACL returns the ACL definition to parse. An ACL that starts with the ACLFilePrefix references a file:
+// the trimmed content of that file is returned. Any other ACL is returned unchanged.
+func ResolveACL(acl string) (string, error) {
+	trimmed := strings.TrimSpace(acl)
+	if !strings.HasPrefix(trimmed, ACLFilePrefix) {
+		return acl, nil
+	}
+	path := strings.TrimSpace(strings.TrimPrefix(trimmed, ACLFilePrefix))
+	if path == "" {
+		return "", fmt.Errorf("ACL file reference without a path: '%s'", acl)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read ACL file '%s': %w", path, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
 // Check the ACL
 func checkACL(acl string) error {
+	var err error
+	acl, err = ResolveACL(acl)
+	if err != nil {
+		return err
+	}
 	// trim any white space
 	acl = strings.TrimSpace(acl)
 	// handle special cases: deny and wildcard
@@ -110,7 +166,10 @@ func checkACL(acl string) error {
 	return nil
 }
 
-func checkQueueResource(cur QueueConfig, parentM *resources.Resource) (*resources.Resource, error) {
+// checkQueueResource checks the resources of the queue and its children. The guaranteed percentages are resolved
+// against the base of the parent: the resolved and the absolute guaranteed resources of the children together must fit
+// in the guaranteed or maximum resource of the queue.
+func checkQueueResource(cur QueueConfig, parentM, parentBase *resources.Resource) (*resources.Resource, error) {
 	curG, curM, err := checkResourceConfig(cur)
 	if err != nil {
 		return nil, err
@@ -119,10 +178,24 @@ func checkQueueResource(cur QueueConfig, parentM *resources.Resource) (*resource
 		return nil, fmt.Errorf("max resource of parent %s is smaller than maximum resource %s for queue %s", parentM.String(), curM.String(), cur.Name)
 	}
 	curM = resources.ComponentWiseMin(curM, parentM)
+	if err = checkGuaranteedPercentage(cur); err != nil {
+		return nil, err
+	}
+	curG = resolveGuaranteedConf(cur, curG, curM, parentBase)
+	// base for the percentages of the children: the guaranteed resource completed with the maximum resource
+	base := resources.NewResource()
+	if curM != nil {
+		base = curM.Clone()
+	}
+	if curG != nil {
+		for name, value := range curG.Resources {
+			base.Resources[name] = value
+		}
+	}
 	sumG := resources.NewResource()
 	for _, child := range cur.Queues {
 		var childG *resources.Resource
-		childG, err = checkQueueResource(child, curM)
+		childG, err = checkQueueResource(child, curM, base)
 		if err != nil {
 			return nil, err
 		}
@@ -140,6 +213,51 @@ func checkQueueResource(cur QueueConfig, parentM *resources.Resource) (*resource
 	return curG, nil
 }
 
+// resolveGuaranteedConf returns the guaranteed resource of the queue with the percentages resolved against the base of
+// the parent and capped at the maximum resource of the queue, as done when the queue is created. The guaranteed
+// resource is returned unchanged if there is no base or no percentage.
+func resolveGuaranteedConf(cur QueueConfig, guaranteed, maxResource, base *resources.Resource) *resources.Resource {
+	_, percentages, err := resources.NewResourceFromConfWithPercentage(cur.Resources.Guaranteed)
+	if err != nil || len(percentages) == 0 || base == nil {
+		return guaranteed
+	}
+	resolved := resources.NewResource()
+	if guaranteed != nil {
+		resolved = guaranteed.Clone()
+	}
+	for name, value := range resources.ResolvePercentage(percentages, base).Resources {
+		resolved.Resources[name] = value
+	}
+	if maxResource == nil {
+		return resolved
+	}
+	for name, value := range resolved.Resources {
+		if limit, ok := maxResource.Resources[name]; ok && value > limit {
+			resolved.Resources[name] = limit
+		}
+	}
+	return resolved
+}
+
+// checkGuaranteedPercentage checks that the guaranteed percentages of the children of the queue do not add up to
+// more than 100% for a resource type.
+func checkGuaranteedPercentage(cur QueueConfig) error {
+	sum := make(map[string]float64)
+	for _, child := range cur.Queues {
+		_, percentages, err := resources.NewResourceFromConfWithPercentage(child.Resources.Guaranteed)
+		if err != nil {
+			return err
+		}
+		for name, percentage := range percentages {
+			sum[name] += percentage
+			if sum[name] > 100 {
+				return fmt.Errorf("guaranteed percentages of the children of queue %s add up to more than 100%% for resource %s", cur.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
 func checkLimitResource(cur QueueConfig, users map[string]map[string]*resources.Resource, groups map[string]map[string]*resources.Resource, queuePath string) error {
 	var curQueuePath string
 	if cur.Name == RootQueue {
@@ -299,7 +417,8 @@ func checkLimitMaxApplications(cur QueueConfig, users map[string]map[string]uint
 func checkResourceConfig(cur QueueConfig) (*resources.Resource, *resources.Resource, error) {
 	var g, m *resources.Resource
 	var err error
-	g, err = resources.NewResourceFromConf(cur.Resources.Guaranteed)
+	// percentages are resolved against the parent when the queue is created and cannot be checked here
+	g, _, err = resources.NewResourceFromConfWithPercentage(cur.Resources.Guaranteed)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -310,9 +429,36 @@ func checkResourceConfig(cur QueueConfig) (*resources.Resource, *resources.Resou
 	if !m.FitInMaxUndef(g) {
 		return nil, nil, fmt.Errorf("guaranteed resource %s is larger than maximum resource %s for queue %s", g.String(), m.String(), cur.Name)
 	}
+	if err = checkQuotaConfig(cur); err != nil {
+		return nil, nil, err
+	}
 	return g, m, nil
 }
 
+// checkQuotaConfig checks the quota and burst of the queue: the burst can only be set for resource types with a quota
+// and must not be smaller than the quota.
+func checkQuotaConfig(cur QueueConfig) error {
+	quota, err := resources.NewResourceFromConf(cur.Resources.Quota)
+	if err != nil {
+		return err
+	}
+	var burst *resources.Resource
+	burst, err = resources.NewResourceFromConf(cur.Resources.Burst)
+	if err != nil {
+		return err
+	}
+	for name, value := range burst.Resources {
+		limit, ok := quota.Resources[name]
+		if !ok {
+			return fmt.Errorf("burst resource %s set without quota for queue %s", name, cur.Name)
+		}
+		if value < limit {
+			return fmt.Errorf("burst resource %s is smaller than quota resource %s for queue %s", burst.String(), quota.String(), cur.Name)
+		}
+	}
+	return nil
+}
+
 // Check the placement rules for correctness
 func checkPlacementRules(partition *PartitionConfig) error {
 	// return if nothing defined
@@ -357,6 +503,82 @@ func checkPlacementRules(partition *PartitionConfig) error {
 	return nil
 }
 
+// CheckPlacementReferences cross checks the queues referenced by the fixed rules in the placement rule chains of the
+// partition against the configured queue tree. A diagnostic message is returned for every rule chain that references
+// a queue that does not exist and is not created by the rule, create set to false: applications are never placed by
+// that rule. The references are checked from the first rule in the chain, the parent, up to the first rule that is
+// not a fixed rule. The rules of a group are checked as top level rules, using the position of the group.
+// The diagnostics are logged as part of the validation of the configuration, they do not fail the validation.
+func CheckPlacementReferences(partition *PartitionConfig) []string {
+	var diagnostics []string
+	var check func(rules []PlacementRule, ruleNo int)
+	check = func(rules []PlacementRule, ruleNo int) {
+		for i, rule := range rules {
+			if ruleNo >= 0 {
+				i = ruleNo
+			}
+			if rule.Name == types.Group {
+				check(rule.Rules, i)
+				continue
+			}
+			if diagnostic := checkRuleChainReferences(rule, i, partition.Queues); diagnostic != "" {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+	}
+	check(partition.PlacementRules, -1)
+	return diagnostics
+}
+
+// checkRuleChainReferences returns a diagnostic message for the first queue referenced by a fixed rule in the chain
+// that does not exist and is not created by that rule. An empty string is returned if all references are valid.
+func checkRuleChainReferences(rule PlacementRule, ruleNo int, conf []QueueConfig) string {
+	rules := getRuleChain(rule)
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.Name
+	}
+	ruleChain := strings.Join(names, "->")
+	queuePath := ""
+	for _, r := range rules {
+		if r.Name != types.Fixed {
+			return ""
+		}
+		queueName := r.Value
+		if r.QueuePrefix != "" || r.QueueSuffix != "" {
+			last := strings.LastIndex(queueName, DOT) + 1
+			queueName = queueName[:last] + r.QueuePrefix + queueName[last:] + r.QueueSuffix
+		}
+		switch {
+		case strings.HasPrefix(queueName, RootQueue):
+			queuePath = queueName
+		case queuePath == "":
+			queuePath = RootQueue + DOT + queueName
+		default:
+			queuePath = queuePath + DOT + queueName
+		}
+		if !r.Create && !queueConfigExists(strings.Split(queuePath, DOT), conf) {
+			return fmt.Sprintf("placement rule no. #%d (%s) references queue %s which does not exist and is not created",
+				ruleNo, ruleChain, queuePath)
+		}
+	}
+	return ""
+}
+
+// queueConfigExists returns true if the queue path, starting at the root, is part of the configured queue tree.
+func queueConfigExists(path []string, conf []QueueConfig) bool {
+	for _, queue := range conf {
+		if !strings.EqualFold(queue.Name, path[0]) {
+			continue
+		}
+		if len(path) == 1 {
+			return true
+		}
+		return queueConfigExists(path[1:], queue.Queues)
+	}
+	return false
+}
+
 func checkQueueHierarchyForPlacement(path []string, create, hasDynamicPart bool, conf []QueueConfig, parentConf *QueueConfig) (placementPathCheckResult, string) {
 	queueName := path[0]
 	lastQueueName := ""
@@ -430,6 +652,21 @@ func checkPlacementRule(rule PlacementRule) error {
 			return err
 		}
 	}
+	// check the rules of a group
+	for _, subRule := range rule.Rules {
+		if err := checkPlacementRule(subRule); err != nil {
+			log.Log(log.Config).Debug("group placement rule failed",
+				zap.String("rule", rule.Name),
+				zap.String("groupRule", subRule.Name))
+			return err
+		}
+	}
+	// the static segments must form a valid queue name without the dynamic part
+	for _, affix := range []string{rule.QueuePrefix, rule.QueueSuffix} {
+		if affix != "" && !QueueNameRegExp.MatchString(affix) {
+			return fmt.Errorf("invalid queue prefix or suffix %s in rule %s", affix, rule.Name)
+		}
+	}
 	// check filter if given
 	if err := checkPlacementFilter(rule.Filter); err != nil {
 		log.Log(log.Config).Debug("placement rule filter failed",
@@ -452,10 +689,12 @@ func checkPlacementFilter(filter Filter) error {
 	}
 	// check users and groups: as long as we have 1 good entry we accept it and continue
 	// anything that does not parse in a list of users is ignored (like ACL list)
+	// slash delimited expressions that do not compile are ignored when the filter is created
 	if len(filter.Users) == 1 {
 		// for a length of 1 we could either have regexp or username
 		user := filter.Users[0]
-		isUser := UserRegExp.MatchString(user)
+		_, isExp := GetFilterExp(user)
+		isUser := isExp || UserRegExp.MatchString(user)
 		// if it is not a user name it must be a regexp
 		// two step check: first compile if that fails it is
 		if !isUser {
@@ -467,7 +706,8 @@ func checkPlacementFilter(filter Filter) error {
 	if len(filter.Groups) == 1 {
 		// for a length of 1 we could either have regexp or groupname
 		group := filter.Groups[0]
-		isGroup := GroupRegExp.MatchString(group)
+		_, isExp := GetFilterExp(group)
+		isGroup := isExp || GroupRegExp.MatchString(group)
 		// if it is not a group name it must be a regexp
 		if !isGroup {
 			if _, err := regexp.Compile(group); err != nil || !SpecialRegExp.MatchString(group) {
@@ -630,6 +870,253 @@ func checkNodeSortingPolicy(partition *PartitionConfig) error {
 	return nil
 }
 
+// Check the overcommit ratios: a ratio can never lower the node capacity
+func checkOvercommit(partition *PartitionConfig) error {
+	for k, v := range partition.Overcommit {
+		if v < float64(1) {
+			return fmt.Errorf("overcommit ratio for %s must be at least 1, got %v", k, v)
+		}
+	}
+	return nil
+}
+
+// Check the allocation granularity: each granularity must be a valid positive quantity
+func checkGranularity(partition *PartitionConfig) error {
+	granularity, err := resources.NewResourceFromConf(partition.Granularity)
+	if err != nil {
+		return fmt.Errorf("invalid granularity: %w", err)
+	}
+	for k, v := range granularity.Resources {
+		if v <= 0 {
+			return fmt.Errorf("granularity for %s must be positive, got %s", k, partition.Granularity[k])
+		}
+	}
+	return nil
+}
+
+// checkPreemption validates the preemption cooldown, grace period and quota window, if set, are valid non negative
+// durations and the quota is a valid resource.
+func checkPreemption(partition *PartitionConfig) error {
+	if err := checkPreemptionDuration(partition.Preemption.Cooldown, "cooldown"); err != nil {
+		return err
+	}
+	if err := checkPreemptionDuration(partition.Preemption.GracePeriod, "grace period"); err != nil {
+		return err
+	}
+	if err := checkPreemptionDuration(partition.Preemption.QuotaWindow, "quota window"); err != nil {
+		return err
+	}
+	if _, err := resources.NewResourceFromConf(partition.Preemption.Quota); err != nil {
+		return fmt.Errorf("invalid preemption quota: %w", err)
+	}
+	return nil
+}
+
+func checkPreemptionDuration(value, kind string) error {
+	if value == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid preemption %s %s: %w", kind, value, err)
+	}
+	if duration < 0 {
+		return fmt.Errorf("preemption %s must not be negative, got %s", kind, value)
+	}
+	return nil
+}
+
+// checkSandbox validates the sandbox queue, if set, is the fully qualified name of a leaf queue defined in the
+// partition. Trusted resource managers can only be listed when the sandbox queue is set.
+func checkSandbox(partition *PartitionConfig) error {
+	sandbox := partition.Sandbox.Queue
+	if sandbox == "" {
+		if len(partition.Sandbox.TrustedRMs) != 0 {
+			return fmt.Errorf("sandbox queue must be set when trusted resource managers are configured")
+		}
+		return nil
+	}
+	return checkLeafQueuePath(partition, sandbox, "sandbox")
+}
+
+// checkZeroRequest validates the zero request policy and that the queue is set, for the route policy only, to the
+// fully qualified name of a leaf queue defined in the partition.
+func checkZeroRequest(partition *PartitionConfig) error {
+	policy, err := policies.ZeroRequestPolicyFromString(partition.ZeroRequest.Policy)
+	if err != nil {
+		return err
+	}
+	queue := partition.ZeroRequest.Queue
+	if policy != policies.RouteZeroRequestPolicy {
+		if queue != "" {
+			return fmt.Errorf("zero request queue %s can only be set for the %s policy", queue, policies.RouteZeroRequestPolicy)
+		}
+		return nil
+	}
+	if queue == "" {
+		return fmt.Errorf("zero request queue must be set for the %s policy", policies.RouteZeroRequestPolicy)
+	}
+	return checkLeafQueuePath(partition, queue, "zero request")
+}
+
+// checkParentPlacement validates the handling of applications placed in a parent queue.
+func checkParentPlacement(partition *PartitionConfig) error {
+	_, err := policies.ParentPlacementPolicyFromString(partition.ParentPlacement)
+	return err
+}
+
+// checkSchedulingMode validates the scheduling mode of the partition.
+func checkSchedulingMode(partition *PartitionConfig) error {
+	_, err := policies.SchedulingModePolicyFromString(partition.SchedulingMode)
+	return err
+}
+
+// checkCordon validates the fair share policy for cordoned nodes.
+func checkCordon(partition *PartitionConfig) error {
+	_, err := policies.CordonFairSharePolicyFromString(partition.Cordon.FairShare)
+	return err
+}
+
+// checkMinRequest validates the minimum request policy and that each minimum is a valid quantity.
+func checkMinRequest(partition *PartitionConfig) error {
+	if _, err := policies.MinRequestPolicyFromString(partition.MinRequest.Policy); err != nil {
+		return err
+	}
+	if _, err := resources.NewResourceFromConf(partition.MinRequest.Resources); err != nil {
+		return fmt.Errorf("invalid minimum request: %w", err)
+	}
+	return nil
+}
+
+// checkBurstPool validates the burst pool capacity and that the refill is a non negative duration.
+func checkBurstPool(partition *PartitionConfig) error {
+	if _, err := resources.NewResourceFromConf(partition.BurstPool.Capacity); err != nil {
+		return fmt.Errorf("invalid burst pool capacity: %w", err)
+	}
+	if partition.BurstPool.Refill == "" {
+		return nil
+	}
+	refill, err := time.ParseDuration(partition.BurstPool.Refill)
+	if err != nil {
+		return fmt.Errorf("invalid burst pool refill %s: %w", partition.BurstPool.Refill, err)
+	}
+	if refill < 0 {
+		return fmt.Errorf("burst pool refill must not be negative, got %s", partition.BurstPool.Refill)
+	}
+	return nil
+}
+
+// checkNodeOverhead validates the reserved node overhead and each override are valid resources. An override must
+// have at least one label to match nodes on.
+func checkNodeOverhead(partition *PartitionConfig) error {
+	if _, err := resources.NewResourceFromConf(partition.NodeOverhead.Reserved); err != nil {
+		return fmt.Errorf("invalid node overhead: %w", err)
+	}
+	for i, override := range partition.NodeOverhead.Overrides {
+		if len(override.Labels) == 0 {
+			return fmt.Errorf("node overhead override %d must have at least one label", i)
+		}
+		for k := range override.Labels {
+			if k == "" {
+				return fmt.Errorf("node overhead override %d has an empty label name", i)
+			}
+		}
+		if _, err := resources.NewResourceFromConf(override.Reserved); err != nil {
+			return fmt.Errorf("invalid node overhead override %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkLeafQueuePath validates the path is the fully qualified name of a leaf queue defined in the partition.
+// The kind describes the use of the queue in the error returned.
+func checkLeafQueuePath(partition *PartitionConfig, path string, kind string) error {
+	parts := strings.Split(path, DOT)
+	if len(parts) < 2 || parts[0] != RootQueue {
+		return fmt.Errorf("%s queue %s must be a fully qualified queue name", kind, path)
+	}
+	// the root queue is always present when the structure has been checked
+	queue := &partition.Queues[0]
+	for _, name := range parts[1:] {
+		var child *QueueConfig
+		for i := range queue.Queues {
+			if queue.Queues[i].Name == name {
+				child = &queue.Queues[i]
+				break
+			}
+		}
+		if child == nil {
+			return fmt.Errorf("%s queue %s is not defined in the partition", kind, path)
+		}
+		queue = child
+	}
+	if queue.Parent {
+		return fmt.Errorf("%s queue %s must be a leaf queue", kind, path)
+	}
+	return nil
+}
+
+// checkPriorityOffset validates the priority offset is an integer that fits the priority range. The minimum priority
+// is excluded as it marks a queue without pending requests.
+func checkPriorityOffset(value string) error {
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || offset <= int64(MinPriority) || offset > int64(MaxPriority) {
+		return fmt.Errorf("invalid %s %s: must be an integer between %d and %d", PriorityOffset, value, MinPriority+1, MaxPriority)
+	}
+	return nil
+}
+
+// checkFairShareFloor validates the fair share floor is a fraction larger than zero and at most one.
+func checkFairShareFloor(value string) error {
+	floor, err := strconv.ParseFloat(value, 64)
+	if err != nil || floor <= 0 || floor > 1 {
+		return fmt.Errorf("invalid %s %s: must be a number larger than 0 and at most 1", FairShareFloor, value)
+	}
+	return nil
+}
+
+// checkAllocationLogSample validates the allocation log sample is a positive integer: one in that many allocations is
+// logged.
+func checkAllocationLogSample(value string) error {
+	sample, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || sample == 0 {
+		return fmt.Errorf("invalid %s %s: must be a positive integer", AllocationLogSample, value)
+	}
+	return nil
+}
+
+// checkSchedulingWeight validates the scheduling weight is a positive number.
+func checkSchedulingWeight(value string) error {
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || weight <= 0 {
+		return fmt.Errorf("invalid %s %s: must be a positive number", SchedulingWeight, value)
+	}
+	return nil
+}
+
+// checkWeightHalfLife validates the half life of the scheduling weight decay is a non negative duration.
+func checkWeightHalfLife(value string) error {
+	halfLife, err := time.ParseDuration(value)
+	if err != nil || halfLife < 0 {
+		return fmt.Errorf("invalid %s %s: must be a non negative duration", WeightHalfLife, value)
+	}
+	return nil
+}
+
+// checkQueueInfo checks the length of the descriptive metadata of the queue.
+func checkQueueInfo(queue *QueueConfig) error {
+	if len(queue.Info.Owner) > QueueInfoMaxLength {
+		return fmt.Errorf("queue %s: owner is longer than %d characters", queue.Name, QueueInfoMaxLength)
+	}
+	if len(queue.Info.Team) > QueueInfoMaxLength {
+		return fmt.Errorf("queue %s: team is longer than %d characters", queue.Name, QueueInfoMaxLength)
+	}
+	if len(queue.Info.Description) > QueueDescriptionMaxLength {
+		return fmt.Errorf("queue %s: description is longer than %d characters", queue.Name, QueueDescriptionMaxLength)
+	}
+	return nil
+}
+
 // Check the queue names configured for compliance and uniqueness
 // - no duplicate names at each branched level in the tree
 // - queue name is alphanumeric (case ignore) with - and _
@@ -651,6 +1138,56 @@ func checkQueues(queue *QueueConfig, level int) error {
 		return err
 	}
 
+	// check the metadata is not too long (if defined)
+	if err = checkQueueInfo(queue); err != nil {
+		return err
+	}
+
+	// check the per group maximum (if defined)
+	if _, err = resources.NewResourceFromConf(queue.MaxResourcesPerGroup); err != nil {
+		return fmt.Errorf("queue %s: invalid max resources per group: %w", queue.Name, err)
+	}
+
+	// check the resource comparator is registered (if defined)
+	if name, ok := queue.Properties[ResourceComparator]; ok {
+		if _, err = resources.GetComparator(name); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+	}
+
+	// check the priority offset is in range (if defined)
+	if value, ok := queue.Properties[PriorityOffset]; ok {
+		if err = checkPriorityOffset(value); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+	}
+
+	// check the fair share floor is a valid fraction (if defined)
+	if value, ok := queue.Properties[FairShareFloor]; ok {
+		if err = checkFairShareFloor(value); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+	}
+
+	// check the allocation log sample is a positive integer (if defined)
+	if value, ok := queue.Properties[AllocationLogSample]; ok {
+		if err = checkAllocationLogSample(value); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+	}
+
+	// check the scheduling weight and its decay (if defined)
+	if value, ok := queue.Properties[SchedulingWeight]; ok {
+		if err = checkSchedulingWeight(value); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+	}
+	if value, ok := queue.Properties[WeightHalfLife]; ok {
+		if err = checkWeightHalfLife(value); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+	}
+
 	// check this level for name compliance and uniqueness
 	queueMap := make(map[string]bool)
 	for _, child := range queue.Queues {
@@ -682,6 +1219,29 @@ func IsQueueNameValid(queueName string) error {
 	return nil
 }
 
+// checkQueueCycles makes sure the queue hierarchy is a tree. A queue that is its own ancestor, for instance because the
+// child list of a queue shares its backing array with an ancestor, would make any walk of the hierarchy loop.
+// The path is the list of ancestors of the queues passed in.
+func checkQueueCycles(queues []QueueConfig, path []*QueueConfig) error {
+	for i := range queues {
+		queue := &queues[i]
+		for j, ancestor := range path {
+			if ancestor == queue {
+				names := make([]string, 0, len(path)-j+1)
+				for _, q := range path[j:] {
+					names = append(names, q.Name)
+				}
+				names = append(names, queue.Name)
+				return fmt.Errorf("queue hierarchy contains a cycle: %s", strings.Join(names, " -> "))
+			}
+		}
+		if err := checkQueueCycles(queue.Queues, append(path, queue)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Check the structure of the queue in the config:
 // - exactly 1 root queue, added if missing
 // - the parent flag is set on queues that are missing it
@@ -726,7 +1286,8 @@ func checkQueuesStructure(partition *PartitionConfig) error {
 	// check name uniqueness: we have a root to start with directly
 	var rootQueue = partition.Queues[0]
 	// special check for root resources: must not be set
-	if rootQueue.Resources.Guaranteed != nil || rootQueue.Resources.Max != nil {
+	if rootQueue.Resources.Guaranteed != nil || rootQueue.Resources.Max != nil ||
+		rootQueue.Resources.Quota != nil || rootQueue.Resources.Burst != nil {
 		return fmt.Errorf("root queue must not have resource limits set")
 	}
 	return nil
@@ -758,8 +1319,13 @@ func Validate(newConfig *SchedulerConfig) error {
 			return fmt.Errorf("duplicate partition name found with name %s", partition.Name)
 		}
 		partitionMap[strings.ToLower(partition.Name)] = true
+		// check for cycles before anything walks the hierarchy
+		err := checkQueueCycles(partition.Queues, nil)
+		if err != nil {
+			return err
+		}
 		// check the queue structure
-		err := checkQueuesStructure(&partition)
+		err = checkQueuesStructure(&partition)
 		if err != nil {
 			return err
 		}
@@ -771,7 +1337,7 @@ func Validate(newConfig *SchedulerConfig) error {
 		if err != nil {
 			return err
 		}
-		_, err = checkQueueResource(partition.Queues[0], nil)
+		_, err = checkQueueResource(partition.Queues[0], nil, nil)
 		if err != nil {
 			return err
 		}
@@ -779,10 +1345,64 @@ func Validate(newConfig *SchedulerConfig) error {
 		if err != nil {
 			return err
 		}
+		// references to queues that cannot be placed in do not fail the validation
+		for _, diagnostic := range CheckPlacementReferences(&partition) {
+			log.Log(log.Config).Warn("placement rule references an unusable queue",
+				zap.String("partitionName", partition.Name),
+				zap.String("diagnostic", diagnostic))
+		}
 		err = checkNodeSortingPolicy(&partition)
 		if err != nil {
 			return err
 		}
+		err = checkOvercommit(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkGranularity(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkPreemption(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkACL(partition.DefaultSubmitACL)
+		if err != nil {
+			return err
+		}
+		err = checkSandbox(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkZeroRequest(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkParentPlacement(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkSchedulingMode(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkCordon(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkMinRequest(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkBurstPool(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkNodeOverhead(&partition)
+		if err != nil {
+			return err
+		}
 
 		err = checkQueueMaxApplications(partition.Queues[0])
 		if err != nil {
@@ -809,6 +1429,18 @@ func getLongestPlacementPaths(rules []PlacementRule) ([]placementStaticPath, err
 	paths := make([]placementStaticPath, 0)
 
 	for i, rule := range rules {
+		// the rules of a group are checked as if they were top level rules, using the position of the group
+		if rule.Name == types.Group {
+			groupPaths, err := getLongestPlacementPaths(rule.Rules)
+			if err != nil {
+				return nil, err
+			}
+			for _, groupPath := range groupPaths {
+				groupPath.ruleNo = i
+				paths = append(paths, groupPath)
+			}
+			continue
+		}
 		path, ruleChain, hasDynamicPart, err := getLongestStaticPath(rule)
 		if err != nil {
 			return nil, err
@@ -851,6 +1483,10 @@ func getLongestStaticPath(rule PlacementRule) (staticPath, ruleChain string, fou
 		}
 
 		queueName := r.Value
+		if r.QueuePrefix != "" || r.QueueSuffix != "" {
+			last := strings.LastIndex(queueName, DOT) + 1
+			queueName = queueName[:last] + r.QueuePrefix + queueName[last:] + r.QueueSuffix
+		}
 		qualified := strings.HasPrefix(queueName, RootQueue)
 		if qualified {
 			if staticPath != "" {
@@ -185,10 +185,81 @@ func TestCheckResourceConfigurationsForQueue(t *testing.T) {
 			},
 			false,
 		},
+		{"Guaranteed percentages of children up to 100%",
+			QueueConfig{
+				Resources: Resources{
+					Max: higherResourceMap,
+				},
+				Queues: []QueueConfig{
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "60%"}}},
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "40%", "vcore": "100%"}}},
+				},
+			},
+			false,
+		},
+		{"Guaranteed percentages of children over 100%",
+			QueueConfig{
+				Resources: Resources{
+					Max: higherResourceMap,
+				},
+				Queues: []QueueConfig{
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "60%"}}},
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "50%"}}},
+				},
+			},
+			true,
+		},
+		{"Absolute and resolved guaranteed of children fit the parent",
+			QueueConfig{
+				Resources: Resources{
+					Guaranteed: map[string]string{"memory": "100"},
+					Max:        map[string]string{"memory": "200"},
+				},
+				Queues: []QueueConfig{
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "60"}}},
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "40%"}}},
+				},
+			},
+			false,
+		},
+		{"Absolute and resolved guaranteed of children over the parent",
+			QueueConfig{
+				Resources: Resources{
+					Guaranteed: map[string]string{"memory": "100"},
+					Max:        map[string]string{"memory": "200"},
+				},
+				Queues: []QueueConfig{
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "60"}}},
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "50%"}}},
+				},
+			},
+			true,
+		},
+		{"Resolved guaranteed of child capped at the child max",
+			QueueConfig{
+				Resources: Resources{
+					Guaranteed: map[string]string{"memory": "100"},
+					Max:        map[string]string{"memory": "200"},
+				},
+				Queues: []QueueConfig{
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "60"}}},
+					{Resources: Resources{Guaranteed: map[string]string{"memory": "50%"}, Max: map[string]string{"memory": "40"}}},
+				},
+			},
+			false,
+		},
+		{"Invalid guaranteed percentage",
+			QueueConfig{
+				Resources: Resources{
+					Guaranteed: map[string]string{"memory": "150%"},
+				},
+			},
+			true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := checkQueueResource(tc.current, nil)
+			_, err := checkQueueResource(tc.current, nil, nil)
 			if tc.errorExpected {
 				assert.Assert(t, err != nil, "An error is expected")
 			} else {
@@ -259,6 +330,12 @@ func TestGetLongestPlacementPath(t *testing.T) {
 	}
 	_, err = getLongestPlacementPaths(illegal)
 	assert.ErrorContains(t, err, "illegal fully qualified 'fixed' rule")
+
+	// the static suffix is part of the fixed queue name
+	staticPaths, err = getLongestPlacementPaths([]PlacementRule{{Name: "fixed", Value: "root.system", QueueSuffix: "-apps"}})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(staticPaths))
+	assert.Equal(t, "root.system-apps", staticPaths[0].path)
 }
 
 func TestCheckQueueHierarchyForPlacement(t *testing.T) {
@@ -442,6 +519,25 @@ func TestCheckPlacementRule(t *testing.T) {
 			expected: fmt.Errorf("invalid rule filter group list"),
 			message:  "invalid rule filter group list",
 		},
+		{
+			rule: PlacementRule{
+				Name:        "tag",
+				Value:       "namespace",
+				QueuePrefix: "ns-",
+				QueueSuffix: "-apps",
+			},
+			expected: nil,
+			message:  "valid queue prefix and suffix",
+		},
+		{
+			rule: PlacementRule{
+				Name:        "tag",
+				Value:       "namespace",
+				QueueSuffix: ".apps",
+			},
+			expected: fmt.Errorf("invalid queue prefix or suffix .apps in rule tag"),
+			message:  "invalid queue suffix with dot",
+		},
 	}
 
 	for _, tc := range tests {
@@ -492,6 +588,20 @@ func TestCheckPlacementRules(t *testing.T) {
 	err = checkPlacementRules(conf)
 	assert.ErrorContains(t, err, "placement rule no. #0 (fixed) references non-existing queues (root.default.leaf) which cannot be created because the last queue (default) in the hierarchy is a leaf")
 
+	// the rules of a group are checked using the position of the group
+	conf.PlacementRules = []PlacementRule{
+		{Name: "provided"},
+		{
+			Name:  "group",
+			Rules: []PlacementRule{{Name: "user"}, {Name: "fixed", Value: "root.users"}},
+		},
+	}
+	err = checkPlacementRules(conf)
+	assert.ErrorContains(t, err, "placement rule no. #1 (fixed) references a queue (root.users) which is not a leaf")
+	conf.PlacementRules[1].Rules[1].Name = "in valid"
+	err = checkPlacementRules(conf)
+	assert.ErrorContains(t, err, "invalid rule name in valid")
+
 	// two "fixed" rule in a chain with both having fully qualified queues
 	conf.PlacementRules = []PlacementRule{
 		{
@@ -507,6 +617,54 @@ func TestCheckPlacementRules(t *testing.T) {
 	assert.ErrorContains(t, err, "illegal fully qualified 'fixed' rule with value root.default.leaf")
 }
 
+func TestCheckPlacementReferences(t *testing.T) {
+	conf := &PartitionConfig{
+		PlacementRules: createPlacementRules(),
+		Queues:         createQueueConfig(),
+	}
+
+	// default case, all references exist or are created
+	assert.Assert(t, len(CheckPlacementReferences(conf)) == 0, "no diagnostics expected")
+
+	// referencing "root.admins.dev" which doesn't exist and 'create' is false
+	conf.PlacementRules[1].Create = false
+	assert.DeepEqual(t, CheckPlacementReferences(conf), []string{
+		"placement rule no. #1 (fixed->fixed) references queue root.admins.dev which does not exist and is not created",
+	})
+
+	// a non-existing parent is not created by the dynamic rule below it, queue names are not case sensitive
+	conf.PlacementRules = []PlacementRule{
+		{Name: "user", Create: true, Parent: &PlacementRule{Name: "fixed", Value: "root.missing"}},
+		{Name: "user", Create: true, Parent: &PlacementRule{Name: "fixed", Value: "Devs.Yunikorn"}},
+		{Name: "fixed", Value: "leaf", Parent: &PlacementRule{Name: "fixed", Value: "root.created", Create: true}},
+		{Name: "provided", Parent: &PlacementRule{Name: "user", Parent: &PlacementRule{Name: "fixed", Value: "root.unknown"}}},
+	}
+	assert.DeepEqual(t, CheckPlacementReferences(conf), []string{
+		"placement rule no. #0 (fixed->user) references queue root.missing which does not exist and is not created",
+		"placement rule no. #2 (fixed->fixed) references queue root.created.leaf which does not exist and is not created",
+		"placement rule no. #3 (fixed->user->provided) references queue root.unknown which does not exist and is not created",
+	})
+
+	// the rules of a group are checked using the position of the group
+	conf.PlacementRules = []PlacementRule{
+		{Name: "provided"},
+		{
+			Name:  "group",
+			Rules: []PlacementRule{{Name: "user"}, {Name: "fixed", Value: "root.nothere"}, {Name: "fixed", Value: "default"}},
+		},
+	}
+	assert.DeepEqual(t, CheckPlacementReferences(conf), []string{
+		"placement rule no. #1 (fixed) references queue root.nothere which does not exist and is not created",
+	})
+
+	// the diagnostics are logged by the validation, they do not fail it
+	conf.PlacementRules = []PlacementRule{
+		{Name: "user", Create: true, Parent: &PlacementRule{Name: "fixed", Value: "root.missing"}},
+	}
+	assert.Equal(t, len(CheckPlacementReferences(conf)), 1, "diagnostic expected")
+	assert.NilError(t, Validate(&SchedulerConfig{Partitions: []PartitionConfig{*conf}}), "unusable references should not fail the validation")
+}
+
 func createQueueConfig() []QueueConfig {
 	return []QueueConfig{
 		{
@@ -721,6 +879,29 @@ func createQueueWithMaxApplication(maxApplication [4]uint64) QueueConfig {
 	return root
 }
 
+func TestGetFilterExp(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  string
+		isExp bool
+	}{
+		{"/^dev-.*$/", "^dev-.*$", true},
+		{"/user/", "user", true},
+		{"user", "user", false},
+		{"/", "/", false},
+		{"//", "//", false},
+		{"/user", "/user", false},
+		{"user/", "user/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got, isExp := GetFilterExp(tt.entry)
+			assert.Equal(t, got, tt.want)
+			assert.Equal(t, isExp, tt.isExp)
+		})
+	}
+}
+
 func TestUserName(t *testing.T) {
 	allowedUserNames := []string{
 		"username-allowed_99",
@@ -729,6 +910,7 @@ func TestUserName(t *testing.T) {
 		"user_name",
 		"user@name@",
 		"username$$",
+		"/^dev-[a-z]+$/",
 	}
 	for _, allowed := range allowedUserNames {
 		t.Run(allowed, func(t *testing.T) {
@@ -765,6 +947,7 @@ func TestGroupName(t *testing.T) {
 		"groupname*regexp",
 		"group_name",
 		"group-name",
+		"/^team-.*$/",
 	}
 	for _, allowed := range allowedGroupNames {
 		t.Run(allowed, func(t *testing.T) {
@@ -2229,6 +2412,42 @@ func TestCheckQueues(t *testing.T) { //nolint:funlen
 			level:            0,
 			expectedErrorMsg: "multiple spaces found in ACL: 'submit group extra'",
 		},
+		{
+			name: "Invalid Priority Offset",
+			queue: &QueueConfig{
+				Name:       "validQueue",
+				Properties: map[string]string{PriorityOffset: "high"},
+			},
+			level:            0,
+			expectedErrorMsg: "queue validQueue: invalid priority.offset high",
+		},
+		{
+			name: "Priority Offset Out Of Range",
+			queue: &QueueConfig{
+				Name:       "validQueue",
+				Properties: map[string]string{PriorityOffset: "2147483648"},
+			},
+			level:            0,
+			expectedErrorMsg: "must be an integer between -2147483647 and 2147483647",
+		},
+		{
+			name: "Priority Offset Minimum Priority",
+			queue: &QueueConfig{
+				Name:       "validQueue",
+				Properties: map[string]string{PriorityOffset: "-2147483648"},
+			},
+			level:            0,
+			expectedErrorMsg: "invalid priority.offset -2147483648",
+		},
+		{
+			name: "Invalid Max Resources Per Group",
+			queue: &QueueConfig{
+				Name:                 "validQueue",
+				MaxResourcesPerGroup: map[string]string{"memory": "invalid"},
+			},
+			level:            0,
+			expectedErrorMsg: "queue validQueue: invalid max resources per group",
+		},
 		{
 			name: "Duplicate Child Queue Names",
 			queue: &QueueConfig{
@@ -2327,6 +2546,25 @@ func TestCheckQueues(t *testing.T) { //nolint:funlen
 				assert.Equal(t, 2, len(q.Queues), "Expected two queues")
 			},
 		},
+		{
+			name: "Valid Resource Comparator",
+			queue: &QueueConfig{
+				Name:       "root",
+				Properties: map[string]string{ResourceComparator: resources.TotalComparator},
+			},
+			level: 0,
+		},
+		{
+			name: "Unknown Resource Comparator In Child",
+			queue: &QueueConfig{
+				Name: "root",
+				Queues: []QueueConfig{
+					{Name: "child", Properties: map[string]string{ResourceComparator: "weighted"}},
+				},
+			},
+			level:            0,
+			expectedErrorMsg: "queue child: undefined resource comparator: weighted",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -2416,6 +2654,148 @@ func TestCheckNodeSortingPolicy(t *testing.T) { //nolint:funlen
 	}
 }
 
+func TestCheckOvercommit(t *testing.T) {
+	testCases := []struct {
+		name             string
+		overcommit       map[string]float64
+		expectedErrorMsg string
+	}{
+		{"No ratios", nil, ""},
+		{"Valid ratios", map[string]float64{"vcore": 2.0, "memory": 1.0}, ""},
+		{"Ratio below one", map[string]float64{"memory": 0.5}, "overcommit ratio for memory must be at least 1"},
+		{"Negative ratio", map[string]float64{"vcore": -2.0}, "overcommit ratio for vcore must be at least 1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkOvercommit(&PartitionConfig{Overcommit: tc.overcommit})
+			if tc.expectedErrorMsg != "" {
+				assert.ErrorContains(t, err, tc.expectedErrorMsg, "Error message mismatch")
+			} else {
+				assert.NilError(t, err, "No error is expected")
+			}
+		})
+	}
+}
+
+func TestCheckPriorityOffset(t *testing.T) {
+	for _, value := range []string{"0", "-10", "100", "2147483647", "-2147483647"} {
+		assert.NilError(t, checkPriorityOffset(value), "offset %s should be valid", value)
+	}
+	for _, value := range []string{"", "1.5", "invalid", "2147483648", "-2147483648"} {
+		assert.ErrorContains(t, checkPriorityOffset(value), "invalid priority.offset", "offset %s should be invalid", value)
+	}
+}
+
+func TestCheckFairShareFloor(t *testing.T) {
+	for _, value := range []string{"1", "0.5", "0.01", "1e-3"} {
+		assert.NilError(t, checkFairShareFloor(value), "floor %s should be valid", value)
+	}
+	for _, value := range []string{"", "0", "-0.5", "1.5", "invalid"} {
+		assert.ErrorContains(t, checkFairShareFloor(value), "invalid fairshare.floor", "floor %s should be invalid", value)
+	}
+}
+
+func TestCheckAllocationLogSample(t *testing.T) {
+	for _, value := range []string{"1", "10", "1000"} {
+		assert.NilError(t, checkAllocationLogSample(value), "sample %s should be valid", value)
+	}
+	for _, value := range []string{"", "0", "-1", "0.5", "invalid"} {
+		assert.ErrorContains(t, checkAllocationLogSample(value), "invalid allocation.log.sample", "sample %s should be invalid", value)
+	}
+}
+
+func TestCheckSchedulingWeight(t *testing.T) {
+	for _, value := range []string{"1", "0.5", "10"} {
+		assert.NilError(t, checkSchedulingWeight(value), "weight %s should be valid", value)
+	}
+	for _, value := range []string{"", "0", "-1", "invalid"} {
+		assert.ErrorContains(t, checkSchedulingWeight(value), "invalid scheduling.weight", "weight %s should be invalid", value)
+	}
+	for _, value := range []string{"0s", "10m", "1h"} {
+		assert.NilError(t, checkWeightHalfLife(value), "half life %s should be valid", value)
+	}
+	for _, value := range []string{"", "-1m", "10", "invalid"} {
+		assert.ErrorContains(t, checkWeightHalfLife(value), "invalid scheduling.weight.halflife", "half life %s should be invalid", value)
+	}
+}
+
+func TestCheckSandbox(t *testing.T) {
+	queues := []QueueConfig{
+		{
+			Name:   "root",
+			Parent: true,
+			Queues: []QueueConfig{
+				{Name: "sandbox"},
+				{Name: "parent", Parent: true, Queues: []QueueConfig{{Name: "leaf"}}},
+			},
+		},
+	}
+	testCases := []struct {
+		name    string
+		sandbox PartitionSandboxConfig
+		errMsg  string
+	}{
+		{"Not set", PartitionSandboxConfig{}, ""},
+		{"Leaf queue", PartitionSandboxConfig{Queue: "root.sandbox", TrustedRMs: []string{"rm1"}}, ""},
+		{"Nested leaf queue", PartitionSandboxConfig{Queue: "root.parent.leaf"}, ""},
+		{"Trusted without queue", PartitionSandboxConfig{TrustedRMs: []string{"rm1"}}, "sandbox queue must be set"},
+		{"Not qualified", PartitionSandboxConfig{Queue: "sandbox"}, "must be a fully qualified queue name"},
+		{"Root queue", PartitionSandboxConfig{Queue: "root"}, "must be a fully qualified queue name"},
+		{"Unknown queue", PartitionSandboxConfig{Queue: "root.unknown"}, "is not defined in the partition"},
+		{"Parent queue", PartitionSandboxConfig{Queue: "root.parent"}, "must be a leaf queue"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkSandbox(&PartitionConfig{Queues: queues, Sandbox: tc.sandbox})
+			if tc.errMsg != "" {
+				assert.ErrorContains(t, err, tc.errMsg, "Error message mismatch")
+			} else {
+				assert.NilError(t, err, "No error is expected")
+			}
+		})
+	}
+}
+
+func TestCheckZeroRequest(t *testing.T) {
+	queues := []QueueConfig{
+		{
+			Name:   "root",
+			Parent: true,
+			Queues: []QueueConfig{
+				{Name: "empty"},
+				{Name: "parent", Parent: true, Queues: []QueueConfig{{Name: "leaf"}}},
+			},
+		},
+	}
+	testCases := []struct {
+		name        string
+		zeroRequest PartitionZeroRequestConfig
+		errMsg      string
+	}{
+		{"Not set", PartitionZeroRequestConfig{}, ""},
+		{"Accept", PartitionZeroRequestConfig{Policy: "accept"}, ""},
+		{"Reject", PartitionZeroRequestConfig{Policy: "reject"}, ""},
+		{"Route leaf queue", PartitionZeroRequestConfig{Policy: "route", Queue: "root.empty"}, ""},
+		{"Route nested leaf queue", PartitionZeroRequestConfig{Policy: "route", Queue: "root.parent.leaf"}, ""},
+		{"Unknown policy", PartitionZeroRequestConfig{Policy: "unknown"}, "undefined zero request policy"},
+		{"Queue without route", PartitionZeroRequestConfig{Policy: "reject", Queue: "root.empty"}, "can only be set for the route policy"},
+		{"Route without queue", PartitionZeroRequestConfig{Policy: "route"}, "zero request queue must be set"},
+		{"Not qualified", PartitionZeroRequestConfig{Policy: "route", Queue: "empty"}, "must be a fully qualified queue name"},
+		{"Unknown queue", PartitionZeroRequestConfig{Policy: "route", Queue: "root.unknown"}, "is not defined in the partition"},
+		{"Parent queue", PartitionZeroRequestConfig{Policy: "route", Queue: "root.parent"}, "must be a leaf queue"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkZeroRequest(&PartitionConfig{Queues: queues, ZeroRequest: tc.zeroRequest})
+			if tc.errMsg != "" {
+				assert.ErrorContains(t, err, tc.errMsg, "Error message mismatch")
+			} else {
+				assert.NilError(t, err, "No error is expected")
+			}
+		})
+	}
+}
+
 func TestIsQueueNameValid(t *testing.T) {
 	assert.NilError(t, IsQueueNameValid("parent_Child_test-a_b_#_c_#_d_/_e@dom:ain"))
 	err := IsQueueNameValid("invalid!queue")
@@ -2427,3 +2807,304 @@ func TestIsQueueNameValid(t *testing.T) {
 		t.Errorf("invalid queue name, validation should have failed. err is %v", err)
 	}
 }
+
+func TestCheckQueueCycles(t *testing.T) {
+	// a queue that lists itself as a child
+	self := make([]QueueConfig, 1)
+	self[0] = QueueConfig{Name: "self", Parent: true}
+	self[0].Queues = self
+	// two queues that list each other as a child
+	first := []QueueConfig{{Name: "first", Parent: true}}
+	second := []QueueConfig{{Name: "second", Parent: true, Queues: first}}
+	first[0].Queues = second
+
+	tests := []struct {
+		name   string
+		queues []QueueConfig
+		errMsg string
+	}{
+		{"tree", []QueueConfig{{Name: "root", Parent: true, Queues: []QueueConfig{{Name: "leaf1"}, {Name: "leaf2"}}}}, ""},
+		{"self cycle", []QueueConfig{{Name: "root", Parent: true, Queues: self}}, "queue hierarchy contains a cycle: self -> self"},
+		{"indirect cycle", []QueueConfig{{Name: "root", Parent: true, Queues: first}}, "queue hierarchy contains a cycle: first -> second -> first"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkQueueCycles(tc.queues, nil)
+			if tc.errMsg == "" {
+				assert.NilError(t, err, "tree should not have been rejected")
+			} else {
+				assert.Error(t, err, tc.errMsg)
+			}
+		})
+	}
+
+	// the config load rejects the cycle
+	conf := &SchedulerConfig{
+		Partitions: []PartitionConfig{{Name: "default", Queues: []QueueConfig{{Name: "root", Parent: true, Queues: first}}}},
+	}
+	assert.Error(t, Validate(conf), "queue hierarchy contains a cycle: first -> second -> first")
+
+	// a yaml alias cannot be used to create a cycle
+	data := `
+partitions:
+  - name: default
+    queues: &queues
+      - name: root
+        queues: *queues
+`
+	_, err := CreateConfig(data)
+	assert.ErrorContains(t, err, "anchor 'queues' value contains itself")
+}
+
+func TestCheckGranularity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		granularity      map[string]string
+		expectedErrorMsg string
+	}{
+		{"No granularity", nil, ""},
+		{"Valid granularity", map[string]string{"vcore": "100m", "memory": "1Mi"}, ""},
+		{"Invalid quantity", map[string]string{"memory": "invalid"}, "invalid granularity"},
+		{"Zero granularity", map[string]string{"vcore": "0"}, "granularity for vcore must be positive, got 0"},
+		{"Negative granularity", map[string]string{"memory": "-1"}, "invalid granularity"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkGranularity(&PartitionConfig{Granularity: tc.granularity})
+			if tc.expectedErrorMsg != "" {
+				assert.ErrorContains(t, err, tc.expectedErrorMsg, "Error message mismatch")
+			} else {
+				assert.NilError(t, err, "No error is expected")
+			}
+		})
+	}
+}
+
+func TestCheckMinRequest(t *testing.T) {
+	testCases := []struct {
+		name             string
+		minRequest       PartitionMinRequestConfig
+		expectedErrorMsg string
+	}{
+		{"Not set", PartitionMinRequestConfig{}, ""},
+		{"Valid minimum", PartitionMinRequestConfig{Resources: map[string]string{"vcore": "1m", "memory": "1Mi"}}, ""},
+		{"Valid reject policy", PartitionMinRequestConfig{Resources: map[string]string{"vcore": "1m"}, Policy: "reject"}, ""},
+		{"Invalid policy", PartitionMinRequestConfig{Policy: "ignore"}, "undefined minimum request policy: ignore"},
+		{"Invalid quantity", PartitionMinRequestConfig{Resources: map[string]string{"memory": "invalid"}}, "invalid minimum request"},
+		{"Negative quantity", PartitionMinRequestConfig{Resources: map[string]string{"vcore": "-1"}}, "invalid minimum request"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkMinRequest(&PartitionConfig{MinRequest: tc.minRequest})
+			if tc.expectedErrorMsg != "" {
+				assert.ErrorContains(t, err, tc.expectedErrorMsg, "Error message mismatch")
+			} else {
+				assert.NilError(t, err, "No error is expected")
+			}
+		})
+	}
+}
+
+func TestCheckPreemption(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cooldown string
+		errMsg   string
+	}{
+		{"Not set", "", ""},
+		{"Valid cooldown", "30s", ""},
+		{"Zero cooldown", "0s", ""},
+		{"Invalid cooldown", "soon", "invalid preemption cooldown soon"},
+		{"Negative cooldown", "-5s", "preemption cooldown must not be negative, got -5s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPreemption(&PartitionConfig{Preemption: PartitionPreemptionConfig{Cooldown: tc.cooldown}})
+			if tc.errMsg == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestCheckPreemptionGracePeriod(t *testing.T) {
+	testCases := []struct {
+		name   string
+		grace  string
+		errMsg string
+	}{
+		{"Not set", "", ""},
+		{"Valid grace period", "30s", ""},
+		{"Zero grace period", "0s", ""},
+		{"Invalid grace period", "later", "invalid preemption grace period later"},
+		{"Negative grace period", "-5s", "preemption grace period must not be negative, got -5s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPreemption(&PartitionConfig{Preemption: PartitionPreemptionConfig{GracePeriod: tc.grace}})
+			if tc.errMsg == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestCheckPreemptionQuota(t *testing.T) {
+	testCases := []struct {
+		name   string
+		quota  map[string]string
+		errMsg string
+	}{
+		{"Not set", nil, ""},
+		{"Valid quota", map[string]string{"vcore": "10", "memory": "1G"}, ""},
+		{"Invalid quota", map[string]string{"vcore": "many"}, "invalid preemption quota"},
+		{"Negative quota", map[string]string{"vcore": "-1"}, "invalid preemption quota"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPreemption(&PartitionConfig{Preemption: PartitionPreemptionConfig{Quota: tc.quota, QuotaWindow: "30s"}})
+			if tc.errMsg == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestCheckPreemptionQuotaWindow(t *testing.T) {
+	err := checkPreemption(&PartitionConfig{Preemption: PartitionPreemptionConfig{QuotaWindow: "invalid"}})
+	assert.ErrorContains(t, err, "invalid preemption quota window")
+	err = checkPreemption(&PartitionConfig{Preemption: PartitionPreemptionConfig{QuotaWindow: "-1s"}})
+	assert.ErrorContains(t, err, "preemption quota window must not be negative")
+}
+
+func TestCheckQuotaConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		quota  map[string]string
+		burst  map[string]string
+		errMsg string
+	}{
+		{"Not set", nil, nil, ""},
+		{"Quota only", map[string]string{"vcore": "10"}, nil, ""},
+		{"Quota and burst", map[string]string{"vcore": "10"}, map[string]string{"vcore": "20"}, ""},
+		{"Burst equal to quota", map[string]string{"vcore": "10"}, map[string]string{"vcore": "10"}, ""},
+		{"Invalid quota", map[string]string{"vcore": "invalid"}, nil, "invalid quantity"},
+		{"Invalid burst", map[string]string{"vcore": "10"}, map[string]string{"vcore": "invalid"}, "invalid quantity"},
+		{"Burst without quota", map[string]string{"vcore": "10"}, map[string]string{"memory": "10"}, "burst resource memory set without quota for queue leaf"},
+		{"Burst smaller than quota", map[string]string{"vcore": "10"}, map[string]string{"vcore": "5"}, "is smaller than quota resource"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkQuotaConfig(QueueConfig{Name: "leaf", Resources: Resources{Quota: tc.quota, Burst: tc.burst}})
+			if tc.errMsg == "" {
+				assert.NilError(t, err, "No error is expected")
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg, "Error message mismatch")
+			}
+		})
+	}
+}
+
+func TestCheckBurstPool(t *testing.T) {
+	testCases := []struct {
+		name      string
+		burstPool PartitionBurstPoolConfig
+		errMsg    string
+	}{
+		{"Not set", PartitionBurstPoolConfig{}, ""},
+		{"Valid pool", PartitionBurstPoolConfig{Capacity: map[string]string{"vcore": "10"}, Refill: "1m"}, ""},
+		{"No refill", PartitionBurstPoolConfig{Capacity: map[string]string{"vcore": "10"}}, ""},
+		{"Invalid capacity", PartitionBurstPoolConfig{Capacity: map[string]string{"vcore": "invalid"}}, "invalid burst pool capacity"},
+		{"Invalid refill", PartitionBurstPoolConfig{Refill: "soon"}, "invalid burst pool refill soon"},
+		{"Negative refill", PartitionBurstPoolConfig{Refill: "-5s"}, "burst pool refill must not be negative, got -5s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBurstPool(&PartitionConfig{BurstPool: tc.burstPool})
+			if tc.errMsg == "" {
+				assert.NilError(t, err, "No error is expected")
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg, "Error message mismatch")
+			}
+		})
+	}
+}
+
+func TestCheckParentPlacement(t *testing.T) {
+	for _, policy := range []string{"", "reject", "promote"} {
+		assert.NilError(t, checkParentPlacement(&PartitionConfig{ParentPlacement: policy}), "policy %s should be valid", policy)
+	}
+	assert.ErrorContains(t, checkParentPlacement(&PartitionConfig{ParentPlacement: "invalid"}), "undefined parent placement policy: invalid")
+}
+
+func TestCheckSchedulingMode(t *testing.T) {
+	for _, mode := range []string{"", "fair", "capacity"} {
+		assert.NilError(t, checkSchedulingMode(&PartitionConfig{SchedulingMode: mode}), "mode %s should be valid", mode)
+	}
+	assert.ErrorContains(t, checkSchedulingMode(&PartitionConfig{SchedulingMode: "invalid"}), "undefined scheduling mode: invalid")
+}
+
+func TestCheckCordon(t *testing.T) {
+	for _, policy := range []string{"", "keep", "exclude"} {
+		assert.NilError(t, checkCordon(&PartitionConfig{Cordon: PartitionCordonConfig{FairShare: policy}}), "policy %s should be valid", policy)
+	}
+	assert.ErrorContains(t, checkCordon(&PartitionConfig{Cordon: PartitionCordonConfig{FairShare: "invalid"}}), "undefined cordon fair share policy: invalid")
+}
+
+func TestCheckNodeOverhead(t *testing.T) {
+	testCases := []struct {
+		name     string
+		overhead NodeOverheadConfig
+		errMsg   string
+	}{
+		{"Not set", NodeOverheadConfig{}, ""},
+		{"Valid overhead", NodeOverheadConfig{Reserved: map[string]string{"vcore": "1", "memory": "1G"}}, ""},
+		{"Valid override", NodeOverheadConfig{Overrides: []NodeOverheadOverride{{Labels: map[string]string{"pool": "gpu"}, Reserved: map[string]string{"vcore": "2"}}}}, ""},
+		{"Invalid overhead", NodeOverheadConfig{Reserved: map[string]string{"vcore": "invalid"}}, "invalid node overhead"},
+		{"Override without labels", NodeOverheadConfig{Overrides: []NodeOverheadOverride{{Reserved: map[string]string{"vcore": "2"}}}}, "node overhead override 0 must have at least one label"},
+		{"Override empty label", NodeOverheadConfig{Overrides: []NodeOverheadOverride{{Labels: map[string]string{"": "gpu"}}}}, "node overhead override 0 has an empty label name"},
+		{"Invalid override", NodeOverheadConfig{Overrides: []NodeOverheadOverride{{Labels: map[string]string{"pool": "gpu"}, Reserved: map[string]string{"vcore": "-1"}}}}, "invalid node overhead override 0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkNodeOverhead(&PartitionConfig{NodeOverhead: tc.overhead})
+			if tc.errMsg == "" {
+				assert.NilError(t, err, "No error is expected")
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg, "Error message mismatch")
+			}
+		})
+	}
+}
+
+func TestCheckQueueInfo(t *testing.T) {
+	long := strings.Repeat("x", QueueInfoMaxLength+1)
+	testCases := []struct {
+		name   string
+		info   QueueInfo
+		errMsg string
+	}{
+		{"Not set", QueueInfo{}, ""},
+		{"All set", QueueInfo{Owner: "alice", Team: "team", Description: "description"}, ""},
+		{"Max length", QueueInfo{Owner: long[1:], Team: long[1:], Description: strings.Repeat("x", QueueDescriptionMaxLength)}, ""},
+		{"Owner too long", QueueInfo{Owner: long}, "queue leaf: owner is longer than 256 characters"},
+		{"Team too long", QueueInfo{Team: long}, "queue leaf: team is longer than 256 characters"},
+		{"Description too long", QueueInfo{Description: strings.Repeat("x", QueueDescriptionMaxLength+1)}, "queue leaf: description is longer than 1024 characters"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkQueueInfo(&QueueConfig{Name: "leaf", Info: tc.info})
+			if tc.errMsg == "" {
+				assert.NilError(t, err, "No error is expected")
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg, "Error message mismatch")
+			}
+		})
+	}
+}
@@ -29,4 +29,10 @@ const (
 	RecoveryQueue         = "@recovery@"
 	RecoveryQueueFull     = "root." + RecoveryQueue
 	DefaultPlacementQueue = "root.default"
+	AppTagDependsOn       = "application.dependson"
+	AppTagMinResource     = "application.minresource"
+	AppTagDeadline        = "application.deadline"
+	AppTagQueueOverride   = "yunikorn.apache.org/queue-override"
+
+	AllocTagMetadataPrefix = "yunikorn.apache.org/metadata/"
 )
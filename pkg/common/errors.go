@@ -39,5 +39,6 @@ const (
 	PreemptionDoesNotGuarantee    = "Preemption queue guarantees check failed"
 	PreemptionShortfall           = "Preemption helped but short of resources"
 	PreemptionDoesNotHelp         = "Preemption does not help"
+	PreemptionQuotaExhausted      = "Preemption quota exhausted"
 	NoVictimForRequiredNode       = "No fit on required node, preemption does not help"
 )
@@ -0,0 +1,150 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/apache/yunikorn-core/pkg/locking"
+)
+
+const (
+	DominantComparator = "dominant"
+	TotalComparator    = "total"
+)
+
+// ResourceComparator defines how the usage of two objects is compared when sorting based on fairness.
+// Both comparisons return the same values as compareShares does:
+// 0 for equal shares
+// 1 if the left share is larger
+// -1 if the right share is larger
+type ResourceComparator interface {
+	// CompUsageRatio compares the usage of left and right as a share of the same total.
+	CompUsageRatio(left, right, total *Resource) int
+	// CompUsageRatioSeparately compares the usage of left and right, each as a share of its own guaranteed
+	// resource or, if a type is not guaranteed, its fair max resource.
+	CompUsageRatioSeparately(leftAllocated, leftGuaranteed, leftFairMax, rightAllocated, rightGuaranteed, rightFairMax *Resource) int
+}
+
+var comparators = struct {
+	byName map[string]ResourceComparator
+	locking.RWMutex
+}{
+	byName: map[string]ResourceComparator{
+		DominantComparator: dominantComparator{},
+		TotalComparator:    totalComparator{},
+	},
+}
+
+// RegisterComparator adds a comparator that can be referenced by name from the configuration.
+// An existing comparator cannot be replaced.
+func RegisterComparator(name string, comparator ResourceComparator) error {
+	if name == "" || comparator == nil {
+		return fmt.Errorf("comparator name and implementation must be set")
+	}
+	comparators.Lock()
+	defer comparators.Unlock()
+	if _, ok := comparators.byName[name]; ok {
+		return fmt.Errorf("comparator %s is already registered", name)
+	}
+	comparators.byName[name] = comparator
+	return nil
+}
+
+// GetComparator returns the comparator registered for the name, an empty name returns the default comparator.
+// If the name is not registered the default comparator is returned with an error.
+func GetComparator(name string) (ResourceComparator, error) {
+	if name == "" {
+		return DefaultComparator(), nil
+	}
+	comparators.RLock()
+	defer comparators.RUnlock()
+	if comparator, ok := comparators.byName[name]; ok {
+		return comparator, nil
+	}
+	return DefaultComparator(), fmt.Errorf("undefined resource comparator: %s", name)
+}
+
+// DefaultComparator returns the comparator used when none is configured: the dominant resource share.
+func DefaultComparator() ResourceComparator {
+	return dominantComparator{}
+}
+
+// dominantComparator compares the largest share of all resource types.
+type dominantComparator struct{}
+
+func (dominantComparator) CompUsageRatio(left, right, total *Resource) int {
+	return CompUsageRatio(left, right, total)
+}
+
+func (dominantComparator) CompUsageRatioSeparately(leftAllocated, leftGuaranteed, leftFairMax, rightAllocated, rightGuaranteed, rightFairMax *Resource) int {
+	return CompUsageRatioSeparately(leftAllocated, leftGuaranteed, leftFairMax, rightAllocated, rightGuaranteed, rightFairMax)
+}
+
+// totalComparator compares the sum of the shares of all resource types.
+type totalComparator struct{}
+
+func (totalComparator) CompUsageRatio(left, right, total *Resource) int {
+	return compareFloat(sumShares(getShares(left, total)), sumShares(getShares(right, total)))
+}
+
+func (totalComparator) CompUsageRatioSeparately(leftAllocated, leftGuaranteed, leftFairMax, rightAllocated, rightGuaranteed, rightFairMax *Resource) int {
+	return compareFloat(getTotalFairShare(leftAllocated, leftGuaranteed, leftFairMax), getTotalFairShare(rightAllocated, rightGuaranteed, rightFairMax))
+}
+
+// getTotalFairShare returns the sum of the shares of the allocated resource types, using the same denominators as getFairShare.
+func getTotalFairShare(allocated, guaranteed, fair *Resource) float64 {
+	if allocated == nil {
+		return 0.0
+	}
+	var total float64
+	for k, v := range allocated.Resources {
+		// if usage <= 0, resource has no share
+		if v < 0 {
+			continue
+		}
+		share, found := getShareFairForDenominator(k, v, guaranteed)
+		if !found {
+			share, found = getShareFairForDenominator(k, v, fair)
+		}
+		if found {
+			total += share
+		}
+	}
+	return total
+}
+
+func sumShares(shares []float64) float64 {
+	var total float64
+	for _, share := range shares {
+		total += share
+	}
+	return total
+}
+
+func compareFloat(left, right float64) int {
+	switch {
+	case left > right:
+		return 1
+	case left < right:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type testComparator struct {
+	dominantComparator
+}
+
+func TestGetComparator(t *testing.T) {
+	comparator, err := GetComparator("")
+	assert.NilError(t, err, "empty name should return the default")
+	assert.Equal(t, comparator, DefaultComparator(), "unexpected comparator for empty name")
+	comparator, err = GetComparator(DominantComparator)
+	assert.NilError(t, err, "dominant comparator should be registered")
+	assert.Equal(t, comparator, DefaultComparator(), "dominant should be the default")
+	comparator, err = GetComparator(TotalComparator)
+	assert.NilError(t, err, "total comparator should be registered")
+	assert.Equal(t, comparator, ResourceComparator(totalComparator{}), "unexpected comparator for total")
+	comparator, err = GetComparator("unknown")
+	assert.ErrorContains(t, err, "undefined resource comparator: unknown")
+	assert.Equal(t, comparator, DefaultComparator(), "unknown name should return the default")
+}
+
+func TestRegisterComparator(t *testing.T) {
+	assert.ErrorContains(t, RegisterComparator("", testComparator{}), "must be set")
+	assert.ErrorContains(t, RegisterComparator("test", nil), "must be set")
+	assert.ErrorContains(t, RegisterComparator(DominantComparator, testComparator{}), "already registered")
+	assert.NilError(t, RegisterComparator("test", testComparator{}), "registration failed")
+	t.Cleanup(func() {
+		comparators.Lock()
+		defer comparators.Unlock()
+		delete(comparators.byName, "test")
+	})
+	comparator, err := GetComparator("test")
+	assert.NilError(t, err, "registered comparator not found")
+	assert.Equal(t, comparator, ResourceComparator(testComparator{}), "unexpected comparator returned")
+}
+
+func TestComparatorOrdering(t *testing.T) {
+	total := NewResourceFromMap(map[string]Quantity{"first": 10, "second": 10})
+	// largest share is larger on the left, sum of shares is larger on the right
+	left := NewResourceFromMap(map[string]Quantity{"first": 6})
+	right := NewResourceFromMap(map[string]Quantity{"first": 4, "second": 4})
+
+	dominant := DefaultComparator()
+	assert.Equal(t, dominant.CompUsageRatio(left, right, total), 1, "left should be larger for dominant")
+	assert.Equal(t, dominant.CompUsageRatioSeparately(left, nil, total, right, nil, total), 1, "left should be larger for dominant")
+	sum := totalComparator{}
+	assert.Equal(t, sum.CompUsageRatio(left, right, total), -1, "right should be larger for total")
+	assert.Equal(t, sum.CompUsageRatioSeparately(left, nil, total, right, nil, total), -1, "right should be larger for total")
+	// guaranteed is used before the fair max
+	guaranteed := NewResourceFromMap(map[string]Quantity{"first": 5})
+	assert.Equal(t, sum.CompUsageRatioSeparately(left, guaranteed, total, right, nil, total), 1, "left should be larger using guaranteed")
+	assert.Equal(t, sum.CompUsageRatio(nil, nil, total), 0, "nil usage should be equal")
+	assert.Equal(t, sum.CompUsageRatioSeparately(nil, nil, nil, nil, nil, nil), 0, "nil usage should be equal")
+}
@@ -0,0 +1,93 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/apache/yunikorn-core/pkg/locking"
+	"github.com/apache/yunikorn-scheduler-interface/lib/go/common"
+)
+
+// DefaultDisplayOrder lists the resource types that are rendered first when a resource is displayed.
+var DefaultDisplayOrder = []string{common.CPU, common.Memory}
+
+var displayOrder = struct {
+	types []string
+	locking.RWMutex
+}{
+	types: DefaultDisplayOrder,
+}
+
+// SetDisplayOrder sets the resource types that are rendered first, in the order given, when a resource is
+// displayed. All other types follow in alphabetical order. Empty and duplicate types are ignored. An empty list
+// resets the order to the DefaultDisplayOrder.
+func SetDisplayOrder(order []string) {
+	types := make([]string, 0, len(order))
+	seen := make(map[string]bool)
+	for _, name := range order {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		types = append(types, name)
+	}
+	if len(types) == 0 {
+		types = DefaultDisplayOrder
+	}
+	displayOrder.Lock()
+	defer displayOrder.Unlock()
+	displayOrder.types = types
+}
+
+// GetDisplayOrder returns a copy of the resource types that are rendered first when a resource is displayed.
+func GetDisplayOrder() []string {
+	displayOrder.RLock()
+	defer displayOrder.RUnlock()
+	types := make([]string, len(displayOrder.types))
+	copy(types, displayOrder.types)
+	return types
+}
+
+// displayKeys returns the resource types of the resource in the display order.
+func (r *Resource) displayKeys() []string {
+	rank := make(map[string]int)
+	for i, name := range GetDisplayOrder() {
+		rank[name] = i
+	}
+	keys := make([]string, 0, len(r.Resources))
+	for name := range r.Resources {
+		keys = append(keys, name)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		rankI, okI := rank[keys[i]]
+		rankJ, okJ := rank[keys[j]]
+		switch {
+		case okI && okJ:
+			return rankI < rankJ
+		case okI != okJ:
+			return okI
+		default:
+			return keys[i] < keys[j]
+		}
+	})
+	return keys
+}
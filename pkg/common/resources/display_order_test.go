@@ -0,0 +1,63 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-scheduler-interface/lib/go/common"
+)
+
+func TestDisplayOrderString(t *testing.T) {
+	defer SetDisplayOrder(nil)
+	res := NewResourceFromMap(map[string]Quantity{"pods": 1, common.Memory: 2, "ephemeral-storage": 3, common.CPU: 4, "gpu": 5})
+	expected := "map[vcore:4 memory:2 ephemeral-storage:3 gpu:5 pods:1]"
+	// rendering must not depend on the map iteration order
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, res.String(), expected, "unstable rendering on iteration %d", i)
+	}
+	// a resource without the default types is rendered in alphabetical order
+	res = NewResourceFromMap(map[string]Quantity{"b": 1, "c": 2, "a": 3})
+	assert.Equal(t, res.String(), "map[a:3 b:1 c:2]")
+	assert.Equal(t, NewResource().String(), "map[]")
+}
+
+func TestSetDisplayOrder(t *testing.T) {
+	defer SetDisplayOrder(nil)
+	assert.DeepEqual(t, GetDisplayOrder(), DefaultDisplayOrder)
+	res := NewResourceFromMap(map[string]Quantity{"pods": 1, common.Memory: 2, common.CPU: 4, "gpu": 5})
+
+	SetDisplayOrder([]string{"gpu", " pods ", "", "gpu", "unknown"})
+	assert.DeepEqual(t, GetDisplayOrder(), []string{"gpu", "pods", "unknown"})
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, res.String(), "map[gpu:5 pods:1 memory:2 vcore:4]", "unstable rendering on iteration %d", i)
+	}
+
+	// the returned order is a copy
+	order := GetDisplayOrder()
+	order[0] = "changed"
+	assert.DeepEqual(t, GetDisplayOrder(), []string{"gpu", "pods", "unknown"})
+
+	// an empty order resets to the default
+	SetDisplayOrder([]string{" "})
+	assert.DeepEqual(t, GetDisplayOrder(), DefaultDisplayOrder)
+	assert.Equal(t, res.String(), "map[vcore:4 memory:2 gpu:5 pods:1]")
+}
@@ -0,0 +1,123 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/yunikorn-core/pkg/locking"
+)
+
+// OverflowMode defines what the checked add operations do when a quantity overflows the integer range.
+type OverflowMode int
+
+const (
+	// SaturateOverflow limits the quantity to the maximum or minimum possible value and logs a warning.
+	SaturateOverflow OverflowMode = iota
+	// ErrorOverflow returns an error and leaves the resources unchanged.
+	ErrorOverflow
+)
+
+func (m OverflowMode) String() string {
+	return [...]string{"saturate", "error"}[m]
+}
+
+// OverflowModeFromString converts the text to an overflow mode. An empty text is the default SaturateOverflow mode.
+// An unknown text returns the default mode and an error.
+func OverflowModeFromString(str string) (OverflowMode, error) {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "", SaturateOverflow.String():
+		return SaturateOverflow, nil
+	case ErrorOverflow.String():
+		return ErrorOverflow, nil
+	default:
+		return SaturateOverflow, fmt.Errorf("undefined resource overflow mode: %s", str)
+	}
+}
+
+var overflowMode = struct {
+	mode OverflowMode
+	locking.RWMutex
+}{
+	mode: SaturateOverflow,
+}
+
+// SetOverflowMode sets the behaviour of the checked add operations when a quantity overflows.
+func SetOverflowMode(mode OverflowMode) {
+	overflowMode.Lock()
+	defer overflowMode.Unlock()
+	overflowMode.mode = mode
+}
+
+// GetOverflowMode returns the behaviour of the checked add operations when a quantity overflows.
+func GetOverflowMode() OverflowMode {
+	overflowMode.RLock()
+	defer overflowMode.RUnlock()
+	return overflowMode.mode
+}
+
+// AddChecked adds the resources returning a new resource with the result, like Add. If a quantity overflows the
+// result depends on the overflow mode: in the SaturateOverflow mode the quantity is saturated and a warning is
+// logged, in the ErrorOverflow mode nil and an error naming the resource type are returned.
+// A nil resource is considered an empty resource.
+func AddChecked(left, right *Resource) (*Resource, error) {
+	if err := checkAddOverflow(left, right); err != nil {
+		return nil, err
+	}
+	return Add(left, right), nil
+}
+
+// AddToChecked adds the resource to the base updating the base resource, like AddTo. If a quantity overflows the
+// result depends on the overflow mode: in the SaturateOverflow mode the quantity is saturated and a warning is
+// logged, in the ErrorOverflow mode the base is left unchanged and an error naming the resource type is returned.
+func (r *Resource) AddToChecked(add *Resource) error {
+	if r == nil {
+		return nil
+	}
+	if err := checkAddOverflow(r, add); err != nil {
+		return err
+	}
+	r.AddTo(add)
+	return nil
+}
+
+// checkAddOverflow returns an error for the first resource type that overflows when adding the resources. No error is
+// returned in the SaturateOverflow mode.
+func checkAddOverflow(left, right *Resource) error {
+	if right == nil || GetOverflowMode() == SaturateOverflow {
+		return nil
+	}
+	for k, v := range right.Resources {
+		var base Quantity
+		if left != nil {
+			base = left.Resources[k]
+		}
+		if addOverflows(base, v) {
+			return fmt.Errorf("resource %s overflows: adding %d to %d", k, v, base)
+		}
+	}
+	return nil
+}
+
+// addOverflows returns true if adding the quantities wraps the sign of the result.
+func addOverflows(valA, valB Quantity) bool {
+	result := valA + valB
+	return (result < valA) != (valB < 0)
+}
@@ -0,0 +1,95 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestOverflowModeFromString(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected OverflowMode
+		wantErr  bool
+	}{
+		{"", SaturateOverflow, false},
+		{"saturate", SaturateOverflow, false},
+		{"Error", ErrorOverflow, false},
+		{"unknown", SaturateOverflow, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := OverflowModeFromString(tt.value)
+			assert.Equal(t, mode, tt.expected, "unexpected mode")
+			assert.Equal(t, err != nil, tt.wantErr, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestAddChecked(t *testing.T) {
+	defer SetOverflowMode(SaturateOverflow)
+	base := NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64 - 1, "second": math.MinInt64 + 1})
+	fits := NewResourceFromMap(map[string]Quantity{"first": 1, "second": -1})
+	over := NewResourceFromMap(map[string]Quantity{"first": 2})
+	under := NewResourceFromMap(map[string]Quantity{"second": -2})
+
+	// saturate: the result is limited and no error is returned
+	result, err := AddChecked(base, over)
+	assert.NilError(t, err, "saturate mode should not return an error")
+	assert.Equal(t, result.Resources["first"], Quantity(math.MaxInt64), "result should saturate at the maximum")
+	result, err = AddChecked(base, under)
+	assert.NilError(t, err, "saturate mode should not return an error")
+	assert.Equal(t, result.Resources["second"], Quantity(math.MinInt64), "result should saturate at the minimum")
+
+	// error: an overflow in either direction is detected, the boundary itself is not an overflow
+	SetOverflowMode(ErrorOverflow)
+	result, err = AddChecked(base, fits)
+	assert.NilError(t, err, "adding up to the boundary should not overflow")
+	assert.Assert(t, Equals(result, NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64, "second": math.MinInt64})), "unexpected result %v", result)
+	result, err = AddChecked(base, over)
+	assert.ErrorContains(t, err, "resource first overflows")
+	assert.Assert(t, result == nil, "no result expected on overflow")
+	_, err = AddChecked(base, under)
+	assert.ErrorContains(t, err, "resource second overflows")
+	result, err = AddChecked(nil, over)
+	assert.NilError(t, err, "nil resource should be considered empty")
+	assert.Assert(t, Equals(result, over), "unexpected result %v", result)
+}
+
+func TestAddToChecked(t *testing.T) {
+	defer SetOverflowMode(SaturateOverflow)
+	var res *Resource
+	assert.NilError(t, res.AddToChecked(NewResourceFromMap(map[string]Quantity{"first": 1})), "nil base should not fail")
+
+	res = NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64})
+	assert.NilError(t, res.AddToChecked(NewResourceFromMap(map[string]Quantity{"first": 1})), "saturate mode should not return an error")
+	assert.Equal(t, res.Resources["first"], Quantity(math.MaxInt64), "result should saturate at the maximum")
+
+	// error: the base is left unchanged, also for the types that do not overflow
+	SetOverflowMode(ErrorOverflow)
+	res = NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64, "second": 1})
+	err := res.AddToChecked(NewResourceFromMap(map[string]Quantity{"first": 1, "second": 1}))
+	assert.ErrorContains(t, err, "resource first overflows")
+	assert.Assert(t, Equals(res, NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64, "second": 1})), "base should not change: %v", res)
+	assert.NilError(t, res.AddToChecked(NewResourceFromMap(map[string]Quantity{"first": -1, "second": 1})), "add without overflow should not fail")
+	assert.Assert(t, Equals(res, NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64 - 1, "second": 2})), "unexpected result %v", res)
+}
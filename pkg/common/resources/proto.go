@@ -0,0 +1,59 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
+)
+
+// ToProto converts a resource into the scheduler interface resource.
+// A nil resource converts into a nil proto, an empty resource into a proto without quantities.
+// All resource types are converted, including types not known by the scheduler.
+func ToProto(r *Resource) *si.Resource {
+	if r == nil {
+		return nil
+	}
+	proto := &si.Resource{
+		Resources: make(map[string]*si.Quantity, len(r.Resources)),
+	}
+	for k, v := range r.Resources {
+		proto.Resources[k] = &si.Quantity{Value: int64(v)}
+	}
+	return proto
+}
+
+// FromProto converts a scheduler interface resource into a resource.
+// A nil proto converts into a nil resource, a proto without quantities into an empty resource.
+// All resource types are converted, including types not known by the scheduler. Resource types with a nil quantity
+// are skipped.
+func FromProto(proto *si.Resource) *Resource {
+	if proto == nil {
+		return nil
+	}
+	out := &Resource{
+		Resources: make(map[string]Quantity, len(proto.Resources)),
+	}
+	for k, v := range proto.Resources {
+		if v == nil {
+			continue
+		}
+		out.Resources[k] = Quantity(v.Value)
+	}
+	return out
+}
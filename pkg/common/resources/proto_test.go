@@ -0,0 +1,96 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestToProtoConverter(t *testing.T) {
+	assert.Assert(t, ToProto(nil) == nil, "nil resource should convert to nil proto")
+
+	proto := ToProto(NewResource())
+	assert.Assert(t, proto != nil, "empty resource should convert to a proto")
+	assert.Equal(t, len(proto.Resources), 0, "empty resource should convert to an empty proto")
+
+	proto = ToProto(&Resource{})
+	assert.Assert(t, proto != nil && proto.Resources != nil, "resource without map should convert to an empty proto")
+	assert.Equal(t, len(proto.Resources), 0, "resource without map should convert to an empty proto")
+
+	res := NewResourceFromMap(map[string]Quantity{"memory": 1024, "vcore": 0, "example.com/unknown": -5})
+	proto = ToProto(res)
+	assert.DeepEqual(t, protoValues(proto), map[string]int64{"memory": 1024, "vcore": 0, "example.com/unknown": -5})
+}
+
+func TestFromProtoConverter(t *testing.T) {
+	assert.Assert(t, FromProto(nil) == nil, "nil proto should convert to nil resource")
+
+	res := FromProto(&si.Resource{})
+	assert.Assert(t, res != nil && res.Resources != nil, "empty proto should convert to an empty resource")
+	assert.Equal(t, len(res.Resources), 0, "empty proto should convert to an empty resource")
+
+	proto := &si.Resource{Resources: map[string]*si.Quantity{
+		"memory":              {Value: 1024},
+		"example.com/unknown": {Value: math.MaxInt64},
+		"nil":                 nil,
+	}}
+	res = FromProto(proto)
+	assert.DeepEqual(t, res.Resources, map[string]Quantity{"memory": 1024, "example.com/unknown": math.MaxInt64})
+	// the constructor handles the nil quantity the same
+	assert.DeepEqual(t, NewResourceFromProto(proto).Resources, res.Resources)
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *Resource
+	}{
+		{"nil", nil},
+		{"empty", NewResource()},
+		{"zero", NewResourceFromMap(map[string]Quantity{"vcore": 0})},
+		{"multiple", NewResourceFromMap(map[string]Quantity{"memory": 1024, "vcore": 2, "nvidia.com/gpu": 1})},
+		{"limits", NewResourceFromMap(map[string]Quantity{"max": math.MaxInt64, "min": math.MinInt64, "negative": -1})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proto := ToProto(tt.res)
+			back := FromProto(proto)
+			if tt.res == nil {
+				assert.Assert(t, proto == nil && back == nil, "nil resource should round trip to nil")
+				return
+			}
+			assert.DeepEqual(t, back.Resources, tt.res.Resources)
+			assert.DeepEqual(t, protoValues(ToProto(back)), protoValues(proto))
+		})
+	}
+}
+
+// protoValues returns the quantities of the proto as a plain map for comparison.
+func protoValues(proto *si.Resource) map[string]int64 {
+	values := make(map[string]int64, len(proto.Resources))
+	for k, v := range proto.Resources {
+		values[k] = v.GetValue()
+	}
+	return values
+}
@@ -53,14 +53,10 @@ func NewResource() *Resource {
 }
 
 func NewResourceFromProto(proto *si.Resource) *Resource {
-	out := NewResource()
 	if proto == nil {
-		return out
-	}
-	for k, v := range proto.Resources {
-		out.Resources[k] = Quantity(v.Value)
+		return NewResource()
 	}
-	return out
+	return FromProto(proto)
 }
 
 func NewResourceFromMap(m map[string]Quantity) *Resource {
@@ -101,11 +97,66 @@ func NewResourceFromConf(configMap map[string]string) (*Resource, error) {
 	return res, nil
 }
 
+// NewResourceFromConfWithPercentage creates a new resource from a config map in which a value can also be set as a
+// percentage, like "50%". The returned resource contains the types with an absolute value, the returned map the
+// percentage for the other types. A percentage must be larger than 0 and not larger than 100.
+func NewResourceFromConfWithPercentage(configMap map[string]string) (*Resource, map[string]float64, error) {
+	absolute := make(map[string]string)
+	var percentages map[string]float64
+	for key, strVal := range configMap {
+		value, found := strings.CutSuffix(strings.TrimSpace(strVal), "%")
+		if !found {
+			absolute[key] = strVal
+			continue
+		}
+		percentage, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || percentage <= 0 || percentage > 100 {
+			return nil, nil, fmt.Errorf("invalid percentage '%s' for resource %s: must be larger than 0 and at most 100", strVal, key)
+		}
+		if percentages == nil {
+			percentages = make(map[string]float64)
+		}
+		percentages[key] = percentage
+	}
+	res, err := NewResourceFromConf(absolute)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, percentages, nil
+}
+
+// ResolvePercentage returns a new resource with the percentages applied to the base resource.
+// A type that is not set in the base resource is not set in the returned resource.
+func ResolvePercentage(percentages map[string]float64, base *Resource) *Resource {
+	res := NewResource()
+	if base == nil {
+		return res
+	}
+	for key, percentage := range percentages {
+		if value, ok := base.Resources[key]; ok {
+			res.Resources[key] = Quantity(float64(value) * percentage / 100)
+		}
+	}
+	return res
+}
+
+// String renders the resource as a map with the types in the display order: see SetDisplayOrder.
 func (r *Resource) String() string {
 	if r == nil {
 		return "nil resource"
 	}
-	return fmt.Sprintf("%v", r.Resources)
+	var sb strings.Builder
+	sb.WriteString("map[")
+	for i, name := range r.displayKeys() {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(r.Resources[name].string())
+	}
+	sb.WriteString("]")
+	return sb.String()
 }
 
 func (r *Resource) DAOMap() map[string]int64 {
@@ -121,14 +172,10 @@ func (r *Resource) DAOMap() map[string]int64 {
 // Convert to a protobuf implementation
 // a nil resource passes back an empty proto object
 func (r *Resource) ToProto() *si.Resource {
-	proto := &si.Resource{}
-	proto.Resources = make(map[string]*si.Quantity)
-	if r != nil {
-		for k, v := range r.Resources {
-			proto.Resources[k] = &si.Quantity{Value: int64(v)}
-		}
+	if r == nil {
+		return &si.Resource{Resources: make(map[string]*si.Quantity)}
 	}
-	return proto
+	return ToProto(r)
 }
 
 // Clone returns a clone (copy) of the resource it is called on.
@@ -243,9 +290,8 @@ func (r *Resource) FitInScore(fit *Resource) float64 {
 // They will always return a valid int64. Logging if the calculator wrapped the value.
 // Returning the appropriate MaxInt64 or MinInt64 value.
 func addVal(valA, valB Quantity) Quantity {
-	result := valA + valB
 	// check if the sign wrapped
-	if (result < valA) != (valB < 0) {
+	if addOverflows(valA, valB) {
 		if valA < 0 {
 			// return the minimum possible
 			log.Log(log.Resources).Warn("Resource calculation wrapped: returned minimum value possible",
@@ -260,7 +306,7 @@ func addVal(valA, valB Quantity) Quantity {
 		return math.MaxInt64
 	}
 	// not wrapped normal case
-	return result
+	return valA + valB
 }
 
 func subVal(valA, valB Quantity) Quantity {
@@ -813,6 +859,27 @@ func MultiplyBy(base *Resource, ratio float64) *Resource {
 	return ret
 }
 
+// RoundUp rounds each quantity in the resource up to the nearest multiple of the granularity of the same type
+// returning a new resource. Types without a positive granularity and non positive quantities are not changed.
+// Result is protected from overflow.
+// A nil resource passed in returns a new empty resource (zero)
+func RoundUp(base, granularity *Resource) *Resource {
+	ret := NewResource()
+	if base == nil {
+		return ret
+	}
+	for k, v := range base.Resources {
+		ret.Resources[k] = v
+		if granularity == nil {
+			continue
+		}
+		if g, ok := granularity.Resources[k]; ok && g > 0 && v > 0 && v%g != 0 {
+			ret.Resources[k] = mulVal(v/g+1, g)
+		}
+	}
+	return ret
+}
+
 // Return true if all quantities in larger > smaller
 // Two resources that are equal are not considered strictly larger than each other.
 func StrictlyGreaterThan(larger, smaller *Resource) bool {
@@ -144,6 +144,46 @@ func TestNewResourceFromConf(t *testing.T) {
 	}
 }
 
+func TestNewResourceFromConfWithPercentage(t *testing.T) {
+	var tests = []struct {
+		name        string
+		input       map[string]string
+		absolute    map[string]Quantity
+		percentages map[string]float64
+		wantErr     bool
+	}{
+		{"nil input", nil, map[string]Quantity{}, nil, false},
+		{"absolute only", map[string]string{"memory": "10", "vcore": "1"}, map[string]Quantity{"memory": 10, "vcore": 1000}, nil, false},
+		{"percentage only", map[string]string{"memory": "50%"}, map[string]Quantity{}, map[string]float64{"memory": 50}, false},
+		{"mixed", map[string]string{"memory": "12.5 %", "vcore": "2"}, map[string]Quantity{"vcore": 2000}, map[string]float64{"memory": 12.5}, false},
+		{"full percentage", map[string]string{"memory": "100%"}, map[string]Quantity{}, map[string]float64{"memory": 100}, false},
+		{"zero percentage", map[string]string{"memory": "0%"}, nil, nil, true},
+		{"over 100 percent", map[string]string{"memory": "101%"}, nil, nil, true},
+		{"invalid percentage", map[string]string{"memory": "x%"}, nil, nil, true},
+		{"invalid absolute", map[string]string{"memory": "x", "vcore": "10%"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, percentages, err := NewResourceFromConfWithPercentage(tt.input)
+			if tt.wantErr {
+				assert.Assert(t, err != nil, "expected error for %v", tt.input)
+				return
+			}
+			assert.NilError(t, err, "unexpected error for %v", tt.input)
+			assert.DeepEqual(t, res.Resources, tt.absolute)
+			assert.DeepEqual(t, percentages, tt.percentages)
+		})
+	}
+}
+
+func TestResolvePercentage(t *testing.T) {
+	base := NewResourceFromMap(map[string]Quantity{"memory": 1000, "vcore": 3})
+	res := ResolvePercentage(map[string]float64{"memory": 25, "vcore": 50, "gpu": 10}, base)
+	assert.DeepEqual(t, res.Resources, map[string]Quantity{"memory": 250, "vcore": 1})
+	res = ResolvePercentage(map[string]float64{"memory": 25}, nil)
+	assert.Assert(t, IsZero(res), "nil base should resolve to an empty resource")
+}
+
 func TestCloneNil(t *testing.T) {
 	// make sure we're nil safe IDE will complain about the non nil check
 	defer func() {
@@ -768,6 +808,34 @@ func TestMultiplyBy(t *testing.T) {
 	}
 }
 
+func TestRoundUp(t *testing.T) {
+	granularity := NewResourceFromMap(map[string]Quantity{"vcore": 100, "memory": 1024, "zero": 0})
+	tests := []struct {
+		name        string
+		base        *Resource
+		granularity *Resource
+		expected    *Resource
+	}{
+		{"nil base", nil, granularity, NewResource()},
+		{"nil granularity", NewResourceFromMap(map[string]Quantity{"vcore": 250}), nil, NewResourceFromMap(map[string]Quantity{"vcore": 250})},
+		{"round up", NewResourceFromMap(map[string]Quantity{"vcore": 250}), granularity, NewResourceFromMap(map[string]Quantity{"vcore": 300})},
+		{"multiple unchanged", NewResourceFromMap(map[string]Quantity{"vcore": 200, "memory": 2048}), granularity, NewResourceFromMap(map[string]Quantity{"vcore": 200, "memory": 2048})},
+		{"below granularity", NewResourceFromMap(map[string]Quantity{"memory": 1}), granularity, NewResourceFromMap(map[string]Quantity{"memory": 1024})},
+		{"type without granularity", NewResourceFromMap(map[string]Quantity{"pods": 3, "zero": 5}), granularity, NewResourceFromMap(map[string]Quantity{"pods": 3, "zero": 5})},
+		{"zero and negative unchanged", NewResourceFromMap(map[string]Quantity{"vcore": 0, "memory": -5}), granularity, NewResourceFromMap(map[string]Quantity{"vcore": 0, "memory": -5})},
+		{"overflow", NewResourceFromMap(map[string]Quantity{"vcore": math.MaxInt64 - 1}), granularity, NewResourceFromMap(map[string]Quantity{"vcore": math.MaxInt64})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RoundUp(tt.base, tt.granularity)
+			assert.Assert(t, DeepEquals(result, tt.expected), "unexpected result: expected %v, got %v", tt.expected, result)
+			if tt.base != nil {
+				assert.Assert(t, result != tt.base, "a new resource should have been returned")
+			}
+		})
+	}
+}
+
 func TestMultiply(t *testing.T) {
 	// simple case (nil checks)
 	result := Multiply(nil, 0)
@@ -21,11 +21,13 @@ package security
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
 
 	"github.com/apache/yunikorn-core/pkg/common"
+	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/log"
 )
 
@@ -34,10 +36,13 @@ import (
 var userNameRegExp = regexp.MustCompile("^[_a-zA-Z][a-zA-Z0-9_.@-]*[$]?$")
 var groupRegExp = regexp.MustCompile("^[_a-zA-Z][a-zA-Z0-9_-]*$")
 
+// ACL is a parsed access control list: a list of users and a list of groups.
+// The handling of a wildcard in one of the two fields is defined by the WildcardPolicy set when the ACL is created.
 type ACL struct {
 	users      map[string]bool
 	groups     map[string]bool
 	allAllowed bool
+	allGroups  bool // field scoped group wildcard: every user with a group is allowed
 }
 
 // the ACL allows all access, set the flag
@@ -75,8 +80,10 @@ func (a *ACL) setUsers(userList []string, silence bool) {
 }
 
 // set the group list in the ACL, invalid group names are ignored
+// A group wildcard allows all access using the AnyFieldWildcard policy and all users with a group using the
+// FieldScopedWildcard policy. The user list is kept using the FieldScopedWildcard policy.
 // If the silence flag is set to true, the function will not log when setting the groups.
-func (a *ACL) setGroups(groupList []string, silence bool) {
+func (a *ACL) setGroups(groupList []string, policy WildcardPolicy, silence bool) {
 	a.groups = make(map[string]bool)
 	// special case if the wildcard was already set
 	if a.allAllowed {
@@ -86,6 +93,13 @@ func (a *ACL) setGroups(groupList []string, silence bool) {
 		return
 	}
 	if len(groupList) == 1 && groupList[0] == common.Wildcard {
+		if policy == FieldScopedWildcard {
+			if !silence {
+				log.Log(log.Security).Info("group list is wildcard, allowing all users with a group")
+			}
+			a.allGroups = true
+			return
+		}
 		if !silence {
 			log.Log(log.Security).Info("group list is wildcard, allowing all access")
 		}
@@ -109,9 +123,15 @@ func (a *ACL) setGroups(groupList []string, silence bool) {
 	}
 }
 
-// create a new ACL from scratch
+// create a new ACL from scratch, an ACL that references a file is read from that file
+// The current WildcardPolicy is applied to the ACL. A user wildcard always allows all access: every user matches it.
 func NewACL(aclStr string, silence bool) (ACL, error) {
 	acl := ACL{}
+	var err error
+	aclStr, err = configs.ResolveACL(aclStr)
+	if err != nil {
+		return acl, err
+	}
 	if aclStr == "" {
 		return acl, nil
 	}
@@ -121,12 +141,15 @@ func NewACL(aclStr string, silence bool) (ACL, error) {
 	if len(fields) > 2 {
 		return acl, fmt.Errorf("multiple spaces found in ACL: '%s'", aclStr)
 	}
-	// trim and check for wildcard
-	acl.setAllAllowed(aclStr)
+	policy := GetWildcardPolicy()
+	// trim and check for wildcard: a trimmed group wildcard (" *") is scoped to the group field
+	if policy == AnyFieldWildcard {
+		acl.setAllAllowed(aclStr)
+	}
 	// parse users and groups
 	acl.setUsers(strings.Split(fields[0], common.Separator), silence)
 	if len(fields) == 2 {
-		acl.setGroups(strings.Split(fields[1], common.Separator), silence)
+		acl.setGroups(strings.Split(fields[1], common.Separator), policy, silence)
 	}
 	return acl, nil
 }
@@ -142,6 +165,10 @@ func (a ACL) CheckAccess(userObj UserGroup) bool {
 	if a.users[userObj.User] {
 		return true
 	}
+	// a field scoped group wildcard allows any user that has a group
+	if a.allGroups && len(userObj.Groups) > 0 {
+		return true
+	}
 	// get groups for the user and check them
 	for _, group := range userObj.Groups {
 		if a.groups[group] {
@@ -150,3 +177,76 @@ func (a ACL) CheckAccess(userObj UserGroup) bool {
 	}
 	return false
 }
+
+// CheckAccessBatch checks the access for each of the users. The result at each index is the CheckAccess result for the
+// user at the same index.
+func (a ACL) CheckAccessBatch(users []UserGroup) []bool {
+	allowed := make([]bool, len(users))
+	// shortcut allow all: no need to check the users
+	if a.allAllowed {
+		for i := range allowed {
+			allowed[i] = true
+		}
+		return allowed
+	}
+	for i, user := range users {
+		allowed[i] = a.CheckAccess(user)
+	}
+	return allowed
+}
+
+// Merge returns a new ACL that allows access to everyone allowed by this ACL or the other ACL.
+// Neither ACL is modified.
+func (a ACL) Merge(other ACL) ACL {
+	merged := ACL{
+		users:      make(map[string]bool),
+		groups:     make(map[string]bool),
+		allAllowed: a.allAllowed || other.allAllowed,
+		allGroups:  a.allGroups || other.allGroups,
+	}
+	if merged.allAllowed {
+		merged.allGroups = false
+		return merged
+	}
+	for _, acl := range []ACL{a, other} {
+		for user := range acl.users {
+			merged.users[user] = true
+		}
+		if merged.allGroups {
+			continue
+		}
+		for group := range acl.groups {
+			merged.groups[group] = true
+		}
+	}
+	return merged
+}
+
+// String returns the ACL in the config format: a sorted user list and a sorted group list separated by a space.
+// The wildcard is returned if all access is allowed and an empty string if no access is allowed. A field scoped group
+// wildcard is returned as the wildcard in the group field.
+func (a ACL) String() string {
+	if a.allAllowed {
+		return common.Wildcard
+	}
+	if len(a.users) == 0 && len(a.groups) == 0 && !a.allGroups {
+		return ""
+	}
+	users := make([]string, 0, len(a.users))
+	for user := range a.users {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	if a.allGroups {
+		return strings.Join(users, common.Separator) + common.Space + common.Wildcard
+	}
+	groups := make([]string, 0, len(a.groups))
+	for group := range a.groups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	if len(groups) == 0 {
+		return strings.Join(users, common.Separator)
+	}
+	return strings.Join(users, common.Separator) + common.Space + strings.Join(groups, common.Separator)
+}
@@ -21,6 +21,8 @@ package security
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/apache/yunikorn-core/pkg/common"
@@ -184,6 +186,37 @@ func TestNewACLErrorCase(t *testing.T) {
 	}
 }
 
+func TestNewACLFromFile(t *testing.T) {
+	aclFile := filepath.Join(t.TempDir(), "submit.acl")
+	if err := os.WriteFile(aclFile, []byte(" user1,user2 group1\n"), 0600); err != nil {
+		t.Fatalf("failed to write ACL file: %v", err)
+	}
+	got, err := NewACL("@"+aclFile, false)
+	if err != nil {
+		t.Fatalf("parsing ACL from file failed: %v", err)
+	}
+	expected := ACL{users: map[string]bool{"user1": true, "user2": true}, groups: map[string]bool{"group1": true}}
+	if err = IsSameACL(got, expected); err != nil {
+		t.Error(err.Error())
+	}
+
+	// the file is read again on each call
+	if err = os.WriteFile(aclFile, []byte("*"), 0600); err != nil {
+		t.Fatalf("failed to update ACL file: %v", err)
+	}
+	got, err = NewACL("@"+aclFile, false)
+	if err != nil {
+		t.Fatalf("parsing updated ACL from file failed: %v", err)
+	}
+	if !got.allAllowed {
+		t.Error("updated ACL file should allow all")
+	}
+
+	if _, err = NewACL("@"+filepath.Join(t.TempDir(), "missing.acl"), false); err == nil {
+		t.Error("parsing ACL from a missing file should have failed")
+	}
+}
+
 func TestACLAccess(t *testing.T) {
 	tests := []struct {
 		acl      string
@@ -248,3 +281,175 @@ func TestACLAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestACLCheckAccessBatch(t *testing.T) {
+	users := []UserGroup{
+		{User: "", Groups: nil},
+		{User: "user1", Groups: nil},
+		{User: "user3", Groups: []string{"group1"}},
+		{User: "user3", Groups: []string{"group3"}},
+		{User: "user4", Groups: []string{"group3", "group2"}},
+	}
+	for _, aclStr := range []string{"user1,user2 group1,group2", "user1", " group3", " *", common.Wildcard, ""} {
+		t.Run(aclStr, func(t *testing.T) {
+			acl, err := NewACL(aclStr, false)
+			if err != nil {
+				t.Fatalf("ACL create failed: %v", err)
+			}
+			allowed := acl.CheckAccessBatch(users)
+			if len(allowed) != len(users) {
+				t.Fatalf("result not aligned to the users, expected %d results, got %d", len(users), len(allowed))
+			}
+			for i, user := range users {
+				if expected := acl.CheckAccess(user); allowed[i] != expected {
+					t.Errorf("batch result for user %v expect:%v, got %v", user, expected, allowed[i])
+				}
+			}
+		})
+	}
+	acl, err := NewACL(common.Wildcard, false)
+	if err != nil {
+		t.Fatalf("ACL create failed: %v", err)
+	}
+	if allowed := acl.CheckAccessBatch(nil); len(allowed) != 0 {
+		t.Errorf("empty batch should return an empty result, got %v", allowed)
+	}
+}
+
+func TestACLMerge(t *testing.T) {
+	tests := []struct {
+		left     string
+		right    string
+		expected string
+	}{
+		{"", "", ""},
+		{"user1", "", "user1"},
+		{"", " group1", " group1"},
+		{"user1 group1", "user2,user1 group2", "user1,user2 group1,group2"},
+		{"user1 group1", common.Wildcard, common.Wildcard},
+		{common.Wildcard, "", common.Wildcard},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s+%s", tt.left, tt.right), func(t *testing.T) {
+			left, err := NewACL(tt.left, false)
+			if err != nil {
+				t.Fatalf("left ACL create failed: %v", err)
+			}
+			right, err := NewACL(tt.right, false)
+			if err != nil {
+				t.Fatalf("right ACL create failed: %v", err)
+			}
+			leftStr := left.String()
+			merged := left.Merge(right)
+			if got := merged.String(); got != tt.expected {
+				t.Errorf("merged ACL expected '%s', got '%s'", tt.expected, got)
+			}
+			// merging must not change the input ACLs
+			if got := left.String(); got != leftStr {
+				t.Errorf("left ACL changed by merge: '%s'", got)
+			}
+			merged.users["changed"] = true
+			if left.users["changed"] || right.users["changed"] {
+				t.Error("merged ACL shares state with the input ACLs")
+			}
+		})
+	}
+}
+
+func TestACLString(t *testing.T) {
+	tests := []struct {
+		acl      string
+		expected string
+	}{
+		{"", ""},
+		{common.Wildcard, common.Wildcard},
+		{"user2,user1", "user1,user2"},
+		{" group2,group1", " group1,group2"},
+		{"user1 group1", "user1 group1"},
+		{"user1 *", common.Wildcard},
+		{"user1,invalid! group1", "user1 group1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acl, func(t *testing.T) {
+			acl, err := NewACL(tt.acl, true)
+			if err != nil {
+				t.Fatalf("ACL create failed: %v", err)
+			}
+			if got := acl.String(); got != tt.expected {
+				t.Errorf("ACL string expected '%s', got '%s'", tt.expected, got)
+			}
+			// the string form can be parsed back into the same ACL
+			parsed, err := NewACL(acl.String(), true)
+			if err != nil {
+				t.Fatalf("ACL parse of string form failed: %v", err)
+			}
+			if err = IsSameACL(parsed, acl); err != nil {
+				t.Errorf("ACL round trip failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestACLWildcardPolicy(t *testing.T) {
+	defer SetWildcardPolicy(AnyFieldWildcard)
+	userA := UserGroup{User: "userA"}
+	userB := UserGroup{User: "userB", Groups: []string{"groupB"}}
+	userC := UserGroup{User: "userC", Groups: []string{"groupC"}}
+	noGroup := UserGroup{User: "userD"}
+	tests := []struct {
+		acl      string
+		policy   WildcardPolicy
+		allowed  []UserGroup
+		denied   []UserGroup
+		rendered string
+	}{
+		{"userA *", AnyFieldWildcard, []UserGroup{userA, userB, userC, noGroup}, nil, common.Wildcard},
+		{"* groupB", AnyFieldWildcard, []UserGroup{userA, userB, userC, noGroup}, nil, common.Wildcard},
+		{" *", AnyFieldWildcard, []UserGroup{userA, userB, userC, noGroup}, nil, common.Wildcard},
+		{"userA *", FieldScopedWildcard, []UserGroup{userA, userB, userC}, []UserGroup{noGroup}, "userA *"},
+		{"* groupB", FieldScopedWildcard, []UserGroup{userA, userB, userC, noGroup}, nil, common.Wildcard},
+		{" *", FieldScopedWildcard, []UserGroup{userB, userC}, []UserGroup{userA, noGroup}, " *"},
+		{common.Wildcard, FieldScopedWildcard, []UserGroup{userA, userB, userC, noGroup}, nil, common.Wildcard},
+		{"userA groupB", FieldScopedWildcard, []UserGroup{userA, userB}, []UserGroup{userC, noGroup}, "userA groupB"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.policy, tt.acl), func(t *testing.T) {
+			SetWildcardPolicy(tt.policy)
+			acl, err := NewACL(tt.acl, true)
+			if err != nil {
+				t.Fatalf("ACL create failed: %v", err)
+			}
+			for _, user := range tt.allowed {
+				if !acl.CheckAccess(user) {
+					t.Errorf("user %s should have access", user.User)
+				}
+			}
+			for _, user := range tt.denied {
+				if acl.CheckAccess(user) {
+					t.Errorf("user %s should not have access", user.User)
+				}
+			}
+			if got := acl.String(); got != tt.rendered {
+				t.Errorf("ACL string expected '%s', got '%s'", tt.rendered, got)
+			}
+		})
+	}
+
+	// merging keeps a field scoped group wildcard
+	SetWildcardPolicy(FieldScopedWildcard)
+	left, err := NewACL("userA *", true)
+	if err != nil {
+		t.Fatalf("ACL create failed: %v", err)
+	}
+	right, err := NewACL("userC groupB", true)
+	if err != nil {
+		t.Fatalf("ACL create failed: %v", err)
+	}
+	merged := left.Merge(right)
+	if got := merged.String(); got != "userA,userC *" {
+		t.Errorf("merged ACL expected 'userA,userC *', got '%s'", got)
+	}
+	if merged.CheckAccess(noGroup) {
+		t.Error("user without a group should not have access to the merged ACL")
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package security
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/locking"
+	"github.com/apache/yunikorn-core/pkg/log"
+)
+
+// WildcardPolicy defines how a wildcard in one field of an ACL affects the other field.
+type WildcardPolicy int
+
+const (
+	// AnyFieldWildcard allows all access if a wildcard is set in any field: "userA *" and "* groupB" allow everyone.
+	AnyFieldWildcard WildcardPolicy = iota
+	// FieldScopedWildcard limits a wildcard to its field: a user wildcard allows every user, a group wildcard allows
+	// every user that is a member of at least one group. Users listed next to a group wildcard are still allowed.
+	FieldScopedWildcard
+)
+
+var wildcardPolicyNames = [...]string{"any", "field"}
+
+func (w WildcardPolicy) String() string {
+	return wildcardPolicyNames[w]
+}
+
+// WildcardPolicyFromString returns the wildcard policy for the name, an empty name returns the default.
+func WildcardPolicyFromString(str string) (WildcardPolicy, bool) {
+	switch strings.ToLower(str) {
+	case AnyFieldWildcard.String(), "":
+		return AnyFieldWildcard, true
+	case FieldScopedWildcard.String():
+		return FieldScopedWildcard, true
+	default:
+		return AnyFieldWildcard, false
+	}
+}
+
+var wildcardPolicy = struct {
+	policy WildcardPolicy
+	locking.RWMutex
+}{
+	policy: AnyFieldWildcard,
+}
+
+func init() {
+	configs.AddConfigMapCallback("acl-wildcard-policy", updateWildcardPolicy)
+}
+
+// updateWildcardPolicy sets the wildcard policy from the config map, an unset or unknown value resets the policy to
+// the default.
+func updateWildcardPolicy() {
+	value := configs.GetConfigMap()[configs.CMACLWildcardPolicy]
+	policy, ok := WildcardPolicyFromString(value)
+	if !ok {
+		log.Log(log.Security).Warn("unknown ACL wildcard policy, using default",
+			zap.String("policy", value),
+			zap.Stringer("default", policy))
+	}
+	SetWildcardPolicy(policy)
+}
+
+// SetWildcardPolicy sets the wildcard policy applied when an ACL is created. ACLs created before the change keep the
+// policy they were created with: the queue ACLs are recreated when the configuration is updated, or when the policy is
+// changed by a configuration update that does not change the queue configuration.
+func SetWildcardPolicy(policy WildcardPolicy) {
+	wildcardPolicy.Lock()
+	defer wildcardPolicy.Unlock()
+	wildcardPolicy.policy = policy
+}
+
+// GetWildcardPolicy returns the wildcard policy applied when an ACL is created.
+func GetWildcardPolicy() WildcardPolicy {
+	wildcardPolicy.RLock()
+	defer wildcardPolicy.RUnlock()
+	return wildcardPolicy.policy
+}
@@ -0,0 +1,66 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package security
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+)
+
+func TestWildcardPolicyFromString(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected WildcardPolicy
+		ok       bool
+	}{
+		{"empty", "", AnyFieldWildcard, true},
+		{"any", "any", AnyFieldWildcard, true},
+		{"field", "field", FieldScopedWildcard, true},
+		{"case", "FIELD", FieldScopedWildcard, true},
+		{"unknown", "unknown", AnyFieldWildcard, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, ok := WildcardPolicyFromString(tt.value)
+			assert.Equal(t, policy, tt.expected, "unexpected policy")
+			assert.Equal(t, ok, tt.ok, "unexpected result")
+		})
+	}
+}
+
+func TestWildcardPolicyToString(t *testing.T) {
+	assert.Equal(t, AnyFieldWildcard.String(), "any")
+	assert.Equal(t, FieldScopedWildcard.String(), "field")
+}
+
+func TestUpdateWildcardPolicy(t *testing.T) {
+	defer configs.SetConfigMap(map[string]string{})
+	assert.Equal(t, GetWildcardPolicy(), AnyFieldWildcard, "unexpected default policy")
+	configs.SetConfigMap(map[string]string{configs.CMACLWildcardPolicy: "field"})
+	assert.Equal(t, GetWildcardPolicy(), FieldScopedWildcard, "policy not updated from the config map")
+	configs.SetConfigMap(map[string]string{configs.CMACLWildcardPolicy: "unknown"})
+	assert.Equal(t, GetWildcardPolicy(), AnyFieldWildcard, "unknown policy should reset to the default")
+	configs.SetConfigMap(map[string]string{configs.CMACLWildcardPolicy: "field"})
+	configs.SetConfigMap(map[string]string{})
+	assert.Equal(t, GetWildcardPolicy(), AnyFieldWildcard, "unset policy should reset to the default")
+}
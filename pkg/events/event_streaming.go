@@ -19,6 +19,7 @@
 package events
 
 import (
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -45,6 +46,7 @@ type eventConsumerDetails struct {
 	stopCh    chan struct{}
 	name      string
 	createdAt time.Time
+	filter    func(*si.EventRecord) bool // only events for which the filter returns true are streamed, nil means all
 }
 
 // EventStreamData contains data about an event stream.
@@ -70,6 +72,9 @@ func (e *EventStreaming) PublishEvent(event *si.EventRecord) {
 	defer e.Unlock()
 
 	for consumer, details := range e.eventStreams {
+		if details.filter != nil && !details.filter(event) {
+			continue
+		}
 		if len(details.local) == defaultChannelBufSize {
 			log.Log(log.Events).Warn("Listener buffer full due to potentially slow consumer, removing it")
 			e.removeEventStream(consumer)
@@ -88,14 +93,48 @@ func (e *EventStreaming) PublishEvent(event *si.EventRecord) {
 // Consumers have an arbitrary name for logging purposes. The "count" parameter defines the number
 // of maximum historical events from the ring buffer. "0" is a valid value and means no past events.
 func (e *EventStreaming) CreateEventStream(name string, count uint64) *EventStream {
+	return e.createEventStream(name, count, nil)
+}
+
+// CreateQueueEventStream sets up event streaming for a consumer that is only interested in the events of a queue
+// and all the queues below it. Only queue events with an object ID that is equal to the queue path, or that starts
+// with the queue path followed by a dot, are streamed. The historical events are filtered the same way, the "count"
+// parameter limits the number of historical events checked.
+//
+// When a consumer is finished, it must call RemoveEventStream to free up resources.
+func (e *EventStreaming) CreateQueueEventStream(name string, queuePath string, count uint64) *EventStream {
+	return e.createEventStream(name, count, queuePathFilter(queuePath))
+}
+
+// queuePathFilter returns a filter that accepts the queue events for the queue path and its descendants.
+func queuePathFilter(queuePath string) func(*si.EventRecord) bool {
+	prefix := queuePath + "."
+	return func(event *si.EventRecord) bool {
+		if event.GetType() != si.EventRecord_QUEUE {
+			return false
+		}
+		return event.GetObjectID() == queuePath || strings.HasPrefix(event.GetObjectID(), prefix)
+	}
+}
+
+func (e *EventStreaming) createEventStream(name string, count uint64, filter func(*si.EventRecord) bool) *EventStream {
 	consumer := make(chan *si.EventRecord, defaultChannelBufSize)
 	stream := &EventStream{
 		Events: consumer,
 	}
 	local := make(chan *si.EventRecord, defaultChannelBufSize)
 	stop := make(chan struct{})
-	e.createEventStreamInternal(stream, local, consumer, stop, name)
+	e.createEventStreamInternal(stream, local, consumer, stop, name, filter)
 	history := e.buffer.GetRecentEvents(count)
+	if filter != nil {
+		filtered := make([]*si.EventRecord, 0, len(history))
+		for _, event := range history {
+			if filter(event) {
+				filtered = append(filtered, event)
+			}
+		}
+		history = filtered
+	}
 
 	go func(consumer chan<- *si.EventRecord, local <-chan *si.EventRecord, stop <-chan struct{}) {
 		// Store the refs of historical events; it's possible that some events are added to the
@@ -137,7 +176,8 @@ func (e *EventStreaming) createEventStreamInternal(stream *EventStream,
 	local chan *si.EventRecord,
 	consumer chan *si.EventRecord,
 	stop chan struct{},
-	name string) {
+	name string,
+	filter func(*si.EventRecord) bool) {
 	// stuff that needs locking
 	e.Lock()
 	defer e.Unlock()
@@ -148,6 +188,7 @@ func (e *EventStreaming) createEventStreamInternal(stream *EventStream,
 		stopCh:    stop,
 		name:      name,
 		createdAt: time.Now(),
+		filter:    filter,
 	}
 }
 
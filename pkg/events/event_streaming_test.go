@@ -134,6 +134,43 @@ func TestEventStreaming_SlowConsumer(t *testing.T) {
 	assert.Equal(t, 0, len(streaming.eventStreams))
 }
 
+func TestEventStreaming_QueueFilter(t *testing.T) {
+	buffer := newEventRingBuffer(10)
+	streaming := NewEventStreaming(buffer)
+	defer streaming.Close()
+
+	queueEvent := func(queuePath string, ts int64) *si.EventRecord {
+		return &si.EventRecord{Type: si.EventRecord_QUEUE, ObjectID: queuePath, TimestampNano: ts}
+	}
+	buffer.Add(queueEvent("root.parent", 1))
+	buffer.Add(queueEvent("root.sibling", 2))
+	es := streaming.CreateQueueEventStream("test", "root.parent", defaultCount)
+	all := streaming.CreateEventStream("all", 0)
+
+	streaming.PublishEvent(queueEvent("root.parent.child", 3))
+	streaming.PublishEvent(queueEvent("root.sibling.child", 4))
+	streaming.PublishEvent(queueEvent("root.parentsibling", 5))
+	streaming.PublishEvent(&si.EventRecord{Type: si.EventRecord_APP, ObjectID: "root.parent", TimestampNano: 6})
+	streaming.PublishEvent(queueEvent("root.parent", 7))
+
+	// the subtree subscriber only gets the events of the parent queue and its child
+	for _, expected := range []int64{1, 3, 7} {
+		assert.Equal(t, expected, receive(t, es.Events).TimestampNano)
+	}
+	select {
+	case event := <-es.Events:
+		t.Fatalf("unexpected event received: %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+	// the unfiltered subscriber gets all events
+	for _, expected := range []int64{3, 4, 5, 6, 7} {
+		assert.Equal(t, expected, receive(t, all.Events).TimestampNano)
+	}
+	streaming.RemoveEventStream(es)
+	streaming.RemoveEventStream(all)
+	assert.Equal(t, 0, len(streaming.eventStreams))
+}
+
 func TestGetEventStreams(t *testing.T) {
 	buffer := newEventRingBuffer(10)
 	streaming := NewEventStreaming(buffer)
@@ -68,6 +68,11 @@ type EventSystem interface {
 	// events piling up inside the channel buffers.
 	CreateEventStream(name string, count uint64) *EventStream
 
+	// CreateQueueEventStream creates an event stream (channel) for a consumer that only receives the events of the
+	// queue with the "queuePath" and all queues below it. The other arguments and the returned type are the same as
+	// for CreateEventStream. The stream must be removed using RemoveStream.
+	CreateQueueEventStream(name string, queuePath string, count uint64) *EventStream
+
 	// RemoveStream stops streaming for a given consumer.
 	// Consumers that no longer wish to be updated (e.g., a remote client
 	// disconnected) *must* call this method to gracefully stop the streaming.
@@ -101,6 +106,11 @@ func (ec *EventSystemImpl) CreateEventStream(name string, count uint64) *EventSt
 	return ec.streaming.CreateEventStream(name, count)
 }
 
+// CreateQueueEventStream creates an event stream for a queue subtree. See the interface for details.
+func (ec *EventSystemImpl) CreateQueueEventStream(name string, queuePath string, count uint64) *EventStream {
+	return ec.streaming.CreateQueueEventStream(name, queuePath, count)
+}
+
 // RemoveStream graceful termination of an event streaming for a consumer. See the interface for details.
 func (ec *EventSystemImpl) RemoveStream(consumer *EventStream) {
 	ec.streaming.RemoveEventStream(consumer)
@@ -32,6 +32,10 @@ func (m *EventSystem) CreateEventStream(_ string, _ uint64) *events.EventStream
 	return nil
 }
 
+func (m *EventSystem) CreateQueueEventStream(_ string, _ string, _ uint64) *events.EventStream {
+	return nil
+}
+
 func (m *EventSystem) RemoveStream(_ *events.EventStream) {
 }
 
@@ -49,6 +49,8 @@ const (
 	QueuePending        = "pending"
 	QueuePreempting     = "preempting"
 	QueueMaxRunningApps = "maxRunningApps"
+	QueueFairShare      = "fairShare"
+	QueueDeficit        = "deficit"
 )
 
 // QueueMetrics to declare queue metrics
@@ -100,7 +102,7 @@ func InitQueueMetrics(name string) *QueueMetrics {
 			Namespace:   Namespace,
 			Name:        "queue_resource",
 			ConstLabels: prometheus.Labels{"queue": name},
-			Help:        "Queue resource metrics. State of the resource includes `guaranteed`, `max`, `allocated`, `pending`, `preempting`, `maxRunningApps`.",
+			Help:        "Queue resource metrics. State of the resource includes `guaranteed`, `max`, `allocated`, `pending`, `preempting`, `maxRunningApps`, `fairShare`, `deficit`.",
 		}, []string{"state", "resource"})
 
 	q.resourceMetricsSubsystem = prometheus.NewGaugeVec(
@@ -108,7 +110,7 @@ func InitQueueMetrics(name string) *QueueMetrics {
 			Namespace: Namespace,
 			Subsystem: replaceStr,
 			Name:      "queue_resource",
-			Help:      "Queue resource metrics. State of the resource includes `guaranteed`, `max`, `allocated`, `pending`, `preempting`, `maxRunningApps`.",
+			Help:      "Queue resource metrics. State of the resource includes `guaranteed`, `max`, `allocated`, `pending`, `preempting`, `maxRunningApps`, `fairShare`, `deficit`.",
 		}, []string{"state", "resource"})
 
 	var queueMetricsList = []prometheus.Collector{
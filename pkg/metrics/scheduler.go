@@ -39,6 +39,9 @@ const (
 	NodeActive         = "active"
 	NodeDraining       = "draining"
 	NodeDecommissioned = "decommissioned"
+
+	PlacementDefault  = "default"
+	PlacementRejected = "rejected"
 )
 
 var resourceUsageRangeBuckets = []string{
@@ -65,6 +68,8 @@ type SchedulerMetrics struct {
 	sortingLatency        *prometheus.HistogramVec
 	tryNodeLatency        prometheus.Histogram
 	tryPreemptionLatency  prometheus.Histogram
+	placementRuleMatch    *prometheus.CounterVec
+	placementFallThrough  *prometheus.CounterVec
 	lock                  locking.RWMutex
 }
 
@@ -146,6 +151,22 @@ func InitSchedulerMetrics() *SchedulerMetrics {
 		},
 	)
 
+	s.placementRuleMatch = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "placement_rule_match_total",
+			Help:      "Total number of applications placed by a placement rule, by rule name.",
+		}, []string{"rule"})
+
+	s.placementFallThrough = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "placement_fallthrough_total",
+			Help:      "Total number of applications not placed by any placement rule. Result of the placement includes `default` and `rejected`.",
+		}, []string{"result"})
+
 	// Register the metrics
 	var metricsList = []prometheus.Collector{
 		s.containerAllocation,
@@ -156,6 +177,8 @@ func InitSchedulerMetrics() *SchedulerMetrics {
 		s.sortingLatency,
 		s.tryNodeLatency,
 		s.tryPreemptionLatency,
+		s.placementRuleMatch,
+		s.placementFallThrough,
 	}
 	for _, metric := range metricsList {
 		if err := prometheus.Register(metric); err != nil {
@@ -172,6 +195,7 @@ func (m *SchedulerMetrics) Reset() {
 	m.application.Reset()
 	m.applicationSubmission.Reset()
 	m.containerAllocation.Reset()
+	m.placementRuleMatch.Reset()
 }
 
 func SinceInSeconds(start time.Time) float64 {
@@ -415,3 +439,29 @@ func (m *SchedulerMetrics) GetDrainingNodes() (int, error) {
 func (m *SchedulerMetrics) IncTotalDecommissionedNodes() {
 	m.node.WithLabelValues(NodeDecommissioned).Inc()
 }
+
+func (m *SchedulerMetrics) IncPlacementRuleMatch(rule string) {
+	m.placementRuleMatch.WithLabelValues(rule).Inc()
+}
+
+func (m *SchedulerMetrics) GetPlacementRuleMatch(rule string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.placementRuleMatch.WithLabelValues(rule).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
+func (m *SchedulerMetrics) IncPlacementFallThrough(result string) {
+	m.placementFallThrough.WithLabelValues(result).Inc()
+}
+
+func (m *SchedulerMetrics) GetPlacementFallThrough(result string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.placementFallThrough.WithLabelValues(result).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
@@ -96,6 +96,31 @@ func TestSchedulerApplicationsRejected(t *testing.T) {
 	assert.Equal(t, curr, 1)
 }
 
+func TestPlacementRuleMatch(t *testing.T) {
+	sm = getSchedulerMetrics(t)
+	defer unregisterMetrics()
+
+	sm.IncPlacementRuleMatch("provided")
+	verifyMetric(t, 1, "provided", "yunikorn_scheduler_placement_rule_match_total", dto.MetricType_COUNTER, "rule")
+
+	curr, err := sm.GetPlacementRuleMatch("provided")
+	assert.NilError(t, err)
+	assert.Equal(t, curr, 1)
+}
+
+func TestPlacementFallThrough(t *testing.T) {
+	sm = getSchedulerMetrics(t)
+	defer unregisterMetrics()
+
+	sm.IncPlacementFallThrough(PlacementDefault)
+	curr, err := sm.GetPlacementFallThrough(PlacementDefault)
+	assert.NilError(t, err)
+	assert.Equal(t, curr, 1)
+	curr, err = sm.GetPlacementFallThrough(PlacementRejected)
+	assert.NilError(t, err)
+	assert.Equal(t, curr, 0)
+}
+
 func TestSchedulerApplicationsRunning(t *testing.T) {
 	sm = getSchedulerMetrics(t)
 	defer unregisterMetrics()
@@ -226,4 +251,6 @@ func unregisterMetrics() {
 	prometheus.Unregister(sm.sortingLatency)
 	prometheus.Unregister(sm.tryNodeLatency)
 	prometheus.Unregister(sm.tryPreemptionLatency)
+	prometheus.Unregister(sm.placementRuleMatch)
+	prometheus.Unregister(sm.placementFallThrough)
 }
@@ -0,0 +1,114 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+)
+
+// AllocationEventType is the allocation lifecycle transition reported to an AllocationSink.
+type AllocationEventType int
+
+const (
+	// AllocationAllocated an allocation was added to the partition, scheduled or recovered.
+	AllocationAllocated AllocationEventType = iota
+	// AllocationReplaced a placeholder allocation was replaced by the real allocation.
+	AllocationReplaced
+	// AllocationReleased an allocation was removed from the partition.
+	AllocationReleased
+)
+
+func (t AllocationEventType) String() string {
+	return [...]string{"Allocated", "Replaced", "Released"}[t]
+}
+
+// AllocationEvent describes one allocation lifecycle transition in a partition.
+type AllocationEvent struct {
+	Type          AllocationEventType
+	PartitionName string
+	QueuePath     string
+	ApplicationID string
+	AllocationKey string
+	NodeID        string
+	Resource      *resources.Resource
+	Placeholder   bool
+	Time          time.Time
+}
+
+// AllocationSink receives the allocation lifecycle events of a partition, for instance to stream them to an external
+// audit system. Record is called synchronously from the scheduling and update paths without holding the partition lock:
+// implementations must be safe for concurrent use and must not block.
+type AllocationSink interface {
+	Record(event AllocationEvent)
+}
+
+// noopAllocationSink is the sink used when no sink is registered on the partition.
+type noopAllocationSink struct{}
+
+func (noopAllocationSink) Record(AllocationEvent) {}
+
+var defaultAllocationSink AllocationSink = noopAllocationSink{}
+
+// RegisterAllocationSink adds a sink that receives all allocation lifecycle events of the partition.
+// A nil sink is ignored.
+func (pc *PartitionContext) RegisterAllocationSink(sink AllocationSink) {
+	if sink == nil {
+		return
+	}
+	pc.Lock()
+	defer pc.Unlock()
+	pc.allocationSinks = append(pc.allocationSinks, sink)
+}
+
+// getAllocationSinks returns a copy of the registered sinks, or the no-op sink if none are registered.
+func (pc *PartitionContext) getAllocationSinks() []AllocationSink {
+	pc.RLock()
+	defer pc.RUnlock()
+	if len(pc.allocationSinks) == 0 {
+		return []AllocationSink{defaultAllocationSink}
+	}
+	sinks := make([]AllocationSink, len(pc.allocationSinks))
+	copy(sinks, pc.allocationSinks)
+	return sinks
+}
+
+// recordAllocationEvent sends the event for the allocation to all registered sinks.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) recordAllocationEvent(eventType AllocationEventType, alloc *objects.Allocation, queuePath string) {
+	if alloc == nil {
+		return
+	}
+	event := AllocationEvent{
+		Type:          eventType,
+		PartitionName: pc.Name,
+		QueuePath:     queuePath,
+		ApplicationID: alloc.GetApplicationID(),
+		AllocationKey: alloc.GetAllocationKey(),
+		NodeID:        alloc.GetNodeID(),
+		Resource:      alloc.GetAllocatedResource().Clone(),
+		Placeholder:   alloc.IsPlaceholder(),
+		Time:          time.Now(),
+	}
+	for _, sink := range pc.getAllocationSinks() {
+		sink.Record(event)
+	}
+}
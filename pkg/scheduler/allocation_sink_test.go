@@ -0,0 +1,132 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/locking"
+	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
+)
+
+type capturingSink struct {
+	events []AllocationEvent
+	locking.Mutex
+}
+
+func (cs *capturingSink) Record(event AllocationEvent) {
+	cs.Lock()
+	defer cs.Unlock()
+	cs.events = append(cs.events, event)
+}
+
+func (cs *capturingSink) getEvents() []AllocationEvent {
+	cs.Lock()
+	defer cs.Unlock()
+	return cs.events
+}
+
+func TestAllocationEventTypeString(t *testing.T) {
+	assert.Equal(t, AllocationAllocated.String(), "Allocated")
+	assert.Equal(t, AllocationReplaced.String(), "Replaced")
+	assert.Equal(t, AllocationReleased.String(), "Released")
+}
+
+func TestRegisterAllocationSink(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	sinks := partition.getAllocationSinks()
+	assert.Equal(t, len(sinks), 1, "expected only the default sink")
+	assert.Equal(t, sinks[0], defaultAllocationSink, "expected the no-op sink without registration")
+
+	partition.RegisterAllocationSink(nil)
+	assert.Equal(t, partition.getAllocationSinks()[0], defaultAllocationSink, "nil sink should be ignored")
+
+	sink1 := &capturingSink{}
+	sink2 := &capturingSink{}
+	partition.RegisterAllocationSink(sink1)
+	partition.RegisterAllocationSink(sink2)
+	sinks = partition.getAllocationSinks()
+	assert.Equal(t, len(sinks), 2, "expected both registered sinks")
+	assert.Equal(t, sinks[0], AllocationSink(sink1), "unexpected first sink")
+	assert.Equal(t, sinks[1], AllocationSink(sink2), "unexpected second sink")
+}
+
+func TestAllocationSinkEvents(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	sink := &capturingSink{}
+	partition.RegisterAllocationSink(sink)
+
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+
+	// scheduled allocation
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	events := sink.getEvents()
+	assert.Equal(t, len(events), 1, "expected one event after allocation")
+	assert.Equal(t, events[0].Type, AllocationAllocated, "unexpected event type")
+	assert.Equal(t, events[0].PartitionName, partition.Name, "unexpected partition")
+	assert.Equal(t, events[0].QueuePath, "root.leaf", "unexpected queue")
+	assert.Equal(t, events[0].ApplicationID, appID1, "unexpected application")
+	assert.Equal(t, events[0].AllocationKey, allocKey, "unexpected allocation key")
+	assert.Equal(t, events[0].NodeID, result.NodeID, "unexpected node")
+	assert.Assert(t, resources.Equals(events[0].Resource, res), "unexpected resource")
+
+	// existing allocation added by the RM
+	_, allocCreated, err := partition.UpdateAllocation(newAllocation(allocKey2, appID1, nodeID2, res))
+	assert.NilError(t, err, "failed to add existing allocation")
+	assert.Assert(t, allocCreated, "allocation should have been created")
+	events = sink.getEvents()
+	assert.Equal(t, len(events), 2, "expected an event for the existing allocation")
+	assert.Equal(t, events[1].Type, AllocationAllocated, "unexpected event type")
+	assert.Equal(t, events[1].AllocationKey, allocKey2, "unexpected allocation key")
+	assert.Equal(t, events[1].NodeID, nodeID2, "unexpected node")
+
+	// release
+	partition.removeAllocation(&si.AllocationRelease{
+		PartitionName:   partition.Name,
+		ApplicationID:   appID1,
+		AllocationKey:   allocKey,
+		TerminationType: si.TerminationType_STOPPED_BY_RM,
+	})
+	events = sink.getEvents()
+	assert.Equal(t, len(events), 3, "expected an event after release")
+	assert.Equal(t, events[2].Type, AllocationReleased, "unexpected event type")
+	assert.Equal(t, events[2].AllocationKey, allocKey, "unexpected allocation key")
+	assert.Equal(t, events[2].QueuePath, "root.leaf", "unexpected queue")
+
+	// removing the application releases the remaining allocation
+	partition.removeApplication(appID1)
+	events = sink.getEvents()
+	assert.Equal(t, len(events), 4, "expected an event after application removal")
+	assert.Equal(t, events[3].Type, AllocationReleased, "unexpected event type")
+	assert.Equal(t, events[3].AllocationKey, allocKey2, "unexpected allocation key")
+}
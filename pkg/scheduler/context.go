@@ -29,12 +29,15 @@ import (
 	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/common/security"
 	"github.com/apache/yunikorn-core/pkg/handler"
 	"github.com/apache/yunikorn-core/pkg/locking"
 	"github.com/apache/yunikorn-core/pkg/log"
 	"github.com/apache/yunikorn-core/pkg/metrics"
 	"github.com/apache/yunikorn-core/pkg/rmproxy/rmevent"
 	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement"
+	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/yunikorn-core/pkg/webservice/dao"
 	siCommon "github.com/apache/yunikorn-scheduler-interface/lib/go/common"
 	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
@@ -214,6 +217,7 @@ func (cc *ClusterContext) processRMConfigUpdateEvent(event *rmevent.RMConfigUpda
 	}
 
 	// set extra configuration
+	policy := security.GetWildcardPolicy()
 	configs.SetConfigMap(event.ExtraConfig)
 
 	// load the config this returns a validated configuration
@@ -227,9 +231,10 @@ func (cc *ClusterContext) processRMConfigUpdateEvent(event *rmevent.RMConfigUpda
 		event.Channel <- &rmevent.Result{Succeeded: false, Reason: err.Error()}
 		return
 	}
-	// skip update if config has not changed
+	// skip update if config has not changed: the ACLs are parsed using the wildcard policy and must be recreated if
+	// only the policy has changed
 	oldConf := configs.ConfigContext.Get(cc.policyGroup)
-	if conf.Checksum == oldConf.Checksum {
+	if conf.Checksum == oldConf.Checksum && policy == security.GetWildcardPolicy() {
 		event.Channel <- &rmevent.Result{
 			Succeeded: true,
 		}
@@ -356,38 +361,56 @@ func (cc *ClusterContext) UpdateRMSchedulerConfig(rmID string, config []byte) er
 // During tests this is called outside of the even system to init.
 // unlocked call must only be called holding the ClusterContext lock
 func (cc *ClusterContext) updateSchedulerConfig(conf *configs.SchedulerConfig, rmID string) error {
-	visited := map[string]bool{}
-	var err error
-	// walk over the partitions in the config: update existing ones
-	for _, p := range conf.Partitions {
-		partitionName := common.GetNormalizedPartitionName(p.Name, rmID)
-		p.Name = partitionName
-		part, ok := cc.partitions[p.Name]
-		if ok {
-			// make sure the new info passes all checks
-			_, err = newPartitionContext(p, rmID, nil, true)
-			if err != nil {
-				return err
-			}
-			// checks passed perform the real update
-			log.Log(log.SchedContext).Info("updating partitions", zap.String("partitionName", partitionName))
-			err = part.updatePartitionDetails(p)
-			if err != nil {
-				return err
-			}
-		} else {
-			// not found: new partition, no checks needed
-			log.Log(log.SchedContext).Info("added partitions", zap.String("partitionName", partitionName))
+	// stage the full config first: nothing is changed unless all partitions pass the checks
+	staged, err := cc.stageSchedulerConfig(conf, rmID)
+	if err != nil {
+		log.Log(log.SchedContext).Error("scheduler config rejected, keeping current config",
+			zap.String("rmID", rmID),
+			zap.Error(err))
+		return err
+	}
+	return cc.applySchedulerConfig(staged, configs.ConfigContext.Get(cc.policyGroup), rmID)
+}
 
-			part, err = newPartitionContext(p, rmID, cc, false)
-			if err != nil {
-				return err
-			}
-			go part.partitionManager.Run()
-			cc.partitions[partitionName] = part
+// applySchedulerConfig applies the staged partitions: existing partitions are updated first, new partitions are
+// added after all updates succeeded. If a partition fails the partitions that were already updated are restored to
+// the previous config and no partition is added or removed.
+// unlocked call must only be called holding the ClusterContext lock
+func (cc *ClusterContext) applySchedulerConfig(staged []stagedPartition, previous *configs.SchedulerConfig, rmID string) error {
+	updated := make([]*PartitionContext, 0, len(staged))
+	for _, sp := range staged {
+		if sp.current == nil {
+			continue
+		}
+		log.Log(log.SchedContext).Info("updating partitions", zap.String("partitionName", sp.conf.Name))
+		// the partition could be partially updated: restore it with the others
+		updated = append(updated, sp.current)
+		if err := sp.current.updatePartitionDetails(sp.conf); err != nil {
+			cc.restorePartitions(updated, previous, rmID)
+			return err
 		}
-		// add it to the partitions to update
-		visited[p.Name] = true
+	}
+	added := make([]*PartitionContext, 0, len(staged)-len(updated))
+	for _, sp := range staged {
+		if sp.current != nil {
+			continue
+		}
+		part, err := newPartitionContext(sp.conf, rmID, cc, false)
+		if err != nil {
+			cc.restorePartitions(updated, previous, rmID)
+			return err
+		}
+		added = append(added, part)
+	}
+	visited := map[string]bool{}
+	for _, part := range updated {
+		visited[part.Name] = true
+	}
+	for _, part := range added {
+		log.Log(log.SchedContext).Info("added partitions", zap.String("partitionName", part.Name))
+		go part.partitionManager.Run()
+		cc.partitions[part.Name] = part
+		visited[part.Name] = true
 	}
 
 	// get the removed partitions, mark them as deleted
@@ -402,6 +425,67 @@ func (cc *ClusterContext) updateSchedulerConfig(conf *configs.SchedulerConfig, r
 	return nil
 }
 
+// restorePartitions applies the previous config to the partitions after a failed config update. A partition without a
+// previous config, or that fails the update, is logged and left as is.
+// unlocked call must only be called holding the ClusterContext lock
+func (cc *ClusterContext) restorePartitions(parts []*PartitionContext, previous *configs.SchedulerConfig, rmID string) {
+	confs := make(map[string]configs.PartitionConfig)
+	if previous != nil {
+		for _, p := range previous.Partitions {
+			p.Name = common.GetNormalizedPartitionName(p.Name, rmID)
+			confs[p.Name] = p
+		}
+	}
+	for _, part := range parts {
+		conf, ok := confs[part.Name]
+		if !ok {
+			log.Log(log.SchedContext).Error("no previous config, partition not restored after failed config update",
+				zap.String("partitionName", part.Name))
+			continue
+		}
+		log.Log(log.SchedContext).Info("restoring partition after failed config update",
+			zap.String("partitionName", part.Name))
+		if err := part.updatePartitionDetails(conf); err != nil {
+			log.Log(log.SchedContext).Error("partition restore failed",
+				zap.String("partitionName", part.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+// stagedPartition is a partition config that passed all checks and is ready to be applied.
+// The current partition is nil if the config defines a new partition.
+type stagedPartition struct {
+	conf    configs.PartitionConfig
+	current *PartitionContext
+}
+
+// stageSchedulerConfig checks all partitions in the config without changing the active scheduler state.
+// All partitions are checked, the returned error combines the failures of every partition that did not pass.
+// unlocked call must only be called holding the ClusterContext lock
+func (cc *ClusterContext) stageSchedulerConfig(conf *configs.SchedulerConfig, rmID string) ([]stagedPartition, error) {
+	staged := make([]stagedPartition, 0, len(conf.Partitions))
+	var errs []error
+	for _, p := range conf.Partitions {
+		p.Name = common.GetNormalizedPartitionName(p.Name, rmID)
+		// make sure the new info passes all checks using a silent throw away partition
+		if _, err := newPartitionContext(p, rmID, nil, true); err != nil {
+			errs = append(errs, fmt.Errorf("partition %s: %w", p.Name, err))
+			continue
+		}
+		// the placement manager does not fail on broken rules, check them separately
+		if err := placement.ValidateRules(p.PlacementRules); err != nil {
+			errs = append(errs, fmt.Errorf("partition %s: placement rules: %w", p.Name, err))
+			continue
+		}
+		staged = append(staged, stagedPartition{conf: p, current: cc.partitions[p.Name]})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return staged, nil
+}
+
 // Get the config name.
 func (cc *ClusterContext) GetPolicyGroup() string {
 	cc.RLock()
@@ -443,6 +527,30 @@ func (cc *ClusterContext) GetPartition(partitionName string) *PartitionContext {
 	return cc.partitions[partitionName]
 }
 
+// checkAppIDScope checks the application ID against the configured uniqueness scope. With a global scope an error is
+// returned if the ID is used by an application in any other partition. Uniqueness within the partition is enforced
+// when the application is added to the partition.
+func (cc *ClusterContext) checkAppIDScope(appID, partitionName string) error {
+	value := configs.GetConfigMap()[configs.CMAppIDScope]
+	scope, err := policies.AppIDScopePolicyFromString(value)
+	if err != nil {
+		log.Log(log.SchedContext).Warn("unknown application ID scope, using default",
+			zap.String("scope", value),
+			zap.Stringer("default", scope))
+	}
+	if scope != policies.GlobalAppIDScope {
+		return nil
+	}
+	cc.RLock()
+	defer cc.RUnlock()
+	for name, partition := range cc.partitions {
+		if name != partitionName && partition.GetApplication(appID) != nil {
+			return fmt.Errorf("application %s already exists in partition %s", appID, name)
+		}
+	}
+	return nil
+}
+
 func (cc *ClusterContext) GetPartitionWithoutClusterID(partitionName string) *PartitionContext {
 	cc.RLock()
 	defer cc.RUnlock()
@@ -520,6 +628,19 @@ func (cc *ClusterContext) handleRMUpdateApplicationEvent(event *rmevent.RMUpdate
 				zap.Error(err))
 			continue
 		}
+		// the application ID might be in use in another partition
+		if err = cc.checkAppIDScope(app.ApplicationID, partition.Name); err != nil {
+			rejectedApps = append(rejectedApps, &si.RejectedApplication{
+				ApplicationID: app.ApplicationID,
+				Reason:        err.Error(),
+			})
+			partition.AddRejectedApplication(objects.NewApplication(app, ugi, cc.rmEventHandler, request.RmID), err.Error())
+			log.Log(log.SchedContext).Error("Failed to add application to partition (application ID not unique)",
+				zap.String("applicationID", app.ApplicationID),
+				zap.String("partitionName", app.PartitionName),
+				zap.Error(err))
+			continue
+		}
 		// create a new app object and add it to the partition (partition logs details)
 		schedApp := objects.NewApplication(app, ugi, cc.rmEventHandler, request.RmID)
 		if err = partition.AddApplication(schedApp); err != nil {
@@ -659,7 +780,8 @@ func (cc *ClusterContext) updateNode(nodeInfo *si.NodeInfo) {
 	switch nodeInfo.Action {
 	case si.NodeInfo_UPDATE:
 		if sr := nodeInfo.SchedulableResource; sr != nil {
-			partition.updatePartitionResource(node.SetCapacity(resources.NewResourceFromProto(sr)))
+			capacity := partition.applyNodeOverhead(node, resources.NewResourceFromProto(sr))
+			partition.updatePartitionResource(node.SetCapacity(partition.applyOvercommit(capacity)))
 		}
 	case si.NodeInfo_DRAIN_NODE:
 		if node.IsSchedulable() {
@@ -718,6 +840,8 @@ func (cc *ClusterContext) handleRMUpdateAllocationEvent(event *rmevent.RMUpdateA
 func (cc *ClusterContext) processAllocations(request *si.AllocationRequest) {
 	// Send rejected allocations back to RM
 	rejectedAllocs := make([]*si.RejectedAllocation, 0)
+	// Applications rejected by the zero request policy on their first request
+	rejectedApps := make([]*si.RejectedApplication, 0)
 
 	// Send to scheduler
 	for _, siAlloc := range request.Allocations {
@@ -747,6 +871,12 @@ func (cc *ClusterContext) processAllocations(request *si.AllocationRequest) {
 				ApplicationID: siAlloc.ApplicationID,
 				Reason:        err.Error(),
 			})
+			if isAppRejected(err) {
+				rejectedApps = append(rejectedApps, &si.RejectedApplication{
+					ApplicationID: siAlloc.ApplicationID,
+					Reason:        err.Error(),
+				})
+			}
 			log.Log(log.SchedContext).Error("Invalid allocation update requested by shim",
 				zap.String("partition", siAlloc.PartitionName),
 				zap.String("nodeID", siAlloc.NodeID),
@@ -768,6 +898,14 @@ func (cc *ClusterContext) processAllocations(request *si.AllocationRequest) {
 			RejectedAllocations: rejectedAllocs,
 		})
 	}
+	if len(rejectedApps) > 0 {
+		cc.rmEventHandler.HandleEvent(
+			&rmevent.RMApplicationUpdateEvent{
+				RmID:                 request.RmID,
+				AcceptedApplications: make([]*si.AcceptedApplication, 0),
+				RejectedApplications: rejectedApps,
+			})
+	}
 }
 
 func (cc *ClusterContext) processAllocationReleases(releases []*si.AllocationRelease, rmID string) {
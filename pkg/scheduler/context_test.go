@@ -28,6 +28,7 @@ import (
 
 	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/common/security"
 	"github.com/apache/yunikorn-core/pkg/metrics"
 	"github.com/apache/yunikorn-core/pkg/rmproxy/rmevent"
 	siCommon "github.com/apache/yunikorn-scheduler-interface/lib/go/common"
@@ -40,6 +41,7 @@ type mockEventHandler struct {
 	eventHandled    bool
 	rejectedNodes   []*si.RejectedNode
 	acceptedNodes   []*si.AcceptedNode
+	rejectedApps    []*si.RejectedApplication
 	newAllocHandler func(*rmevent.RMNewAllocationsEvent)
 }
 
@@ -61,6 +63,10 @@ func (m *mockEventHandler) HandleEvent(ev interface{}) {
 	if allocEvent, ok := ev.(*rmevent.RMNewAllocationsEvent); ok && m.newAllocHandler != nil {
 		m.newAllocHandler(allocEvent)
 	}
+
+	if appEvent, ok := ev.(*rmevent.RMApplicationUpdateEvent); ok {
+		m.rejectedApps = append(m.rejectedApps, appEvent.RejectedApplications...)
+	}
 }
 
 func createTestContext(t *testing.T, partitionName string) *ClusterContext {
@@ -300,6 +306,109 @@ func TestContextDrainingNodeBackToSchedulableMetrics(t *testing.T) {
 	verifyMetrics(t, 0, "draining")
 }
 
+func TestContext_AppIDScope(t *testing.T) {
+	defer configs.SetConfigMap(map[string]string{})
+	const otherPartition = "other"
+	addApp := func(context *ClusterContext, partitionName string) {
+		appReq := &si.ApplicationRequest{
+			New: []*si.AddApplicationRequest{
+				{
+					QueueName:     defQueue,
+					PartitionName: partitionName,
+					Ugi: &si.UserGroupInformation{
+						User:   "testuser",
+						Groups: []string{"testgroup"},
+					},
+					ApplicationID: appID1,
+				},
+			},
+			RmID: "rm:123",
+		}
+		context.handleRMUpdateApplicationEvent(&rmevent.RMUpdateApplicationEvent{Request: appReq})
+	}
+	tests := []struct {
+		name     string
+		scope    string
+		accepted bool
+	}{
+		{"default scope", "", true},
+		{"partition scope", "partition", true},
+		{"unknown scope", "unknown", true},
+		{"global scope", "global", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs.SetConfigMap(map[string]string{configs.CMAppIDScope: tt.scope})
+			context := createTestContext(t, pName)
+			other := createTestContext(t, otherPartition).GetPartition(otherPartition)
+			context.partitions[otherPartition] = other
+			addApp(context, pName)
+			assert.Assert(t, context.GetPartition(pName).GetApplication(appID1) != nil, "first application should be accepted")
+			addApp(context, otherPartition)
+			if tt.accepted {
+				assert.Assert(t, other.GetApplication(appID1) != nil, "same ID should be accepted in another partition")
+				assert.Equal(t, len(other.GetRejectedApplications()), 0, "no application should be rejected")
+			} else {
+				assert.Assert(t, other.GetApplication(appID1) == nil, "same ID should be rejected in another partition")
+				rejected := other.GetRejectedApplications()
+				assert.Equal(t, len(rejected), 1, "application should be rejected")
+				assert.Equal(t, rejected[0].GetRejectedMessage(), "application app-1 already exists in partition default")
+			}
+			// duplicates within a partition are always rejected
+			addApp(context, pName)
+			assert.Equal(t, len(context.GetPartition(pName).GetRejectedApplications()), 1, "duplicate in the same partition should be rejected")
+		})
+	}
+}
+
+func TestContext_ZeroRequestPlacementRejected(t *testing.T) {
+	context := createTestContext(t, pName)
+	eventHandler := context.rmEventHandler.(*mockEventHandler) //nolint:errcheck
+	partition := context.GetPartition(pName)
+	assert.Assert(t, partition != nil)
+	partition.updateZeroRequest(configs.PartitionConfig{
+		ZeroRequest: configs.PartitionZeroRequestConfig{Policy: "route", Queue: "root.default"},
+	})
+
+	// the app is routed on submit, the placement fails on the first request
+	appReq := &si.ApplicationRequest{
+		New: []*si.AddApplicationRequest{
+			{
+				QueueName:     "root.in$valid",
+				PartitionName: pName,
+				Ugi: &si.UserGroupInformation{
+					User:   "testuser",
+					Groups: []string{"testgroup"},
+				},
+				ApplicationID: appID1,
+			},
+		},
+		RmID: "rm:123",
+	}
+	context.handleRMUpdateApplicationEvent(&rmevent.RMUpdateApplicationEvent{Request: appReq})
+	assert.Assert(t, partition.getApplication(appID1) != nil, "app should have been routed")
+	assert.Equal(t, len(eventHandler.rejectedApps), 0, "no app should be rejected on submit")
+	allocReq := &si.AllocationRequest{
+		Allocations: []*si.Allocation{
+			{
+				AllocationKey: allocKey,
+				ResourcePerAlloc: &si.Resource{
+					Resources: map[string]*si.Quantity{
+						"first": {Value: 1},
+					},
+				},
+				ApplicationID: appID1,
+				PartitionName: pName,
+			},
+		},
+		RmID: "rm:123",
+	}
+	context.handleRMUpdateAllocationEvent(&rmevent.RMUpdateAllocationEvent{Request: allocReq})
+	assert.Assert(t, partition.getApplication(appID1) == nil, "app should have been removed")
+	assert.Equal(t, len(eventHandler.rejectedApps), 1, "RM should be informed about the rejected app")
+	assert.Equal(t, eventHandler.rejectedApps[0].ApplicationID, appID1, "unexpected app rejected")
+}
+
 func TestContext_OnAllocationNotification(t *testing.T) {
 	context := createTestContext(t, pName)
 	eventHandler := context.rmEventHandler.(*mockEventHandler) //nolint:errcheck
@@ -430,3 +539,124 @@ outer:
 
 	assert.Assert(t, checked, "Failed to find metric")
 }
+
+func TestContext_UpdateRMSchedulerConfig(t *testing.T) {
+	partitionName := "[test]" + pName
+	context := createTestContext(t, partitionName)
+	context.policyGroup = "test"
+	configs.ConfigContext.Set(context.policyGroup, &configs.SchedulerConfig{Checksum: "initial"})
+	part := context.GetPartition(partitionName)
+	assert.Assert(t, part != nil, "test partition not found")
+
+	// valid reload: new queue is added and the config context is updated
+	validConf := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+          - name: added
+`
+	err := context.UpdateRMSchedulerConfig("test", []byte(validConf))
+	assert.NilError(t, err, "valid config reload should not have failed")
+	assert.Assert(t, part.GetQueue("root.added") != nil, "queue should have been added on reload")
+	checksum := configs.ConfigContext.Get(context.policyGroup).Checksum
+	assert.Assert(t, checksum != "initial", "config context should have been updated")
+
+	// broken reload: the first partition is valid, the second one has an unknown placement rule
+	brokenConf := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+          - name: rejected
+  - name: other
+    placementrules:
+      - name: unknown
+    queues:
+      - name: root
+`
+	err = context.UpdateRMSchedulerConfig("test", []byte(brokenConf))
+	assert.ErrorContains(t, err, "partition [test]other", "broken config reload should have failed")
+	assert.Assert(t, part.GetQueue("root.rejected") == nil, "queue should not have been added on failed reload")
+	assert.Assert(t, part.GetQueue("root.added") != nil, "queue should still exist after failed reload")
+	assert.Assert(t, context.GetPartition("[test]other") == nil, "partition should not have been added on failed reload")
+	assert.Equal(t, configs.ConfigContext.Get(context.policyGroup).Checksum, checksum, "config context should not have changed")
+}
+
+func TestContext_ApplySchedulerConfigRestore(t *testing.T) {
+	context := createTestContext(t, "[test]default")
+	previous := &configs.SchedulerConfig{
+		Partitions: []configs.PartitionConfig{
+			{
+				Name:   "default",
+				Queues: []configs.QueueConfig{{Name: "root", Parent: true, SubmitACL: "*", Queues: []configs.QueueConfig{{Name: "default"}}}},
+			},
+			{
+				Name:   "second",
+				Queues: []configs.QueueConfig{{Name: "root", Parent: true, SubmitACL: "*"}},
+			},
+		},
+	}
+	second, err := newPartitionContext(configs.PartitionConfig{Name: "[test]second", Queues: previous.Partitions[1].Queues}, "test", context, false)
+	assert.NilError(t, err, "partition create should not have failed with error")
+	context.partitions[second.Name] = second
+	first := context.GetPartition("[test]default")
+
+	// the second partition fails after the first one was updated: bypass the staging checks with a broken ACL
+	staged := []stagedPartition{
+		{
+			conf: configs.PartitionConfig{
+				Name:   "[test]default",
+				Queues: []configs.QueueConfig{{Name: "root", Parent: true, SubmitACL: "*", Queues: []configs.QueueConfig{{Name: "default"}, {Name: "added"}}}},
+			},
+			current: first,
+		},
+		{
+			conf: configs.PartitionConfig{
+				Name:   "[test]second",
+				Queues: []configs.QueueConfig{{Name: "root", Parent: true, SubmitACL: "a b c"}},
+			},
+			current: second,
+		},
+		{
+			conf: configs.PartitionConfig{
+				Name:   "[test]new",
+				Queues: []configs.QueueConfig{{Name: "root", Parent: true}},
+			},
+		},
+	}
+	err = context.applySchedulerConfig(staged, previous, "test")
+	assert.ErrorContains(t, err, "multiple spaces found in ACL")
+	added := first.GetQueue("root.added")
+	assert.Assert(t, added != nil && added.IsDraining(), "queue added by the failed update should have been removed")
+	assert.Assert(t, first.GetQueue("root.default").IsRunning(), "queue of the previous config should be running")
+	assert.Assert(t, second.GetQueue("root").CheckSubmitAccess(security.UserGroup{User: "testuser"}), "ACL of the previous config should have been restored")
+	assert.Assert(t, context.GetPartition("[test]new") == nil, "partition should not have been added on failed update")
+}
+
+func TestContext_StageSchedulerConfigAllErrors(t *testing.T) {
+	context := createTestContext(t, pName)
+	conf := &configs.SchedulerConfig{
+		Partitions: []configs.PartitionConfig{
+			{
+				Name:           "first",
+				PlacementRules: []configs.PlacementRule{{Name: "unknown"}},
+				Queues:         []configs.QueueConfig{{Name: "root", Parent: true}},
+			},
+			{
+				Name:   "second",
+				Queues: []configs.QueueConfig{{Name: "notroot"}},
+			},
+		},
+	}
+	staged, err := context.stageSchedulerConfig(conf, "test")
+	assert.Assert(t, staged == nil, "nothing should be staged on failure")
+	assert.ErrorContains(t, err, "partition [test]first")
+	assert.ErrorContains(t, err, "partition [test]second")
+}
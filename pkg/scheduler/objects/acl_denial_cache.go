@@ -0,0 +1,117 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/locking"
+)
+
+const (
+	aclDenialTTL     = 5 * time.Second // time a submit access denial is remembered
+	aclDenialMaxSize = 1024            // maximum number of denials remembered
+)
+
+// aclDenialCache remembers recent submit access denials by queue, user and groups. Repeated requests from a denied user
+// are rejected without evaluating the ACLs of the queue hierarchy again. The cache is kept on the root queue and must
+// be cleared whenever an ACL in the hierarchy changes.
+type aclDenialCache struct {
+	denials map[string]time.Time // expiry time of the denial by queue, user and groups
+
+	locking.Mutex
+}
+
+func newACLDenialCache() *aclDenialCache {
+	return &aclDenialCache{
+		denials: make(map[string]time.Time),
+	}
+}
+
+// aclDenialKey returns the cache key for the queue and user. The groups are part of the key, sorted: the same user
+// with a different set of groups can be allowed access.
+func aclDenialKey(queuePath string, user security.UserGroup) string {
+	groups := append([]string{}, user.Groups...)
+	sort.Strings(groups)
+	return queuePath + "|" + user.User + "|" + strings.Join(groups, ",")
+}
+
+// isDenied returns true if access to the queue was denied for the user within the TTL.
+func (c *aclDenialCache) isDenied(queuePath string, user security.UserGroup) bool {
+	if c == nil {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	key := aclDenialKey(queuePath, user)
+	expiry, ok := c.denials[key]
+	if !ok {
+		return false
+	}
+	if !getClock().Now().Before(expiry) {
+		delete(c.denials, key)
+		return false
+	}
+	return true
+}
+
+// add records a denial of access to the queue for the user. When the cache is full the expired denials are removed,
+// if it is still full the denial is not recorded.
+func (c *aclDenialCache) add(queuePath string, user security.UserGroup) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	now := getClock().Now()
+	if len(c.denials) >= aclDenialMaxSize {
+		for key, expiry := range c.denials {
+			if !now.Before(expiry) {
+				delete(c.denials, key)
+			}
+		}
+		if len(c.denials) >= aclDenialMaxSize {
+			return
+		}
+	}
+	c.denials[aclDenialKey(queuePath, user)] = now.Add(aclDenialTTL)
+}
+
+// clear removes all recorded denials.
+func (c *aclDenialCache) clear() {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.denials = make(map[string]time.Time)
+}
+
+// size returns the number of recorded denials, including expired ones that were not removed yet.
+func (c *aclDenialCache) size() int {
+	if c == nil {
+		return 0
+	}
+	c.Lock()
+	defer c.Unlock()
+	return len(c.denials)
+}
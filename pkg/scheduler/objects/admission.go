@@ -0,0 +1,81 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/log"
+)
+
+// AdmissionHook gives an external system a final veto over an allocation. The hook is consulted after the node has
+// been selected and all node checks have passed, just before the allocation is committed. The hook is also consulted
+// before a placeholder is replaced by a real allocation.
+// The hook is called while the application is locked, it is passed a snapshot and not the application itself.
+type AdmissionHook interface {
+	// Allow returns true if the ask may be allocated for the application. If the allocation is vetoed the reason is
+	// returned and logged against the ask.
+	Allow(request *AdmissionRequest) (bool, string)
+}
+
+// AdmissionRequest is the snapshot of the application and the ask passed to the admission hook. All values are
+// copies, changes are not applied to the application or the ask.
+type AdmissionRequest struct {
+	ApplicationID string
+	QueuePath     string
+	User          security.UserGroup
+	Tags          map[string]string
+	AllocationKey string
+	Resource      *resources.Resource
+}
+
+// checkAdmission consults the admission hook of the partition for the ask. A missing hook allows all allocations.
+// NOTE: this is a lock free call, it must be called holding the application lock.
+func (sa *Application) checkAdmission(ask *Allocation) error {
+	hook := sa.queue.getAdmissionHook()
+	if hook == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(sa.tags))
+	for key, value := range sa.tags {
+		tags[key] = value
+	}
+	request := &AdmissionRequest{
+		ApplicationID: sa.ApplicationID,
+		QueuePath:     sa.queuePath,
+		User:          security.UserGroup{User: sa.user.User, Groups: append([]string{}, sa.user.Groups...)},
+		Tags:          tags,
+		AllocationKey: ask.GetAllocationKey(),
+		Resource:      ask.GetAllocatedResource().Clone(),
+	}
+	if allowed, reason := hook.Allow(request); !allowed {
+		log.Log(log.SchedApplication).Debug("allocation vetoed by admission hook",
+			zap.String("appID", sa.ApplicationID),
+			zap.String("allocationKey", ask.GetAllocationKey()),
+			zap.String("reason", reason))
+		err := fmt.Errorf("allocation rejected by admission hook: %s", reason)
+		ask.LogAllocationFailure(err.Error(), true)
+		return err
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ package objects
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,6 +36,13 @@ import (
 	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
 )
 
+const (
+	// placementBackoffBase is the time an ask is skipped after the first failure to find a node
+	placementBackoffBase = 100 * time.Millisecond
+	// placementBackoffMax is the upper limit for the time an ask is skipped after repeated failures
+	placementBackoffMax = 10 * time.Second
+)
+
 type Allocation struct {
 	// Read-only fields
 	allocationKey     string
@@ -48,6 +56,7 @@ type Allocation struct {
 	allowPreemptOther bool
 	originator        bool
 	tags              map[string]string
+	metadata          map[string]string // reporting metadata taken from the tags
 	foreign           bool
 	preemptable       bool
 
@@ -62,6 +71,9 @@ type Allocation struct {
 	askEvents            *schedEvt.AskEvents
 	userQuotaCheckFailed bool
 	headroomCheckFailed  bool
+	placementFailures    int       // number of consecutive failures to find a node
+	backoffUntil         time.Time // the node search for this allocation is skipped until this time
+	backoffGeneration    uint64    // placement generation of the root queue at the last failure
 
 	// Fields used once an allocation is bound
 	nodeID                string      // the node this allocation is bound to
@@ -71,6 +83,7 @@ type Allocation struct {
 	released              bool        // whether this allocation has been released (for placeholders)
 	release               *Allocation // placeholder to be released for this allocation
 	preempted             bool        // whether this allocation has been marked for preemption
+	preemptedTime         time.Time   // time the allocation was marked for preemption
 	instType              string      // the instance type of the node at the time this allocation was bound
 
 	locking.RWMutex
@@ -101,7 +114,7 @@ func NewAllocationFromSI(alloc *si.Allocation) *Allocation {
 	if err != nil {
 		log.Log(log.SchedAllocation).Debug("CreationTime is not set on the Allocation object or invalid",
 			zap.String("creationTime", alloc.AllocationTags[siCommon.CreationTime]))
-		createTime = time.Now()
+		createTime = getClock().Now()
 	} else {
 		createTime = time.Unix(siCreationTime, 0)
 	}
@@ -126,7 +139,7 @@ func NewAllocationFromSI(alloc *si.Allocation) *Allocation {
 	if alloc.NodeID != "" {
 		allocated = true
 		nodeID = alloc.NodeID
-		bindTime = time.Now()
+		bindTime = getClock().Now()
 	}
 
 	return &Allocation{
@@ -134,6 +147,7 @@ func NewAllocationFromSI(alloc *si.Allocation) *Allocation {
 		applicationID:     alloc.ApplicationID,
 		allocatedResource: resources.NewResourceFromProto(alloc.ResourcePerAlloc),
 		tags:              CloneAllocationTags(alloc.AllocationTags),
+		metadata:          getMetadataFromTags(alloc.AllocationTags),
 		createTime:        createTime,
 		priority:          alloc.Priority,
 		placeholder:       alloc.Placeholder,
@@ -305,6 +319,12 @@ func (a *Allocation) GetTagsClone() map[string]string {
 	return CloneAllocationTags(a.tags)
 }
 
+// GetMetadata returns the copy of the reporting metadata for this allocation.
+// Metadata is set by the shim using tags with the metadata prefix, the prefix is removed from the key.
+func (a *Allocation) GetMetadata() map[string]string {
+	return CloneAllocationTags(a.metadata)
+}
+
 // GetRelease returns the associated release for this allocation.
 func (a *Allocation) GetRelease() *Allocation {
 	a.RLock()
@@ -352,6 +372,15 @@ func (a *Allocation) MarkPreempted() {
 	a.Lock()
 	defer a.Unlock()
 	a.preempted = true
+	a.preemptedTime = getClock().Now()
+}
+
+// GetPreemptedTime returns the time the allocation was marked for preemption.
+// A zero time is returned if the allocation is not marked for preemption.
+func (a *Allocation) GetPreemptedTime() time.Time {
+	a.RLock()
+	defer a.RUnlock()
+	return a.preemptedTime
 }
 
 // IsPreempted returns whether the allocation has been marked for preemption or not.
@@ -370,6 +399,17 @@ func CloneAllocationTags(tags map[string]string) map[string]string {
 	return result
 }
 
+// getMetadataFromTags extracts the metadata from the tags, removing the metadata prefix from the key.
+func getMetadataFromTags(tags map[string]string) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range tags {
+		if key, ok := strings.CutPrefix(k, common.AllocTagMetadataPrefix); ok && key != "" {
+			metadata[key] = v
+		}
+	}
+	return metadata
+}
+
 // allocate marks this request as allocated and returns true if successful. A request may not be allocated multiple times.
 func (a *Allocation) allocate() bool {
 	a.Lock()
@@ -412,7 +452,7 @@ func (a *Allocation) GetPreemptCheckTime() time.Time {
 func (a *Allocation) UpdatePreemptCheckTime() {
 	a.Lock()
 	defer a.Unlock()
-	a.preemptCheckTime = time.Now()
+	a.preemptCheckTime = getClock().Now()
 }
 
 // GetRequiredNode gets the node (if any) required by this allocation.
@@ -461,7 +501,7 @@ func (a *Allocation) LogAllocationFailure(message string, allocate bool) {
 		}
 		a.allocLog[message] = entry
 	}
-	entry.LastOccurrence = time.Now()
+	entry.LastOccurrence = getClock().Now()
 	entry.Count++
 }
 
@@ -536,6 +576,33 @@ func (a *Allocation) IsSchedulingAttempted() bool {
 	return a.schedulingAttempted
 }
 
+// isBackedOff returns true if the node search for this allocation must be skipped at the passed in time.
+// A change of the placement generation since the last failure ends the backoff.
+func (a *Allocation) isBackedOff(now time.Time, generation uint64) bool {
+	a.RLock()
+	defer a.RUnlock()
+	return generation == a.backoffGeneration && now.Before(a.backoffUntil)
+}
+
+// placementFailed records a failure to find a node for this allocation. The node search is skipped for an interval
+// that doubles with each consecutive failure, up to placementBackoffMax. Failures recorded in an older placement
+// generation are not counted.
+func (a *Allocation) placementFailed(now time.Time, generation uint64) {
+	a.Lock()
+	defer a.Unlock()
+	if generation != a.backoffGeneration {
+		a.placementFailures = 0
+		a.backoffGeneration = generation
+	}
+	interval := placementBackoffMax
+	// limit the shift to prevent an overflow: the max is reached long before that
+	if a.placementFailures < 16 {
+		interval = min(placementBackoffBase<<a.placementFailures, placementBackoffMax)
+	}
+	a.placementFailures++
+	a.backoffUntil = now.Add(interval)
+}
+
 // SetScaleUpTriggered marks this allocation as having triggered the autoscaler.
 func (a *Allocation) SetScaleUpTriggered(triggered bool) {
 	a.Lock()
@@ -28,6 +28,7 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/resources"
 	"github.com/apache/yunikorn-core/pkg/events/mock"
 	schedEvt "github.com/apache/yunikorn-core/pkg/scheduler/objects/events"
@@ -108,6 +109,35 @@ func TestPreemptCheckTime(t *testing.T) {
 	assert.Check(t, now.Before(ask.GetPreemptCheckTime()), "preemptCheckTime was not current")
 }
 
+func TestPlacementBackoff(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAsk(aKey, appID1, res)
+	now := time.Now()
+	assert.Assert(t, !ask.isBackedOff(now, 0), "new ask should not be backed off")
+
+	// the interval doubles with each failure
+	expected := placementBackoffBase
+	for i := 0; i < 5; i++ {
+		ask.placementFailed(now, 0)
+		assert.Equal(t, ask.backoffUntil, now.Add(expected), "unexpected backoff after %d failures", i+1)
+		assert.Assert(t, ask.isBackedOff(now, 0), "ask should be backed off")
+		assert.Assert(t, !ask.isBackedOff(now.Add(expected), 0), "backoff should have passed")
+		expected *= 2
+	}
+
+	// the interval is capped
+	for i := 0; i < 20; i++ {
+		ask.placementFailed(now, 0)
+	}
+	assert.Equal(t, ask.backoffUntil, now.Add(placementBackoffMax), "backoff not capped")
+
+	// a new generation ends the backoff and restarts the interval
+	assert.Assert(t, !ask.isBackedOff(now, 1), "new generation should end the backoff")
+	ask.placementFailed(now, 1)
+	assert.Equal(t, ask.backoffUntil, now.Add(placementBackoffBase), "backoff not reset by new generation")
+	assert.Equal(t, ask.placementFailures, 1, "failures not reset by new generation")
+}
+
 func TestPlaceHolder(t *testing.T) {
 	siAsk := &si.Allocation{
 		AllocationKey: "ask1",
@@ -498,3 +528,32 @@ func TestNewForeignAllocFromSI(t *testing.T) {
 	alloc = NewAllocationFromSI(siAlloc)
 	assert.Assert(t, alloc.IsPreemptable())
 }
+
+func TestAllocationMetadata(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	alloc := NewAllocationFromSI(&si.Allocation{
+		AllocationKey:    "ask-1",
+		ApplicationID:    "app-1",
+		ResourcePerAlloc: res.ToProto(),
+	})
+	assert.Equal(t, len(alloc.GetMetadata()), 0, "metadata should be empty without tags")
+
+	tags := map[string]string{
+		common.AllocTagMetadataPrefix + "costCenter": "cc-1",
+		common.AllocTagMetadataPrefix:                "no key",
+		"kubernetes.io/meta/namespace":               "default",
+	}
+	alloc = NewAllocationFromSI(&si.Allocation{
+		AllocationKey:    "ask-1",
+		ApplicationID:    "app-1",
+		ResourcePerAlloc: res.ToProto(),
+		AllocationTags:   tags,
+	})
+	metadata := alloc.GetMetadata()
+	assert.DeepEqual(t, metadata, map[string]string{"costCenter": "cc-1"})
+	// returned map is a copy
+	metadata["team"] = "infra"
+	assert.Equal(t, len(alloc.GetMetadata()), 1, "metadata should not be changed via the copy")
+	// tags are kept as is
+	assert.DeepEqual(t, alloc.GetTagsClone(), tags)
+}
@@ -51,6 +51,7 @@ var (
 	completingTimeout         = 30 * time.Second
 	terminatedTimeout         = 3 * 24 * time.Hour
 	defaultPlaceholderTimeout = 15 * time.Minute
+	maxStateLogEntries        = 64 // state transitions kept per application, the oldest are dropped first
 )
 var initAppLogOnce sync.Once
 var rateLimitedAppLog *log.RateLimitedLogger
@@ -60,7 +61,12 @@ const (
 	Hard string = "Hard"
 
 	NotEnoughUserQuota  = "Not enough user quota"
+	NotEnoughGroupQuota = "Not enough group quota"
 	NotEnoughQueueQuota = "Not enough queue quota"
+	NotEnoughBurstQuota = "Not enough burst quota"
+
+	DeadlineExceeded     = "DeadlineExceeded"
+	DependencyUnresolved = "DependencyUnresolved"
 )
 
 type PlaceholderData struct {
@@ -108,16 +114,22 @@ type Application struct {
 	startTime            time.Time                   // the time that the application starts running. Default is zero.
 	finishedTime         time.Time                   // the time of finishing this application. the default value is zero time
 	rejectedMessage      string                      // If the application is rejected, save the rejected message
-	stateLog             []*StateLogEntry            // state log for this application
+	stateLog             []*StateLogEntry            // state log for this application, bounded ring buffer
+	stateLogStart        int                         // index of the oldest entry in the state log once it is full
 	placeholderData      map[string]*PlaceholderData // track placeholder and gang related info
 	askMaxPriority       int32                       // highest priority value of outstanding asks
 	hasPlaceholderAlloc  bool                        // Whether there is at least one allocated placeholder
 	runnableInQueue      bool                        // whether the application is runnable/schedulable in the queue. Default is true.
 	runnableByUserLimit  bool                        // whether the application is runnable/schedulable based on user/group quota. Default is true.
+	dependenciesMet      bool                        // whether all applications this application depends on have been running. Default is false.
+	paused               bool                        // whether the application is excluded from getting new allocations. Default is false.
+	recentNodes          map[string]time.Time        // nodes used by the application with the time they were last used
+	deadline             time.Time                   // time after which scheduling the application is pointless, zero if not set
 
 	rmEventHandler        handler.EventHandler
 	rmID                  string
 	terminatedCallback    func(appID string)
+	dependencyCallback    func(appID string) bool
 	appEvents             *schedEvt.ApplicationEvents
 	sendStateChangeEvents bool // whether to send state-change events or not (simplifies testing)
 
@@ -128,7 +140,7 @@ func NewApplication(siApp *si.AddApplicationRequest, ugi security.UserGroup, eve
 	app := &Application{
 		ApplicationID:         siApp.ApplicationID,
 		Partition:             siApp.PartitionName,
-		SubmissionTime:        time.Now(),
+		SubmissionTime:        getClock().Now(),
 		queuePath:             siApp.QueueName,
 		tags:                  siApp.Tags,
 		pending:               resources.NewResource(),
@@ -165,6 +177,7 @@ func NewApplication(siApp *si.AddApplicationRequest, ugi security.UserGroup, eve
 	app.user = ugi
 	app.rmEventHandler = eventHandler
 	app.rmID = rmID
+	app.deadline = app.getDeadlineFromTags()
 	app.appEvents = schedEvt.NewApplicationEvents(events.GetEventSystem())
 	app.appEvents.SendNewApplicationEvent(app.ApplicationID)
 	return app
@@ -184,16 +197,33 @@ func (sa *Application) SetState(state string) {
 
 func (sa *Application) recordState(appState string) {
 	// lock not acquired here as it is already held during HandleApplicationEvent() / OnStateChange()
-	sa.stateLog = append(sa.stateLog, &StateLogEntry{
-		Time:             time.Now(),
+	entry := &StateLogEntry{
+		Time:             getClock().Now(),
 		ApplicationState: appState,
-	})
+	}
+	if len(sa.stateLog) < maxStateLogEntries {
+		sa.stateLog = append(sa.stateLog, entry)
+		return
+	}
+	// log is full: overwrite the oldest entry
+	sa.stateLog[sa.stateLogStart] = entry
+	sa.stateLogStart = (sa.stateLogStart + 1) % len(sa.stateLog)
 }
 
+// GetStateLog returns a copy of the state transitions of the application, oldest first.
+// Only the last maxStateLogEntries transitions are kept.
 func (sa *Application) GetStateLog() []*StateLogEntry {
 	sa.RLock()
 	defer sa.RUnlock()
-	return sa.stateLog
+	stateLog := make([]*StateLogEntry, 0, len(sa.stateLog))
+	for i := range sa.stateLog {
+		entry := sa.stateLog[(sa.stateLogStart+i)%len(sa.stateLog)]
+		stateLog = append(stateLog, &StateLogEntry{
+			Time:             entry.Time,
+			ApplicationState: entry.ApplicationState,
+		})
+	}
+	return stateLog
 }
 
 // Set the reservation delay.
@@ -294,7 +324,7 @@ func (sa *Application) OnStateChange(event *fsm.Event, eventInfo string) {
 			UpdatedApplications: []*si.UpdatedApplication{{
 				ApplicationID:            sa.ApplicationID,
 				State:                    sa.stateMachine.Current(),
-				StateTransitionTimestamp: time.Now().UnixNano(),
+				StateTransitionTimestamp: getClock().Now().UnixNano(),
 				Message:                  message,
 			}},
 		})
@@ -983,6 +1013,8 @@ func (sa *Application) tryAllocate(headRoom *resources.Resource, allowPreemption
 	}
 	// calculate the users' headroom, includes group check which requires the applicationID
 	userHeadroom := ugm.GetUserManager().Headroom(sa.queuePath, sa.ApplicationID, sa.user)
+	generation := sa.queue.getPlacementGeneration()
+	affinityWindow := sa.queue.getNodeAffinityWindow()
 	// get all the requests from the app sorted in order
 	for _, request := range sa.sortedRequests {
 		if request.IsAllocated() {
@@ -1001,6 +1033,16 @@ func (sa *Application) tryAllocate(headRoom *resources.Resource, allowPreemption
 			continue
 		}
 		request.setUserQuotaCheckPassed()
+		// the per group limit of the queues in the hierarchy, preemption does not help here either
+		if group := sa.queue.checkGroupLimits(sa.user, request.GetAllocatedResource()); group != "" {
+			request.LogAllocationFailure(NotEnoughGroupQuota, true) // error message MUST be constant!
+			continue
+		}
+		// the quota and burst of the queues in the hierarchy, preemption does not return burst pool tokens
+		if !sa.queue.checkBurst(request.GetAllocatedResource()) {
+			request.LogAllocationFailure(NotEnoughBurstQuota, true) // error message MUST be constant!
+			continue
+		}
 		request.SetSchedulingAttempted(true)
 
 		// resource must fit in headroom otherwise skip the request (unless preemption could help)
@@ -1037,9 +1079,14 @@ func (sa *Application) tryAllocate(headRoom *resources.Resource, allowPreemption
 
 		iterator := nodeIterator()
 		if iterator != nil {
-			if result := sa.tryNodes(request, iterator); result != nil {
-				// have a candidate return it
-				return result
+			// skip the node search for a request that repeatedly failed to find a node, preemption is still checked
+			now := getClock().Now()
+			if !request.isBackedOff(now, generation) {
+				if result := sa.tryNodes(request, newAffinityIterator(iterator, sa.getRecentNodes(now, affinityWindow))); result != nil {
+					// have a candidate return it
+					return result
+				}
+				request.placementFailed(now, generation)
 			}
 
 			// no nodes qualify, attempt preemption
@@ -1196,7 +1243,7 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() NodeIterator,
 			node := getNodeFn(ph.GetNodeID())
 			// got the node run same checks as for reservation (all but fits)
 			// resource usage should not change anyway between placeholder and real one at this point
-			if node != nil && node.preReserveConditions(request) == nil {
+			if node != nil && node.preReserveConditions(request) == nil && sa.checkAdmission(request) == nil {
 				_, err := sa.allocateAsk(request)
 				if err != nil {
 					log.Log(log.SchedApplication).Warn("allocation of ask failed unexpectedly",
@@ -1210,7 +1257,7 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() NodeIterator,
 				// mark placeholder as released
 				ph.SetReleased(true)
 				// bind node here so it will be handled properly upon replacement
-				request.SetBindTime(time.Now())
+				request.SetBindTime(getClock().Now())
 				request.SetNodeID(node.NodeID)
 				request.SetInstanceType(node.GetInstanceType())
 				return newReplacedAllocationResult(node.NodeID, request)
@@ -1228,7 +1275,7 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() NodeIterator,
 	if phFit != nil && reqFit != nil {
 		resKey := reqFit.GetAllocationKey()
 		iterator.ForEachNode(func(node *Node) bool {
-			if !node.IsSchedulable() {
+			if !node.canSchedule() {
 				log.Log(log.SchedApplication).Debug("skipping node for placeholder alloc as state is unschedulable",
 					zap.String("allocationKey", resKey),
 					zap.String("node", node.NodeID))
@@ -1241,6 +1288,10 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() NodeIterator,
 			if err := node.preAllocateConditions(reqFit); err != nil {
 				return true
 			}
+			// last check before the replacement is committed
+			if err := sa.checkAdmission(reqFit); err != nil {
+				return false
+			}
 			// update just the node to make sure we keep its spot
 			// no queue update as we're releasing the placeholder and are just temp over the size
 			if !node.TryAddAllocation(reqFit) {
@@ -1267,7 +1318,7 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() NodeIterator,
 			// mark placeholder as released
 			phFit.SetReleased(true)
 			// bind node here so it will be handled properly upon replacement
-			reqFit.SetBindTime(time.Now())
+			reqFit.SetBindTime(getClock().Now())
 			reqFit.SetNodeID(node.NodeID)
 			reqFit.SetInstanceType(node.GetInstanceType())
 			result := newReplacedAllocationResult(node.NodeID, reqFit)
@@ -1280,10 +1331,13 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() NodeIterator,
 	return allocResult
 }
 
-// check ask against both user headRoom and queue headRoom
+// check ask against both user headRoom and queue headRoom, the per group limit and the burst of the queues
 func (sa *Application) checkHeadRooms(ask *Allocation, userHeadroom *resources.Resource, headRoom *resources.Resource) bool {
 	// check if this fits in the users' headroom first, if that fits check the queues' headroom
-	return userHeadroom.FitInMaxUndef(ask.GetAllocatedResource()) && headRoom.FitInMaxUndef(ask.GetAllocatedResource())
+	if !userHeadroom.FitInMaxUndef(ask.GetAllocatedResource()) || !headRoom.FitInMaxUndef(ask.GetAllocatedResource()) {
+		return false
+	}
+	return sa.queue.checkGroupLimits(sa.user, ask.GetAllocatedResource()) == "" && sa.queue.checkBurst(ask.GetAllocatedResource())
 }
 
 // tryReservedAllocate tries allocating an outstanding reservation
@@ -1367,13 +1421,69 @@ func (sa *Application) tryPreemption(headRoom *resources.Resource, preemptionDel
 	}
 
 	// track time spent trying preemption
-	tryPreemptionStart := time.Now()
+	tryPreemptionStart := getClock().Now()
 	defer metrics.GetSchedulerMetrics().ObserveTryPreemptionLatency(tryPreemptionStart)
 
 	// attempt preemption
 	return preemptor.TryPreemption()
 }
 
+// PreemptionDryRun reports the victims preemption would select to place the pending ask of the application, the
+// victims are not preempted. The preconditions are checked, except for the preemption delay and attempt frequency.
+func (sa *Application) PreemptionDryRun(allocKey string, iterator NodeIterator) (*PreemptionReport, error) {
+	sa.Lock()
+	defer sa.Unlock()
+	ask := sa.requests[allocKey]
+	if ask == nil || ask.IsAllocated() {
+		return nil, fmt.Errorf("pending ask %s not found for application %s", allocKey, sa.ApplicationID)
+	}
+	return sa.preemptionDryRun(ask, iterator), nil
+}
+
+// GetPreemptionVictims reports the victims preemption would select to place the ask in the queue of the application,
+// the ask does not need to be added to the application. An ask that fits in the headroom of the queue and on a node
+// without preemption is reported with that node and without victims. The node must be the required node of the ask,
+// if set, and must pass the predicates. Otherwise the report is the same as for PreemptionDryRun. The ask can be placed
+// if the report has no reason set. Nothing is preempted and the ask is not changed, apart from logging the failed
+// predicates.
+func (sa *Application) GetPreemptionVictims(ask *Allocation, iterator NodeIterator) (*PreemptionReport, error) {
+	if ask == nil {
+		return nil, fmt.Errorf("no ask to report preemption victims for application %s", sa.ApplicationID)
+	}
+	sa.Lock()
+	defer sa.Unlock()
+	if iterator != nil && sa.queue.getHeadRoom().FitInMaxUndef(ask.GetAllocatedResource()) {
+		var nodeID string
+		requiredNode := ask.GetRequiredNode()
+		iterator.ForEachNode(func(node *Node) bool {
+			// same node checks as the allocation: the required node first, then the predicates
+			if requiredNode != "" && node.NodeID != requiredNode {
+				return true
+			}
+			if node.canSchedule() && node.CanAllocate(ask.GetAllocatedResource()) && node.preReserveConditions(ask) == nil {
+				nodeID = node.NodeID
+				return false
+			}
+			return requiredNode == ""
+		})
+		if nodeID != "" {
+			return &PreemptionReport{AllocationKey: ask.GetAllocationKey(), NodeID: nodeID, Freed: resources.NewResource()}, nil
+		}
+	}
+	return sa.preemptionDryRun(ask, iterator), nil
+}
+
+// preemptionDryRun checks the preconditions for the ask, except for the preemption delay and attempt frequency, and
+// reports the victims preemption would select.
+// lock free call, must be called holding the application lock
+func (sa *Application) preemptionDryRun(ask *Allocation, iterator NodeIterator) *PreemptionReport {
+	if !ask.IsAllowPreemptOther() || ask.HasTriggeredPreemption() || ask.GetRequiredNode() != "" || iterator == nil {
+		return &PreemptionReport{AllocationKey: ask.GetAllocationKey(), Reason: common.PreemptionPreconditionsFailed}
+	}
+	preemptor := NewPreemptor(sa, sa.queue.getHeadRoom(), 0, ask, iterator, false)
+	return preemptor.DryRun()
+}
+
 func (sa *Application) tryRequiredNodePreemption(reserve *reservation, ask *Allocation) bool {
 	// try preemption and see if we can free up resource
 	preemptor := NewRequiredNodePreemptor(reserve.node, ask)
@@ -1383,6 +1493,15 @@ func (sa *Application) tryRequiredNodePreemption(reserve *reservation, ask *Allo
 	// Are there any victims/asks to preempt?
 	victims := preemptor.GetVictims()
 	if len(victims) > 0 {
+		// the partition limits the resource preempted within the quota window: retry in a later cycle
+		preempting := resources.NewResource()
+		for _, victim := range victims {
+			preempting.AddTo(victim.GetAllocatedResource())
+		}
+		if !sa.queue.usePreemptionQuota(preempting) {
+			ask.LogAllocationFailure(common.PreemptionQuotaExhausted, true)
+			return false
+		}
 		log.Log(log.SchedApplication).Info("Found victims for required node preemption",
 			zap.String("ds allocation key", ask.GetAllocationKey()),
 			zap.Int("no.of victims", len(victims)))
@@ -1407,7 +1526,7 @@ func (sa *Application) tryRequiredNodePreemption(reserve *reservation, ask *Allo
 func (sa *Application) tryNodesNoReserve(ask *Allocation, iterator NodeIterator, reservedNode string) *AllocationResult {
 	var allocResult *AllocationResult
 	iterator.ForEachNode(func(node *Node) bool {
-		if !node.IsSchedulable() {
+		if !node.canSchedule() {
 			log.Log(log.SchedApplication).Debug("skipping node for reserved ask as state is unschedulable",
 				zap.String("allocationKey", ask.GetAllocationKey()),
 				zap.String("node", node.NodeID))
@@ -1445,7 +1564,7 @@ func (sa *Application) tryNodes(ask *Allocation, iterator NodeIterator) *Allocat
 	var predicateErrors map[string]int
 	iterator.ForEachNode(func(node *Node) bool {
 		// skip the node if the node is not schedulable
-		if !node.IsSchedulable() {
+		if !node.canSchedule() {
 			log.Log(log.SchedApplication).Debug("skipping node for ask as state is unschedulable",
 				zap.String("allocationKey", allocKey),
 				zap.String("node", node.NodeID))
@@ -1455,7 +1574,7 @@ func (sa *Application) tryNodes(ask *Allocation, iterator NodeIterator) *Allocat
 		if !node.FitInNode(ask.GetAllocatedResource()) {
 			return true
 		}
-		tryNodeStart := time.Now()
+		tryNodeStart := getClock().Now()
 		result, err := sa.tryNode(node, ask)
 		if err != nil {
 			if predicateErrors == nil {
@@ -1491,7 +1610,7 @@ func (sa *Application) tryNodes(ask *Allocation, iterator NodeIterator) *Allocat
 			return false
 		}
 		// nothing allocated should we look at a reservation?
-		askAge := time.Since(ask.GetCreateTime())
+		askAge := getClock().Since(ask.GetCreateTime())
 		if reserved == nil && askAge > reservationDelay {
 			log.Log(log.SchedApplication).Debug("app reservation check",
 				zap.String("allocationKey", allocKey),
@@ -1548,7 +1667,13 @@ func (sa *Application) tryNode(node *Node, ask *Allocation) (*AllocationResult,
 	if err := node.preAllocateConditions(ask); err != nil {
 		return nil, err
 	}
+	// last check before the allocation is committed
+	if err := sa.checkAdmission(ask); err != nil {
+		return nil, err
+	}
 
+	// the burst pool tokens must be calculated before the queue usage changes
+	burstDemand, _ := sa.queue.GetBurstDemand(toAllocate)
 	// everything OK really allocate
 	if node.TryAddAllocation(ask) {
 		if err := sa.queue.TryIncAllocatedResource(ask.GetAllocatedResource()); err != nil {
@@ -1558,6 +1683,7 @@ func (sa *Application) tryNode(node *Node, ask *Allocation) (*AllocationResult,
 			node.RemoveAllocation(allocationKey)
 			return nil, nil
 		}
+		sa.queue.getBurstPool().consume(burstDemand)
 		// mark this alloc as allocated
 		_, err := sa.allocateAsk(ask)
 		if err != nil {
@@ -1611,7 +1737,7 @@ func (sa *Application) UnSetQueue() {
 	sa.Lock()
 	defer sa.Unlock()
 	sa.queue = nil
-	sa.finishedTime = time.Now()
+	sa.finishedTime = getClock().Now()
 }
 
 func (sa *Application) StartTime() time.Time {
@@ -1660,6 +1786,27 @@ func (sa *Application) GetAllRequests() []*Allocation {
 	return sa.getAllRequestsInternal()
 }
 
+// GetMaxAskResource returns the smallest single node resource that fits each of the pending asks of the application.
+// Every quantity is the largest value requested for that resource type over all pending asks, the result can be
+// larger than each individual ask if the asks have different shapes.
+// Returns nil if the application has no pending asks.
+func (sa *Application) GetMaxAskResource() *resources.Resource {
+	sa.RLock()
+	defer sa.RUnlock()
+	var maxAsk *resources.Resource
+	for _, ask := range sa.requests {
+		if ask.IsAllocated() {
+			continue
+		}
+		if maxAsk == nil {
+			maxAsk = ask.GetAllocatedResource().Clone()
+			continue
+		}
+		maxAsk = resources.ComponentWiseMax(maxAsk, ask.GetAllocatedResource())
+	}
+	return maxAsk
+}
+
 func (sa *Application) getAllRequestsInternal() []*Allocation {
 	var requests []*Allocation
 	for _, req := range sa.requests {
@@ -1722,18 +1869,55 @@ func (sa *Application) addAllocationInternal(allocType AllocationResultType, all
 	}
 	sa.appEvents.SendNewAllocationEvent(sa.ApplicationID, alloc.allocationKey, alloc.GetAllocatedResource())
 	sa.allocations[alloc.GetAllocationKey()] = alloc
+	sa.recordNodeUse(alloc.GetNodeID())
+}
+
+// recordNodeUse marks the node as used by the application now.
+// No locking must be called while holding the lock
+func (sa *Application) recordNodeUse(nodeID string) {
+	if nodeID == "" {
+		return
+	}
+	if sa.recentNodes == nil {
+		sa.recentNodes = make(map[string]time.Time)
+	}
+	sa.recentNodes[nodeID] = getClock().Now()
+}
+
+// getRecentNodes returns the nodes the application used within the window before now.
+// Nodes last used before the window are removed from the history. A zero window returns nil.
+// No locking must be called while holding the lock
+func (sa *Application) getRecentNodes(now time.Time, window time.Duration) map[string]bool {
+	if window <= 0 || len(sa.recentNodes) == 0 {
+		return nil
+	}
+	cutoff := now.Add(-window)
+	var nodes map[string]bool
+	for nodeID, used := range sa.recentNodes {
+		if used.Before(cutoff) {
+			delete(sa.recentNodes, nodeID)
+			continue
+		}
+		if nodes == nil {
+			nodes = make(map[string]bool)
+		}
+		nodes[nodeID] = true
+	}
+	return nodes
 }
 
 // Increase user resource usage
 // No locking must be called while holding the lock
 func (sa *Application) incUserResourceUsage(resource *resources.Resource) {
 	ugm.GetUserManager().IncreaseTrackedResource(sa.queuePath, sa.ApplicationID, resource, sa.user)
+	sa.queue.incUserResourceUsage(sa.user, resource)
 }
 
 // Decrease user resource usage
 // No locking must be called while holding the lock
 func (sa *Application) decUserResourceUsage(resource *resources.Resource, removeApp bool) {
 	ugm.GetUserManager().DecreaseTrackedResource(sa.queuePath, sa.ApplicationID, resource, sa.user, removeApp)
+	sa.queue.decUserResourceUsage(sa.user, resource)
 }
 
 // Track used and preempted resources
@@ -1793,7 +1977,7 @@ func (sa *Application) ReplaceAllocation(allocationKey string) *Allocation {
 	// we double linked the real and placeholder allocation
 	alloc.SetPlaceholderUsed(true)
 	alloc.SetPlaceholderCreateTime(ph.GetCreateTime())
-	alloc.SetBindTime(time.Now())
+	alloc.SetBindTime(getClock().Now())
 	sa.addAllocationInternal(Replaced, alloc)
 	// order is important: clean up the allocation after adding it to the app
 	// we need the original Replaced allocation resultType.
@@ -1819,6 +2003,7 @@ func (sa *Application) removeAllocationInternal(allocationKey string, releaseTyp
 	if alloc == nil {
 		return nil
 	}
+	sa.recordNodeUse(alloc.GetNodeID())
 
 	var event applicationEvent = EventNotNeeded
 	var eventWarning string
@@ -1921,6 +2106,7 @@ func (sa *Application) RemoveAllAllocations() []*Allocation {
 			}
 		}
 		allocationsToRelease = append(allocationsToRelease, alloc)
+		sa.recordNodeUse(alloc.GetNodeID())
 		// Aggregate the resources used by this alloc to the application's user resource tracker
 		sa.trackCompletedResource(alloc)
 		sa.appEvents.SendRemoveAllocationEvent(sa.ApplicationID, alloc.allocationKey, alloc.GetAllocatedResource(), si.TerminationType_STOPPED_BY_RM)
@@ -1985,6 +2171,217 @@ func (sa *Application) GetTag(tag string) string {
 	return tagVal
 }
 
+// GetRMID returns the ID of the resource manager that submitted the application.
+// The ID is set on creation and never changes.
+func (sa *Application) GetRMID() string {
+	return sa.rmID
+}
+
+// GetDependencies returns the IDs of the applications that must be running before this application is scheduled.
+// The dependencies are set as a comma separated list in the application tags and never change.
+// Empty and duplicate IDs are ignored.
+func (sa *Application) GetDependencies() []string {
+	value := sa.GetTag(common.AppTagDependsOn)
+	if value == "" {
+		return nil
+	}
+	var dependencies []string
+	seen := make(map[string]bool)
+	for _, appID := range strings.Split(value, common.Separator) {
+		appID = strings.TrimSpace(appID)
+		if appID == "" || seen[appID] {
+			continue
+		}
+		seen[appID] = true
+		dependencies = append(dependencies, appID)
+	}
+	return dependencies
+}
+
+// getDeadlineFromTags returns the deadline set in the application tags as a duration after the submission time.
+// The deadline is ignored if the tag is not set, not a duration or not positive.
+func (sa *Application) getDeadlineFromTags() time.Time {
+	value := sa.GetTag(common.AppTagDeadline)
+	if value == "" {
+		return time.Time{}
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Log(log.SchedApplication).Warn("application deadline tag ignored",
+			zap.String("appID", sa.ApplicationID),
+			zap.String("deadline", value),
+			zap.Error(err))
+		return time.Time{}
+	}
+	return sa.SubmissionTime.Add(duration)
+}
+
+// SetDeadline sets the time after which the application is failed if it is not fully allocated.
+// A zero time removes the deadline.
+func (sa *Application) SetDeadline(deadline time.Time) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.deadline = deadline
+}
+
+// GetDeadline returns the scheduling deadline of the application, zero if not set.
+func (sa *Application) GetDeadline() time.Time {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.deadline
+}
+
+// CheckDeadline fails the application if the deadline has passed while it still has pending requests. The pending
+// requests are removed and released to the RM. An application that is fully allocated, or already completing, failing
+// or terminated, is not changed. Returns true if the application was failed.
+func (sa *Application) CheckDeadline() bool {
+	sa.Lock()
+	defer sa.Unlock()
+	if sa.deadline.IsZero() || getClock().Now().Before(sa.deadline) || resources.IsZero(sa.pending) {
+		return false
+	}
+	if !sa.IsNew() && !sa.IsAccepted() && !sa.IsRunning() {
+		return false
+	}
+	log.Log(log.SchedApplication).Info("Application deadline exceeded, failing application",
+		zap.String("appID", sa.ApplicationID),
+		zap.Time("deadline", sa.deadline),
+		zap.Stringer("pending", sa.pending))
+	return sa.failAndReleasePending(DeadlineExceeded, "releasing pending requests on application deadline")
+}
+
+// CheckDependencyTimeout fails the application if it waits, longer than the timeout after submission, for a
+// dependency that is not known: known returns false for the application ID. A known dependency that has not been
+// running yet is waited for without a timeout. The pending requests are removed and released to the RM. Returns
+// true if the application was failed.
+func (sa *Application) CheckDependencyTimeout(known func(appID string) bool, timeout time.Duration) bool {
+	if getClock().Since(sa.SubmissionTime) < timeout {
+		return false
+	}
+	var unknown []string
+	for _, appID := range sa.getUnmetDependencies() {
+		if !known(appID) {
+			unknown = append(unknown, appID)
+		}
+	}
+	if len(unknown) == 0 {
+		return false
+	}
+	sa.Lock()
+	defer sa.Unlock()
+	if !sa.IsNew() && !sa.IsAccepted() {
+		return false
+	}
+	log.Log(log.SchedApplication).Info("Application dependency unresolved, failing application",
+		zap.String("appID", sa.ApplicationID),
+		zap.Strings("unknownDependencies", unknown))
+	return sa.failAndReleasePending(DependencyUnresolved, "releasing pending requests on unresolved application dependency")
+}
+
+// failAndReleasePending fails the application with the info and removes the pending requests, the requests are
+// released to the RM with the message. Returns true if the application was failed.
+// lock free call, must be called holding the application lock
+func (sa *Application) failAndReleasePending(info, message string) bool {
+	if err := sa.HandleApplicationEventWithInfo(FailApplication, info); err != nil {
+		log.Log(log.SchedApplication).Warn("Application state change failed when failing application",
+			zap.String("appID", sa.ApplicationID),
+			zap.String("currentState", sa.CurrentState()),
+			zap.String("info", info),
+			zap.Error(err))
+		return false
+	}
+	var pendingRelease []*Allocation
+	for _, ask := range sa.requests {
+		if !ask.IsAllocated() {
+			ask.SetReleased(true)
+			pendingRelease = append(pendingRelease, ask)
+		}
+	}
+	sa.removeAsksInternal("", si.EventRecord_REQUEST_TIMEOUT)
+	sa.notifyRMAllocationReleased(pendingRelease, si.TerminationType_TIMEOUT, message)
+	return true
+}
+
+// SetDependencyCallback sets the function that resolves a dependency of the application: it returns true if the
+// application with the ID has been running. Without a callback the dependencies are never satisfied.
+func (sa *Application) SetDependencyCallback(callback func(appID string) bool) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.dependencyCallback = callback
+}
+
+// HasRun returns true if the application is running or has been running, even if it has terminated since.
+func (sa *Application) HasRun() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return !sa.startTime.IsZero() || sa.stateMachine.Is(Running.String()) ||
+		sa.stateMachine.Is(Completing.String()) || sa.stateMachine.Is(Completed.String())
+}
+
+// getUnmetDependencies returns the applications this application depends on that have not been running yet.
+// Once all dependencies are met the result is stored on the application: the dependencies are not checked again.
+func (sa *Application) getUnmetDependencies() []string {
+	sa.RLock()
+	met := sa.dependenciesMet
+	callback := sa.dependencyCallback
+	sa.RUnlock()
+	if met {
+		return nil
+	}
+	var waiting []string
+	for _, appID := range sa.GetDependencies() {
+		if callback == nil || !callback(appID) {
+			waiting = append(waiting, appID)
+		}
+	}
+	if len(waiting) == 0 {
+		sa.Lock()
+		sa.dependenciesMet = true
+		sa.Unlock()
+	}
+	return waiting
+}
+
+// dependenciesSatisfied returns true if all applications this application depends on have been running.
+func (sa *Application) dependenciesSatisfied() bool {
+	return len(sa.getUnmetDependencies()) == 0
+}
+
+// Pause stops the application from getting new allocations. Pending requests are kept and existing allocations are
+// not changed. Pausing an already paused application is a no-op.
+func (sa *Application) Pause() {
+	sa.Lock()
+	defer sa.Unlock()
+	if sa.paused {
+		return
+	}
+	sa.paused = true
+	log.Log(log.SchedApplication).Info("application paused",
+		zap.String("appID", sa.ApplicationID))
+	sa.appEvents.SendAppPausedEvent(sa.ApplicationID)
+}
+
+// Resume allows a paused application to get new allocations again. Resuming an application that is not paused
+// is a no-op.
+func (sa *Application) Resume() {
+	sa.Lock()
+	defer sa.Unlock()
+	if !sa.paused {
+		return
+	}
+	sa.paused = false
+	log.Log(log.SchedApplication).Info("application resumed",
+		zap.String("appID", sa.ApplicationID))
+	sa.appEvents.SendAppResumedEvent(sa.ApplicationID)
+}
+
+// IsPaused returns true if the application is paused and should not get new allocations.
+func (sa *Application) IsPaused() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.paused
+}
+
 func (sa *Application) IsCreateForced() bool {
 	return common.IsAppCreationForced(sa.tags)
 }
@@ -2221,6 +2618,32 @@ func (sa *Application) GetMaxResource() *resources.Resource {
 	return sa.getResourceFromTags(siCommon.AppTagNamespaceResourceQuota)
 }
 
+// GetMinResource returns the minimum total resource the application needs to run that is set in the application tags
+func (sa *Application) GetMinResource() *resources.Resource {
+	return sa.getResourceFromTags(common.AppTagMinResource)
+}
+
+// isReadyToStart returns true if the application can be scheduled. An application that declares a minimum resource
+// waits until the available resources of the partition fit that minimum. After the first allocation, real or
+// placeholder, the application is always ready: it must be able to complete its gang.
+func (sa *Application) isReadyToStart(available *resources.Resource) bool {
+	minRes := sa.GetMinResource()
+	if resources.IsZero(minRes) {
+		return true
+	}
+	sa.RLock()
+	started := !resources.IsZero(sa.allocatedResource) || !resources.IsZero(sa.allocatedPlaceholder)
+	sa.RUnlock()
+	if started || available.FitIn(minRes) {
+		return true
+	}
+	log.Log(log.SchedApplication).Debug("application waiting for partition capacity",
+		zap.String("appID", sa.ApplicationID),
+		zap.Stringer("minResource", minRes),
+		zap.Stringer("available", available))
+	return false
+}
+
 // GetMaxApps returns the max apps that is set in the application tags
 func (sa *Application) GetMaxApps() uint64 {
 	return sa.getUint64Tag(siCommon.AppTagNamespaceResourceMaxApps)
@@ -21,7 +21,6 @@ package objects
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/looplab/fsm"
 	"go.uber.org/zap"
@@ -190,7 +189,7 @@ func callbacks() fsm.Callbacks {
 			metrics.GetQueueMetrics(app.queuePath).IncQueueApplicationsRejected()
 			metrics.GetSchedulerMetrics().IncTotalApplicationsRejected()
 			app.setStateTimer(terminatedTimeout, app.stateMachine.Current(), ExpireApplication)
-			app.finishedTime = time.Now()
+			app.finishedTime = getClock().Now()
 			app.cleanupTrackedResource()
 			// No rejected message when use app.HandleApplicationEvent(RejectApplication)
 			if len(event.Args) == 2 {
@@ -200,7 +199,7 @@ func callbacks() fsm.Callbacks {
 		fmt.Sprintf("enter_%s", Running.String()): func(_ context.Context, event *fsm.Event) {
 			if event.Src != Running.String() {
 				app := event.Args[0].(*Application) //nolint:errcheck
-				app.startTime = time.Now()
+				app.startTime = getClock().Now()
 				app.queue.incRunningApps(app.ApplicationID)
 				metrics.GetQueueMetrics(app.queuePath).IncQueueApplicationsRunning()
 				metrics.GetSchedulerMetrics().IncTotalApplicationsRunning()
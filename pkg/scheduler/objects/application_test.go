@@ -1347,6 +1347,28 @@ func TestIsCreateForced(t *testing.T) {
 	assert.Check(t, app.IsCreateForced(), "found unforced app but forced tag was set")
 }
 
+func TestGetDependencies(t *testing.T) {
+	app := newApplicationWithTags(appID1, "default", "root.a", nil)
+	assert.Assert(t, app.GetDependencies() == nil, "expected no dependencies if tags nil")
+	tags := map[string]string{common.AppTagDependsOn: ""}
+	app = newApplicationWithTags(appID1, "default", "root.a", tags)
+	assert.Assert(t, app.GetDependencies() == nil, "expected no dependencies if tag empty")
+	tags[common.AppTagDependsOn] = " app-2, ,app-3,app-2 "
+	app = newApplicationWithTags(appID1, "default", "root.a", tags)
+	assert.DeepEqual(t, app.GetDependencies(), []string{"app-2", "app-3"})
+}
+
+func TestHasRun(t *testing.T) {
+	app := newApplication(appID1, "default", "root.a")
+	assert.Assert(t, !app.HasRun(), "new app should not have run")
+	app.SetState(Running.String())
+	assert.Assert(t, app.HasRun(), "running app should have run")
+	app.SetState(Failed.String())
+	assert.Assert(t, !app.HasRun(), "failed app without start time should not have run")
+	app.startTime = time.Now()
+	assert.Assert(t, app.HasRun(), "failed app with start time should have run")
+}
+
 func TestOnStatusChangeCalled(t *testing.T) {
 	app, testHandler := newApplicationWithHandler(appID1, "default", "root.a")
 	assert.Equal(t, New.String(), app.CurrentState(), "new app not in New state")
@@ -1836,6 +1858,37 @@ func TestGetAllRequests(t *testing.T) {
 	assert.Equal(t, app.getAllRequestsInternal()[0], ask, "Unexpected request found in the app")
 }
 
+func TestGetMaxAskResource(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Assert(t, app.GetMaxAskResource() == nil, "App without asks should not have a max ask")
+
+	// asks with different shapes: the max is taken per resource type
+	ask1 := newAllocationAsk("ask-1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 1}))
+	err = app.AddAllocationAsk(ask1)
+	assert.NilError(t, err, "ask-1 should have been added to app")
+	ask2 := newAllocationAsk("ask-2", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2, "second": 8}))
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "ask-2 should have been added to app")
+	ask3 := newAllocationAsk("ask-3", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"third": 3}))
+	err = app.AddAllocationAsk(ask3)
+	assert.NilError(t, err, "ask-3 should have been added to app")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 8, "third": 3})
+	assert.Assert(t, resources.Equals(app.GetMaxAskResource(), expected), "unexpected max ask: %s", app.GetMaxAskResource())
+
+	// allocated asks are no longer considered
+	ask2.allocated = true
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 1, "third": 3})
+	assert.Assert(t, resources.Equals(app.GetMaxAskResource(), expected), "unexpected max ask after allocation: %s", app.GetMaxAskResource())
+
+	// the result is a copy
+	maxAsk := app.GetMaxAskResource()
+	maxAsk.AddTo(expected)
+	assert.Assert(t, resources.Equals(ask1.GetAllocatedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 1})), "ask resource should not have changed")
+}
+
 func TestGetQueueNameAfterUnsetQueue(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
 	assert.Equal(t, app.GetQueuePath(), "root.unknown")
@@ -2188,6 +2241,149 @@ func TestTryAllocateFit(t *testing.T) {
 	assert.Equal(t, "node1", result.NodeID, "wrong node")
 }
 
+func TestTryAllocateBackoff(t *testing.T) {
+	node := newNode("node1", map[string]resources.Quantity{"first": 5})
+	nodeMap := map[string]*Node{"node1": node}
+	iterator := getNodeIteratorFn(node)
+	getNode := func(nodeID string) *Node {
+		return nodeMap[nodeID]
+	}
+
+	rootQ, err := createRootQueue(map[string]string{"first": "20"})
+	assert.NilError(t, err)
+	childQ, err := createManagedQueue(rootQ, "child", false, map[string]string{"first": "20"})
+	assert.NilError(t, err)
+
+	app := newApplication(appID1, "default", "root.child")
+	app.SetQueue(childQ)
+	childQ.applications[appID1] = app
+	ask := newAllocationAsk("alloc1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err)
+
+	// the ask does not fit on the node: backed off
+	headroom := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})
+	preemptionAttemptsRemaining := 0
+	result := app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result == nil, "unexpected result")
+	assert.Equal(t, ask.placementFailures, 1, "failure not recorded")
+	assert.Assert(t, ask.isBackedOff(time.Now(), rootQ.getPlacementGeneration()), "ask should be backed off")
+
+	// a node that fits joins: skipped while backed off
+	node2 := newNode("node2", map[string]resources.Quantity{"first": 20})
+	nodeMap["node2"] = node2
+	iterator = getNodeIteratorFn(node, node2)
+	result = app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result == nil, "backed off ask should not be allocated")
+	assert.Equal(t, ask.placementFailures, 1, "backed off ask should not be retried")
+
+	// the topology change resets the backoff
+	rootQ.ResetPlacementBackoff()
+	result = app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result != nil, "alloc expected after backoff reset")
+	assert.Equal(t, "node2", result.NodeID, "wrong node")
+}
+
+func TestTryAllocateNodeAffinity(t *testing.T) {
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"first": 20})
+	node2 := newNode("node-2", map[string]resources.Quantity{"first": 20})
+	nodeMap := map[string]*Node{nodeID1: node1, "node-2": node2}
+	iterator := getNodeIteratorFn(node1, node2)
+	getNode := func(nodeID string) *Node {
+		return nodeMap[nodeID]
+	}
+
+	rootQ, err := createRootQueue(map[string]string{"first": "40"})
+	assert.NilError(t, err)
+	childQ, err := createManagedQueueWithProps(rootQ, "child", false, nil, map[string]string{configs.NodeAffinityWindow: "1h"})
+	assert.NilError(t, err)
+	assert.Equal(t, childQ.getNodeAffinityWindow(), time.Hour, "affinity window property not set")
+
+	app := newApplication(appID1, "default", "root.child")
+	app.SetQueue(childQ)
+	childQ.applications[appID1] = app
+
+	// the app ran on node-2 before the restart
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	app.AddAllocation(newAllocation(appID1, "node-2", res))
+	app.RemoveAllAllocations()
+
+	headroom := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 40})
+	preemptionAttemptsRemaining := 0
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err)
+	result := app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result != nil, "alloc expected")
+	assert.Equal(t, result.NodeID, "node-2", "previously used node should be preferred")
+
+	// the previously used node is full: fall back to the other nodes
+	node2.SetSchedulable(false)
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, res))
+	assert.NilError(t, err)
+	result = app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result != nil, "alloc expected")
+	assert.Equal(t, result.NodeID, nodeID1, "should fall back to other node")
+	node2.SetSchedulable(true)
+
+	// the use of node-1 expired: only node-2 is preferred
+	app.recentNodes[nodeID1] = time.Now().Add(-2 * time.Hour)
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-3", appID1, res))
+	assert.NilError(t, err)
+	result = app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result != nil, "alloc expected")
+	assert.Equal(t, result.NodeID, "node-2", "expired node should not be preferred")
+	_, ok := app.recentNodes[nodeID1]
+	assert.Assert(t, !ok, "expired node should be removed from the history")
+
+	// no window on the queue: the history is ignored
+	childQ.nodeAffinityWindow = 0
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-4", appID1, res))
+	assert.NilError(t, err)
+	result = app.tryAllocate(headroom, false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result != nil, "alloc expected")
+	assert.Equal(t, result.NodeID, nodeID1, "history should be ignored without a window")
+}
+
+func TestTryAllocateGroupLimit(t *testing.T) {
+	node := newNode("node1", map[string]resources.Quantity{"first": 20})
+	nodeMap := map[string]*Node{"node1": node}
+	iterator := getNodeIteratorFn(node)
+	getNode := func(nodeID string) *Node {
+		return nodeMap[nodeID]
+	}
+
+	rootQ, err := createRootQueue(map[string]string{"first": "20"})
+	assert.NilError(t, err)
+	childQ, err := NewConfiguredQueue(configs.QueueConfig{
+		Name:                 "child",
+		MaxResourcesPerGroup: map[string]string{"first": "10"},
+	}, rootQ, false)
+	assert.NilError(t, err)
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	newGroupApp := func(appID, user, group string) *Application {
+		app := newApplicationWithUserGroup(appID, "default", "root.child", user, []string{group})
+		app.SetQueue(childQ)
+		childQ.applications[appID] = app
+		err = app.AddAllocationAsk(newAllocationAsk("alloc-"+appID, appID, res))
+		assert.NilError(t, err)
+		return app
+	}
+	// group1 uses its full limit
+	app1 := newGroupApp(appID1, "user1", "group1")
+	app1.AddAllocation(newAllocation(appID1, nodeID1, resources.Multiply(res, 2)))
+
+	preemptionAttemptsRemaining := 0
+	app2 := newGroupApp(appID2, "user2", "group1")
+	result := app2.tryAllocate(node.GetAvailableResource(), false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result == nil, "group1 should be over its limit")
+	assert.Equal(t, app2.GetAllocationAsk("alloc-" + appID2).GetAllocationLog()[0].Message, NotEnoughGroupQuota)
+
+	app3 := newGroupApp(appID3, "user3", "group2")
+	result = app3.tryAllocate(node.GetAvailableResource(), false, 30*time.Second, &preemptionAttemptsRemaining, iterator, iterator, getNode)
+	assert.Assert(t, result != nil, "group2 should be able to allocate")
+}
+
 func TestTryAllocatePreemptQueue(t *testing.T) {
 	node := newNode("node1", map[string]resources.Quantity{"first": 20})
 	nodeMap := map[string]*Node{"node1": node}
@@ -3016,6 +3212,103 @@ func TestTryAllocateWithReservedHeadRoomChecking(t *testing.T) {
 	assert.Assert(t, result == nil, "result is expected to be nil due to insufficient headroom")
 }
 
+func TestTryReservedAllocateGroupLimit(t *testing.T) {
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"first": 20})
+	node2 := newNode(nodeID2, map[string]resources.Quantity{"first": 20})
+	iterator := getNodeIteratorFn(node1, node2)
+
+	rootQ, err := createRootQueue(map[string]string{"first": "40"})
+	assert.NilError(t, err)
+	childQ, err := NewConfiguredQueue(configs.QueueConfig{
+		Name:                 "child",
+		MaxResourcesPerGroup: map[string]string{"first": "10"},
+	}, rootQ, false)
+	assert.NilError(t, err)
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	newGroupApp := func(appID, user string) (*Application, *Allocation) {
+		app := newApplicationWithUserGroup(appID, "default", "root.child", user, []string{"group1"})
+		app.SetQueue(childQ)
+		childQ.applications[appID] = app
+		ask := newAllocationAsk("alloc-"+appID, appID, res)
+		err = app.AddAllocationAsk(ask)
+		assert.NilError(t, err)
+		return app, ask
+	}
+	// group1 uses its full limit
+	app1, _ := newGroupApp(appID1, "user1")
+	app1.AddAllocation(newAllocation(appID1, nodeID1, resources.Multiply(res, 2)))
+
+	// a reservation made before the group reached its limit must not bypass the limit
+	app2, ask := newGroupApp(appID2, "user2")
+	err = app2.Reserve(node1, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+	result := app2.tryReservedAllocate(node1.GetAvailableResource(), iterator)
+	assert.Assert(t, result == nil, "reserved allocation should be blocked by the group limit")
+}
+
+func TestTryReservedAllocateBurst(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"first": 20})
+	node2 := newNode(nodeID2, map[string]resources.Quantity{"first": 20})
+	iterator := getNodeIteratorFn(node1, node2)
+
+	rootQ, err := createRootQueue(map[string]string{"first": "40"})
+	assert.NilError(t, err)
+	var leaf *Queue
+	leaf, err = createManagedQueue(rootQ, "leaf", false, nil)
+	assert.NilError(t, err)
+	leaf.setQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20}))
+	rootQ.SetBurstPool(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}), time.Minute)
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	app := newApplication(appID1, "default", "root.leaf")
+	app.SetQueue(leaf)
+	leaf.applications[appID1] = app
+	ask := newAllocationAsk(aKey, appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err)
+	err = app.Reserve(node1, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+
+	// the pool is drained after the reservation was made: the reserved allocation must not burst
+	rootQ.getBurstPool().consume(res)
+	result := app.tryReservedAllocate(node1.GetAvailableResource(), iterator)
+	assert.Assert(t, result == nil, "reserved allocation should be blocked without burst tokens")
+
+	// the pool refills: the reserved allocation bursts and uses the tokens
+	mockClock.Advance(time.Minute)
+	result = app.tryReservedAllocate(node1.GetAvailableResource(), iterator)
+	assert.Assert(t, result != nil, "reserved allocation should burst with tokens")
+	assert.Assert(t, resources.IsZero(rootQ.getBurstPool().getTokens()), "burst tokens should have been used")
+}
+
+func TestPauseResume(t *testing.T) {
+	app := newApplication(appID0, "default", "root.unknown")
+	assert.Assert(t, !app.IsPaused(), "new application should not be paused")
+	eventSystem := mock.NewEventSystem()
+	app.appEvents = schedEvt.NewApplicationEvents(eventSystem)
+
+	app.Pause()
+	assert.Assert(t, app.IsPaused(), "application should be paused")
+	assert.Equal(t, 1, len(eventSystem.Events))
+	assert.Equal(t, "application paused", eventSystem.Events[0].Message)
+	// pause again - no new events
+	app.Pause()
+	assert.Equal(t, 1, len(eventSystem.Events))
+
+	eventSystem.Reset()
+	app.Resume()
+	assert.Assert(t, !app.IsPaused(), "application should not be paused")
+	assert.Equal(t, 1, len(eventSystem.Events))
+	assert.Equal(t, "application resumed", eventSystem.Events[0].Message)
+	// resume again - no new events
+	app.Resume()
+	assert.Equal(t, 1, len(eventSystem.Events))
+}
+
 func TestUpdateRunnableStatus(t *testing.T) {
 	app := newApplication(appID0, "default", "root.unknown")
 	assert.Assert(t, app.runnableInQueue)
@@ -3650,3 +3943,101 @@ func TestTryNodesNoReserve(t *testing.T) {
 	assert.Equal(t, result.ResultType, AllocatedReserved, "result type should be AllocatedReserved")
 	assert.Equal(t, result.ReservedNodeID, node1.NodeID, "reserved node should be node1")
 }
+
+func TestApplicationDeadline(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+
+	// deadline from the tags is relative to the submission
+	app := newApplicationWithTags(appID1, "default", "root.a", map[string]string{common.AppTagDeadline: "1m"})
+	assert.Equal(t, app.GetDeadline(), mockClock.Now().Add(time.Minute), "deadline not set from tag")
+	app = newApplicationWithTags(appID1, "default", "root.a", map[string]string{common.AppTagDeadline: "soon"})
+	assert.Assert(t, app.GetDeadline().IsZero(), "invalid deadline tag should be ignored")
+	app = newApplicationWithTags(appID1, "default", "root.a", map[string]string{common.AppTagDeadline: "-1m"})
+	assert.Assert(t, app.GetDeadline().IsZero(), "negative deadline tag should be ignored")
+
+	var testHandler *rmproxy.MockedRMProxy
+	app, testHandler = newApplicationWithHandler(appID1, "default", "root.a")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	err = app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+
+	// no deadline set: never fails
+	mockClock.Advance(time.Hour)
+	assert.Assert(t, !app.CheckDeadline(), "app without deadline should not fail")
+
+	app.SetDeadline(mockClock.Now().Add(time.Second))
+	assert.Assert(t, !app.CheckDeadline(), "app should not fail before the deadline")
+	assert.Equal(t, app.CurrentState(), Accepted.String(), "app should still be accepted")
+	mockClock.Advance(time.Second)
+	assert.Assert(t, app.CheckDeadline(), "app should fail when the deadline passed")
+	assert.Equal(t, app.CurrentState(), Failing.String(), "app should be failing")
+	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "pending requests should be removed")
+	assert.Assert(t, app.GetAllocationAsk(aKey) == nil, "ask should be removed")
+	log := app.GetStateLog()
+	assert.Equal(t, log[len(log)-1].ApplicationState, Failing.String(), "failing state not logged")
+	released, notified := false, false
+	for _, event := range testHandler.GetEvents() {
+		switch ev := event.(type) {
+		case *rmevent.RMReleaseAllocationEvent:
+			assert.Equal(t, len(ev.ReleasedAllocations), 1, "one pending request should be released")
+			assert.Equal(t, ev.ReleasedAllocations[0].AllocationKey, aKey, "wrong request released")
+			released = true
+		case *rmevent.RMApplicationUpdateEvent:
+			update := ev.UpdatedApplications[0]
+			if update.State == Failing.String() {
+				assert.Equal(t, update.Message, DeadlineExceeded, "failure reason not sent to the RM")
+				notified = true
+			}
+		}
+	}
+	assert.Assert(t, released, "pending request should be released to the RM")
+	assert.Assert(t, notified, "RM should be notified of the failure")
+
+	// a failing app is not changed again
+	assert.Assert(t, !app.CheckDeadline(), "failing app should not be failed again")
+}
+
+func TestStateLogHistory(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+	defer func(size int) { maxStateLogEntries = size }(maxStateLogEntries)
+
+	app := newApplication(appID1, "default", "root.a")
+	start := mockClock.Now()
+	events := []applicationEvent{RunApplication, RunApplication, CompleteApplication, CompleteApplication}
+	for _, event := range events {
+		mockClock.Advance(time.Second)
+		assert.NilError(t, app.HandleApplicationEvent(event), "state change failed for event %s", event)
+	}
+	log := app.GetStateLog()
+	expected := []string{Accepted.String(), Running.String(), Completing.String(), Completed.String()}
+	assert.Equal(t, len(log), len(expected), "wrong number of state log entries")
+	for i, state := range expected {
+		assert.Equal(t, log[i].ApplicationState, state, "unexpected state at entry %d", i)
+		assert.Equal(t, log[i].Time, start.Add(time.Duration(i+1)*time.Second), "unexpected time at entry %d", i)
+	}
+	// the returned log is a copy
+	log[0].ApplicationState = Failed.String()
+	assert.Equal(t, app.GetStateLog()[0].ApplicationState, Accepted.String(), "state log of the app should not change")
+
+	// the log is bounded: the oldest entries are dropped
+	maxStateLogEntries = 3
+	app = newApplication(appID2, "default", "root.a")
+	for _, event := range events {
+		assert.NilError(t, app.HandleApplicationEvent(event), "state change failed for event %s", event)
+	}
+	log = app.GetStateLog()
+	assert.Equal(t, len(log), 3, "state log should be bounded")
+	for i, state := range expected[1:] {
+		assert.Equal(t, log[i].ApplicationState, state, "unexpected state at entry %d after wrap", i)
+	}
+	assert.NilError(t, app.HandleApplicationEvent(ExpireApplication), "expire failed")
+	log = app.GetStateLog()
+	assert.Equal(t, len(log), 3, "state log should be bounded")
+	assert.Equal(t, log[0].ApplicationState, Completing.String(), "oldest entry not dropped")
+	assert.Equal(t, log[2].ApplicationState, Expired.String(), "newest entry not last")
+}
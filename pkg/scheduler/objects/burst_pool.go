@@ -0,0 +1,106 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"time"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/locking"
+)
+
+// burstPool is the token bucket shared by the queues of a partition to use resources between their quota and burst.
+// The pool starts full and is refilled linearly: an empty pool is full again after the refill duration.
+type burstPool struct {
+	capacity   *resources.Resource // maximum tokens per resource type
+	tokens     *resources.Resource // tokens left per resource type
+	refill     time.Duration       // time to refill an empty pool, zero never refills
+	lastRefill time.Time           // last time tokens were added
+
+	locking.Mutex
+}
+
+func newBurstPool(capacity *resources.Resource, refill time.Duration) *burstPool {
+	return &burstPool{
+		capacity:   capacity.Clone(),
+		tokens:     capacity.Clone(),
+		refill:     refill,
+		lastRefill: getClock().Now(),
+	}
+}
+
+// replenish adds the tokens for the time passed since the last refill, the pool never holds more than its capacity.
+// lock free call, must be called holding the pool lock.
+func (bp *burstPool) replenish() {
+	now := getClock().Now()
+	if bp.refill <= 0 {
+		bp.lastRefill = now
+		return
+	}
+	elapsed := now.Sub(bp.lastRefill)
+	if elapsed <= 0 {
+		return
+	}
+	ratio := float64(elapsed) / float64(bp.refill)
+	added := resources.MultiplyBy(bp.capacity, ratio)
+	bp.tokens = resources.ComponentWiseMinOnlyExisting(resources.Add(bp.tokens, added), bp.capacity)
+	bp.lastRefill = now
+}
+
+// hasTokens returns true if the pool has enough tokens left for the demand. A demand without any positive quantity
+// always fits, a nil pool has no tokens.
+func (bp *burstPool) hasTokens(demand *resources.Resource) bool {
+	if resources.IsZero(demand) {
+		return true
+	}
+	if bp == nil {
+		return false
+	}
+	bp.Lock()
+	defer bp.Unlock()
+	bp.replenish()
+	for name, value := range demand.Resources {
+		if value > 0 && bp.tokens.Resources[name] < value {
+			return false
+		}
+	}
+	return true
+}
+
+// consume removes the tokens for the demand from the pool. The tokens never drop below zero.
+func (bp *burstPool) consume(demand *resources.Resource) {
+	if bp == nil || resources.IsZero(demand) {
+		return
+	}
+	bp.Lock()
+	defer bp.Unlock()
+	bp.replenish()
+	bp.tokens = resources.SubEliminateNegative(bp.tokens, demand)
+}
+
+// getTokens returns a copy of the tokens left in the pool.
+func (bp *burstPool) getTokens() *resources.Resource {
+	if bp == nil {
+		return nil
+	}
+	bp.Lock()
+	defer bp.Unlock()
+	bp.replenish()
+	return bp.tokens.Clone()
+}
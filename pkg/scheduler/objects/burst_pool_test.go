@@ -0,0 +1,64 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+func TestBurstPool(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+
+	var nilPool *burstPool
+	assert.Assert(t, nilPool.hasTokens(nil), "nil pool should allow an empty demand")
+	assert.Assert(t, !nilPool.hasTokens(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})), "nil pool should not have tokens")
+	nilPool.consume(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}))
+
+	capacity := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	pool := newBurstPool(capacity, 10*time.Second)
+	assert.Assert(t, resources.Equals(pool.getTokens(), capacity), "pool should start full")
+	assert.Assert(t, pool.hasTokens(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})), "full pool should have all tokens")
+	assert.Assert(t, !pool.hasTokens(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 11})), "pool should not have more than its capacity")
+	assert.Assert(t, !pool.hasTokens(resources.NewResourceFromMap(map[string]resources.Quantity{"second": 1})), "pool should not have tokens for other types")
+
+	// consuming removes tokens, never below zero
+	pool.consume(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8}))
+	assert.Assert(t, resources.Equals(pool.getTokens(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})), "tokens should have been consumed")
+	assert.Assert(t, !pool.hasTokens(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3})), "pool should not have the tokens")
+	pool.consume(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	assert.Assert(t, resources.IsZero(pool.getTokens()), "tokens should not drop below zero")
+
+	// refilled linearly up to the capacity
+	mockClock.Advance(5 * time.Second)
+	assert.Assert(t, resources.Equals(pool.getTokens(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})), "half the pool should be refilled")
+	mockClock.Advance(time.Minute)
+	assert.Assert(t, resources.Equals(pool.getTokens(), capacity), "pool should not be refilled over capacity")
+
+	// no refill: consumed tokens are never returned
+	pool = newBurstPool(capacity, 0)
+	pool.consume(capacity)
+	mockClock.Advance(time.Hour)
+	assert.Assert(t, resources.IsZero(pool.getTokens()), "pool without refill should stay empty")
+}
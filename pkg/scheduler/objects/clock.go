@@ -0,0 +1,114 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"time"
+
+	"github.com/apache/yunikorn-core/pkg/locking"
+)
+
+// Clock provides the current time to the scheduling objects. All timestamps in the objects, and the timestamps the
+// scheduler records next to them, use the package clock which allows tests to control time. The application state
+// and placeholder timers run on the wall clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+}
+
+// realClock is the wall clock used outside tests.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// MockClock is a clock that only moves when it is set or advanced.
+type MockClock struct {
+	current time.Time
+
+	locking.RWMutex
+}
+
+// NewMockClock creates a mock clock starting at the passed in time.
+func NewMockClock(start time.Time) *MockClock {
+	return &MockClock{current: start}
+}
+
+func (mc *MockClock) Now() time.Time {
+	mc.RLock()
+	defer mc.RUnlock()
+	return mc.current
+}
+
+func (mc *MockClock) Since(t time.Time) time.Duration {
+	return mc.Now().Sub(t)
+}
+
+// Set moves the clock to the passed in time.
+func (mc *MockClock) Set(t time.Time) {
+	mc.Lock()
+	defer mc.Unlock()
+	mc.current = t
+}
+
+// Advance moves the clock forward by the duration.
+func (mc *MockClock) Advance(d time.Duration) {
+	mc.Lock()
+	defer mc.Unlock()
+	mc.current = mc.current.Add(d)
+}
+
+var clock = struct {
+	clock Clock
+	locking.RWMutex
+}{
+	clock: realClock{},
+}
+
+// SetClock replaces the package clock and returns the clock that was replaced. A nil clock restores the wall clock.
+func SetClock(c Clock) Clock {
+	if c == nil {
+		c = realClock{}
+	}
+	clock.Lock()
+	defer clock.Unlock()
+	old := clock.clock
+	clock.clock = c
+	return old
+}
+
+// Now returns the current time of the package clock. Timestamps that are set on, or compared with, the scheduling
+// objects from outside the package must use it.
+func Now() time.Time {
+	return getClock().Now()
+}
+
+// getClock returns the package clock.
+func getClock() Clock {
+	clock.RLock()
+	defer clock.RUnlock()
+	return clock.clock
+}
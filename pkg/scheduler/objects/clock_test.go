@@ -0,0 +1,68 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMockClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockClock := NewMockClock(start)
+	assert.Equal(t, mockClock.Now(), start, "mock clock should start at the given time")
+	assert.Equal(t, mockClock.Since(start), time.Duration(0), "mock clock should not move on its own")
+
+	mockClock.Advance(time.Minute)
+	assert.Equal(t, mockClock.Now(), start.Add(time.Minute), "advance should move the clock forward")
+	assert.Equal(t, mockClock.Since(start), time.Minute, "since should use the mock time")
+
+	later := start.Add(time.Hour)
+	mockClock.Set(later)
+	assert.Equal(t, mockClock.Now(), later, "set should move the clock to the given time")
+}
+
+func TestSetClock(t *testing.T) {
+	_, ok := getClock().(realClock)
+	assert.Assert(t, ok, "package clock should default to the wall clock")
+
+	mockClock := NewMockClock(time.Now())
+	old := SetClock(mockClock)
+	_, ok = old.(realClock)
+	assert.Assert(t, ok, "replaced clock should be the wall clock")
+	assert.Equal(t, getClock(), Clock(mockClock), "package clock should be the mock clock")
+
+	old = SetClock(nil)
+	assert.Equal(t, old, Clock(mockClock), "replaced clock should be the mock clock")
+	_, ok = getClock().(realClock)
+	assert.Assert(t, ok, "nil clock should restore the wall clock")
+}
+
+func TestAllocationTimestampsMockClock(t *testing.T) {
+	mockClock := NewMockClock(time.Now().Add(-time.Hour))
+	defer SetClock(SetClock(mockClock))
+
+	alloc := newAllocationWithKey(aKey, appID1, nodeID1, nil)
+	assert.Equal(t, alloc.GetCreateTime(), mockClock.Now(), "create time should come from the mock clock")
+	mockClock.Advance(time.Minute)
+	alloc.MarkPreempted()
+	assert.Equal(t, alloc.GetPreemptedTime(), mockClock.Now(), "preempted time should come from the mock clock")
+}
@@ -143,6 +143,22 @@ func (ae *ApplicationEvents) SendAppRunnableQuotaEvent(appID string) {
 	ae.eventSystem.AddEvent(event)
 }
 
+func (ae *ApplicationEvents) SendAppPausedEvent(appID string) {
+	if !ae.eventSystem.IsEventTrackingEnabled() {
+		return
+	}
+	event := events.CreateAppEventRecord(appID, "application paused", common.Empty, si.EventRecord_NONE, si.EventRecord_DETAILS_NONE, nil)
+	ae.eventSystem.AddEvent(event)
+}
+
+func (ae *ApplicationEvents) SendAppResumedEvent(appID string) {
+	if !ae.eventSystem.IsEventTrackingEnabled() {
+		return
+	}
+	event := events.CreateAppEventRecord(appID, "application resumed", common.Empty, si.EventRecord_NONE, si.EventRecord_DETAILS_NONE, nil)
+	ae.eventSystem.AddEvent(event)
+}
+
 func NewApplicationEvents(es events.EventSystem) *ApplicationEvents {
 	return &ApplicationEvents{
 		eventSystem: es,
@@ -51,7 +51,7 @@ func TestRequestDoesNotFitInQueueEvent(t *testing.T) {
 	assert.Equal(t, si.EventRecord_REQUEST, event.Type)
 	assert.Equal(t, si.EventRecord_NONE, event.EventChangeType)
 	assert.Equal(t, si.EventRecord_DETAILS_NONE, event.EventChangeDetail)
-	assert.Equal(t, "Request 'alloc-0' does not fit in queue 'root.test' (requested map[cpu:100 memory:100], available map[first:1])", event.Message)
+	assert.Equal(t, "Request 'alloc-0' does not fit in queue 'root.test' (requested map[memory:100 cpu:100], available map[first:1])", event.Message)
 }
 
 func TestRequestFitsInQueueEvent(t *testing.T) {
@@ -90,7 +90,7 @@ func TestRequestExceedsUserQuotaEvent(t *testing.T) {
 	assert.Equal(t, si.EventRecord_REQUEST, event.Type)
 	assert.Equal(t, si.EventRecord_NONE, event.EventChangeType)
 	assert.Equal(t, si.EventRecord_DETAILS_NONE, event.EventChangeDetail)
-	assert.Equal(t, "Request 'alloc-0' exceeds the available user quota (requested map[cpu:100 memory:100], available map[first:1])", event.Message)
+	assert.Equal(t, "Request 'alloc-0' exceeds the available user quota (requested map[memory:100 cpu:100], available map[first:1])", event.Message)
 }
 
 func TestRequestFitsInUserQuotaEvent(t *testing.T) {
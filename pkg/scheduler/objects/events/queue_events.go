@@ -19,6 +19,9 @@
 package events
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/resources"
 	"github.com/apache/yunikorn-core/pkg/events"
@@ -104,6 +107,46 @@ func (q *QueueEvents) SendTypeChangedEvent(queuePath string, isLeaf bool) {
 	q.eventSystem.AddEvent(event)
 }
 
+func (q *QueueEvents) SendAllocatedDriftEvent(queuePath string, recorded, actual *resources.Resource) {
+	if !q.eventSystem.IsEventTrackingEnabled() {
+		return
+	}
+	message := fmt.Sprintf("allocated resource drift detected: recorded %s, actual %s", recorded, actual)
+	event := events.CreateQueueEventRecord(queuePath, message, common.Empty, si.EventRecord_NONE,
+		si.EventRecord_DETAILS_NONE, actual)
+	q.eventSystem.AddEvent(event)
+}
+
+func (q *QueueEvents) SendACLAuditEvent(queuePath, user string) {
+	if !q.eventSystem.IsEventTrackingEnabled() {
+		return
+	}
+	message := "submit access denied in acl audit mode for user: " + user
+	event := events.CreateQueueEventRecord(queuePath, message, common.Empty, si.EventRecord_NONE,
+		si.EventRecord_DETAILS_NONE, nil)
+	q.eventSystem.AddEvent(event)
+}
+
+func (q *QueueEvents) SendPriorityBoostEvent(queuePath string, offset int32, expiry time.Time) {
+	if !q.eventSystem.IsEventTrackingEnabled() {
+		return
+	}
+	message := fmt.Sprintf("priority boosted to offset %d until %s", offset, expiry.Format(time.RFC3339))
+	event := events.CreateQueueEventRecord(queuePath, message, common.Empty, si.EventRecord_SET,
+		si.EventRecord_DETAILS_NONE, nil)
+	q.eventSystem.AddEvent(event)
+}
+
+func (q *QueueEvents) SendPriorityBoostExpiredEvent(queuePath string, offset int32) {
+	if !q.eventSystem.IsEventTrackingEnabled() {
+		return
+	}
+	message := fmt.Sprintf("priority boost expired, reverted to offset %d", offset)
+	event := events.CreateQueueEventRecord(queuePath, message, common.Empty, si.EventRecord_REMOVE,
+		si.EventRecord_DETAILS_NONE, nil)
+	q.eventSystem.AddEvent(event)
+}
+
 func NewQueueEvents(evt events.EventSystem) *QueueEvents {
 	return &QueueEvents{
 		eventSystem: evt,
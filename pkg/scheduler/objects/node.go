@@ -54,6 +54,7 @@ type Node struct {
 	availableResource *resources.Resource
 	allocations       map[string]*Allocation
 	schedulable       bool
+	cordoned          bool
 
 	reservations map[string]*reservation // a map of reservations
 	listeners    []NodeListener          // a list of node listeners
@@ -273,6 +274,29 @@ func (sn *Node) IsSchedulable() bool {
 	return sn.schedulable
 }
 
+// SetCordoned sets the cordon state of the node. A cordoned node is skipped during the scheduling cycle, the
+// allocations already on the node are not changed.
+func (sn *Node) SetCordoned(cordoned bool) {
+	defer sn.notifyListeners()
+	sn.Lock()
+	defer sn.Unlock()
+	sn.cordoned = cordoned
+}
+
+// IsCordoned returns true if the node has been cordoned.
+func (sn *Node) IsCordoned() bool {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.cordoned
+}
+
+// canSchedule returns true if new allocations can be placed on the node: it is schedulable and not cordoned.
+func (sn *Node) canSchedule() bool {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.schedulable && !sn.cordoned
+}
+
 // Get the allocated resource on this node.
 func (sn *Node) GetAllocatedResource() *resources.Resource {
 	sn.RLock()
@@ -651,6 +675,20 @@ func (sn *Node) GetReservations() []*reservation {
 	return res
 }
 
+// GetReservedResource returns the total resource of the reservations on this node that have not been allocated.
+// Returns an empty resource if the node has no reservations.
+func (sn *Node) GetReservedResource() *resources.Resource {
+	sn.RLock()
+	defer sn.RUnlock()
+	reserved := resources.NewResource()
+	for _, r := range sn.reservations {
+		if !r.alloc.IsAllocated() {
+			reserved.AddTo(r.alloc.GetAllocatedResource())
+		}
+	}
+	return reserved
+}
+
 // GetResourceUsageShares gets a map of name -> resource usages per type in shares (0 to 1). Can return NaN.
 func (sn *Node) GetResourceUsageShares() map[string]float64 {
 	sn.RLock()
@@ -19,6 +19,8 @@
 package objects
 
 import (
+	"sort"
+
 	"github.com/google/btree"
 )
 
@@ -55,3 +57,86 @@ func NewTreeIterator(accept func(*Node) bool, getTree func() *btree.BTree) *tree
 	}
 	return ti
 }
+
+// policyIterator iterates over the nodes of a base iterator in the order defined by a node sorting policy.
+// The nodes are scored and sorted the first time the iterator is used, later calls use the same order.
+type policyIterator struct {
+	base   NodeIterator
+	policy NodeSortingPolicy
+	refs   []nodeRef
+	sorted bool
+}
+
+// ForEachNode Calls the provided "f" function on the Node objects of the base iterator, sorted using the
+// node sorting policy, until it returns false.
+func (pi *policyIterator) ForEachNode(f func(*Node) bool) {
+	if !pi.sorted {
+		pi.base.ForEachNode(func(node *Node) bool {
+			pi.refs = append(pi.refs, nodeRef{node: node, nodeScore: pi.policy.ScoreNode(node)})
+			return true
+		})
+		sort.Slice(pi.refs, func(i, j int) bool {
+			return pi.refs[i].Less(pi.refs[j])
+		})
+		pi.sorted = true
+	}
+	for _, ref := range pi.refs {
+		if !f(ref.node) {
+			return
+		}
+	}
+}
+
+// newPolicyIterator returns an iterator that orders the nodes of the base iterator using the policy.
+// A nil base iterator returns nil.
+func newPolicyIterator(base NodeIterator, policy NodeSortingPolicy) NodeIterator {
+	if base == nil {
+		return nil
+	}
+	return &policyIterator{
+		base:   base,
+		policy: policy,
+	}
+}
+
+// affinityIterator iterates over the nodes of a base iterator returning the preferred nodes first.
+// The order of the base iterator is kept for the preferred nodes and for the other nodes.
+type affinityIterator struct {
+	base      NodeIterator
+	preferred map[string]bool
+}
+
+// ForEachNode Calls the provided "f" function on the preferred Node objects of the base iterator followed by the
+// other Node objects, until it returns false.
+func (ai *affinityIterator) ForEachNode(f func(*Node) bool) {
+	var others []*Node
+	done := false
+	ai.base.ForEachNode(func(node *Node) bool {
+		if !ai.preferred[node.NodeID] {
+			others = append(others, node)
+			return true
+		}
+		done = !f(node)
+		return !done
+	})
+	if done {
+		return
+	}
+	for _, node := range others {
+		if !f(node) {
+			return
+		}
+	}
+}
+
+// newAffinityIterator returns an iterator that moves the preferred nodes of the base iterator to the front.
+// The base iterator is returned unchanged if it is nil or there are no preferred nodes.
+func newAffinityIterator(base NodeIterator, preferred map[string]bool) NodeIterator {
+	if base == nil || len(preferred) == 0 {
+		return base
+	}
+	return &affinityIterator{
+		base:      base,
+		preferred: preferred,
+	}
+}
@@ -65,6 +65,63 @@ func TestTreeIterator_AcceptUnreserved(t *testing.T) {
 	}
 }
 
+func TestPolicyIterator(t *testing.T) {
+	assert.Assert(t, newPolicyIterator(nil, fairnessNodeSortingPolicy{}) == nil, "nil base should not return an iterator")
+
+	tree := getTree()
+	treeItr := NewTreeIterator(acceptAll, func() *btree.BTree {
+		return tree
+	})
+	policyItr := newPolicyIterator(treeItr, fairnessNodeSortingPolicy{resourceWeights: defaultResourceWeights()})
+	checked := make([]*Node, 0)
+	policyItr.ForEachNode(func(node *Node) bool {
+		checked = append(checked, node)
+		return true
+	})
+	assert.Equal(t, 10, len(checked))
+	// equal scores fall back to the node ID
+	for i := 1; i < len(checked); i++ {
+		assert.Assert(t, checked[i-1].NodeID < checked[i].NodeID, "nodes not sorted as expected")
+	}
+
+	checked = make([]*Node, 0)
+	policyItr.ForEachNode(func(node *Node) bool {
+		checked = append(checked, node)
+		return len(checked) < 3
+	})
+	assert.Equal(t, 3, len(checked))
+}
+
+func TestAffinityIterator(t *testing.T) {
+	assert.Assert(t, newAffinityIterator(nil, map[string]bool{"node-1": true}) == nil, "nil base should not return an iterator")
+
+	tree := getTree()
+	treeItr := NewTreeIterator(acceptAll, func() *btree.BTree {
+		return tree
+	})
+	assert.Equal(t, newAffinityIterator(treeItr, nil), NodeIterator(treeItr), "no preferred nodes should return the base iterator")
+
+	affinityItr := newAffinityIterator(treeItr, map[string]bool{"node-7": true, "node-3": true, "unknown": true})
+	checked := make([]string, 0)
+	affinityItr.ForEachNode(func(node *Node) bool {
+		checked = append(checked, node.NodeID)
+		return true
+	})
+	assert.Equal(t, 10, len(checked))
+	assert.DeepEqual(t, checked[:2], []string{"node-3", "node-7"})
+	for i := 3; i < len(checked); i++ {
+		assert.Assert(t, checked[i-1] < checked[i], "other nodes not in base order")
+	}
+
+	// stop while iterating over the preferred nodes
+	checked = make([]string, 0)
+	affinityItr.ForEachNode(func(node *Node) bool {
+		checked = append(checked, node.NodeID)
+		return false
+	})
+	assert.DeepEqual(t, checked, []string{"node-3"})
+}
+
 func getTree() *btree.BTree {
 	nodesReserved := newSchedNodeList(0, 5, true)
 	nodes := newSchedNodeList(5, 10, false)
@@ -35,6 +35,7 @@ import (
 
 var (
 	preemptAttemptFrequency        = 15 * time.Second
+	preemptedUserMemory            = 5 * time.Minute
 	preemptCheckConcurrency        = 10
 	scoreFitMax             uint64 = 1 << 32
 	scoreOriginator         uint64 = 1 << 33
@@ -58,6 +59,7 @@ type Preemptor struct {
 	queueByAlloc       map[string]*QueuePreemptionSnapshot // map of queue snapshots by allocationKey
 	allocationsByNode  map[string][]*Allocation            // map of allocation by nodeID
 	nodeAvailableMap   map[string]*resources.Resource      // map of available resources by nodeID
+	recentVictims      map[string]bool                     // allocationKeys of victims owned by a recently preempted user
 }
 
 // QueuePreemptionSnapshot is used to track a snapshot of a queue for preemption
@@ -90,7 +92,7 @@ func NewPreemptor(application *Application, headRoom *resources.Resource, preemp
 // CheckPreconditions performs simple sanity checks designed to determine if preemption should be attempted
 // for an ask. If checks succeed, updates the ask preemption check time.
 func (p *Preemptor) CheckPreconditions() bool {
-	now := time.Now()
+	now := getClock().Now()
 
 	// skip if ask is not allowed to preempt other tasks
 	if !p.ask.IsAllowPreemptOther() {
@@ -124,6 +126,7 @@ func (p *Preemptor) CheckPreconditions() bool {
 }
 
 // initQueueSnapshots ensures that snapshots have been taken of the queue
+// Victims are only searched in the queue hierarchy of the preemptor: preemption never crosses a partition.
 func (p *Preemptor) initQueueSnapshots() {
 	if p.allocationsByQueue != nil {
 		return
@@ -161,7 +164,7 @@ func (p *Preemptor) initWorkingState() {
 
 	// walk node iterator and track available resources per node
 	p.iterator.ForEachNode(func(node *Node) bool {
-		if !node.IsSchedulable() || (node.IsReserved() && !node.isReservedForAllocation(p.ask.GetAllocationKey())) || !node.FitInNode(p.ask.GetAllocatedResource()) {
+		if !node.canSchedule() || (node.IsReserved() && !node.isReservedForAllocation(p.ask.GetAllocationKey())) || !node.FitInNode(p.ask.GetAllocatedResource()) {
 			// node is not available, remove any potential victims from consideration
 			delete(allocationsByNode, node.NodeID)
 		} else {
@@ -171,14 +174,46 @@ func (p *Preemptor) initWorkingState() {
 		return true
 	})
 
+	p.recentVictims = p.findRecentVictims()
+
 	// sort the allocations on each node in the order we'd like to try them
-	sortVictimsForPreemption(allocationsByNode)
+	sortVictimsForPreemption(allocationsByNode, p.recentVictims)
 
 	p.allocationsByNode = allocationsByNode
 	p.queueByAlloc = queueByAlloc
 	p.nodeAvailableMap = nodeAvailableMap
 }
 
+// findRecentVictims returns the allocationKeys of the potential victims that belong to a user that had allocations
+// preempted recently. These victims are considered after the victims of other users to spread preemption over users.
+func (p *Preemptor) findRecentVictims() map[string]bool {
+	users := p.queue.getRecentlyPreemptedUsers()
+	if len(users) == 0 {
+		return nil
+	}
+	recent := make(map[string]bool)
+	appUsers := make(map[string]string)
+	for _, victims := range p.allocationsByQueue {
+		for _, allocation := range victims.PotentialVictims {
+			appID := allocation.GetApplicationID()
+			user, ok := appUsers[appID]
+			if !ok {
+				// the application could have been removed since the snapshot was taken
+				if appQueue := p.queue.FindQueueByAppID(appID); appQueue != nil {
+					if app := appQueue.GetApplication(appID); app != nil {
+						user = app.GetUser().User
+					}
+				}
+				appUsers[appID] = user
+			}
+			if users[user] {
+				recent[allocation.GetAllocationKey()] = true
+			}
+		}
+	}
+	return recent
+}
+
 // checkPreemptionQueueGuarantees verifies that it's possible to free enough resources to fit the given ask
 func (p *Preemptor) checkPreemptionQueueGuarantees() bool {
 	p.initQueueSnapshots()
@@ -236,7 +271,12 @@ func (p *Preemptor) calculateVictimsByNode(nodeAvailable *resources.Resource, po
 	// capacity and the queue guaranteed headroom.
 	head := make([]*Allocation, 0)
 	tail := make([]*Allocation, 0)
+	// only victims holding a resource type the node is short of can help
+	contended := resources.SubEliminateNegative(p.ask.GetAllocatedResource(), nodeCurrentAvailable)
 	for _, victim := range potentialVictims {
+		if !holdsContendedResource(victim, contended) {
+			continue
+		}
 		// check to see if removing this task will keep queue above guaranteed amount; if not, skip to the next one
 		if qv, ok := p.queueByAlloc[victim.GetAllocationKey()]; ok {
 			if queueSnapshot, ok2 := allocationsByQueueSnap[qv.QueuePath]; ok2 {
@@ -462,7 +502,7 @@ func (p *Preemptor) calculateAdditionalVictims(nodeVictims []*Allocation) ([]*Al
 		}
 	}
 	sort.SliceStable(potentialVictims, func(i, j int) bool {
-		return compareAllocationLess(potentialVictims[i], potentialVictims[j])
+		return compareAllocationLess(potentialVictims[i], potentialVictims[j], p.recentVictims)
 	})
 
 	// evaluate each potential victim in turn, stopping once sufficient resources have been freed
@@ -555,10 +595,104 @@ func (p *Preemptor) tryNodes() (string, []*Allocation, bool) {
 }
 
 func (p *Preemptor) TryPreemption() (*AllocationResult, bool) {
+	nodeID, finalVictims, reason := p.selectVictims()
+	if finalVictims == nil {
+		if reason != common.PreemptionDoesNotHelp {
+			p.ask.LogAllocationFailure(reason, true)
+		}
+		return nil, false
+	}
+
+	// the partition limits the resource preempted within the quota window: the ask is evaluated again after the
+	// preemption attempt frequency, not in every cycle while the quota is used up
+	preempting := resources.NewResource()
+	for _, victim := range finalVictims {
+		preempting.AddTo(victim.GetAllocatedResource())
+	}
+	if !p.queue.usePreemptionQuota(preempting) {
+		p.ask.LogAllocationFailure(common.PreemptionQuotaExhausted, true)
+		return nil, false
+	}
+
+	// preempt the victims
+	for _, victim := range finalVictims {
+		if victimQueue := p.queue.FindQueueByAppID(victim.GetApplicationID()); victimQueue != nil {
+			victimQueue.IncPreemptingResource(victim.GetAllocatedResource())
+			victimQueue.startPreemptionCooldown()
+			if victimApp := victimQueue.GetApplication(victim.GetApplicationID()); victimApp != nil {
+				victimQueue.recordUserPreempted(victimApp.GetUser().User)
+			}
+			victim.MarkPreempted()
+			log.Log(log.SchedPreemption).Info("Preempting task",
+				zap.String("askApplicationID", p.ask.applicationID),
+				zap.String("askAllocationKey", p.ask.allocationKey),
+				zap.String("askQueue", p.queue.Name),
+				zap.String("victimApplicationID", victim.GetApplicationID()),
+				zap.String("victimAllocationKey", victim.GetAllocationKey()),
+				zap.Stringer("victimAllocatedResource", victim.GetAllocatedResource()),
+				zap.String("victimNodeID", victim.GetNodeID()),
+				zap.String("victimQueue", victimQueue.Name),
+			)
+			victim.SendPreemptedBySchedulerEvent(p.ask.allocationKey, p.ask.applicationID, p.application.queuePath)
+		} else {
+			log.Log(log.SchedPreemption).Warn("BUG: Queue not found for preemption victim",
+				zap.String("queue", p.queue.Name),
+				zap.String("victimApplicationID", victim.GetApplicationID()),
+				zap.String("victimAllocationKey", victim.GetAllocationKey()))
+		}
+	}
+
+	// mark ask as having triggered preemption so that we don't preempt again
+	p.ask.MarkTriggeredPreemption()
+
+	// notify RM that victims should be released
+	p.application.notifyRMAllocationReleased(finalVictims, si.TerminationType_PREEMPTED_BY_SCHEDULER,
+		"preempting allocations to free up resources to run ask: "+p.ask.GetAllocationKey())
+
+	// reserve the selected node for the new allocation if it will fit
+	log.Log(log.SchedPreemption).Info("Reserving node for ask after preemption",
+		zap.String("allocationKey", p.ask.GetAllocationKey()),
+		zap.String("nodeID", nodeID),
+		zap.Int("victimCount", len(finalVictims)))
+	return newReservedAllocationResult(nodeID, p.ask), true
+}
+
+// PreemptionReport is the outcome of a preemption dry run for an ask: the node the ask would be placed on, the
+// victims that would be preempted and the resources they free. The reason is set if preemption would not help.
+type PreemptionReport struct {
+	AllocationKey string
+	NodeID        string
+	Victims       []*Allocation
+	Freed         *resources.Resource
+	Reason        string
+}
+
+// DryRun selects the victims and node in the same way as TryPreemption without preempting the victims. The victims,
+// the ask, the queues and the preemption quota are not changed.
+func (p *Preemptor) DryRun() *PreemptionReport {
+	report := &PreemptionReport{
+		AllocationKey: p.ask.GetAllocationKey(),
+		Freed:         resources.NewResource(),
+	}
+	nodeID, victims, reason := p.selectVictims()
+	if victims == nil {
+		report.Reason = reason
+		return report
+	}
+	report.NodeID = nodeID
+	report.Victims = victims
+	for _, victim := range victims {
+		report.Freed.AddTo(victim.GetAllocatedResource())
+	}
+	return report
+}
+
+// selectVictims finds the node and the victims to preempt to place the ask. If no victims are found the reason is
+// returned, and the victims are nil.
+func (p *Preemptor) selectVictims() (string, []*Allocation, string) {
 	// validate that sufficient capacity can be freed
 	if !p.checkPreemptionQueueGuarantees() {
-		p.ask.LogAllocationFailure(common.PreemptionDoesNotGuarantee, true)
-		return nil, false
+		return "", nil, common.PreemptionDoesNotGuarantee
 	}
 
 	// ensure required data structures are populated
@@ -568,18 +702,18 @@ func (p *Preemptor) TryPreemption() (*AllocationResult, bool) {
 	nodeID, victims, ok := p.tryNodes()
 	if !ok {
 		// no preemption possible
-		return nil, false
+		return "", nil, common.PreemptionDoesNotHelp
 	}
 
 	// look for additional victims in case we have not yet made enough capacity in the queue
 	extraVictims, ok := p.calculateAdditionalVictims(victims)
 	if !ok {
 		// not enough resources were preempted
-		return nil, false
+		return "", nil, common.PreemptionDoesNotHelp
 	}
 	victims = append(victims, extraVictims...)
 	if len(victims) == 0 {
-		return nil, false
+		return "", nil, common.PreemptionDoesNotHelp
 	}
 
 	// Did victims collected so far fulfill the ask need? In case of any shortfall between the ask resource requirement
@@ -593,6 +727,13 @@ func (p *Preemptor) TryPreemption() (*AllocationResult, bool) {
 	if nodeCurrentAvailable[nodeID].FitIn(p.ask.GetAllocatedResource()) {
 		fitIn = true
 	}
+	// only victims holding a resource type the node or the queue is short of can help
+	var contended *resources.Resource
+	if fitIn {
+		contended = p.getQueueContended()
+	} else {
+		contended = resources.SubEliminateNegative(p.ask.GetAllocatedResource(), nodeCurrentAvailable[nodeID])
+	}
 
 	// Since there could be more victims than the actual need, ensure only required victims are filtered finally
 	// to do: There is room for improvements especially when there are more victims. victims could be chosen based
@@ -605,6 +746,10 @@ func (p *Preemptor) TryPreemption() (*AllocationResult, bool) {
 		if !fitIn && victim.GetNodeID() != nodeID {
 			continue
 		}
+		// skip victims that only hold resource types that are not contended
+		if !holdsContendedResource(victim, contended) {
+			continue
+		}
 		// stop collecting the victims once ask resource requirement met
 		if p.ask.GetAllocatedResource().StrictlyGreaterThanOnlyExisting(victimsTotalResource) {
 			finalVictims = append(finalVictims, victim)
@@ -615,47 +760,12 @@ func (p *Preemptor) TryPreemption() (*AllocationResult, bool) {
 
 	if p.ask.GetAllocatedResource().StrictlyGreaterThanOnlyExisting(victimsTotalResource) {
 		// there is shortfall, so preemption doesn't help
-		p.ask.LogAllocationFailure(common.PreemptionShortfall, true)
-		return nil, false
+		return "", nil, common.PreemptionShortfall
 	}
-
-	// preempt the victims
-	for _, victim := range finalVictims {
-		if victimQueue := p.queue.FindQueueByAppID(victim.GetApplicationID()); victimQueue != nil {
-			victimQueue.IncPreemptingResource(victim.GetAllocatedResource())
-			victim.MarkPreempted()
-			log.Log(log.SchedPreemption).Info("Preempting task",
-				zap.String("askApplicationID", p.ask.applicationID),
-				zap.String("askAllocationKey", p.ask.allocationKey),
-				zap.String("askQueue", p.queue.Name),
-				zap.String("victimApplicationID", victim.GetApplicationID()),
-				zap.String("victimAllocationKey", victim.GetAllocationKey()),
-				zap.Stringer("victimAllocatedResource", victim.GetAllocatedResource()),
-				zap.String("victimNodeID", victim.GetNodeID()),
-				zap.String("victimQueue", victimQueue.Name),
-			)
-			victim.SendPreemptedBySchedulerEvent(p.ask.allocationKey, p.ask.applicationID, p.application.queuePath)
-		} else {
-			log.Log(log.SchedPreemption).Warn("BUG: Queue not found for preemption victim",
-				zap.String("queue", p.queue.Name),
-				zap.String("victimApplicationID", victim.GetApplicationID()),
-				zap.String("victimAllocationKey", victim.GetAllocationKey()))
-		}
+	if len(finalVictims) == 0 {
+		return "", nil, common.PreemptionDoesNotHelp
 	}
-
-	// mark ask as having triggered preemption so that we don't preempt again
-	p.ask.MarkTriggeredPreemption()
-
-	// notify RM that victims should be released
-	p.application.notifyRMAllocationReleased(finalVictims, si.TerminationType_PREEMPTED_BY_SCHEDULER,
-		"preempting allocations to free up resources to run ask: "+p.ask.GetAllocationKey())
-
-	// reserve the selected node for the new allocation if it will fit
-	log.Log(log.SchedPreemption).Info("Reserving node for ask after preemption",
-		zap.String("allocationKey", p.ask.GetAllocationKey()),
-		zap.String("nodeID", nodeID),
-		zap.Int("victimCount", len(victims)))
-	return newReservedAllocationResult(nodeID, p.ask), true
+	return nodeID, finalVictims, ""
 }
 
 // Duplicate creates a copy of this snapshot into the given map by queue path
@@ -797,16 +907,51 @@ func (qps *QueuePreemptionSnapshot) RemoveAllocation(alloc *resources.Resource)
 	qps.AllocatedResource.SubFrom(alloc)
 }
 
+// getQueueContended returns the part of the ask that does not fit in the headroom of the queue. Resource types that
+// are not limited by the headroom are not contended.
+func (p *Preemptor) getQueueContended() *resources.Resource {
+	contended := resources.NewResource()
+	if p.headRoom == nil {
+		return contended
+	}
+	for name, quantity := range p.ask.GetAllocatedResource().Resources {
+		if available, ok := p.headRoom.Resources[name]; ok && quantity > available {
+			contended.Resources[name] = quantity - available
+		}
+	}
+	return contended
+}
+
+// holdsContendedResource returns true if the allocation holds any of the contended resource types. If no resource
+// type is contended every allocation qualifies.
+func holdsContendedResource(alloc *Allocation, contended *resources.Resource) bool {
+	if resources.IsZero(contended) {
+		return true
+	}
+	allocated := alloc.GetAllocatedResource()
+	for name, quantity := range contended.Resources {
+		if quantity > 0 && allocated.Resources[name] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // compareAllocationLess compares two allocations for preemption. Allocations which have opted into preemption are
 // considered first, then allocations which are not the originator of their associated application. Ties are broken
-// by creation time, with
-// then
-func compareAllocationLess(left *Allocation, right *Allocation) bool {
+// by the allocations in the recent map, which are considered last, then
+// by creation time, with newest first
+func compareAllocationLess(left *Allocation, right *Allocation, recent map[string]bool) bool {
 	scoreLeft := scoreAllocation(left)
 	scoreRight := scoreAllocation(right)
 	if scoreLeft != scoreRight {
 		return scoreLeft < scoreRight
 	}
+	recentLeft := recent[left.allocationKey]
+	recentRight := recent[right.allocationKey]
+	if recentLeft != recentRight {
+		return recentRight
+	}
 	return left.createTime.After(right.createTime)
 }
 
@@ -825,8 +970,8 @@ func scoreAllocation(allocation *Allocation) uint64 {
 }
 
 // sortVictimsForPreemption sorts allocations on each node, preferring those that have opted-in to preemption,
-// those that are not originating tasks for an application, and newest first
-func sortVictimsForPreemption(allocationsByNode map[string][]*Allocation) {
+// those that are not originating tasks for an application, those that are not in the recent map, and newest first
+func sortVictimsForPreemption(allocationsByNode map[string][]*Allocation, recent map[string]bool) {
 	for _, allocations := range allocationsByNode {
 		sort.SliceStable(allocations, func(i, j int) bool {
 			leftAsk := allocations[i]
@@ -848,6 +993,13 @@ func sortVictimsForPreemption(allocationsByNode map[string][]*Allocation) {
 				return true
 			}
 
+			// next those that do not belong to a recently preempted user
+			recentLeft := recent[leftAsk.GetAllocationKey()]
+			recentRight := recent[rightAsk.GetAllocationKey()]
+			if recentLeft != recentRight {
+				return recentRight
+			}
+
 			// finally sort by creation time descending
 			return leftAsk.GetCreateTime().After(rightAsk.GetCreateTime())
 		})
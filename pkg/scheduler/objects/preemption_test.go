@@ -295,6 +295,230 @@ func TestTryPreemption(t *testing.T) {
 	assert.Equal(t, len(ask3.GetAllocationLog()), 0)
 }
 
+func TestPreemptorDryRun(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10, "pods": 5})
+	iterator := getNodeIteratorFn(node)
+	rootQ, err := createRootQueue(map[string]string{"first": "20", "pods": "5"})
+	assert.NilError(t, err)
+	parentQ, err := createManagedQueueGuaranteed(rootQ, "parent", true, map[string]string{"first": "20"}, map[string]string{"first": "10"})
+	assert.NilError(t, err)
+	childQ1, err := createManagedQueueGuaranteed(parentQ, "child1", false, map[string]string{"first": "10"}, map[string]string{"first": "5"})
+	assert.NilError(t, err)
+	childQ2, err := createManagedQueueGuaranteed(parentQ, "child2", false, map[string]string{"first": "10"}, map[string]string{"first": "5"})
+	assert.NilError(t, err)
+
+	alloc1, alloc2, err := creatApp1(childQ1, node, nil, map[string]resources.Quantity{"first": 5, "pods": 1})
+	assert.NilError(t, err)
+
+	app2, ask3, err := creatApp2(childQ2, map[string]resources.Quantity{"first": 5, "pods": 1}, "alloc3")
+	assert.NilError(t, err)
+	childQ2.incPendingResource(ask3.GetAllocatedResource())
+
+	headRoom := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "pods": 3})
+
+	// register predicate handler
+	preemptions := []mock.Preemption{
+		mock.NewPreemption(true, "alloc3", nodeID1, []string{"alloc1"}, 0, 0),
+	}
+	plugin := mock.NewPreemptionPredicatePlugin(nil, nil, preemptions)
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.UnregisterSchedulerPlugins()
+
+	report := NewPreemptor(app2, headRoom, 30*time.Second, ask3, iterator(), false).DryRun()
+	assert.NilError(t, plugin.GetPredicateError())
+	assert.Equal(t, "alloc3", report.AllocationKey, "wrong alloc")
+	assert.Equal(t, "", report.Reason, "victims expected")
+	assert.Equal(t, nodeID1, report.NodeID, "wrong node")
+	assert.Equal(t, 1, len(report.Victims), "wrong number of victims")
+	assert.Equal(t, alloc1, report.Victims[0], "wrong victim")
+	assert.Assert(t, resources.Equals(report.Freed, alloc1.GetAllocatedResource()), "wrong freed resource")
+	assert.Check(t, !alloc1.IsPreempted(), "alloc1 preempted by dry run")
+	assert.Check(t, !ask3.HasTriggeredPreemption(), "ask marked by dry run")
+	assert.Check(t, resources.IsZero(childQ1.GetPreemptingResource()), "preempting resource changed by dry run")
+
+	// the real preemption selects the same victim
+	result, ok := NewPreemptor(app2, headRoom, 30*time.Second, ask3, iterator(), false).TryPreemption()
+	assert.Assert(t, result != nil, "no result")
+	assert.Assert(t, ok, "no victims found")
+	assert.Check(t, alloc1.IsPreempted(), "alloc1 not preempted")
+	assert.Check(t, !alloc2.IsPreempted(), "alloc2 preempted")
+}
+
+func TestTryPreemptionCooldown(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10, "pods": 5})
+	iterator := getNodeIteratorFn(node)
+	rootQ, err := createRootQueue(map[string]string{"first": "20", "pods": "5"})
+	assert.NilError(t, err)
+	rootQ.SetPreemptionCooldown(time.Minute)
+	parentQ, err := createManagedQueueGuaranteed(rootQ, "parent", true, map[string]string{"first": "20"}, map[string]string{"first": "10"})
+	assert.NilError(t, err)
+	// no guarantee on the victim queue: it stays eligible after the first preemption
+	childQ1, err := createManagedQueueGuaranteed(parentQ, "child1", false, map[string]string{"first": "10"}, nil)
+	assert.NilError(t, err)
+	childQ2, err := createManagedQueueGuaranteed(parentQ, "child2", false, map[string]string{"first": "10"}, map[string]string{"first": "5"})
+	assert.NilError(t, err)
+
+	alloc1, alloc2, err := creatApp1(childQ1, node, nil, map[string]resources.Quantity{"first": 5, "pods": 1})
+	assert.NilError(t, err)
+	// both allocations on the node belong to the victim application
+	childQ1.applications[appID1].AddAllocation(alloc2)
+	app2, ask3, err := creatApp2(childQ2, map[string]resources.Quantity{"first": 5, "pods": 1}, "alloc3")
+	assert.NilError(t, err)
+	childQ2.incPendingResource(ask3.GetAllocatedResource())
+	assert.Assert(t, !childQ1.isInPreemptionCooldown(), "queue should not be in cooldown before preemption")
+
+	preemptions := []mock.Preemption{
+		mock.NewPreemption(true, "alloc3", nodeID1, []string{"alloc2"}, 0, 0),
+		mock.NewPreemption(true, "alloc4", nodeID1, []string{"alloc1"}, 0, 0),
+	}
+	plugin := mock.NewPreemptionPredicatePlugin(nil, nil, preemptions)
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.UnregisterSchedulerPlugins()
+
+	headRoom := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "pods": 3})
+	preemptor := NewPreemptor(app2, headRoom, 30*time.Second, ask3, iterator(), false)
+	result, ok := preemptor.TryPreemption()
+	assert.NilError(t, plugin.GetPredicateError())
+	assert.Assert(t, ok && result != nil, "first preemption should have succeeded")
+	assert.Check(t, alloc2.IsPreempted(), "alloc2 not preempted")
+	assert.Check(t, !alloc1.IsPreempted(), "alloc1 preempted")
+	assert.Assert(t, childQ1.isInPreemptionCooldown(), "victim queue should be in cooldown after preemption")
+	assert.Assert(t, !childQ2.isInPreemptionCooldown(), "preemptor queue should not be in cooldown")
+
+	// back to back preemption from the same queue is suppressed
+	ask4 := newAllocationAsk("alloc4", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "pods": 1}))
+	assert.NilError(t, app2.AddAllocationAsk(ask4))
+	childQ2.incPendingResource(ask4.GetAllocatedResource())
+	preemptor = NewPreemptor(app2, headRoom, 30*time.Second, ask4, iterator(), false)
+	result, ok = preemptor.TryPreemption()
+	assert.Assert(t, !ok && result == nil, "preemption should have been suppressed during cooldown")
+	assert.Check(t, !alloc1.IsPreempted(), "alloc1 preempted during cooldown")
+
+	// preemption resumes after the cooldown
+	childQ1.cooldownEnd = time.Now().Add(-time.Second)
+	preemptor = NewPreemptor(app2, headRoom, 30*time.Second, ask4, iterator(), false)
+	result, ok = preemptor.TryPreemption()
+	assert.Assert(t, ok && result != nil, "preemption should have resumed after cooldown")
+	assert.NilError(t, plugin.GetPredicateError())
+	assert.Check(t, alloc1.IsPreempted(), "alloc1 not preempted after cooldown")
+}
+
+func TestPreemptionCooldownDisabled(t *testing.T) {
+	rootQ, err := createRootQueue(nil)
+	assert.NilError(t, err)
+	leaf, err := createManagedQueue(rootQ, "leaf", false, nil)
+	assert.NilError(t, err)
+	leaf.startPreemptionCooldown()
+	assert.Assert(t, !leaf.isInPreemptionCooldown(), "queue should not be in cooldown without a partition cooldown")
+	rootQ.SetPreemptionCooldown(time.Minute)
+	assert.Equal(t, leaf.GetPreemptionCooldown(), time.Minute, "cooldown should be read from the root")
+	leaf.startPreemptionCooldown()
+	assert.Assert(t, leaf.isInPreemptionCooldown(), "queue should be in cooldown")
+}
+
+func TestTryPreemptionRotateUsers(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 15, "pods": 5})
+	iterator := getNodeIteratorFn(node)
+	rootQ, err := createRootQueue(map[string]string{"first": "30", "pods": "5"})
+	assert.NilError(t, err)
+	parentQ, err := createManagedQueueGuaranteed(rootQ, "parent", true, map[string]string{"first": "30"}, map[string]string{"first": "10"})
+	assert.NilError(t, err)
+	childQ1, err := createManagedQueueGuaranteed(parentQ, "child1", false, map[string]string{"first": "15"}, nil)
+	assert.NilError(t, err)
+	childQ2, err := createManagedQueueGuaranteed(parentQ, "child2", false, map[string]string{"first": "10"}, map[string]string{"first": "5"})
+	assert.NilError(t, err)
+
+	// user1 owns the newest and the second newest allocation, user2 the oldest
+	res := map[string]resources.Quantity{"first": 5, "pods": 1}
+	addVictim := func(app *Application, allocKey string, age time.Duration) *Allocation {
+		alloc := newAllocationWithKey(allocKey, app.ApplicationID, nodeID1, resources.NewResourceFromMap(res))
+		alloc.createTime = time.Now().Add(-age)
+		app.AddAllocation(alloc)
+		assert.Assert(t, node.TryAddAllocation(alloc), "node alloc %s failed", allocKey)
+		return alloc
+	}
+	app1 := newApplicationWithUserGroup(appID1, "default", "root.parent.child1", "user1", []string{})
+	app1.SetQueue(childQ1)
+	childQ1.applications[appID1] = app1
+	app3 := newApplicationWithUserGroup(appID3, "default", "root.parent.child1", "user2", []string{})
+	app3.SetQueue(childQ1)
+	childQ1.applications[appID3] = app3
+	user1New := addVictim(app1, "alloc-user1-new", 0)
+	user1Old := addVictim(app1, "alloc-user1-old", 30*time.Second)
+	user2 := addVictim(app3, "alloc-user2", time.Minute)
+
+	app2, ask3, err := creatApp2(childQ2, res, "alloc3")
+	assert.NilError(t, err)
+	childQ2.incPendingResource(ask3.GetAllocatedResource())
+	preemptions := []mock.Preemption{
+		mock.NewPreemption(true, "alloc3", nodeID1, []string{"alloc-user1-new"}, 0, 0),
+		mock.NewPreemption(true, "alloc4", nodeID1, []string{"alloc-user2"}, 0, 0),
+	}
+	plugin := mock.NewPreemptionPredicatePlugin(nil, nil, preemptions)
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.UnregisterSchedulerPlugins()
+
+	headRoom := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "pods": 3})
+	preemptor := NewPreemptor(app2, headRoom, 30*time.Second, ask3, iterator(), false)
+	result, ok := preemptor.TryPreemption()
+	assert.NilError(t, plugin.GetPredicateError())
+	assert.Assert(t, ok && result != nil, "first preemption should have succeeded")
+	assert.Check(t, user1New.IsPreempted(), "newest allocation not preempted")
+	assert.DeepEqual(t, rootQ.getRecentlyPreemptedUsers(), map[string]bool{"user1": true})
+
+	// user1 was preempted recently: the allocation of user2 is preempted even though it is older
+	ask4 := newAllocationAsk("alloc4", appID2, resources.NewResourceFromMap(res))
+	assert.NilError(t, app2.AddAllocationAsk(ask4))
+	childQ2.incPendingResource(ask4.GetAllocatedResource())
+	preemptor = NewPreemptor(app2, headRoom, 30*time.Second, ask4, iterator(), false)
+	result, ok = preemptor.TryPreemption()
+	assert.NilError(t, plugin.GetPredicateError())
+	assert.Assert(t, ok && result != nil, "second preemption should have succeeded")
+	assert.Check(t, user2.IsPreempted(), "allocation of user2 not preempted")
+	assert.Check(t, !user1Old.IsPreempted(), "user1 preempted twice in a row")
+	assert.DeepEqual(t, childQ1.getRecentlyPreemptedUsers(), map[string]bool{"user1": true, "user2": true})
+
+	// the memory of preempted users expires
+	rootQ.preemptedUsers["user1"] = time.Now().Add(-preemptedUserMemory - time.Second)
+	assert.DeepEqual(t, rootQ.getRecentlyPreemptedUsers(), map[string]bool{"user2": true})
+	_, ok = rootQ.preemptedUsers["user1"]
+	assert.Assert(t, !ok, "expired user should be removed")
+}
+
+func TestFindRecentVictimsRemovedApp(t *testing.T) {
+	rootQ, err := createRootQueue(map[string]string{"first": "20"})
+	assert.NilError(t, err)
+	childQ, err := createManagedQueue(rootQ, "child", false, nil)
+	assert.NilError(t, err)
+	app2, ask, err := creatApp2(childQ, map[string]resources.Quantity{"first": 5}, "alloc3")
+	assert.NilError(t, err)
+	rootQ.recordUserPreempted("user1")
+
+	// the application of the victim is no longer in any queue
+	victim := newAllocationWithKey("alloc1", appID1, nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	preemptor := NewPreemptor(app2, nil, 30*time.Second, ask, nil, false)
+	preemptor.allocationsByQueue = map[string]*QueuePreemptionSnapshot{
+		"root.child": {PotentialVictims: []*Allocation{victim}},
+	}
+	assert.Equal(t, len(preemptor.findRecentVictims()), 0, "victim of a removed application should not be recent")
+}
+
+func TestSortVictimsRecentlyPreempted(t *testing.T) {
+	oldest := newAllocationWithKey("oldest", appID1, nodeID1, nil)
+	oldest.createTime = time.Now().Add(-time.Minute)
+	newest := newAllocationWithKey("newest", appID1, nodeID1, nil)
+	newest.createTime = time.Now()
+	allocationsByNode := map[string][]*Allocation{nodeID1: {oldest, newest}}
+	sortVictimsForPreemption(allocationsByNode, nil)
+	assert.DeepEqual(t, []string{allocationsByNode[nodeID1][0].allocationKey, allocationsByNode[nodeID1][1].allocationKey}, []string{"newest", "oldest"})
+	assert.Assert(t, compareAllocationLess(newest, oldest, nil), "newest allocation should be preferred")
+
+	recent := map[string]bool{"newest": true}
+	sortVictimsForPreemption(allocationsByNode, recent)
+	assert.DeepEqual(t, []string{allocationsByNode[nodeID1][0].allocationKey, allocationsByNode[nodeID1][1].allocationKey}, []string{"oldest", "newest"})
+	assert.Assert(t, compareAllocationLess(oldest, newest, recent), "allocation of a recently preempted user should be last")
+}
+
 func TestTryPreemption_SendEvent(t *testing.T) {
 	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10, "pods": 5})
 	iterator := getNodeIteratorFn(node)
@@ -396,6 +620,62 @@ func TestTryPreemptionOnNode(t *testing.T) {
 	assert.Equal(t, len(ask3.GetAllocationLog()), 0)
 }
 
+// TestTryPreemption_ContendedResourceType Test try preemption selects only the victims holding the contended resource type.
+// Setup:
+// Node1 has capacity first: 10, gpu: 2. The node has space left for the first resource but all gpu is in use.
+// root.parent.child1. No guarantees. 3 Allocations are running: alloc1 uses first: 2, gpu: 2, alloc2 and alloc3 are newer and only use first: 2.
+// root.parent.child2. Guaranteed set, gpu: 2. Request of first: 2, gpu: 2 is waiting for resources.
+// Only alloc1 holds gpu: alloc2 and alloc3 would be considered first based on age but must be left untouched.
+func TestTryPreemption_ContendedResourceType(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10, "gpu": 2})
+	iterator := getNodeIteratorFn(node)
+	rootQ, err := createRootQueue(map[string]string{"first": "10", "gpu": "2"})
+	assert.NilError(t, err)
+	parentQ, err := createManagedQueueGuaranteed(rootQ, "parent", true, nil, nil)
+	assert.NilError(t, err)
+	childQ1, err := createManagedQueueGuaranteed(parentQ, "child1", false, nil, nil)
+	assert.NilError(t, err)
+	childQ2, err := createManagedQueueGuaranteed(parentQ, "child2", false, nil, map[string]string{"gpu": "2"})
+	assert.NilError(t, err)
+
+	app1 := newApplication(appID1, "default", "root.parent.child1")
+	app1.SetQueue(childQ1)
+	childQ1.applications[appID1] = app1
+	allocs := make([]*Allocation, 0, 3)
+	for i, res := range []map[string]resources.Quantity{{"first": 2, "gpu": 2}, {"first": 2}, {"first": 2}} {
+		alloc := newAllocationWithKey(fmt.Sprintf("alloc%d", i+1), appID1, nodeID1, resources.NewResourceFromMap(res))
+		alloc.createTime = time.Now().Add(time.Duration(i-3) * time.Minute)
+		app1.AddAllocation(alloc)
+		assert.Assert(t, node.TryAddAllocation(alloc), "node alloc%d failed", i+1)
+		allocs = append(allocs, alloc)
+	}
+	assert.NilError(t, childQ1.TryIncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 6, "gpu": 2})))
+
+	app2, ask4, err := creatApp2(childQ2, map[string]resources.Quantity{"first": 2, "gpu": 2}, "alloc4")
+	assert.NilError(t, err)
+	childQ2.incPendingResource(ask4.GetAllocatedResource())
+
+	headRoom := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 4, "gpu": 2})
+	preemptor := NewPreemptor(app2, headRoom, 30*time.Second, ask4, iterator(), false)
+
+	// register predicate handler: only the gpu holding allocation should be offered as a victim
+	preemptions := []mock.Preemption{
+		mock.NewPreemption(true, "alloc4", nodeID1, []string{"alloc1"}, 0, 0),
+	}
+	plugin := mock.NewPreemptionPredicatePlugin(nil, nil, preemptions)
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.UnregisterSchedulerPlugins()
+
+	result, ok := preemptor.TryPreemption()
+	assert.Assert(t, result != nil, "no result")
+	assert.NilError(t, plugin.GetPredicateError())
+	assert.Assert(t, ok, "no victims found")
+	assert.Equal(t, "alloc4", result.Request.GetAllocationKey(), "wrong alloc")
+	assert.Check(t, allocs[0].IsPreempted(), "alloc1 not preempted")
+	assert.Check(t, !allocs[1].IsPreempted(), "alloc2 preempted")
+	assert.Check(t, !allocs[2].IsPreempted(), "alloc3 preempted")
+}
+
 // TestTryPreemption_NodeWithCapacityLesserThanAsk Test try preemption on node whose capacity is lesser than ask resource requirements with simple queue hierarchy. Since Node won't accommodate the ask even after preempting all allocations, there is no use in considering the node.
 // Guaranteed and Max resource set on both victim queue path and preemptor queue path in 2 levels. victim and preemptor queue are siblings.
 // Request (Preemptor) resource type matches with all resource types of the victim. But Guaranteed set only on specific resource type. 2 Victims are available, but 1 should be preempted because further preemption would make usage go below the guaranteed quota
@@ -22,6 +22,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -54,22 +58,50 @@ type Queue struct {
 	Name      string // Queue name as in the config etc.
 
 	// Private fields need protection
-	sortType            policies.SortPolicy       // How applications (leaf) or queues (parents) are sorted
-	children            map[string]*Queue         // Only for direct children, parent queue only
-	childPriorities     map[string]int32          // cached priorities for child queues
-	applications        map[string]*Application   // only for leaf queue
-	appPriorities       map[string]int32          // cached priorities for application
-	reservedApps        map[string]int            // applications reserved within this queue, with reservation count
-	parent              *Queue                    // link back to the parent in the scheduler
-	pending             *resources.Resource       // pending resource for the apps in the queue
-	allocatedResource   *resources.Resource       // allocated resource for the apps in the queue
-	preemptingResource  *resources.Resource       // preempting resource for the apps in the queue
-	prioritySortEnabled bool                      // whether priority is used for request sorting
-	priorityPolicy      policies.PriorityPolicy   // priority policy
-	priorityOffset      int32                     // priority offset for this queue relative to others
-	preemptionPolicy    policies.PreemptionPolicy // preemption policy
-	preemptionDelay     time.Duration             // time before preemption is considered
-	currentPriority     int32                     // the current scheduling priority of this queue
+	sortType            policies.SortPolicy           // How applications (leaf) or queues (parents) are sorted
+	children            map[string]*Queue             // Only for direct children, parent queue only
+	childPriorities     map[string]int32              // cached priorities for child queues
+	applications        map[string]*Application       // only for leaf queue
+	appPriorities       map[string]int32              // cached priorities for application
+	reservedApps        map[string]int                // applications reserved within this queue, with reservation count
+	parent              *Queue                        // link back to the parent in the scheduler
+	pending             *resources.Resource           // pending resource for the apps in the queue
+	allocatedResource   *resources.Resource           // allocated resource for the apps in the queue
+	preemptingResource  *resources.Resource           // preempting resource for the apps in the queue
+	quotaResource       *resources.Resource           // usage always allowed, nil if no quota is set
+	burstResource       *resources.Resource           // usage allowed above the quota while the burst pool has tokens
+	prioritySortEnabled bool                          // whether priority is used for request sorting
+	tieBreakPolicy      policies.TieBreakPolicy       // how applications that sort equal are ordered
+	priorityPolicy      policies.PriorityPolicy       // priority policy
+	priorityOffset      int32                         // priority offset for this queue relative to others
+	priorityBoost       int32                         // temporary priority offset used instead of priorityOffset
+	boostExpiry         time.Time                     // time the priority boost expires, zero if no boost is set
+	preemptionPolicy    policies.PreemptionPolicy     // preemption policy
+	preemptionDelay     time.Duration                 // time before preemption is considered
+	aclEnforcement      policies.ACLEnforcementPolicy // what happens when a submit ACL check fails
+	comparator          resources.ResourceComparator  // how usage is compared when sorting on fairness
+	nodeSelection       policies.NodeSelectionPolicy  // how nodes are ordered when allocating in this queue
+	nodeRankResource    string                        // resource type used to rank nodes when allocating in this queue
+	askSizePolicy       policies.AskSizePolicy        // what happens when an ask is larger than the queue maximum
+	groupChargePolicy   policies.GroupChargePolicy    // which groups of a user are charged against the per group maximum
+	preemptable         bool                          // whether allocations in this queue can be preemption victims
+	enabled             bool                          // whether the queue takes part in scheduling
+	info                configs.QueueInfo             // descriptive metadata from the config, not used for scheduling
+	tags                map[string]string             // tags from the config for grouping and placement rule targeting
+	preemptionCooldown  time.Duration                 // root queue only: time no victims are selected from a queue after preemption
+	preemptionQuota     *resources.Resource           // root queue only: maximum resource preempted within the quota window
+	quotaWindow         time.Duration                 // root queue only: time preempted resource counts against the quota
+	quotaPreempted      []preemptionRecord            // root queue only: preemptions within the quota window, oldest first
+	schedulingMode      policies.SchedulingModePolicy // root queue only: capacity mode does not allow borrowing above guaranteed
+	nodeSortingPolicy   NodeSortingPolicy             // root queue only: node sorting policy of the partition, nil if not set
+	fairShareResource   *resources.Resource           // root queue only: fair share base if it differs from the maximum, nil otherwise
+	admissionHook       AdmissionHook                 // root queue only: consulted before an allocation is committed
+	aclDenials          *aclDenialCache               // root queue only: recent submit access denials, set on create
+	burstPool           *burstPool                    // root queue only: tokens shared by the queues for usage above quota
+	cooldownEnd         time.Time                     // no preemption victims are selected from this queue before this time
+	preemptedUsers      map[string]time.Time          // root queue only: last time allocations of a user were preempted
+	placementGeneration uint64                        // root queue only: changes when node resources become available
+	currentPriority     int32                         // the current scheduling priority of this queue
 
 	// The queue properties should be treated as immutable the value is a merge of the
 	// parent properties with the config for this queue only manipulated during creation
@@ -77,10 +109,16 @@ type Queue struct {
 	properties             map[string]string
 	adminACL               security.ACL        // admin ACL
 	submitACL              security.ACL        // submit ACL
+	explicitSubmitACL      bool                // submit ACL set in the config of the queue
+	defaultSubmitACL       security.ACL        // root queue only: submit ACL for queues without an explicit submit ACL
 	maxResource            *resources.Resource // When not set, max = nil
 	guaranteedResource     *resources.Resource // When not set, Guaranteed == 0
+	guaranteedFixed        *resources.Resource // guaranteed set as an absolute value in the config
+	guaranteedPercent      map[string]float64  // guaranteed set as a percentage of the parent in the config by type
+	maxGroupResource       *resources.Resource // maximum each group can use in the queue, when not set groups are not limited
 	isLeaf                 bool                // this is a leaf queue or not (i.e. parent)
 	isManaged              bool                // queue is part of the config, not auto created
+	promoted               bool                // parent queue changed into a leaf queue when an application was placed
 	stateMachine           *fsm.FSM            // the state of the queue for scheduling
 	stateTime              time.Time           // last time the state was updated (needed for cleanup)
 	maxRunningApps         uint64
@@ -88,10 +126,30 @@ type Queue struct {
 	allocatingAcceptedApps map[string]bool
 	template               *template.Template
 	queueEvents            *schedEvt.QueueEvents
+	completedApps          map[string]completedApp        // terminated applications kept until the retention passes, only for leaf queue
+	completedRetention     time.Duration                  // time a terminated application is kept, zero means not kept
+	nodeAffinityWindow     time.Duration                  // time a node used by an application is preferred, zero means no affinity
+	attemptBudget          uint64                         // applications evaluated per scheduling cycle, zero means unlimited
+	attemptResume          string                         // application the next cycle starts at after the budget ran out, budget only
+	logSample              uint64                         // one in this many allocations is logged, zero or one logs all
+	floorInterval          uint64                         // sorts of the parent with the queue first at least once, zero means no floor
+	floorPassed            uint64                         // sorts of the parent since the queue was last considered first
+	weight                 float64                        // scheduling weight of the queue, zero means the baseline weight of 1
+	weightHalfLife         time.Duration                  // over share time in which the weight halves toward 1, zero means no decay
+	overShareSince         time.Time                      // start of the current over share period, zero if not over its share
+	groupAllocated         map[string]*resources.Resource // allocated resource per group, charged to all groups of the user
+	primaryAllocated       map[string]*resources.Resource // allocated resource per group, charged to the primary group only
+	userAllocated          map[string]*resources.Resource // allocated resource per user
 
 	locking.RWMutex
 }
 
+// completedApp is a terminated application retained in the queue with the time it was removed.
+type completedApp struct {
+	app           *Application
+	completedTime time.Time
+}
+
 // newBlankQueue creates a new empty queue objects with all values initialised.
 func newBlankQueue() *Queue {
 	return &Queue{
@@ -110,6 +168,10 @@ func newBlankQueue() *Queue {
 		prioritySortEnabled:    true,
 		preemptionDelay:        configs.DefaultPreemptionDelay,
 		preemptionPolicy:       policies.DefaultPreemptionPolicy,
+		preemptable:            true,
+		enabled:                true,
+		comparator:             resources.DefaultComparator(),
+		completedApps:          make(map[string]completedApp),
 	}
 }
 
@@ -124,12 +186,15 @@ func NewConfiguredQueue(conf configs.QueueConfig, parent *Queue, silence bool) (
 		sq.QueuePath = parent.QueuePath + configs.DOT + sq.Name
 	}
 	sq.parent = parent
+	if parent == nil {
+		sq.aclDenials = newACLDenialCache()
+	}
 	sq.isManaged = true
 	sq.maxRunningApps = conf.MaxApplications
 	sq.updateMaxRunningAppsMetrics()
 
 	// update the properties
-	if err := sq.applyConf(conf, silence); err != nil {
+	if err := sq.applyConf(conf, sq.getParentPercentageBase(), silence); err != nil {
 		return nil, errors.Join(errors.New("configured queue creation failed: "), err)
 	}
 
@@ -273,6 +338,78 @@ func preemptionDelay(value string) (time.Duration, error) {
 	return result, nil
 }
 
+func completedAppRetention(value string) (time.Duration, error) {
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if int64(result) < int64(0) {
+		return 0, fmt.Errorf("%s must not be negative: %s", configs.CompletedAppRetention, value)
+	}
+	return result, nil
+}
+
+func nodeAffinityWindow(value string) (time.Duration, error) {
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if int64(result) < int64(0) {
+		return 0, fmt.Errorf("%s must not be negative: %s", configs.NodeAffinityWindow, value)
+	}
+	return result, nil
+}
+
+// attemptBudget converts the application attempt budget property: the number of applications evaluated per cycle.
+// The budget does not change the application sort order. Only the cycle after the budget ran out starts at the first
+// application that was not evaluated, all other cycles start at the head of the sorted applications.
+func attemptBudget(value string) (uint64, error) {
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a non negative integer: %s", configs.AppAttemptBudget, value)
+	}
+	return result, nil
+}
+
+// allocationLogSample converts the allocation log sample property: one in that many allocations is logged.
+func allocationLogSample(value string) (uint64, error) {
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || result == 0 {
+		return 0, fmt.Errorf("%s must be a positive integer: %s", configs.AllocationLogSample, value)
+	}
+	return result, nil
+}
+
+// schedulingWeight converts the scheduling weight property.
+func schedulingWeight(value string) (float64, error) {
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil || result <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number: %s", configs.SchedulingWeight, value)
+	}
+	return result, nil
+}
+
+func weightHalfLife(value string) (time.Duration, error) {
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if int64(result) < int64(0) {
+		return 0, fmt.Errorf("%s must not be negative: %s", configs.WeightHalfLife, value)
+	}
+	return result, nil
+}
+
+// shareFloorInterval converts the fair share floor, the fraction of sorts in which the queue must be considered first,
+// into the number of sorts in which the queue must be first at least once.
+func shareFloorInterval(value string) (uint64, error) {
+	floor, err := strconv.ParseFloat(value, 64)
+	if err != nil || floor <= 0 || floor > 1 {
+		return 0, fmt.Errorf("%s must be a number larger than 0 and at most 1: %s", configs.FairShareFloor, value)
+	}
+	return uint64(math.Ceil(1 / floor)), nil
+}
+
 func priorityOffset(value string) (int32, error) {
 	intValue, err := strconv.ParseInt(value, 10, 32)
 	if err != nil {
@@ -312,15 +449,19 @@ func filterParentProperty(key string, value string) string {
 
 // ApplyConf is the locked version of applyConf
 func (sq *Queue) ApplyConf(conf configs.QueueConfig) error {
+	defer sq.updateChildGuaranteed()
+	// read the parent before locking the queue: locks are taken parent before child
+	base := sq.getParentPercentageBase()
 	sq.Lock()
 	defer sq.Unlock()
-	return sq.applyConf(conf, false)
+	return sq.applyConf(conf, base, false)
 }
 
 // applyConf applies all the properties to the queue from the config.
+// The base is used to resolve the guaranteed percentages, see getParentPercentageBase.
 // lock free call, must be called holding the queue lock or during create only.
 // If the silence flag is set to true, the function will not log when setting users and groups.
-func (sq *Queue) applyConf(conf configs.QueueConfig, silence bool) error {
+func (sq *Queue) applyConf(conf configs.QueueConfig, base *resources.Resource, silence bool) error {
 	// Set the ACLs
 	var err error
 	sq.submitACL, err = security.NewACL(conf.SubmitACL, silence)
@@ -329,12 +470,15 @@ func (sq *Queue) applyConf(conf configs.QueueConfig, silence bool) error {
 			zap.Error(err))
 		return err
 	}
+	sq.explicitSubmitACL = conf.SubmitACL != ""
 	sq.adminACL, err = security.NewACL(conf.AdminACL, silence)
 	if err != nil {
 		log.Log(log.SchedQueue).Error("parsing admin ACL failed this should not happen",
 			zap.Error(err))
 		return err
 	}
+	// the ACLs could have changed: access checks of this queue and its children must be evaluated again
+	sq.getACLDenials().clear()
 	// Change from unmanaged to managed
 	if !sq.isManaged {
 		log.Log(log.SchedQueue).Info("changed dynamic queue to managed",
@@ -350,12 +494,27 @@ func (sq *Queue) applyConf(conf configs.QueueConfig, silence bool) error {
 				zap.String("queue", sq.QueuePath))
 		}
 	}
+	sq.preemptable = conf.Preemptable == nil || *conf.Preemptable
+	sq.info = conf.Info
+	sq.tags = make(map[string]string, len(conf.Tags))
+	for k, v := range conf.Tags {
+		sq.tags[k] = v
+	}
+
 	prevLeaf := sq.isLeaf
 	sq.isLeaf = !conf.Parent
 	// Make sure the parent flag is set correctly: config might expect auto parent type creation
 	if len(conf.Queues) > 0 {
 		sq.isLeaf = false
 	}
+	// a parent queue promoted to a leaf queue stays a leaf while it runs applications
+	if sq.promoted && !sq.isLeaf && len(conf.Queues) == 0 && len(sq.applications) > 0 {
+		log.Log(log.SchedQueue).Info("promoted queue with applications kept as leaf queue",
+			zap.String("queue", sq.QueuePath))
+		sq.isLeaf = true
+	} else {
+		sq.promoted = false
+	}
 
 	if prevLeaf != sq.isLeaf && sq.queueEvents != nil {
 		sq.queueEvents.SendTypeChangedEvent(sq.QueuePath, sq.isLeaf)
@@ -369,11 +528,21 @@ func (sq *Queue) applyConf(conf configs.QueueConfig, silence bool) error {
 
 	// Load the max & guaranteed resources and maxApps for all but the root queue
 	if sq.Name != configs.RootQueue {
-		if err = sq.setResourcesFromConf(conf.Resources); err != nil {
+		if err = sq.setResourcesFromConf(conf.Resources, base); err != nil {
 			return err
 		}
 		sq.maxRunningApps = conf.MaxApplications
 		sq.updateMaxRunningAppsMetrics()
+		sq.enabled = conf.Enabled == nil || *conf.Enabled
+	}
+	if sq.maxGroupResource, err = resources.NewResourceFromConf(conf.MaxResourcesPerGroup); err != nil {
+		log.Log(log.SchedQueue).Error("parsing failed on max resources per group this should not happen",
+			zap.String("queue", sq.QueuePath),
+			zap.Error(err))
+		return err
+	}
+	if len(sq.maxGroupResource.Resources) == 0 {
+		sq.maxGroupResource = nil
 	}
 
 	sq.properties = conf.Properties
@@ -381,7 +550,8 @@ func (sq *Queue) applyConf(conf configs.QueueConfig, silence bool) error {
 }
 
 // setResourcesFromConf sets the maxResource and guaranteedResource of the queue from the config.
-func (sq *Queue) setResourcesFromConf(resource configs.Resources) error {
+// The guaranteed percentages are resolved against the base.
+func (sq *Queue) setResourcesFromConf(resource configs.Resources, base *resources.Resource) error {
 	maxResource, err := resources.NewResourceFromConf(resource.Max)
 	if err != nil {
 		log.Log(log.SchedQueue).Error("parsing failed on max resources this should not happen",
@@ -390,18 +560,53 @@ func (sq *Queue) setResourcesFromConf(resource configs.Resources) error {
 		return err
 	}
 
-	var guaranteedResource *resources.Resource
-	guaranteedResource, err = resources.NewResourceFromConf(resource.Guaranteed)
+	sq.guaranteedFixed, sq.guaranteedPercent, err = resources.NewResourceFromConfWithPercentage(resource.Guaranteed)
 	if err != nil {
 		log.Log(log.SchedQueue).Error("parsing failed on guaranteed resources this should not happen",
 			zap.String("queue", sq.QueuePath),
 			zap.Error(err))
 		return err
 	}
-	sq.setResources(guaranteedResource, maxResource)
+	sq.setResources(sq.resolveGuaranteed(base, maxResource), maxResource)
+
+	var quotaResource, burstResource *resources.Resource
+	quotaResource, err = resources.NewResourceFromConf(resource.Quota)
+	if err != nil {
+		log.Log(log.SchedQueue).Error("parsing failed on quota resources this should not happen",
+			zap.String("queue", sq.QueuePath),
+			zap.Error(err))
+		return err
+	}
+	burstResource, err = resources.NewResourceFromConf(resource.Burst)
+	if err != nil {
+		log.Log(log.SchedQueue).Error("parsing failed on burst resources this should not happen",
+			zap.String("queue", sq.QueuePath),
+			zap.Error(err))
+		return err
+	}
+	sq.setQuota(quotaResource, burstResource)
 	return nil
 }
 
+// setQuota sets the quota and burst of the queue. An empty quota removes the quota and the burst.
+// lock free call, must be called holding the queue lock or during create only.
+func (sq *Queue) setQuota(quotaResource, burstResource *resources.Resource) {
+	if resources.IsZero(quotaResource) {
+		sq.quotaResource = nil
+		sq.burstResource = nil
+		return
+	}
+	log.Log(log.SchedQueue).Debug("setting quota and burst resources",
+		zap.String("queue", sq.QueuePath),
+		zap.Stringer("quota", quotaResource),
+		zap.Stringer("burst", burstResource))
+	sq.quotaResource = quotaResource
+	sq.burstResource = burstResource
+}
+
+// setResources replaces the guaranteed and max resources of the queue. The allocated resources are not changed: the
+// usage of a type that is no longer part of the max is still tracked, the type is just no longer limited.
+// lock free call, must be called holding the queue lock or during create only.
 func (sq *Queue) setResources(guaranteedResource, maxResource *resources.Resource) {
 	switch {
 	case resources.StrictlyGreaterThanZero(maxResource):
@@ -457,11 +662,90 @@ func (sq *Queue) setResources(guaranteedResource, maxResource *resources.Resourc
 }
 
 func (sq *Queue) SetResources(guaranteedResource, maxResource *resources.Resource) {
+	defer sq.updateChildGuaranteed()
 	sq.Lock()
 	defer sq.Unlock()
 	sq.setResources(guaranteedResource, maxResource)
 }
 
+// resolveGuaranteed returns the guaranteed resource of the queue with the percentages from the config resolved
+// against the base of the parent. A percentage is taken of the guaranteed resource of the parent, or if the parent
+// has no guaranteed resource for the type, of the maximum resource of the parent. A type not set on the parent is
+// not set. A resolved value is capped at the maximum resource of the queue.
+// lock free call, must be called holding the queue lock or during create only.
+func (sq *Queue) resolveGuaranteed(base, maxResource *resources.Resource) *resources.Resource {
+	guaranteed := sq.guaranteedFixed.Clone()
+	if len(sq.guaranteedPercent) == 0 || base == nil {
+		return guaranteed
+	}
+	if guaranteed == nil {
+		guaranteed = resources.NewResource()
+	}
+	for name, value := range resources.ResolvePercentage(sq.guaranteedPercent, base).Resources {
+		if maxResource != nil {
+			if limit, ok := maxResource.Resources[name]; ok && value > limit {
+				value = limit
+			}
+		}
+		guaranteed.Resources[name] = value
+	}
+	return guaranteed
+}
+
+// getGuaranteedPercentageBase returns the resource the guaranteed percentages of the child queues are resolved
+// against: the guaranteed resource of the queue, completed with the maximum resource for types that are not
+// guaranteed.
+func (sq *Queue) getGuaranteedPercentageBase() *resources.Resource {
+	if sq == nil {
+		return nil
+	}
+	base := resources.NewResource()
+	if maxResource := sq.GetMaxResource(); maxResource != nil {
+		base = maxResource.Clone()
+	}
+	if guaranteed := sq.GetGuaranteedResource(); guaranteed != nil {
+		for name, value := range guaranteed.Resources {
+			base.Resources[name] = value
+		}
+	}
+	return base
+}
+
+// updateChildGuaranteed resolves the guaranteed percentages of the child queues again after a change of the
+// resources of this queue. Changes are passed down the hierarchy.
+// Must be called without holding the queue lock.
+func (sq *Queue) updateChildGuaranteed() {
+	for _, child := range sq.GetCopyOfChildren() {
+		if child.reresolveGuaranteed() {
+			child.updateChildGuaranteed()
+		}
+	}
+}
+
+// getParentPercentageBase returns the base the guaranteed percentages of this queue are resolved against, nil for
+// the root queue.
+// Must be called without holding the queue lock: locks are taken parent before child.
+func (sq *Queue) getParentPercentageBase() *resources.Resource {
+	return sq.parent.getGuaranteedPercentageBase()
+}
+
+// reresolveGuaranteed resolves the guaranteed percentages of the queue again and returns true if the guaranteed
+// resource of the queue changed.
+func (sq *Queue) reresolveGuaranteed() bool {
+	base := sq.getParentPercentageBase()
+	sq.Lock()
+	defer sq.Unlock()
+	if len(sq.guaranteedPercent) == 0 {
+		return false
+	}
+	guaranteed := sq.resolveGuaranteed(base, sq.maxResource)
+	if resources.Equals(sq.guaranteedResource, guaranteed) {
+		return false
+	}
+	sq.setResources(guaranteed, sq.maxResource)
+	return true
+}
+
 // SetMaxRunningApps allows setting the maximum running apps on a queue
 func (sq *Queue) SetMaxRunningApps(maxApps uint64) {
 	if sq == nil {
@@ -519,6 +803,12 @@ func (sq *Queue) UpdateQueueProperties() {
 				log.Log(log.SchedQueue).Debug("queue application sort priority configuration error",
 					zap.Error(err))
 			}
+		case configs.ApplicationSortTieBreak:
+			sq.tieBreakPolicy, err = policies.TieBreakPolicyFromString(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("queue application sort tie break configuration error",
+					zap.Error(err))
+			}
 		case configs.PriorityOffset:
 			sq.priorityOffset, err = priorityOffset(value)
 			if err != nil {
@@ -537,6 +827,86 @@ func (sq *Queue) UpdateQueueProperties() {
 				log.Log(log.SchedQueue).Debug("queue preemption policy configuration error",
 					zap.Error(err))
 			}
+		case configs.ACLEnforcement:
+			sq.aclEnforcement, err = policies.ACLEnforcementPolicyFromString(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("queue acl enforcement configuration error",
+					zap.Error(err))
+			}
+		case configs.ResourceComparator:
+			sq.comparator, err = resources.GetComparator(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("queue resource comparator configuration error",
+					zap.Error(err))
+			}
+		case configs.NodeSelectionPolicy:
+			sq.nodeSelection, err = policies.NodeSelectionPolicyFromString(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("queue node selection policy configuration error",
+					zap.Error(err))
+			}
+		case configs.NodeSelectionResource:
+			sq.nodeRankResource = strings.TrimSpace(value)
+		case configs.AskSizeEnforcement:
+			sq.askSizePolicy, err = policies.AskSizePolicyFromString(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("queue ask size enforcement configuration error",
+					zap.Error(err))
+			}
+		case configs.GroupLimitCharge:
+			sq.groupChargePolicy, err = policies.GroupChargePolicyFromString(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("queue group limit charge configuration error",
+					zap.Error(err))
+			}
+		case configs.CompletedAppRetention:
+			if sq.isLeaf {
+				sq.completedRetention, err = completedAppRetention(value)
+				if err != nil {
+					log.Log(log.SchedQueue).Debug("completed application retention property configuration error",
+						zap.Error(err))
+				}
+			}
+		case configs.NodeAffinityWindow:
+			if sq.isLeaf {
+				sq.nodeAffinityWindow, err = nodeAffinityWindow(value)
+				if err != nil {
+					log.Log(log.SchedQueue).Debug("node affinity window property configuration error",
+						zap.Error(err))
+				}
+			}
+		case configs.AppAttemptBudget:
+			if sq.isLeaf {
+				sq.attemptBudget, err = attemptBudget(value)
+				if err != nil {
+					log.Log(log.SchedQueue).Debug("application attempt budget property configuration error",
+						zap.Error(err))
+				}
+			}
+		case configs.AllocationLogSample:
+			sq.logSample, err = allocationLogSample(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("allocation log sample property configuration error",
+					zap.Error(err))
+			}
+		case configs.SchedulingWeight:
+			sq.weight, err = schedulingWeight(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("scheduling weight property configuration error",
+					zap.Error(err))
+			}
+		case configs.WeightHalfLife:
+			sq.weightHalfLife, err = weightHalfLife(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("scheduling weight half life property configuration error",
+					zap.Error(err))
+			}
+		case configs.FairShareFloor:
+			sq.floorInterval, err = shareFloorInterval(value)
+			if err != nil {
+				log.Log(log.SchedQueue).Debug("fair share floor property configuration error",
+					zap.Error(err))
+			}
 		case configs.PreemptionDelay:
 			if sq.isLeaf {
 				sq.preemptionDelay, err = preemptionDelay(value)
@@ -590,7 +960,7 @@ func (sq *Queue) handleQueueEvent(event ObjectEvent) error {
 	err := sq.stateMachine.Event(context.Background(), event.String(), sq.QueuePath)
 	// err is nil the state transition was done
 	if err == nil {
-		sq.stateTime = time.Now()
+		sq.stateTime = getClock().Now()
 		return nil
 	}
 	// handle the same state transition not nil error (limit of fsm).
@@ -607,6 +977,64 @@ func (sq *Queue) GetAllocatedResource() *resources.Resource {
 	return sq.allocatedResource.Clone()
 }
 
+// DominantShare returns the dominant resource share of the queue: the largest fraction of the capacity allocated for
+// any of the resource types. Resource types that are allocated but not part of the capacity are ignored. A resource
+// type with a zero capacity counts as fully used if it is allocated. Returns 0 if the capacity is nil.
+func (sq *Queue) DominantShare(capacity *resources.Resource) float64 {
+	sq.RLock()
+	defer sq.RUnlock()
+	if capacity == nil || sq.allocatedResource == nil {
+		return 0
+	}
+	var dominant float64
+	for name, allocated := range sq.allocatedResource.Resources {
+		capVal, ok := capacity.Resources[name]
+		if !ok || allocated == 0 {
+			continue
+		}
+		share := float64(1)
+		if capVal != 0 {
+			share = float64(allocated) / float64(capVal)
+		}
+		if share > dominant {
+			dominant = share
+		}
+	}
+	return dominant
+}
+
+// TimeToFull projects how long it takes the queue to reach its maximum at the allocation rate of a recent sample: the
+// resources allocated during the sample interval. The projection is made per resource type of the maximum and the
+// soonest is returned. A resource type that is already at or over the maximum is full now. The second return value is
+// false if the queue never gets full: no maximum, no sample interval, or no positive rate for any limited type.
+func (sq *Queue) TimeToFull(sample *resources.Resource, interval time.Duration) (time.Duration, bool) {
+	if sample == nil || interval <= 0 {
+		return 0, false
+	}
+	maxResource := sq.GetMaxResource()
+	if maxResource == nil {
+		return 0, false
+	}
+	allocated := sq.GetAllocatedResource()
+	var soonest time.Duration
+	full := false
+	for name, limit := range maxResource.Resources {
+		rate := sample.Resources[name]
+		if rate <= 0 {
+			continue
+		}
+		var duration time.Duration
+		if remaining := limit - allocated.Resources[name]; remaining > 0 {
+			duration = time.Duration(float64(remaining) / float64(rate) * float64(interval))
+		}
+		if !full || duration < soonest {
+			soonest = duration
+			full = true
+		}
+	}
+	return soonest, full
+}
+
 // GetPreemptingResource returns a clone of the preempting resources for this queue.
 func (sq *Queue) GetPreemptingResource() *resources.Resource {
 	sq.RLock()
@@ -621,6 +1049,107 @@ func (sq *Queue) GetGuaranteedResource() *resources.Resource {
 	return sq.guaranteedResource
 }
 
+// GetQuotaResource returns the quota of the queue, nil if no quota is set.
+func (sq *Queue) GetQuotaResource() *resources.Resource {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.quotaResource
+}
+
+// GetBurstResource returns the burst of the queue, nil if no burst is set.
+func (sq *Queue) GetBurstResource() *resources.Resource {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.burstResource
+}
+
+// getBurstDemand returns the part of the allocation above the quota of this queue. The second return value is false
+// if the allocation puts the queue over its burst: a resource type without a burst cannot go over its quota.
+// Resource types without a quota never have a demand.
+func (sq *Queue) getBurstDemand(alloc *resources.Resource) (*resources.Resource, bool) {
+	sq.RLock()
+	defer sq.RUnlock()
+	if sq.quotaResource == nil || alloc == nil {
+		return nil, true
+	}
+	demand := resources.NewResource()
+	for name, quota := range sq.quotaResource.Resources {
+		requested := alloc.Resources[name]
+		if requested <= 0 {
+			continue
+		}
+		var allocated resources.Quantity
+		if sq.allocatedResource != nil {
+			allocated = sq.allocatedResource.Resources[name]
+		}
+		used := allocated + requested
+		if used <= quota {
+			continue
+		}
+		limit := quota
+		if sq.burstResource != nil {
+			if burst, ok := sq.burstResource.Resources[name]; ok {
+				limit = burst
+			}
+		}
+		if used > limit {
+			return nil, false
+		}
+		demand.Resources[name] = used - max(allocated, quota)
+	}
+	return demand, true
+}
+
+// GetBurstDemand returns the burst pool tokens the allocation needs in this queue and all its parents. The second
+// return value is false if the allocation puts a queue in the hierarchy over its burst.
+func (sq *Queue) GetBurstDemand(alloc *resources.Resource) (*resources.Resource, bool) {
+	total := resources.NewResource()
+	for queue := sq; queue != nil; queue = queue.parent {
+		demand, ok := queue.getBurstDemand(alloc)
+		if !ok {
+			return nil, false
+		}
+		total.AddTo(demand)
+	}
+	return total, true
+}
+
+// SetBurstPool replaces the burst pool of the partition with a full pool of the capacity. The pool is stored on the
+// root queue and shared by all queues. An unchanged pool keeps its tokens. An empty capacity removes the pool: queues
+// cannot use resources above quota.
+func (sq *Queue) SetBurstPool(capacity *resources.Resource, refill time.Duration) {
+	sq.Lock()
+	defer sq.Unlock()
+	if resources.IsZero(capacity) {
+		sq.burstPool = nil
+		return
+	}
+	if sq.burstPool != nil && resources.Equals(sq.burstPool.capacity, capacity) && sq.burstPool.refill == refill {
+		return
+	}
+	sq.burstPool = newBurstPool(capacity, refill)
+}
+
+// getBurstPool returns the burst pool set on the root queue.
+func (sq *Queue) getBurstPool() *burstPool {
+	if sq == nil {
+		return nil
+	}
+	if sq.parent != nil {
+		return sq.parent.getBurstPool()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.burstPool
+}
+
+// checkBurst returns true if the allocation fits in the quota and burst of the queues in the hierarchy and the burst
+// pool has the tokens for the part of the allocation above quota.
+func (sq *Queue) checkBurst(alloc *resources.Resource) bool {
+	demand, ok := sq.GetBurstDemand(alloc)
+	return ok && sq.getBurstPool().hasTokens(demand)
+}
+
 // GetMaxApps returns the maximum number of applications that can run in this queue.
 func (sq *Queue) GetMaxApps() uint64 {
 	sq.RLock()
@@ -648,53 +1177,526 @@ func (sq *Queue) GetPreemptionDelay() time.Duration {
 // CheckSubmitAccess checks if the user has access to the queue to submit an application.
 // The check is performed recursively: i.e. access to the parent allows access to this queue.
 // This will check both submitACL and adminACL.
+// If the queue runs in ACL audit mode a denied check is logged, an audit event is sent and access is allowed.
 func (sq *Queue) CheckSubmitAccess(user security.UserGroup) bool {
 	if common.IsRecoveryQueue(sq.QueuePath) {
 		// recovery queue can never pass ACL checks
 		return false
 	}
-	sq.RLock()
-	allow := sq.submitACL.CheckAccess(user) || sq.adminACL.CheckAccess(user)
-	sq.RUnlock()
-	if !allow && sq.parent != nil {
-		allow = sq.parent.CheckSubmitAccess(user)
+	// a recent denial short circuits the ACL checks of the hierarchy
+	denials := sq.getACLDenials()
+	denied := denials.isDenied(sq.QueuePath, user)
+	allow := !denied && sq.checkSubmitAccess(user)
+	if !allow && !denied {
+		denials.add(sq.QueuePath, user)
+	}
+	if !allow && sq.getACLEnforcement() == policies.AuditACLPolicy {
+		log.Log(log.SchedQueue).Warn("submit access denied, allowed by acl audit mode",
+			zap.String("queueName", sq.QueuePath),
+			zap.String("user", user.User),
+			zap.Strings("groups", user.Groups))
+		if sq.queueEvents != nil {
+			sq.queueEvents.SendACLAuditEvent(sq.QueuePath, user.User)
+		}
+		return true
 	}
 	return allow
 }
 
-// CheckAdminAccess checks if the user has access to the queue to perform administrative actions.
-// The check is performed recursively: i.e. access to the parent allows access to this queue.
-func (sq *Queue) CheckAdminAccess(user security.UserGroup) bool {
+// checkSubmitAccess performs the recursive submit access check without taking the ACL enforcement into account.
+func (sq *Queue) checkSubmitAccess(user security.UserGroup) bool {
 	sq.RLock()
-	allow := sq.adminACL.CheckAccess(user)
+	allow := sq.submitACL.CheckAccess(user) || sq.adminACL.CheckAccess(user)
+	explicit := sq.explicitSubmitACL
 	sq.RUnlock()
+	// a queue without a submit ACL in its config uses the partition default before checking the parent
+	if !allow && !explicit {
+		defaultACL := sq.getDefaultSubmitACL()
+		allow = defaultACL.CheckAccess(user)
+	}
 	if !allow && sq.parent != nil {
-		allow = sq.parent.CheckAdminAccess(user)
+		allow = sq.parent.checkSubmitAccess(user)
 	}
 	return allow
 }
 
-// GetPartitionQueueDAOInfo returns the queue hierarchy as an object for a REST call.
-// Include is false, which means that returns the specified queue object, but does not return the children of the specified queue.
-func (sq *Queue) GetPartitionQueueDAOInfo(include bool) dao.PartitionQueueDAOInfo {
-	queueInfo := dao.PartitionQueueDAOInfo{}
+// GetSubmittableQueues returns the paths of the leaf queues, at or below this queue, the user can submit applications
+// to. Draining queues and the recovery queue are skipped. The hierarchy is walked depth first with the children of a
+// queue in name order. At most limit paths are returned, a limit of zero or less returns all paths.
+// This is a strict check: the ACL audit mode is not taken into account.
+func (sq *Queue) GetSubmittableQueues(user security.UserGroup, limit int) []string {
+	var paths []string
+	sq.collectSubmittableQueues(user, limit, &paths)
+	return paths
+}
+
+// collectSubmittableQueues adds the submittable leaf queues of the hierarchy to the paths until the limit is reached.
+// Returns false if the limit has been reached and the walk must stop.
+func (sq *Queue) collectSubmittableQueues(user security.UserGroup, limit int, paths *[]string) bool {
+	if common.IsRecoveryQueue(sq.QueuePath) || sq.IsDraining() {
+		return true
+	}
+	if sq.IsLeafQueue() {
+		if sq.checkSubmitAccess(user) {
+			*paths = append(*paths, sq.QueuePath)
+		}
+		return limit <= 0 || len(*paths) < limit
+	}
 	children := sq.GetCopyOfChildren()
-	if include {
-		queueInfo.Children = make([]dao.PartitionQueueDAOInfo, 0, len(children))
-		for _, child := range children {
-			queueInfo.Children = append(queueInfo.Children, child.GetPartitionQueueDAOInfo(true))
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !children[name].collectSubmittableQueues(user, limit, paths) {
+			return false
 		}
 	}
-	// we have held the read lock so following method should not take lock again.
-	queueInfo.HeadRoom = sq.getHeadRoom().DAOMap()
-	sq.RLock()
-	defer sq.RUnlock()
+	return true
+}
 
-	for _, child := range children {
-		queueInfo.ChildNames = append(queueInfo.ChildNames, child.QueuePath)
+// GetEffectiveSubmitACL returns the submit ACL after inheritance: the submit and admin ACL of the queue, the partition
+// default if the queue has no submit ACL in its config, and the effective submit ACL of the parent.
+// A user allowed by the returned ACL passes the submit access check. The returned ACL is a merged copy.
+func (sq *Queue) GetEffectiveSubmitACL() security.ACL {
+	sq.RLock()
+	acl := sq.submitACL.Merge(sq.adminACL)
+	explicit := sq.explicitSubmitACL
+	sq.RUnlock()
+	if !explicit {
+		acl = acl.Merge(sq.getDefaultSubmitACL())
 	}
-	queueInfo.QueueName = sq.QueuePath
-	queueInfo.Status = sq.stateMachine.Current()
+	if sq.parent != nil {
+		acl = acl.Merge(sq.parent.GetEffectiveSubmitACL())
+	}
+	return acl
+}
+
+// IsPreemptable returns true if the allocations in the queue can be selected as preemption victims.
+// A queue is not preemptable if the queue itself or any of its ancestors is configured as not preemptable.
+func (sq *Queue) IsPreemptable() bool {
+	if !sq.isPreemptable() {
+		return false
+	}
+	if sq.parent != nil {
+		return sq.parent.IsPreemptable()
+	}
+	return true
+}
+
+// SetDefaultSubmitACL sets the submit ACL used by all queues in the partition that do not have a submit ACL set
+// in their config. The partition default is set on the root queue. The default is checked before the ACL of the parent.
+func (sq *Queue) SetDefaultSubmitACL(acl security.ACL) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.defaultSubmitACL = acl
+	sq.aclDenials.clear()
+}
+
+// getACLDenials returns the submit access denial cache of the root queue.
+// The cache and the parent links never change after creation: no locks are needed.
+func (sq *Queue) getACLDenials() *aclDenialCache {
+	if sq.parent != nil {
+		return sq.parent.getACLDenials()
+	}
+	return sq.aclDenials
+}
+
+// getDefaultSubmitACL returns the default submit ACL set on the root queue.
+func (sq *Queue) getDefaultSubmitACL() security.ACL {
+	if sq.parent != nil {
+		return sq.parent.getDefaultSubmitACL()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.defaultSubmitACL
+}
+
+// SetPreemptionCooldown sets the time after a preemption round during which no further victims are selected from the
+// queues that were preempted. The partition default is set on the root queue and applies to all queues.
+// A zero cooldown disables the cooldown.
+func (sq *Queue) SetPreemptionCooldown(cooldown time.Duration) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.preemptionCooldown = cooldown
+}
+
+// GetPreemptionCooldown returns the cooldown set on the root queue.
+func (sq *Queue) GetPreemptionCooldown() time.Duration {
+	if sq.parent != nil {
+		return sq.parent.GetPreemptionCooldown()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.preemptionCooldown
+}
+
+// defaultPreemptionQuotaWindow is the preemption quota window used if the partition does not set a window.
+const defaultPreemptionQuotaWindow = time.Minute
+
+// preemptionRecord tracks the resource preempted at a point in time for the preemption quota.
+type preemptionRecord struct {
+	time     time.Time
+	resource *resources.Resource
+}
+
+// SetPreemptionQuota sets the maximum resource preempted within the window. The partition setting is stored on the
+// root queue and applies to all queues. Resource types not in the quota are not limited, a nil or zero quota removes
+// the limit. A window that is not positive is replaced by the default window.
+func (sq *Queue) SetPreemptionQuota(quota *resources.Resource, window time.Duration) {
+	sq.Lock()
+	defer sq.Unlock()
+	if resources.IsZero(quota) {
+		quota = nil
+		sq.quotaPreempted = nil
+	}
+	if window <= 0 {
+		window = defaultPreemptionQuotaWindow
+	}
+	sq.preemptionQuota = quota
+	sq.quotaWindow = window
+}
+
+// GetPreemptionQuota returns the preemption quota set on the root queue.
+func (sq *Queue) GetPreemptionQuota() *resources.Resource {
+	if sq.parent != nil {
+		return sq.parent.GetPreemptionQuota()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.preemptionQuota
+}
+
+// GetPreemptionQuotaWindow returns the time preempted resource counts against the preemption quota.
+func (sq *Queue) GetPreemptionQuotaWindow() time.Duration {
+	if sq.parent != nil {
+		return sq.parent.GetPreemptionQuotaWindow()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.quotaWindow
+}
+
+// usePreemptionQuota records the resource as preempted if the resource preempted within the quota window, including
+// the resource, stays within the preemption quota. The first preemption in a window is always allowed: a request
+// that needs more than the quota would otherwise never trigger preemption.
+// Returns false, without a change, if the preemption must wait until earlier preemptions leave the window.
+func (sq *Queue) usePreemptionQuota(res *resources.Resource) bool {
+	if sq.parent != nil {
+		return sq.parent.usePreemptionQuota(res)
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.preemptionQuota == nil {
+		return true
+	}
+	now := getClock().Now()
+	cutoff := now.Add(-sq.quotaWindow)
+	expired := 0
+	for expired < len(sq.quotaPreempted) && !sq.quotaPreempted[expired].time.After(cutoff) {
+		expired++
+	}
+	sq.quotaPreempted = sq.quotaPreempted[expired:]
+	total := res.Clone()
+	for _, record := range sq.quotaPreempted {
+		total.AddTo(record.resource)
+	}
+	if len(sq.quotaPreempted) != 0 && !sq.preemptionQuota.FitInMaxUndef(total) {
+		return false
+	}
+	sq.quotaPreempted = append(sq.quotaPreempted, preemptionRecord{time: now, resource: res.Clone()})
+	return true
+}
+
+// SetNodeSortingPolicy sets the node sorting policy of the partition. The partition setting is stored on the root queue
+// and is used by queues that override the node selection.
+func (sq *Queue) SetNodeSortingPolicy(policy NodeSortingPolicy) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.nodeSortingPolicy = policy
+}
+
+// getNodeSortingPolicy returns the node sorting policy of the partition set on the root queue.
+func (sq *Queue) getNodeSortingPolicy() NodeSortingPolicy {
+	if sq.parent != nil {
+		return sq.parent.getNodeSortingPolicy()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.nodeSortingPolicy
+}
+
+// SetSchedulingMode sets the scheduling mode of the partition. The partition setting is stored on the root queue and
+// applies to all queues.
+func (sq *Queue) SetSchedulingMode(mode policies.SchedulingModePolicy) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.schedulingMode = mode
+}
+
+// GetSchedulingMode returns the scheduling mode setting of the root queue.
+func (sq *Queue) GetSchedulingMode() policies.SchedulingModePolicy {
+	if sq.parent != nil {
+		return sq.parent.GetSchedulingMode()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.schedulingMode
+}
+
+// SetFairShareResource sets the resource the fair share of the queues is based on. The partition setting is stored on
+// the root queue. A nil resource bases the fair share on the maximum resource of the root queue.
+func (sq *Queue) SetFairShareResource(fairShare *resources.Resource) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.fairShareResource = fairShare.Clone()
+}
+
+// getFairShareResource returns a copy of the fair share resource of the root queue, nil if not set.
+func (sq *Queue) getFairShareResource() *resources.Resource {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.fairShareResource.Clone()
+}
+
+// getUsageLimit returns the resource the queue is limited to. In fair mode this is the maximum resource. In capacity
+// mode a queue cannot borrow: a resource type with a guaranteed quantity is limited to the guaranteed quantity, capped
+// by the maximum. The root queue is always limited by the maximum.
+// NOTE: this is a lock free call. It must only be called holding the queue lock.
+func (sq *Queue) getUsageLimit(capacity bool) *resources.Resource {
+	if !capacity || sq.isRoot() || sq.guaranteedResource == nil || len(sq.guaranteedResource.Resources) == 0 {
+		return sq.maxResource
+	}
+	limit := resources.NewResource()
+	if sq.maxResource != nil {
+		limit = sq.maxResource.Clone()
+	}
+	for k, v := range sq.guaranteedResource.Resources {
+		if maxQuantity, ok := limit.Resources[k]; !ok || v < maxQuantity {
+			limit.Resources[k] = v
+		}
+	}
+	return limit
+}
+
+// getCapacityFairMax returns the fair max resource the fair share of the queue is measured against in capacity mode.
+// A queue cannot borrow: a resource type with a guaranteed quantity is limited to the guaranteed quantity, capped by
+// the fair max resource. The root queue is always measured against the fair max resource.
+func (sq *Queue) getCapacityFairMax(fairMax *resources.Resource) *resources.Resource {
+	sq.RLock()
+	defer sq.RUnlock()
+	if sq.isRoot() || sq.guaranteedResource == nil || len(sq.guaranteedResource.Resources) == 0 {
+		return fairMax
+	}
+	limit := resources.NewResource()
+	if fairMax != nil {
+		limit = fairMax.Clone()
+	}
+	for k, v := range sq.guaranteedResource.Resources {
+		if maxQuantity, ok := limit.Resources[k]; !ok || v < maxQuantity {
+			limit.Resources[k] = v
+		}
+	}
+	return limit
+}
+
+// SetAdmissionHook sets the hook consulted before an allocation is committed in the partition. The hook is stored on
+// the root queue and applies to all queues. A nil hook allows all allocations.
+func (sq *Queue) SetAdmissionHook(hook AdmissionHook) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.admissionHook = hook
+}
+
+// getAdmissionHook returns the admission hook set on the root queue.
+func (sq *Queue) getAdmissionHook() AdmissionHook {
+	if sq == nil {
+		return nil
+	}
+	if sq.parent != nil {
+		return sq.parent.getAdmissionHook()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.admissionHook
+}
+
+// ResetPlacementBackoff ends the placement backoff of all requests in the partition by moving the root queue to a
+// new placement generation. Called when node resources are added, changed or released.
+func (sq *Queue) ResetPlacementBackoff() {
+	if sq.parent != nil {
+		sq.parent.ResetPlacementBackoff()
+		return
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	sq.placementGeneration++
+}
+
+// getPlacementGeneration returns the current placement generation set on the root queue.
+func (sq *Queue) getPlacementGeneration() uint64 {
+	if sq == nil {
+		return 0
+	}
+	if sq.parent != nil {
+		return sq.parent.getPlacementGeneration()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.placementGeneration
+}
+
+// startPreemptionCooldown starts the cooldown for the queue after victims were selected from it.
+func (sq *Queue) startPreemptionCooldown() {
+	cooldown := sq.GetPreemptionCooldown()
+	if cooldown <= 0 {
+		return
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	sq.cooldownEnd = getClock().Now().Add(cooldown)
+}
+
+// isInPreemptionCooldown returns true if the queue was preempted recently and must not provide victims.
+func (sq *Queue) isInPreemptionCooldown() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return getClock().Now().Before(sq.cooldownEnd)
+}
+
+// recordUserPreempted records on the root queue that allocations of the user were preempted now.
+func (sq *Queue) recordUserPreempted(user string) {
+	if sq.parent != nil {
+		sq.parent.recordUserPreempted(user)
+		return
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.preemptedUsers == nil {
+		sq.preemptedUsers = make(map[string]time.Time)
+	}
+	sq.preemptedUsers[user] = getClock().Now()
+}
+
+// getRecentlyPreemptedUsers returns the users that had allocations preempted within the preempted user memory.
+// Users preempted before that are removed from the root queue.
+func (sq *Queue) getRecentlyPreemptedUsers() map[string]bool {
+	if sq.parent != nil {
+		return sq.parent.getRecentlyPreemptedUsers()
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	cutoff := getClock().Now().Add(-preemptedUserMemory)
+	var users map[string]bool
+	for user, preempted := range sq.preemptedUsers {
+		if preempted.Before(cutoff) {
+			delete(sq.preemptedUsers, user)
+			continue
+		}
+		if users == nil {
+			users = make(map[string]bool)
+		}
+		users[user] = true
+	}
+	return users
+}
+
+// IsEnabled returns true if the queue takes part in scheduling. The applications in a disabled queue and its
+// children are not considered for allocation but remain in the queue.
+func (sq *Queue) IsEnabled() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.enabled
+}
+
+func (sq *Queue) isPreemptable() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.preemptable
+}
+
+// getACLEnforcement returns the policy applied when a submit ACL check fails.
+func (sq *Queue) getACLEnforcement() policies.ACLEnforcementPolicy {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.aclEnforcement
+}
+
+// getAskSizePolicy returns the policy applied when an ask is larger than the maximum resource of the queue.
+func (sq *Queue) getAskSizePolicy() policies.AskSizePolicy {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.askSizePolicy
+}
+
+// CheckAskSize checks if the ask can ever fit in the maximum resource set for the queue hierarchy.
+// An error naming the first resource type that does not fit is returned if the ask is larger than the maximum.
+// If the queue runs in the ask size warn mode the oversized ask is logged and no error is returned.
+func (sq *Queue) CheckAskSize(ask *Allocation) error {
+	maxQueue := sq.GetMaxQueueSet()
+	if maxQueue == nil {
+		return nil
+	}
+	res := ask.GetAllocatedResource()
+	names := make([]string, 0, len(res.Resources))
+	for name := range res.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		limit, ok := maxQueue.Resources[name]
+		if !ok || res.Resources[name] <= limit {
+			continue
+		}
+		if sq.getAskSizePolicy() == policies.WarnAskSizePolicy {
+			log.Log(log.SchedQueue).Warn("ask larger than max queue allocation, allowed by ask size warn mode",
+				zap.String("queueName", sq.QueuePath),
+				zap.String("allocationKey", ask.GetAllocationKey()),
+				zap.String("resource", name),
+				zap.Int64("requested", int64(res.Resources[name])),
+				zap.Int64("maximum", int64(limit)))
+			return nil
+		}
+		return fmt.Errorf("queue %s cannot fit request %s: %s requested %d larger than max queue allocation %d",
+			sq.QueuePath, ask.GetAllocationKey(), name, res.Resources[name], limit)
+	}
+	return nil
+}
+
+// CheckAdminAccess checks if the user has access to the queue to perform administrative actions.
+// The check is performed recursively: i.e. access to the parent allows access to this queue.
+func (sq *Queue) CheckAdminAccess(user security.UserGroup) bool {
+	sq.RLock()
+	allow := sq.adminACL.CheckAccess(user)
+	sq.RUnlock()
+	if !allow && sq.parent != nil {
+		allow = sq.parent.CheckAdminAccess(user)
+	}
+	return allow
+}
+
+// GetPartitionQueueDAOInfo returns the queue hierarchy as an object for a REST call.
+// Include is false, which means that returns the specified queue object, but does not return the children of the specified queue.
+func (sq *Queue) GetPartitionQueueDAOInfo(include bool) dao.PartitionQueueDAOInfo {
+	queueInfo := dao.PartitionQueueDAOInfo{}
+	children := sq.GetSortedChildren()
+	if include {
+		queueInfo.Children = make([]dao.PartitionQueueDAOInfo, 0, len(children))
+		for _, child := range children {
+			queueInfo.Children = append(queueInfo.Children, child.GetPartitionQueueDAOInfo(true))
+		}
+	}
+	// we have held the read lock so following method should not take lock again.
+	queueInfo.HeadRoom = sq.getHeadRoom().DAOMap()
+	sq.RLock()
+	defer sq.RUnlock()
+
+	for _, child := range children {
+		queueInfo.ChildNames = append(queueInfo.ChildNames, child.QueuePath)
+	}
+	queueInfo.QueueName = sq.QueuePath
+	queueInfo.Status = sq.stateMachine.Current()
 	queueInfo.PendingResource = sq.pending.DAOMap()
 	queueInfo.MaxResource = sq.maxResource.DAOMap()
 	queueInfo.GuaranteedResource = sq.guaranteedResource.DAOMap()
@@ -712,6 +1714,15 @@ func (sq *Queue) GetPartitionQueueDAOInfo(include bool) dao.PartitionQueueDAOInf
 	queueInfo.PreemptionDelay = sq.preemptionDelay.String()
 	queueInfo.IsPriorityFence = sq.priorityPolicy == policies.FencePriorityPolicy
 	queueInfo.PriorityOffset = sq.priorityOffset
+	queueInfo.Owner = sq.info.Owner
+	queueInfo.Team = sq.info.Team
+	queueInfo.Description = sq.info.Description
+	if len(sq.tags) > 0 {
+		queueInfo.Tags = make(map[string]string, len(sq.tags))
+		for k, v := range sq.tags {
+			queueInfo.Tags[k] = v
+		}
+	}
 	queueInfo.Properties = make(map[string]string)
 	for k, v := range sq.properties {
 		queueInfo.Properties[k] = v
@@ -732,6 +1743,43 @@ func (sq *Queue) GetPartitionQueueDAOInfo(include bool) dao.PartitionQueueDAOInf
 	return queueInfo
 }
 
+// GetInfo returns the descriptive metadata of the queue as set in the config.
+func (sq *Queue) GetInfo() configs.QueueInfo {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.info
+}
+
+// GetTags returns a copy of the tags of the queue as set in the config.
+func (sq *Queue) GetTags() map[string]string {
+	sq.RLock()
+	defer sq.RUnlock()
+	tags := make(map[string]string, len(sq.tags))
+	for k, v := range sq.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// GetTag returns the value of the queue tag, or an empty string if the tag is not set.
+func (sq *Queue) GetTag(key string) string {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.tags[key]
+}
+
+// HasTags returns true if the queue carries all the tags passed in with the same value.
+func (sq *Queue) HasTags(tags map[string]string) bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	for k, v := range tags {
+		if value, ok := sq.tags[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
 // GetPendingResource returns the pending resources for this queue.
 func (sq *Queue) GetPendingResource() *resources.Resource {
 	sq.RLock()
@@ -834,10 +1882,14 @@ func (sq *Queue) RemoveApplication(app *Application) {
 		sq.DecPreemptingResource(preempting)
 	}
 
+	retain := app.IsCompleted() || app.IsFailed()
 	sq.Lock()
 	delete(sq.applications, appID)
 	delete(sq.appPriorities, appID)
 	delete(sq.allocatingAcceptedApps, appID)
+	if retain && sq.completedRetention > 0 {
+		sq.completedApps[appID] = completedApp{app: app, completedTime: getClock().Now()}
+	}
 	priority := sq.recalculatePriority()
 	sq.Unlock()
 	app.appEvents.SendRemoveApplicationEvent(appID)
@@ -868,6 +1920,85 @@ func (sq *Queue) GetCopyOfApps() map[string]*Application {
 	return appsCopy
 }
 
+// GetRecentlyCompletedApps returns the completed and failed applications that are retained in the queue.
+// Applications are only retained if the queue has a completed application retention set. The applications
+// are returned in the order they were removed from the queue, oldest first.
+func (sq *Queue) GetRecentlyCompletedApps() []*Application {
+	sq.RLock()
+	defer sq.RUnlock()
+	retained := make([]completedApp, 0, len(sq.completedApps))
+	for _, completed := range sq.completedApps {
+		retained = append(retained, completed)
+	}
+	sort.SliceStable(retained, func(i, j int) bool {
+		return retained[i].completedTime.Before(retained[j].completedTime)
+	})
+	apps := make([]*Application, len(retained))
+	for i, completed := range retained {
+		apps[i] = completed.app
+	}
+	return apps
+}
+
+// PurgeCompletedApps removes the retained applications that have been terminated for longer than the retention.
+// Returns the number of applications removed from the queue.
+func (sq *Queue) PurgeCompletedApps() int {
+	sq.Lock()
+	defer sq.Unlock()
+	if len(sq.completedApps) == 0 {
+		return 0
+	}
+	cutoff := getClock().Now().Add(-sq.completedRetention)
+	purged := 0
+	for appID, completed := range sq.completedApps {
+		if !completed.completedTime.After(cutoff) {
+			delete(sq.completedApps, appID)
+			purged++
+		}
+	}
+	if purged > 0 {
+		log.Log(log.SchedQueue).Debug("purged completed applications from queue",
+			zap.String("queueName", sq.QueuePath),
+			zap.Int("purged", purged))
+	}
+	return purged
+}
+
+// PromoteToLeaf changes a parent queue into a leaf queue so it can run applications. A parent queue that has child
+// queues cannot be changed, an error is returned.
+// The queue properties are re-applied after the change: the sort policy and other leaf only properties take effect
+// immediately and not on the next configuration reload.
+func (sq *Queue) PromoteToLeaf() error {
+	promoted, err := sq.promoteToLeaf()
+	if err != nil {
+		return err
+	}
+	if promoted {
+		sq.UpdateQueueProperties()
+	}
+	return nil
+}
+
+// promoteToLeaf changes the queue type, returns true if the queue was changed.
+func (sq *Queue) promoteToLeaf() (bool, error) {
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.isLeaf {
+		return false, nil
+	}
+	if len(sq.children) != 0 {
+		return false, fmt.Errorf("queue %s is a parent queue with child queues", sq.QueuePath)
+	}
+	log.Log(log.SchedQueue).Info("parent queue promoted to leaf queue",
+		zap.String("queue", sq.QueuePath))
+	sq.isLeaf = true
+	sq.promoted = true
+	if sq.queueEvents != nil {
+		sq.queueEvents.SendTypeChangedEvent(sq.QueuePath, sq.isLeaf)
+	}
+	return true, nil
+}
+
 // GetCopyOfChildren return a shallow copy of the child queue map.
 // This is used by the partition manager to find all queues to clean however we can not
 // guarantee that there is no new child added while we clean up since there is no overall
@@ -882,6 +2013,21 @@ func (sq *Queue) GetCopyOfChildren() map[string]*Queue {
 	return childCopy
 }
 
+// GetSortedChildren returns the direct children of the queue sorted by name.
+// The order is stable and does not depend on the order in which the children were created.
+func (sq *Queue) GetSortedChildren() []*Queue {
+	sq.RLock()
+	defer sq.RUnlock()
+	children := make([]*Queue, 0, len(sq.children))
+	for _, child := range sq.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name < children[j].Name
+	})
+	return children
+}
+
 // IsEmpty returns true if a queue is empty based on the following definition:
 // A parent queue is empty when it has no children left
 // A leaf queue is empty when there are no applications left
@@ -919,6 +2065,10 @@ func (sq *Queue) addChildQueue(child *Queue) error {
 	if sq.IsDraining() {
 		return fmt.Errorf("cannot add a child queue when queue is marked for deletion: %s", sq.QueuePath)
 	}
+	// concurrent dynamic creation of the same child must not replace the child that was added first
+	if _, ok := sq.children[child.Name]; ok && !child.isManaged {
+		return fmt.Errorf("child queue %s already exists on queue: %s", child.Name, sq.QueuePath)
+	}
 
 	// no need to lock child as it is a new queue which cannot be accessed yet
 	sq.children[child.Name] = child
@@ -1024,6 +2174,17 @@ func (sq *Queue) IsManaged() bool {
 	return sq.isManaged
 }
 
+// PathToRoot returns the queues from the root down to and including this queue.
+// The parent link is set on creation and never changes: no queue lock is held while walking up the hierarchy.
+func (sq *Queue) PathToRoot() []*Queue {
+	var path []*Queue
+	for q := sq; q != nil; q = q.parent {
+		path = append(path, q)
+	}
+	slices.Reverse(path)
+	return path
+}
+
 // test only
 func (sq *Queue) isRoot() bool {
 	return sq.parent == nil
@@ -1037,6 +2198,10 @@ func (sq *Queue) TryIncAllocatedResource(alloc *resources.Resource) error {
 		return fmt.Errorf("allocation (%v) puts queue '%s' over maximum allocation (%v), current usage (%v)",
 			alloc, sq.QueuePath, sq.maxResource, sq.allocatedResource)
 	}
+	// check the update does not overflow: only fails if the resource overflow mode is set to error
+	if err := sq.checkAllocatedOverflow(alloc); err != nil {
+		return err
+	}
 	// check the parent: need to pass before updating
 	if sq.parent != nil {
 		if err := sq.parent.TryIncAllocatedResource(alloc); err != nil {
@@ -1050,95 +2215,505 @@ func (sq *Queue) TryIncAllocatedResource(alloc *resources.Resource) error {
 					zap.Stringer("maxResource", sq.maxResource),
 					zap.Error(err))
 			}
-			return err
+			return err
+		}
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	// all OK update this queue
+	sq.allocatedResource = resources.Add(sq.allocatedResource, alloc)
+	sq.updateAllocatedResourceMetrics()
+	return nil
+}
+
+// checkAllocatedOverflow returns an error if adding the resource to the allocated resource of the queue overflows
+// and the resource overflow mode is set to error. This is the only check of the overflow mode: updates that cannot
+// fail, like IncAllocatedResource, always saturate.
+func (sq *Queue) checkAllocatedOverflow(alloc *resources.Resource) error {
+	sq.RLock()
+	defer sq.RUnlock()
+	if _, err := resources.AddChecked(sq.allocatedResource, alloc); err != nil {
+		return fmt.Errorf("allocation (%v) overflows queue '%s' allocation: %w", alloc, sq.QueuePath, err)
+	}
+	return nil
+}
+
+// IncAllocatedResource increments the allocated resources for this queue (recursively). No queue limits are checked.
+func (sq *Queue) IncAllocatedResource(alloc *resources.Resource) {
+	// fall through if nil
+	if sq == nil {
+		return
+	}
+
+	// update parent
+	sq.parent.IncAllocatedResource(alloc)
+
+	// update this queue
+	sq.Lock()
+	defer sq.Unlock()
+	sq.allocatedResource = resources.Add(sq.allocatedResource, alloc)
+	sq.updateAllocatedResourceMetrics()
+}
+
+// allocatedResFits adds the passed in resource to the allocatedResource of the queue and checks if it still fits in the
+// queues' maximum. If the resource fits it returns true otherwise false.
+// small helper method to access sq.maxResource+sq.allocatedResource and avoid Clone() call
+func (sq *Queue) allocatedResFits(alloc *resources.Resource) bool {
+	capacity := sq.GetSchedulingMode() == policies.CapacitySchedulingModePolicy
+	sq.RLock()
+	defer sq.RUnlock()
+	// on the root we want to reject a new allocation if it asks for resources not registered
+	// so do not use the "undefined" flag, also handles pruned max for root
+	if sq.isRoot() {
+		return sq.maxResource.FitIn(resources.AddOnlyExisting(alloc, sq.allocatedResource))
+	}
+	// any other queue undefined is always good
+	return sq.getUsageLimit(capacity).FitInMaxUndef(resources.AddOnlyExisting(alloc, sq.allocatedResource))
+}
+
+// PreviewAllocation checks if the resource would fit in the queue, and all its parents, based on the max resource
+// and the current allocated resource of each queue. This performs the same checks as TryIncAllocatedResource
+// without changing the queue state.
+// If the resource does not fit false is returned with the limiting resource type of the first queue that blocks,
+// walking up from this queue to the root.
+func (sq *Queue) PreviewAllocation(alloc *resources.Resource) (bool, string) {
+	for queue := sq; queue != nil; queue = queue.parent {
+		if limiting := queue.getLimitingResource(alloc); limiting != "" {
+			return false, limiting
+		}
+	}
+	return true, ""
+}
+
+// getLimitingResource returns the resource type that does not fit in the queue maximum if the resource is added
+// to the current allocated resource. Returns an empty string if the resource fits.
+// The resource types are checked in sorted order to give a stable result.
+func (sq *Queue) getLimitingResource(alloc *resources.Resource) string {
+	if alloc == nil {
+		return ""
+	}
+	capacity := sq.GetSchedulingMode() == policies.CapacitySchedulingModePolicy
+	sq.RLock()
+	defer sq.RUnlock()
+	resTypes := make([]string, 0, len(alloc.Resources))
+	for k := range alloc.Resources {
+		resTypes = append(resTypes, k)
+	}
+	sort.Strings(resTypes)
+	var maxRes, allocated map[string]resources.Quantity
+	if limit := sq.getUsageLimit(capacity); limit != nil {
+		maxRes = limit.Resources
+	}
+	if sq.allocatedResource != nil {
+		allocated = sq.allocatedResource.Resources
+	}
+	for _, k := range resTypes {
+		limit, ok := maxRes[k]
+		// undefined types are not limited, except on the root: it rejects types not registered
+		if !ok && !sq.isRoot() {
+			continue
+		}
+		if alloc.Resources[k]+allocated[k] > max(0, limit) {
+			return k
+		}
+	}
+	return ""
+}
+
+// DecAllocatedResource decrement the allocated resources for this queue (recursively)
+// Guard against going below zero resources.
+func (sq *Queue) DecAllocatedResource(alloc *resources.Resource) error {
+	if sq == nil {
+		return fmt.Errorf("queue is nil")
+	}
+
+	// check this queue: failure stops checks
+	if alloc != nil && !sq.resourceFitsAllocated(alloc) {
+		return fmt.Errorf("released allocation (%v) is larger than '%s' queue allocation (%v)",
+			alloc, sq.QueuePath, sq.allocatedResource)
+	}
+	// check the parent: need to pass before updating
+	if sq.parent != nil {
+		if err := sq.parent.DecAllocatedResource(alloc); err != nil {
+			// only log the warning if we get to the leaf: otherwise we spam the log with the same message
+			// each time we return from a recursive call. Worst case (hierarchy depth-1) times.
+			if sq.isLeaf {
+				log.Log(log.SchedQueue).Warn("released allocation is larger than parent queue allocated resource",
+					zap.String("leafQueue", sq.QueuePath),
+					zap.Stringer("allocationRequest", alloc),
+					zap.Stringer("queueUsage", sq.allocatedResource),
+					zap.Stringer("maxResource", sq.maxResource),
+					zap.Error(err))
+			}
+			return err
+		}
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	// all OK update the queue
+	sq.allocatedResource = resources.Sub(sq.allocatedResource, alloc)
+	// We should update the metrics before pruning the resource.
+	// For example:
+	// If we prune the resource first and the resource become nil after pruning,
+	// the metrics will not be updated with nil resource, this is not expected.
+	sq.updateAllocatedResourceMetrics()
+	sq.allocatedResource.Prune()
+	return nil
+}
+
+// small helper method to access sq.allocatedResource and avoid Clone() call
+func (sq *Queue) resourceFitsAllocated(res *resources.Resource) bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.allocatedResource.FitIn(res)
+}
+
+// ReconcileAllocatedResource recalculates the allocated resource of this queue, and all queues below it, from the
+// live allocations of the applications. A leaf queue is compared with the allocated and placeholder resources of its
+// applications, a parent queue with the sum of its children after they have been reconciled.
+// Any difference is logged, an event is sent and the recorded value is replaced.
+// The applications update the queue while holding their own lock, the sum can thus not be calculated while holding
+// the queue lock. The recorded value is read before the calculation and only replaced if it has not changed since:
+// a concurrent allocation or release skips the correction until the next run.
+// Returns true if a difference was found in any queue of the hierarchy.
+func (sq *Queue) ReconcileAllocatedResource() bool {
+	if sq == nil {
+		return false
+	}
+	drift := false
+	recorded := sq.GetAllocatedResource()
+	actual := resources.NewResource()
+	if sq.IsLeafQueue() {
+		for _, app := range sq.GetCopyOfApps() {
+			actual.AddTo(app.GetAllocatedResource())
+			actual.AddTo(app.GetPlaceholderResource())
+		}
+	} else {
+		for _, child := range sq.GetCopyOfChildren() {
+			if child.ReconcileAllocatedResource() {
+				drift = true
+			}
+			actual.AddTo(child.GetAllocatedResource())
+		}
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	if !resources.Equals(sq.allocatedResource, recorded) {
+		log.Log(log.SchedQueue).Debug("queue allocated resource changed during reconcile, skipping correction",
+			zap.String("queuePath", sq.QueuePath))
+		return drift
+	}
+	if resources.Equals(sq.allocatedResource, actual) {
+		return drift
+	}
+	log.Log(log.SchedQueue).Warn("queue allocated resource drift detected, correcting",
+		zap.String("queuePath", sq.QueuePath),
+		zap.Stringer("recorded", sq.allocatedResource),
+		zap.Stringer("actual", actual))
+	if sq.queueEvents != nil {
+		sq.queueEvents.SendAllocatedDriftEvent(sq.QueuePath, sq.allocatedResource, actual)
+	}
+	sq.replaceAllocatedResource(actual)
+	return true
+}
+
+// CheckMaxResource compares the maximum resource of this queue, and all queues below it, with the passed in capacity.
+// A diagnostic message is returned for every queue that has a maximum set for a resource type that is larger than the
+// capacity, such a maximum can never be reached. The messages are ordered by queue path.
+func (sq *Queue) CheckMaxResource(capacity *resources.Resource) []string {
+	if sq == nil {
+		return nil
+	}
+	var diagnostics []string
+	// the root maximum is set from the partition capacity, use the configured maximum not the effective maximum
+	sq.RLock()
+	maxResource := sq.maxResource.Clone()
+	sq.RUnlock()
+	if maxResource != nil && !sq.isRoot() {
+		var exceeded []string
+		for name, quantity := range maxResource.Resources {
+			if quantity > capacity.Resources[name] {
+				exceeded = append(exceeded, name)
+			}
+		}
+		if len(exceeded) > 0 {
+			sort.Strings(exceeded)
+			diagnostics = append(diagnostics, fmt.Sprintf("queue %s: maximum resource %s exceeds partition capacity %s for %s",
+				sq.GetQueuePath(), maxResource, capacity, strings.Join(exceeded, ", ")))
+		}
+	}
+	children := sq.GetCopyOfChildren()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		diagnostics = append(diagnostics, children[name].CheckMaxResource(capacity)...)
+	}
+	return diagnostics
+}
+
+// GetGuaranteedShareCandidates returns the queues, this queue and all queues below it, that use more than their
+// guaranteed resource (donors) and those that use less (needy). The usage is measured as the dominant share of the
+// guaranteed resource, see DominantShare. Donors are sorted from the largest to the smallest share, needy queues
+// from the smallest to the largest share. Queues with the same share are ordered by queue path. Queues without a
+// guaranteed resource and queues using exactly their guaranteed resource are not returned.
+func (sq *Queue) GetGuaranteedShareCandidates() ([]*Queue, []*Queue) {
+	type queueShare struct {
+		queue *Queue
+		share float64
+	}
+	var donors, needy []queueShare
+	var walk func(queue *Queue)
+	walk = func(queue *Queue) {
+		if guaranteed := queue.GetGuaranteedResource(); !resources.IsZero(guaranteed) {
+			share := queue.DominantShare(guaranteed)
+			switch {
+			case share > 1:
+				donors = append(donors, queueShare{queue, share})
+			case share < 1:
+				needy = append(needy, queueShare{queue, share})
+			}
+		}
+		for _, child := range queue.GetCopyOfChildren() {
+			walk(child)
+		}
+	}
+	walk(sq)
+	sortShares := func(shares []queueShare, descending bool) []*Queue {
+		sort.SliceStable(shares, func(i, j int) bool {
+			if shares[i].share != shares[j].share {
+				return (shares[i].share > shares[j].share) == descending
+			}
+			return shares[i].queue.GetQueuePath() < shares[j].queue.GetQueuePath()
+		})
+		queues := make([]*Queue, len(shares))
+		for i, qs := range shares {
+			queues[i] = qs.queue
+		}
+		return queues
+	}
+	return sortShares(donors, true), sortShares(needy, false)
+}
+
+// CheckFairShareConvergence evaluates this queue and all queues below it and returns true if the share of each queue
+// is within the tolerance of its fair share. The fair share of a queue is its guaranteed resource and the share is
+// the dominant share of the guaranteed resource, see DominantShare. Queues without a guaranteed resource are not
+// evaluated. A queue under its share only counts as deviating if it has pending resources: an idle queue is not
+// starved. A queue over its share only counts as deviating if a queue under its share is starved: borrowing idle
+// capacity is not a deviation. The worst offender is the queue with the largest deviation from its fair share, ties
+// are broken on the queue path, and is returned with the deviation even if it is within the tolerance. The worst
+// offender is nil if no queue deviates from its fair share.
+func (sq *Queue) CheckFairShareConvergence(tolerance float64) (bool, *Queue, float64) {
+	type queueShare struct {
+		queue   *Queue
+		share   float64
+		pending bool
+	}
+	var shares []queueShare
+	starved := false
+	var walk func(queue *Queue)
+	walk = func(queue *Queue) {
+		if guaranteed := queue.GetGuaranteedResource(); !resources.IsZero(guaranteed) {
+			qs := queueShare{
+				queue:   queue,
+				share:   queue.DominantShare(guaranteed),
+				pending: !resources.IsZero(queue.GetPendingResource()),
+			}
+			if qs.share < 1 && qs.pending {
+				starved = true
+			}
+			shares = append(shares, qs)
+		}
+		for _, child := range queue.GetCopyOfChildren() {
+			walk(child)
+		}
+	}
+	walk(sq)
+	var worst *Queue
+	var deviation float64
+	for _, qs := range shares {
+		diff := qs.share - 1
+		switch {
+		case diff < 0 && qs.pending:
+			diff = -diff
+		case diff < 0 || !starved:
+			diff = 0
+		}
+		if diff > deviation || (diff == deviation && diff > 0 && qs.queue.QueuePath < worst.QueuePath) {
+			worst = qs.queue
+			deviation = diff
+		}
+	}
+	return deviation <= tolerance, worst, deviation
+}
+
+// replaceAllocatedResource sets the allocated resource of the queue to the passed in value and updates the metrics.
+// The passed in resource is used directly and not cloned.
+// NOTE: this is a lock free call. It must only be called holding the queue lock.
+func (sq *Queue) replaceAllocatedResource(allocated *resources.Resource) {
+	// reset types that are no longer used before updating the metrics
+	if sq.allocatedResource != nil {
+		for k := range sq.allocatedResource.Resources {
+			if _, ok := allocated.Resources[k]; !ok {
+				allocated.Resources[k] = 0
+			}
 		}
 	}
-	sq.Lock()
-	defer sq.Unlock()
-	// all OK update this queue
-	sq.allocatedResource = resources.Add(sq.allocatedResource, alloc)
+	sq.allocatedResource = allocated
 	sq.updateAllocatedResourceMetrics()
-	return nil
+	sq.allocatedResource.Prune()
 }
 
-// IncAllocatedResource increments the allocated resources for this queue (recursively). No queue limits are checked.
-func (sq *Queue) IncAllocatedResource(alloc *resources.Resource) {
-	// fall through if nil
+// incUserResourceUsage tracks the resource allocated for the user and the groups of the user in this queue
+// (recursively). Usage is always tracked for both the primary group and all groups, the charge policy is only
+// applied on the check.
+func (sq *Queue) incUserResourceUsage(user security.UserGroup, alloc *resources.Resource) {
 	if sq == nil {
 		return
 	}
+	sq.parent.incUserResourceUsage(user, alloc)
+	sq.Lock()
+	defer sq.Unlock()
+	if user.User != "" {
+		sq.userAllocated = addGroupUsage(sq.userAllocated, []string{user.User}, alloc)
+	}
+	if len(user.Groups) == 0 {
+		return
+	}
+	sq.primaryAllocated = addGroupUsage(sq.primaryAllocated, user.Groups[:1], alloc)
+	sq.groupAllocated = addGroupUsage(sq.groupAllocated, user.Groups, alloc)
+}
 
-	// update parent
-	sq.parent.IncAllocatedResource(alloc)
-
-	// update this queue
+// decUserResourceUsage removes the resource allocated for the user and the groups of the user in this queue
+// (recursively).
+func (sq *Queue) decUserResourceUsage(user security.UserGroup, alloc *resources.Resource) {
+	if sq == nil {
+		return
+	}
+	sq.parent.decUserResourceUsage(user, alloc)
 	sq.Lock()
 	defer sq.Unlock()
-	sq.allocatedResource = resources.Add(sq.allocatedResource, alloc)
-	sq.updateAllocatedResourceMetrics()
+	removeGroupUsage(sq.userAllocated, []string{user.User}, alloc)
+	if len(user.Groups) == 0 {
+		return
+	}
+	removeGroupUsage(sq.primaryAllocated, user.Groups[:1], alloc)
+	removeGroupUsage(sq.groupAllocated, user.Groups, alloc)
 }
 
-// allocatedResFits adds the passed in resource to the allocatedResource of the queue and checks if it still fits in the
-// queues' maximum. If the resource fits it returns true otherwise false.
-// small helper method to access sq.maxResource+sq.allocatedResource and avoid Clone() call
-func (sq *Queue) allocatedResFits(alloc *resources.Resource) bool {
+// UserShare is the resource allocated to a user in a queue and the fair share of the queue for that user.
+type UserShare struct {
+	Allocated *resources.Resource
+	FairShare *resources.Resource
+}
+
+// GetUserShares returns the allocated resource and fair share for each user of the queue. The users are the users
+// with resources allocated in the queue and, for a leaf queue, the users of the applications in the queue.
+// The fair maximum of the queue is divided between the users based on the weights: a user without a weight, or with
+// a weight that is not positive, has a weight of 1. Passing no weights divides the queue equally.
+// In capacity mode the fair maximum is limited by the guaranteed resource of the queue.
+func (sq *Queue) GetUserShares(weights map[string]float64) map[string]UserShare {
+	fairMax := sq.GetFairMaxResource()
+	if sq.GetSchedulingMode() == policies.CapacitySchedulingModePolicy {
+		fairMax = sq.getCapacityFairMax(fairMax)
+	}
 	sq.RLock()
 	defer sq.RUnlock()
-	// on the root we want to reject a new allocation if it asks for resources not registered
-	// so do not use the "undefined" flag, also handles pruned max for root
-	if sq.isRoot() {
-		return sq.maxResource.FitIn(resources.AddOnlyExisting(alloc, sq.allocatedResource))
+	users := make(map[string]*resources.Resource, len(sq.userAllocated))
+	for user, allocated := range sq.userAllocated {
+		users[user] = allocated.Clone()
 	}
-	// any other queue undefined is always good
-	return sq.maxResource.FitInMaxUndef(resources.AddOnlyExisting(alloc, sq.allocatedResource))
+	for _, app := range sq.applications {
+		if _, ok := users[app.user.User]; !ok && app.user.User != "" {
+			users[app.user.User] = resources.NewResource()
+		}
+	}
+	total := float64(0)
+	for user := range users {
+		total += userWeight(weights, user)
+	}
+	shares := make(map[string]UserShare, len(users))
+	for user, allocated := range users {
+		shares[user] = UserShare{
+			Allocated: allocated,
+			FairShare: resources.MultiplyBy(fairMax, userWeight(weights, user)/total),
+		}
+	}
+	return shares
 }
 
-// DecAllocatedResource decrement the allocated resources for this queue (recursively)
-// Guard against going below zero resources.
-func (sq *Queue) DecAllocatedResource(alloc *resources.Resource) error {
-	if sq == nil {
-		return fmt.Errorf("queue is nil")
+// userWeight returns the weight of the user used in the fair share calculation, defaults to 1.
+func userWeight(weights map[string]float64, user string) float64 {
+	if weight, ok := weights[user]; ok && weight > 0 {
+		return weight
 	}
+	return 1
+}
 
-	// check this queue: failure stops checks
-	if alloc != nil && !sq.resourceFitsAllocated(alloc) {
-		return fmt.Errorf("released allocation (%v) is larger than '%s' queue allocation (%v)",
-			alloc, sq.QueuePath, sq.allocatedResource)
+// checkGroupLimits checks the resource fits in the per group maximum of this queue and all its parents for the
+// groups of the user charged by the policy of each queue. Returns the first group that does not fit, walking up from
+// this queue to the root, or an empty string if the resource fits.
+func (sq *Queue) checkGroupLimits(user security.UserGroup, alloc *resources.Resource) string {
+	if len(user.Groups) == 0 {
+		return ""
 	}
-	// check the parent: need to pass before updating
-	if sq.parent != nil {
-		if err := sq.parent.DecAllocatedResource(alloc); err != nil {
-			// only log the warning if we get to the leaf: otherwise we spam the log with the same message
-			// each time we return from a recursive call. Worst case (hierarchy depth-1) times.
-			if sq.isLeaf {
-				log.Log(log.SchedQueue).Warn("released allocation is larger than parent queue allocated resource",
-					zap.String("leafQueue", sq.QueuePath),
-					zap.Stringer("allocationRequest", alloc),
-					zap.Stringer("queueUsage", sq.allocatedResource),
-					zap.Stringer("maxResource", sq.maxResource),
-					zap.Error(err))
-			}
-			return err
+	for queue := sq; queue != nil; queue = queue.parent {
+		if group := queue.getGroupOverLimit(user, alloc); group != "" {
+			log.Log(log.SchedQueue).Debug("allocation exceeds the per group maximum of the queue",
+				zap.String("queue", queue.QueuePath),
+				zap.String("group", group),
+				zap.Stringer("allocation", alloc))
+			return group
 		}
 	}
-	sq.Lock()
-	defer sq.Unlock()
-	// all OK update the queue
-	sq.allocatedResource = resources.Sub(sq.allocatedResource, alloc)
-	// We should update the metrics before pruning the resource.
-	// For example:
-	// If we prune the resource first and the resource become nil after pruning,
-	// the metrics will not be updated with nil resource, this is not expected.
-	sq.updateAllocatedResourceMetrics()
-	sq.allocatedResource.Prune()
-	return nil
+	return ""
 }
 
-// small helper method to access sq.allocatedResource and avoid Clone() call
-func (sq *Queue) resourceFitsAllocated(res *resources.Resource) bool {
+// getGroupOverLimit returns the first charged group of the user for which the resource does not fit in the per group
+// maximum of the queue. An empty string is returned if the resource fits or no maximum is set.
+func (sq *Queue) getGroupOverLimit(user security.UserGroup, alloc *resources.Resource) string {
 	sq.RLock()
 	defer sq.RUnlock()
-	return sq.allocatedResource.FitIn(res)
+	if sq.maxGroupResource == nil {
+		return ""
+	}
+	groups, usage := user.Groups[:1], sq.primaryAllocated
+	if sq.groupChargePolicy == policies.AllGroupsChargePolicy {
+		groups, usage = user.Groups, sq.groupAllocated
+	}
+	for _, group := range groups {
+		if !sq.maxGroupResource.FitInMaxUndef(resources.Add(usage[group], alloc)) {
+			return group
+		}
+	}
+	return ""
+}
+
+// addGroupUsage adds the resource to the usage of each group, the map is created if needed.
+func addGroupUsage(usage map[string]*resources.Resource, groups []string, alloc *resources.Resource) map[string]*resources.Resource {
+	if usage == nil {
+		usage = make(map[string]*resources.Resource)
+	}
+	for _, group := range groups {
+		usage[group] = resources.Add(usage[group], alloc)
+	}
+	return usage
+}
+
+// removeGroupUsage removes the resource from the usage of each group, a group without usage is removed.
+func removeGroupUsage(usage map[string]*resources.Resource, groups []string, alloc *resources.Resource) {
+	for _, group := range groups {
+		if current, ok := usage[group]; ok {
+			current = resources.SubEliminateNegative(current, alloc)
+			if resources.IsZero(current) {
+				delete(usage, group)
+			} else {
+				usage[group] = current
+			}
+		}
+	}
 }
 
 // IncPreemptingResource increments the preempting resources for this queue (recursively).
@@ -1176,6 +2751,140 @@ func (sq *Queue) IsPrioritySortEnabled() bool {
 	return sq.prioritySortEnabled
 }
 
+// getTieBreakPolicy returns the policy used to order applications that sort equal.
+func (sq *Queue) getTieBreakPolicy() policies.TieBreakPolicy {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.tieBreakPolicy
+}
+
+func (sq *Queue) getNodeSelectionPolicy() policies.NodeSelectionPolicy {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.nodeSelection
+}
+
+// getNodeRankResource returns the resource type used to rank nodes, empty if nodes are ranked on all types.
+func (sq *Queue) getNodeRankResource() string {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.nodeRankResource
+}
+
+// getNodeAffinityWindow returns the time a node used by an application is preferred for new allocations.
+// Zero means the application has no affinity to the nodes it used.
+func (sq *Queue) getNodeAffinityWindow() time.Duration {
+	if sq == nil {
+		return 0
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.nodeAffinityWindow
+}
+
+// getAttemptBudget returns the number of applications evaluated in this queue per scheduling cycle.
+// Zero means all applications are evaluated.
+func (sq *Queue) getAttemptBudget() uint64 {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.attemptBudget
+}
+
+// getAttemptOffset returns the position in the sorted applications at which the evaluation starts. The evaluation
+// starts at the head of the sorted applications, unless the attempt budget ran out in the previous cycle: the cycle
+// then starts at the first application that was not evaluated and wraps around to the head.
+// The sort order is only changed for the cycle after the budget ran out. If the application is no longer in the list
+// the evaluation starts at the head.
+func (sq *Queue) getAttemptOffset(apps []*Application) int {
+	sq.RLock()
+	defer sq.RUnlock()
+	if sq.attemptResume == "" {
+		return 0
+	}
+	for i, app := range apps {
+		if app.ApplicationID == sq.attemptResume {
+			return i
+		}
+	}
+	return 0
+}
+
+// setAttemptResume sets the application the next cycle starts at, an empty ID starts at the head.
+func (sq *Queue) setAttemptResume(appID string) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.attemptResume = appID
+}
+
+// IsAllocationLogged returns true if the allocation must be logged based on the allocation log sample of the queue.
+// The decision is based on a hash of the allocation key: the same allocation is always logged, or never logged, at
+// each log point.
+func (sq *Queue) IsAllocationLogged(allocationKey string) bool {
+	sq.RLock()
+	sample := sq.logSample
+	sq.RUnlock()
+	if sample <= 1 {
+		return true
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(allocationKey))
+	return uint64(hash.Sum32())%sample == 0
+}
+
+// nodeIterator returns the node iterator function to use for allocations in this queue.
+// The partition iterator is returned unchanged unless the queue overrides the node selection policy, with a policy that
+// differs from the partition policy, or the resource used to rank the nodes. If only the resource is set nodes are
+// ranked on the least leftover of that resource. The nodes are ranked using the resource weights of the partition.
+// The returned function must be used for one allocation cycle only: the nodes are scored and sorted once, on first use.
+func (sq *Queue) nodeIterator(iterator func() NodeIterator) func() NodeIterator {
+	rankResource := sq.getNodeRankResource()
+	partitionPolicy := sq.getNodeSortingPolicy()
+	var weights map[string]float64
+	switch {
+	case rankResource != "":
+		weights = map[string]float64{rankResource: 1.0}
+	case partitionPolicy != nil:
+		weights = partitionPolicy.ResourceWeights()
+	default:
+		weights = defaultResourceWeights()
+	}
+	// the partition sorts the nodes using the same policy: reuse the partition order
+	samePolicy := func(policyType policies.SortingPolicy) bool {
+		return rankResource == "" && partitionPolicy != nil && partitionPolicy.PolicyType() == policyType
+	}
+	var policy NodeSortingPolicy
+	switch sq.getNodeSelectionPolicy() {
+	case policies.BinpackNodeSelectionPolicy:
+		if samePolicy(policies.BinPackingPolicy) {
+			return iterator
+		}
+		policy = binPackingNodeSortingPolicy{resourceWeights: weights}
+	case policies.SpreadNodeSelectionPolicy:
+		if samePolicy(policies.FairnessPolicy) {
+			return iterator
+		}
+		policy = fairnessNodeSortingPolicy{resourceWeights: weights}
+	default:
+		if rankResource == "" {
+			return iterator
+		}
+		policy = binPackingNodeSortingPolicy{resourceWeights: weights}
+	}
+	var sorted NodeIterator
+	return func() NodeIterator {
+		if sorted == nil {
+			sorted = newPolicyIterator(iterator(), policy)
+		}
+		return sorted
+	}
+}
+
+func (sq *Queue) getComparator() resources.ResourceComparator {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.comparator
+}
+
 // sortApplications returns a sorted shallow copy of the applications in the queue.
 // Applications are sorted using the sorting type of the queue.
 // Only applications with a pending resource request are considered.
@@ -1199,37 +2908,129 @@ func (sq *Queue) sortApplications(withPlaceholdersOnly bool) []*Application {
 	}
 
 	// sort applications based on the sorting policy
-	return sortApplications(apps, sq.getSortType(), sq.IsPrioritySortEnabled(), sq.GetGuaranteedResource())
+	return sortApplications(apps, sq.getSortType(), sq.IsPrioritySortEnabled(), sq.GetGuaranteedResource(), sq.getTieBreakPolicy(), sq.getComparator())
 }
 
 // sortQueues returns a sorted shallow copy of the queues for this parent queue.
 // Only queues with a pending resource request are considered. The queues are sorted using the
-// sorting type for the parent queue.
+// sorting type for the parent queue. In capacity mode the queues cannot borrow: the usage of a queue is measured
+// against its guaranteed resource, capped by the fair max resource, and the weights do not apply.
 // Lock free call all locks are taken when needed in called functions
 func (sq *Queue) sortQueues() []*Queue {
 	if sq.IsLeafQueue() {
 		return nil
 	}
+	capacity := sq.GetSchedulingMode() == policies.CapacitySchedulingModePolicy
 	// Create a list of the queues with pending resources
 	sortedQueues := make([]*Queue, 0)
 	sortedMaxFairResources := make([]*resources.Resource, 0)
 	for _, child := range sq.GetCopyOfChildren() {
-		// a stopped queue cannot be scheduled
-		if child.IsStopped() {
+		// a stopped or disabled queue cannot be scheduled
+		if child.IsStopped() || !child.IsEnabled() {
 			continue
 		}
+		// revert the priority of the child before sorting if a boost has expired
+		child.expirePriorityBoost(false)
 		// queue must have pending resources to be considered for scheduling
 		if resources.StrictlyGreaterThanZero(child.GetPendingResource()) {
 			sortedQueues = append(sortedQueues, child)
-			sortedMaxFairResources = append(sortedMaxFairResources, child.GetFairMaxResource())
+			fairMax := child.GetFairMaxResource()
+			if capacity {
+				fairMax = child.getCapacityFairMax(fairMax)
+			} else if weight := child.GetEffectiveWeight(); fairMax != nil && weight != 1 {
+				// a queue with a higher weight is entitled to a larger fair share
+				fairMax = resources.MultiplyBy(fairMax, weight)
+			}
+			sortedMaxFairResources = append(sortedMaxFairResources, fairMax)
 		}
 	}
 	// Sort the queues
-	sortQueue(sortedQueues, sortedMaxFairResources, sq.getSortType(), sq.IsPrioritySortEnabled())
+	sortQueue(sortedQueues, sortedMaxFairResources, sq.getSortType(), sq.IsPrioritySortEnabled(), capacity, sq.getComparator())
+	applyShareFloor(sortedQueues)
 
 	return sortedQueues
 }
 
+// GetEffectiveWeight returns the scheduling weight of the queue after the decay. A queue that uses more than its
+// guaranteed resource, measured as the dominant share, is over its share. The longer a queue is over its share the
+// closer its weight gets to the baseline of 1: the difference halves every half life. The full weight applies again
+// as soon as the queue drops back to its share. The over share period is tracked on each call, using the package
+// clock. Queues without a guaranteed resource are never over their share.
+func (sq *Queue) GetEffectiveWeight() float64 {
+	share := sq.DominantShare(sq.GetGuaranteedResource())
+	now := getClock().Now()
+	sq.Lock()
+	defer sq.Unlock()
+	if share <= 1 {
+		sq.overShareSince = time.Time{}
+	} else if sq.overShareSince.IsZero() {
+		sq.overShareSince = now
+	}
+	var overShare time.Duration
+	if !sq.overShareSince.IsZero() {
+		overShare = now.Sub(sq.overShareSince)
+	}
+	return decayWeight(sq.weight, sq.weightHalfLife, overShare)
+}
+
+// decayWeight decays the weight toward the baseline of 1 based on the time over share. The difference to the
+// baseline halves every half life. A weight that is not positive is the baseline, a half life that is not positive
+// does not decay the weight.
+func decayWeight(weight float64, halfLife time.Duration, overShare time.Duration) float64 {
+	if weight <= 0 {
+		return 1
+	}
+	if halfLife <= 0 || overShare <= 0 {
+		return weight
+	}
+	return 1 + (weight-1)*math.Pow(0.5, float64(overShare)/float64(halfLife))
+}
+
+// applyShareFloor moves a queue that has been passed over for its full floor interval to the front of the sorted
+// queues. A queue with a fair share floor of 0.1 is considered first at least once in every ten sorts in which it has
+// pending resources, independent of its fair share. One queue is moved per sort, others follow in the next sorts.
+func applyShareFloor(sortedQueues []*Queue) {
+	boost := -1
+	for i, child := range sortedQueues {
+		if child.passedOver(i == 0, boost == -1) {
+			boost = i
+		}
+	}
+	if boost > 0 {
+		child := sortedQueues[boost]
+		copy(sortedQueues[1:boost+1], sortedQueues[:boost])
+		sortedQueues[0] = child
+	}
+}
+
+// passedOver tracks the sorts in which the queue was not considered first. Returns true if the queue has reached
+// its floor interval and can be moved to the front, which resets the tracking as if the queue was first.
+func (sq *Queue) passedOver(first bool, canMove bool) bool {
+	sq.Lock()
+	defer sq.Unlock()
+	if first || sq.floorInterval == 0 {
+		sq.floorPassed = 0
+		return false
+	}
+	if canMove && sq.floorPassed+1 >= sq.floorInterval {
+		sq.floorPassed = 0
+		return true
+	}
+	sq.floorPassed++
+	return false
+}
+
+// getPartitionAvailable returns the resources available in the partition: the maximum of the root queue, which is
+// the total node capacity, minus the resources allocated in the partition.
+func (sq *Queue) getPartitionAvailable() *resources.Resource {
+	if sq.parent != nil {
+		return sq.parent.getPartitionAvailable()
+	}
+	sq.RLock()
+	defer sq.RUnlock()
+	return resources.SubEliminateNegative(sq.maxResource, sq.allocatedResource)
+}
+
 // getHeadRoom returns the headroom for the queue. This can never be more than the headroom for the parent.
 // In case there are no nodes in a newly started cluster and no queues have a limit configured this call
 // will return nil.
@@ -1258,9 +3059,10 @@ func (sq *Queue) getMaxHeadRoom() *resources.Resource {
 
 // internalHeadRoom does the real headroom calculation.
 func (sq *Queue) internalHeadRoom(parentHeadRoom *resources.Resource) *resources.Resource {
+	capacity := sq.GetSchedulingMode() == policies.CapacitySchedulingModePolicy
 	sq.RLock()
 	defer sq.RUnlock()
-	headRoom := sq.maxResource
+	headRoom := sq.getUsageLimit(capacity)
 
 	// if we have no max set headroom is always the same as the parent
 	if headRoom == nil {
@@ -1284,8 +3086,9 @@ func (sq *Queue) internalHeadRoom(parentHeadRoom *resources.Resource) *resources
 // based on node registration)
 // In case there are no nodes in a newly started cluster and no queues have a limit configured this call
 // will return nil.
-// NOTE: if a resource quantity is missing and a limit is defined the missing quantity will be seen as a limit of 0.
-// When defining a limit you therefore should define all resource quantities.
+// NOTE: the limit is inherited per resource type. A resource quantity missing from the queue limit is
+// inherited from the closest ancestor that defines it. A quantity defined on the queue overrides the
+// inherited value but can never be larger than it.
 func (sq *Queue) GetMaxResource() *resources.Resource {
 	// get the limit for the parent first and check against the queue's own
 	var limit *resources.Resource
@@ -1303,6 +3106,9 @@ func (sq *Queue) GetMaxResource() *resources.Resource {
 func (sq *Queue) GetFairMaxResource() *resources.Resource {
 	var limit *resources.Resource
 	if sq.parent == nil {
+		if fairShare := sq.getFairShareResource(); fairShare != nil {
+			return fairShare
+		}
 		return sq.GetMaxResource().Clone()
 	}
 
@@ -1331,8 +3137,7 @@ func (sq *Queue) internalGetFairMaxResource(limit *resources.Resource) *resource
 // max resource of the parent. The cluster size, which defines the root limit, is not relevant for this call.
 // Contrary to the GetMaxResource call. This will return nil unless a limit is set.
 // Used during scheduling in an auto-scaling cluster.
-// NOTE: if a resource quantity is missing and a limit is defined the missing quantity will be seen as a limit of 0.
-// When defining a limit you therefore should define all resource quantities.
+// NOTE: the limit is inherited per resource type in the same way as for GetMaxResource.
 func (sq *Queue) GetMaxQueueSet() *resources.Resource {
 	// get the limit for the parent first and check against the queue's own
 	if sq.parent == nil {
@@ -1342,6 +3147,8 @@ func (sq *Queue) GetMaxQueueSet() *resources.Resource {
 }
 
 // internalGetMax does the real max calculation.
+// Each resource type is handled separately: a type only set on the parent limit is inherited, a type only set
+// on this queue is used as is, and a type set on both uses the smallest of the two values.
 func (sq *Queue) internalGetMax(parentLimit *resources.Resource) *resources.Resource {
 	sq.RLock()
 	defer sq.RUnlock()
@@ -1353,13 +3160,14 @@ func (sq *Queue) internalGetMax(parentLimit *resources.Resource) *resources.Reso
 	if sq.maxResource == nil {
 		return parentLimit
 	}
-	// calculate the smallest value for each type
+	// merge per type: ComponentWiseMin keeps types defined on one side only
 	return resources.ComponentWiseMin(parentLimit, sq.maxResource)
 }
 
 // SetMaxResource sets the max resource for the root queue. Called as part of adding or removing a node.
 // Should only happen on the root, all other queues get it from the config via properties.
 func (sq *Queue) SetMaxResource(max *resources.Resource) {
+	defer sq.updateChildGuaranteed()
 	sq.Lock()
 	defer sq.Unlock()
 
@@ -1433,15 +3241,48 @@ func (sq *Queue) TryAllocate(iterator func() NodeIterator, fullIterator func() N
 		headRoom := sq.getHeadRoom()
 		preemptionDelay := sq.GetPreemptionDelay()
 		preemptAttemptsRemaining := maxPreemptionsPerQueue
+		iterator = sq.nodeIterator(iterator)
+		fullIterator = sq.nodeIterator(fullIterator)
+		available := sq.getPartitionAvailable()
+		// the budget is shared by all applications in the queue and reset on each cycle
+		attemptBudget := sq.getAttemptBudget()
+		var attempts uint64
+		exhausted := false
 
 		// process the apps (filters out app without pending requests)
-		for _, app := range sq.sortApplications(false) {
+		// the evaluation starts where the budget ran out in the previous cycle, no application starves
+		apps := sq.sortApplications(false)
+		start := sq.getAttemptOffset(apps)
+		for i := range apps {
+			app := apps[(start+i)%len(apps)]
 			runnableInQueue := sq.canRunApp(app.ApplicationID)
 			runnableByUserLimit := ugm.GetUserManager().CanRunApp(sq.QueuePath, app.ApplicationID, app.user)
 			app.updateRunnableStatus(runnableInQueue, runnableByUserLimit)
 			if app.IsAccepted() && (!runnableInQueue || !runnableByUserLimit) {
 				continue
 			}
+			// a paused application does not get new allocations
+			if app.IsPaused() {
+				continue
+			}
+			// hold the application until all applications it depends on are running
+			if !app.dependenciesSatisfied() {
+				continue
+			}
+			// hold the application until the partition has the capacity for its declared minimum
+			if !app.isReadyToStart(available) {
+				continue
+			}
+			// budget exhausted: yield to the sibling queues for this cycle
+			if attemptBudget > 0 && attempts >= attemptBudget {
+				log.Log(log.SchedQueue).Debug("application attempt budget exhausted",
+					zap.String("queueName", sq.QueuePath),
+					zap.Uint64("budget", attemptBudget))
+				sq.setAttemptResume(app.ApplicationID)
+				exhausted = true
+				break
+			}
+			attempts++
 			result := app.tryAllocate(headRoom, allowPreemption, preemptionDelay, &preemptAttemptsRemaining, iterator, fullIterator, getnode)
 			if result != nil {
 				log.Log(log.SchedQueue).Info("allocation found on queue",
@@ -1454,9 +3295,17 @@ func (sq *Queue) TryAllocate(iterator func() NodeIterator, fullIterator func() N
 				if app.IsAccepted() {
 					sq.setAllocatingAccepted(app.ApplicationID)
 				}
+				// with a budget the next cycle starts at the head of the sorted applications again
+				if attemptBudget > 0 {
+					sq.setAttemptResume("")
+				}
 				return result
 			}
 		}
+		// all applications were evaluated: the next cycle starts at the head of the sorted applications
+		if !exhausted {
+			sq.setAttemptResume("")
+		}
 	} else {
 		// process the child queues (filters out queues without pending requests)
 		for _, child := range sq.sortQueues() {
@@ -1477,8 +3326,12 @@ func (sq *Queue) TryAllocate(iterator func() NodeIterator, fullIterator func() N
 // Lock free call this all locks are taken when needed in called functions
 func (sq *Queue) TryPlaceholderAllocate(iterator func() NodeIterator, getnode func(string) *Node) *AllocationResult {
 	if sq.IsLeafQueue() {
+		iterator = sq.nodeIterator(iterator)
 		// process the apps (filters out app without pending requests)
 		for _, app := range sq.sortApplications(true) {
+			if app.IsPaused() {
+				continue
+			}
 			result := app.tryPlaceholderAllocate(iterator, getnode)
 			if result != nil {
 				log.Log(log.SchedQueue).Info("allocation found on queue",
@@ -1508,6 +3361,10 @@ func (sq *Queue) GetQueueOutstandingRequests(total *[]*Allocation) {
 		// while calculating outstanding requests, we calculate all the requests that can fit into the queue's headroom,
 		// all these requests are qualified to trigger the up scaling.
 		for _, app := range sq.sortApplications(false) {
+			// a paused application should not trigger up scaling
+			if app.IsPaused() {
+				continue
+			}
 			// calculate the users' headroom
 			userHeadroom := ugm.GetUserManager().Headroom(app.queuePath, app.ApplicationID, app.user)
 			app.getOutstandingRequests(headRoom, userHeadroom, total)
@@ -1549,6 +3406,9 @@ func (sq *Queue) TryReservedAllocate(iterator func() NodeIterator) *AllocationRe
 				if app.IsAccepted() && (!sq.canRunApp(appID) || !ugm.GetUserManager().CanRunApp(sq.QueuePath, appID, app.user)) {
 					continue
 				}
+				if app.IsPaused() {
+					continue
+				}
 				result := app.tryReservedAllocate(headRoom, iterator)
 				if result != nil {
 					log.Log(log.SchedQueue).Info("reservation found for allocation found on queue",
@@ -1681,6 +3541,7 @@ func (sq *Queue) updateGuaranteedResourceMetrics() {
 		resourcesToUpdate = sq.guaranteedResource.Resources
 	}
 	queueMetrics.UpdateQueueResourceMetrics(metrics.QueueGuaranteed, resourcesToUpdate)
+	sq.updateFairnessMetrics()
 }
 
 // updateMaxResourceMetrics updates max resource metrics.
@@ -1691,6 +3552,7 @@ func (sq *Queue) updateMaxResourceMetrics() {
 		resourcesToUpdate = sq.maxResource.Resources
 	}
 	queueMetrics.UpdateQueueResourceMetrics(metrics.QueueMax, resourcesToUpdate)
+	sq.updateFairnessMetrics()
 }
 
 // updateAllocatedResourceMetrics updates allocated resource metrics for all queue types.
@@ -1698,6 +3560,36 @@ func (sq *Queue) updateAllocatedResourceMetrics() {
 	for k, v := range sq.allocatedResource.Resources {
 		metrics.GetQueueMetrics(sq.QueuePath).SetQueueAllocatedResourceMetrics(k, float64(v))
 	}
+	sq.updateFairnessMetrics()
+}
+
+// updateFairnessMetrics updates the fair share and deficit metrics for the queue.
+// The fair share follows the fair queue sorting: the guaranteed quantity if set, otherwise the max quantity.
+// The deficit is the part of the fair share that is not allocated, it is never negative.
+// Only resource types that have a fair share are exported.
+func (sq *Queue) updateFairnessMetrics() {
+	fairShare := map[string]resources.Quantity{}
+	if sq.maxResource != nil {
+		for k, v := range sq.maxResource.Resources {
+			fairShare[k] = v
+		}
+	}
+	if sq.guaranteedResource != nil {
+		for k, v := range sq.guaranteedResource.Resources {
+			fairShare[k] = v
+		}
+	}
+	deficit := make(map[string]resources.Quantity, len(fairShare))
+	for k, v := range fairShare {
+		var allocated resources.Quantity
+		if sq.allocatedResource != nil {
+			allocated = sq.allocatedResource.Resources[k]
+		}
+		deficit[k] = max(v-allocated, 0)
+	}
+	queueMetrics := metrics.GetQueueMetrics(sq.QueuePath)
+	queueMetrics.UpdateQueueResourceMetrics(metrics.QueueFairShare, fairShare)
+	queueMetrics.UpdateQueueResourceMetrics(metrics.QueueDeficit, deficit)
 }
 
 // updatePendingResourceMetrics updates pending resource metrics for all queue types.
@@ -1866,11 +3758,19 @@ func (sq *Queue) findEligiblePreemptionVictims(results map[string]*QueuePreempti
 	if sq.GetQueuePath() == queuePath {
 		return
 	}
+	// the walk is top down: skipping a queue that is not preemptable protects the whole subtree
+	if !sq.isPreemptable() {
+		return
+	}
 	if sq.IsLeafQueue() {
 		// leaf queue, skip queue if preemption is disabled
 		if sq.GetPreemptionPolicy() == policies.DisabledPreemptionPolicy {
 			return
 		}
+		// skip queue if victims were selected from it recently
+		if sq.isInPreemptionCooldown() {
+			return
+		}
 
 		victims := sq.createPreemptionSnapshot(results, queuePath)
 
@@ -1971,13 +3871,88 @@ func (sq *Queue) GetCurrentPriority() int32 {
 }
 
 func (sq *Queue) getCurrentPriority() int32 {
-	return priorityValueByPolicy(sq.priorityPolicy, sq.priorityOffset, sq.currentPriority)
+	return priorityValueByPolicy(sq.priorityPolicy, sq.getPriorityOffset(), sq.currentPriority)
 }
 
 func (sq *Queue) GetPriorityPolicyAndOffset() (policies.PriorityPolicy, int32) {
 	sq.RLock()
 	defer sq.RUnlock()
-	return sq.priorityPolicy, sq.priorityOffset
+	return sq.priorityPolicy, sq.getPriorityOffset()
+}
+
+// getPriorityOffset returns the priority offset of the queue: the boost while it has not expired, otherwise the
+// configured offset.
+// Should be called holding the lock.
+func (sq *Queue) getPriorityOffset() int32 {
+	if !sq.boostExpiry.IsZero() && getClock().Now().Before(sq.boostExpiry) {
+		return sq.priorityBoost
+	}
+	return sq.priorityOffset
+}
+
+// SetPriorityBoost temporarily replaces the configured priority offset of the queue with the passed in offset. The
+// boost expires after the duration and the queue reverts to the configured offset. A zero or negative duration
+// removes an active boost.
+func (sq *Queue) SetPriorityBoost(offset int32, duration time.Duration) {
+	if sq == nil {
+		return
+	}
+	if duration <= 0 {
+		sq.expirePriorityBoost(true)
+		return
+	}
+	value := sq.setPriorityBoostInternal(offset, duration)
+	sq.parent.UpdateQueuePriority(sq.Name, value)
+}
+
+func (sq *Queue) setPriorityBoostInternal(offset int32, duration time.Duration) int32 {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.priorityBoost = offset
+	sq.boostExpiry = getClock().Now().Add(duration)
+	log.Log(log.SchedQueue).Info("queue priority boost set",
+		zap.String("queueName", sq.QueuePath),
+		zap.Int32("priorityOffset", offset),
+		zap.Time("expiry", sq.boostExpiry))
+	sq.queueEvents.SendPriorityBoostEvent(sq.QueuePath, offset, sq.boostExpiry)
+	return sq.getCurrentPriority()
+}
+
+// GetPriorityBoost returns the boost offset and expiry time if the queue has an active priority boost.
+func (sq *Queue) GetPriorityBoost() (int32, time.Time, bool) {
+	sq.RLock()
+	defer sq.RUnlock()
+	if sq.boostExpiry.IsZero() || !getClock().Now().Before(sq.boostExpiry) {
+		return 0, time.Time{}, false
+	}
+	return sq.priorityBoost, sq.boostExpiry, true
+}
+
+// expirePriorityBoost removes the priority boost from the queue if it has expired, or unconditionally if force is
+// set. The parent is updated with the reverted priority.
+func (sq *Queue) expirePriorityBoost(force bool) {
+	if sq == nil {
+		return
+	}
+	value, expired := sq.expirePriorityBoostInternal(force)
+	if expired {
+		sq.parent.UpdateQueuePriority(sq.Name, value)
+	}
+}
+
+func (sq *Queue) expirePriorityBoostInternal(force bool) (int32, bool) {
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.boostExpiry.IsZero() || (!force && getClock().Now().Before(sq.boostExpiry)) {
+		return 0, false
+	}
+	sq.priorityBoost = 0
+	sq.boostExpiry = time.Time{}
+	log.Log(log.SchedQueue).Info("queue priority boost expired",
+		zap.String("queueName", sq.QueuePath),
+		zap.Int32("priorityOffset", sq.priorityOffset))
+	sq.queueEvents.SendPriorityBoostExpiredEvent(sq.QueuePath, sq.priorityOffset)
+	return sq.getCurrentPriority(), true
 }
 
 func priorityValueByPolicy(policy policies.PriorityPolicy, offset int32, priority int32) int32 {
@@ -2057,5 +4032,5 @@ func (sq *Queue) recalculatePriority() int32 {
 		curr = max(v, curr)
 	}
 	sq.currentPriority = curr
-	return priorityValueByPolicy(sq.priorityPolicy, sq.priorityOffset, curr)
+	return priorityValueByPolicy(sq.priorityPolicy, sq.getPriorityOffset(), curr)
 }
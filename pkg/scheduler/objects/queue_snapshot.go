@@ -0,0 +1,128 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/log"
+)
+
+// QueueSnapshot is the serialisable accounting state of a queue and all queues below it.
+// Only leaf queues record applications.
+type QueueSnapshot struct {
+	QueuePath    string                        `json:"queuePath"`
+	Leaf         bool                          `json:"leaf,omitempty"`
+	Allocated    map[string]resources.Quantity `json:"allocated,omitempty"`
+	Applications []string                      `json:"applications,omitempty"`
+	Children     []*QueueSnapshot              `json:"children,omitempty"`
+}
+
+// Snapshot returns the accounting state of the queue and all queues below it.
+// Children and applications are sorted to make the snapshot stable.
+func (sq *Queue) Snapshot() *QueueSnapshot {
+	snap := &QueueSnapshot{
+		QueuePath: sq.QueuePath,
+		Leaf:      sq.IsLeafQueue(),
+	}
+	if allocated := sq.GetAllocatedResource(); !resources.IsZero(allocated) {
+		snap.Allocated = allocated.Resources
+	}
+	if snap.Leaf {
+		for appID := range sq.GetCopyOfApps() {
+			snap.Applications = append(snap.Applications, appID)
+		}
+		sort.Strings(snap.Applications)
+		return snap
+	}
+	children := sq.GetCopyOfChildren()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		snap.Children = append(snap.Children, children[name].Snapshot())
+	}
+	return snap
+}
+
+// RestoreSnapshot sets the allocated resources of the queue and all queues below it from the snapshot.
+// The queue hierarchy must already be built from the current configuration. Queues in the snapshot that no longer
+// exist, or that changed between leaf and parent, are skipped. A parent is always set to the sum of its children.
+// Returns the application to queue mapping for the applications in the restored leaf queues.
+func (sq *Queue) RestoreSnapshot(snap *QueueSnapshot) map[string]string {
+	placement := make(map[string]string)
+	if snap == nil || snap.QueuePath != sq.QueuePath {
+		log.Log(log.SchedQueue).Warn("queue snapshot does not match queue, not restored",
+			zap.String("queuePath", sq.QueuePath))
+		return placement
+	}
+	sq.restoreSnapshot(snap, placement)
+	return placement
+}
+
+// restoreSnapshot restores the queue and its children from a snapshot with a matching path.
+func (sq *Queue) restoreSnapshot(snap *QueueSnapshot, placement map[string]string) {
+	if sq.IsLeafQueue() {
+		allocated := resources.NewResource()
+		if snap.Leaf {
+			allocated = resources.NewResourceFromMap(snap.Allocated)
+			for _, appID := range snap.Applications {
+				placement[appID] = sq.QueuePath
+			}
+		} else {
+			log.Log(log.SchedQueue).Warn("queue changed from parent to leaf since snapshot, usage not restored",
+				zap.String("queuePath", sq.QueuePath))
+		}
+		sq.Lock()
+		defer sq.Unlock()
+		sq.replaceAllocatedResource(allocated)
+		return
+	}
+	if snap.Leaf {
+		log.Log(log.SchedQueue).Warn("queue changed from leaf to parent since snapshot, usage not restored",
+			zap.String("queuePath", sq.QueuePath))
+	}
+	children := sq.GetCopyOfChildren()
+	for _, childSnap := range snap.Children {
+		name := childSnap.QueuePath[strings.LastIndex(childSnap.QueuePath, configs.DOT)+1:]
+		child, ok := children[name]
+		if !ok || childSnap.QueuePath != child.QueuePath {
+			log.Log(log.SchedQueue).Warn("queue in snapshot no longer exists, usage not restored",
+				zap.String("queuePath", childSnap.QueuePath),
+				zap.Stringer("allocated", resources.NewResourceFromMap(childSnap.Allocated)))
+			continue
+		}
+		child.restoreSnapshot(childSnap, placement)
+	}
+	// rebuild from the children: also covers children that were not part of the snapshot
+	allocated := resources.NewResource()
+	for _, child := range children {
+		allocated.AddTo(child.GetAllocatedResource())
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	sq.replaceAllocatedResource(allocated)
+}
@@ -0,0 +1,113 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+// createSnapshotQueues creates root.parent.leaf1 and root.leaf2, the children listed are created below root.parent
+func createSnapshotQueues(t *testing.T, children ...string) (*Queue, map[string]*Queue) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	queues := map[string]*Queue{"root": root}
+	var parent *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	queues[parent.QueuePath] = parent
+	for _, name := range children {
+		var leaf *Queue
+		leaf, err = createManagedQueue(parent, name, false, nil)
+		assert.NilError(t, err, "failed to create leaf queue")
+		queues[leaf.QueuePath] = leaf
+	}
+	var leaf2 *Queue
+	leaf2, err = createManagedQueue(root, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	queues[leaf2.QueuePath] = leaf2
+	return root, queues
+}
+
+func TestQueueSnapshotRestore(t *testing.T) {
+	root, queues := createSnapshotQueues(t, "leaf1")
+	res1 := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 1})
+	res2 := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5})
+	for appID, queue := range map[string]*Queue{"app-1": queues["root.parent.leaf1"], "app-2": queues["root.leaf2"]} {
+		app := newApplication(appID, "default", queue.QueuePath)
+		app.SetQueue(queue)
+		queue.AddApplication(app)
+	}
+	queues["root.parent.leaf1"].IncAllocatedResource(res1)
+	queues["root.leaf2"].IncAllocatedResource(res2)
+
+	snap := root.Snapshot()
+	assert.Equal(t, snap.QueuePath, "root")
+	assert.Equal(t, len(snap.Children), 2, "unexpected children in snapshot")
+	assert.Equal(t, snap.Children[0].QueuePath, "root.leaf2", "children should be sorted")
+	assert.DeepEqual(t, snap.Children[0].Applications, []string{"app-2"})
+	assert.Equal(t, snap.Children[1].Children[0].QueuePath, "root.parent.leaf1")
+	assert.Assert(t, snap.Children[1].Children[0].Leaf, "leaf not marked in snapshot")
+	assert.DeepEqual(t, snap.Children[1].Children[0].Allocated, res1.Resources)
+
+	// same structure: all counters restored
+	restored, restoredQueues := createSnapshotQueues(t, "leaf1")
+	placement := restored.RestoreSnapshot(snap)
+	assert.DeepEqual(t, placement, map[string]string{"app-1": "root.parent.leaf1", "app-2": "root.leaf2"})
+	for path, queue := range queues {
+		assert.Assert(t, resources.Equals(restoredQueues[path].GetAllocatedResource(), queue.GetAllocatedResource()), "queue %s not restored", path)
+	}
+
+	// changed config: leaf1 removed and leaf3 added, root.parent is rebuilt from the remaining children
+	changed, changedQueues := createSnapshotQueues(t, "leaf3")
+	changedQueues["root.parent.leaf3"].IncAllocatedResource(res2)
+	placement = changed.RestoreSnapshot(snap)
+	assert.DeepEqual(t, placement, map[string]string{"app-2": "root.leaf2"})
+	assert.Assert(t, resources.Equals(changedQueues["root.parent"].GetAllocatedResource(), res2), "parent not rebuilt from children")
+	assert.Assert(t, resources.Equals(changed.GetAllocatedResource(), resources.Multiply(res2, 2)), "root not rebuilt from children")
+
+	// a snapshot for a different queue is not restored
+	placement = changedQueues["root.leaf2"].RestoreSnapshot(snap)
+	assert.Equal(t, len(placement), 0, "mismatched snapshot should not be restored")
+	placement = changed.RestoreSnapshot(nil)
+	assert.Equal(t, len(placement), 0, "nil snapshot should not be restored")
+}
+
+func TestQueueSnapshotTypeChange(t *testing.T) {
+	root, queues := createSnapshotQueues(t)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	queues["root.leaf2"].IncAllocatedResource(res)
+	snap := root.Snapshot()
+
+	// leaf2 was a leaf, now a parent: usage dropped
+	changed, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	_, err = createManagedQueue(changed, "parent", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	var leaf2 *Queue
+	leaf2, err = createManagedQueue(changed, "leaf2", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	placement := changed.RestoreSnapshot(snap)
+	assert.Equal(t, len(placement), 0, "no applications expected")
+	assert.Assert(t, resources.IsZero(leaf2.GetAllocatedResource()), "type changed queue should not be restored")
+	assert.Assert(t, resources.IsZero(changed.GetAllocatedResource()), "root should be rebuilt from children")
+}
@@ -20,11 +20,13 @@ package objects
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,8 +37,11 @@ import (
 	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/common/security"
 	"github.com/apache/yunikorn-core/pkg/events"
+	"github.com/apache/yunikorn-core/pkg/events/mock"
 	"github.com/apache/yunikorn-core/pkg/metrics"
+	schedEvt "github.com/apache/yunikorn-core/pkg/scheduler/objects/events"
 	"github.com/apache/yunikorn-core/pkg/scheduler/objects/template"
 	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
 	siCommon "github.com/apache/yunikorn-scheduler-interface/lib/go/common"
@@ -270,6 +275,76 @@ func TestPriorityCalc(t *testing.T) {
 	assert.Equal(t, leaf.GetCurrentPriority(), configs.MinPriority, "final leaf priority wrong")
 }
 
+func TestPriorityBoost(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(parent, "leaf", false, nil, map[string]string{
+		configs.PriorityOffset: "3",
+	})
+	assert.NilError(t, err, "failed to create leaf queue")
+	app := newApplication(appID1, "default", "root.parent.leaf")
+	app.SetQueue(leaf)
+	leaf.AddApplication(app)
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create basic resource")
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-1", appID1, res, 0))
+	assert.NilError(t, err, "failed to add ask")
+	assert.Equal(t, leaf.GetCurrentPriority(), int32(3), "leaf priority wrong before boost")
+	assert.Equal(t, parent.GetCurrentPriority(), int32(3), "parent priority wrong before boost")
+	_, _, boosted := leaf.GetPriorityBoost()
+	assert.Assert(t, !boosted, "boost should not be set")
+	eventSystem := mock.NewEventSystem()
+	leaf.queueEvents = schedEvt.NewQueueEvents(eventSystem)
+
+	// boost applies to the leaf and is propagated to the parent
+	leaf.SetPriorityBoost(100, time.Minute)
+	assert.Equal(t, leaf.GetCurrentPriority(), int32(100), "leaf priority wrong after boost")
+	assert.Equal(t, parent.GetCurrentPriority(), int32(100), "parent priority wrong after boost")
+	offset, expiry, boosted := leaf.GetPriorityBoost()
+	assert.Assert(t, boosted, "boost should be set")
+	assert.Equal(t, offset, int32(100), "boost offset wrong")
+	assert.Equal(t, expiry, mockClock.Now().Add(time.Minute), "boost expiry wrong")
+	_, configured := leaf.GetPriorityPolicyAndOffset()
+	assert.Equal(t, configured, int32(100), "offset should be boosted")
+	assert.Equal(t, 1, len(eventSystem.Events), "boost event not sent")
+	assert.Equal(t, si.EventRecord_SET, eventSystem.Events[0].EventChangeType)
+
+	// not expired yet
+	mockClock.Advance(30 * time.Second)
+	assert.Equal(t, len(parent.sortQueues()), 1, "leaf should be sorted")
+	assert.Equal(t, leaf.GetCurrentPriority(), int32(100), "leaf priority wrong before expiry")
+	assert.Equal(t, 1, len(eventSystem.Events), "unexpected event before expiry")
+
+	// expired: leaf reverts directly, the parent on the next sort
+	mockClock.Advance(30 * time.Second)
+	assert.Equal(t, leaf.GetCurrentPriority(), int32(3), "leaf priority wrong after expiry")
+	_, _, boosted = leaf.GetPriorityBoost()
+	assert.Assert(t, !boosted, "boost should have expired")
+	assert.Equal(t, len(parent.sortQueues()), 1, "leaf should be sorted")
+	assert.Equal(t, parent.GetCurrentPriority(), int32(3), "parent priority wrong after expiry")
+	_, configured = leaf.GetPriorityPolicyAndOffset()
+	assert.Equal(t, configured, int32(3), "offset should be reverted")
+	assert.Equal(t, 2, len(eventSystem.Events), "expiry event not sent")
+	assert.Equal(t, si.EventRecord_REMOVE, eventSystem.Events[1].EventChangeType)
+	assert.Equal(t, len(parent.sortQueues()), 1, "leaf should be sorted")
+	assert.Equal(t, 2, len(eventSystem.Events), "expiry event sent twice")
+
+	// a zero duration removes an active boost
+	leaf.SetPriorityBoost(50, time.Hour)
+	assert.Equal(t, parent.GetCurrentPriority(), int32(50), "parent priority wrong after second boost")
+	leaf.SetPriorityBoost(50, 0)
+	assert.Equal(t, leaf.GetCurrentPriority(), int32(3), "leaf priority wrong after boost removal")
+	assert.Equal(t, parent.GetCurrentPriority(), int32(3), "parent priority wrong after boost removal")
+	assert.Equal(t, 4, len(eventSystem.Events), "boost and removal events not sent")
+}
+
 func TestPendingCalc(t *testing.T) {
 	// Reset existing metric storage; otherwise this unit test would get metrics populated by other UTs.
 	// In long run, to make the metrics code more testable, we should pass instantiable Metrics obj to Queue
@@ -348,8 +423,76 @@ yunikorn_root_leaf_queue_resource{resource="apps",state="maxRunningApps"} 0
 	assert.NilError(t, promtu.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), metrics...), "unexpected metrics")
 }
 
+func TestFairnessMetrics(t *testing.T) {
+	metrics.Reset()
+
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var maxRes, guaranteed *resources.Resource
+	maxRes, err = resources.NewResourceFromConf(map[string]string{"memory": "10", "vcores": "10"})
+	assert.NilError(t, err, "failed to create max resource")
+	guaranteed, err = resources.NewResourceFromConf(map[string]string{"memory": "4"})
+	assert.NilError(t, err, "failed to create guaranteed resource")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "fair", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	// config change: guaranteed wins over max for the fair share
+	leaf.SetResources(guaranteed, maxRes)
+	metricNames := []string{"yunikorn_root_fair_queue_resource"}
+	want := concatQueueResourceMetric(metricNames, []string{`
+yunikorn_root_fair_queue_resource{resource="apps",state="maxRunningApps"} 0
+yunikorn_root_fair_queue_resource{resource="memory",state="deficit"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="fairShare"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="guaranteed"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="max"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="deficit"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="fairShare"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="guaranteed"} 0
+yunikorn_root_fair_queue_resource{resource="vcores",state="max"} 10
+`})
+	assert.NilError(t, promtu.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), metricNames...), "unexpected metrics after config change")
+
+	// allocation: deficit shrinks and never goes negative
+	var alloc *resources.Resource
+	alloc, err = resources.NewResourceFromConf(map[string]string{"memory": "6", "vcores": "2"})
+	assert.NilError(t, err, "failed to create allocation")
+	leaf.IncAllocatedResource(alloc)
+	want = concatQueueResourceMetric(metricNames, []string{`
+yunikorn_root_fair_queue_resource{resource="apps",state="maxRunningApps"} 0
+yunikorn_root_fair_queue_resource{resource="memory",state="allocated"} 6
+yunikorn_root_fair_queue_resource{resource="memory",state="deficit"} 0
+yunikorn_root_fair_queue_resource{resource="memory",state="fairShare"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="guaranteed"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="max"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="allocated"} 2
+yunikorn_root_fair_queue_resource{resource="vcores",state="deficit"} 8
+yunikorn_root_fair_queue_resource{resource="vcores",state="fairShare"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="guaranteed"} 0
+yunikorn_root_fair_queue_resource{resource="vcores",state="max"} 10
+`})
+	assert.NilError(t, promtu.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), metricNames...), "unexpected metrics after allocation")
+
+	// release: deficit is back to the full fair share
+	err = leaf.DecAllocatedResource(alloc)
+	assert.NilError(t, err, "failed to release allocation")
+	want = concatQueueResourceMetric(metricNames, []string{`
+yunikorn_root_fair_queue_resource{resource="apps",state="maxRunningApps"} 0
+yunikorn_root_fair_queue_resource{resource="memory",state="allocated"} 0
+yunikorn_root_fair_queue_resource{resource="memory",state="deficit"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="fairShare"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="guaranteed"} 4
+yunikorn_root_fair_queue_resource{resource="memory",state="max"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="allocated"} 0
+yunikorn_root_fair_queue_resource{resource="vcores",state="deficit"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="fairShare"} 10
+yunikorn_root_fair_queue_resource{resource="vcores",state="guaranteed"} 0
+yunikorn_root_fair_queue_resource{resource="vcores",state="max"} 10
+`})
+	assert.NilError(t, promtu.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), metricNames...), "unexpected metrics after release")
+}
+
 const (
-	QueueResourceMetricHelp = "# HELP %v Queue resource metrics. State of the resource includes `guaranteed`, `max`, `allocated`, `pending`, `preempting`, `maxRunningApps`."
+	QueueResourceMetricHelp = "# HELP %v Queue resource metrics. State of the resource includes `guaranteed`, `max`, `allocated`, `pending`, `preempting`, `maxRunningApps`, `fairShare`, `deficit`."
 	QueueResourceMetricType = "# TYPE %v gauge"
 )
 
@@ -868,6 +1011,32 @@ func TestMaxHeadroomMax(t *testing.T) {
 }
 
 // nolint: funlen
+func TestTryIncAllocatedResourceOverflow(t *testing.T) {
+	defer resources.SetOverflowMode(resources.SaturateOverflow)
+	root, err := createRootQueue(map[string]string{"first": strconv.FormatInt(math.MaxInt64, 10)})
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	nearMax := resources.NewResourceFromMap(map[string]resources.Quantity{"first": math.MaxInt64 - 1})
+	assert.NilError(t, leaf.TryIncAllocatedResource(nearMax), "allocation should not overflow")
+
+	// error mode: the overflow is rejected and no queue is updated
+	resources.SetOverflowMode(resources.ErrorOverflow)
+	over := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})
+	err = leaf.TryIncAllocatedResource(over)
+	assert.ErrorContains(t, err, "overflows queue 'root.leaf' allocation")
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), nearMax), "leaf allocation should not change")
+	assert.Assert(t, resources.Equals(root.GetAllocatedResource(), nearMax), "root allocation should not change")
+
+	// saturate mode: the allocation is saturated
+	resources.SetOverflowMode(resources.SaturateOverflow)
+	assert.NilError(t, leaf.TryIncAllocatedResource(over), "saturate mode should not fail")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": math.MaxInt64})
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), expected), "leaf allocation should saturate")
+	assert.Assert(t, resources.Equals(root.GetAllocatedResource(), expected), "root allocation should saturate")
+}
+
 func TestGetFairMaxResource(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -1083,6 +1252,45 @@ func TestGetMaxResource(t *testing.T) {
 	assert.Assert(t, resources.Equals(res, maxUsage), "leaf2 queue should have reset merged max set expected %v, got: %v", res, maxUsage)
 }
 
+func TestGetMaxResourcePerType(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"vcore": "10", "memory": "10"})
+	assert.NilError(t, err, "failed to create root queue with limit")
+	var parent *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"vcore": "4", "memory": "8"})
+	assert.NilError(t, err, "failed to create parent queue")
+
+	// leaf only overrides memory: vcore must be inherited from the parent
+	var leaf *Queue
+	leaf, err = createManagedQueue(parent, "leaf", false, map[string]string{"memory": "2"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	var expected *resources.Resource
+	expected, err = resources.NewResourceFromConf(map[string]string{"vcore": "4", "memory": "2"})
+	assert.NilError(t, err, "failed to create resource")
+	maxRes := leaf.GetMaxResource()
+	assert.Assert(t, resources.Equals(expected, maxRes), "leaf queue max expected %v, got: %v", expected, maxRes)
+	maxRes = leaf.GetMaxQueueSet()
+	assert.Assert(t, resources.Equals(expected, maxRes), "leaf queue max set expected %v, got: %v", expected, maxRes)
+
+	// inherited type is enforced: vcore over the parent limit, memory within the leaf limit
+	alloc := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5000, "memory": 1})
+	assert.Assert(t, leaf.TryIncAllocatedResource(alloc) != nil, "inherited vcore limit should have been enforced")
+	alloc = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000, "memory": 3})
+	assert.Assert(t, leaf.TryIncAllocatedResource(alloc) != nil, "leaf memory limit should have been enforced")
+	alloc = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 4000, "memory": 2})
+	assert.NilError(t, leaf.TryIncAllocatedResource(alloc), "allocation within merged limit should have been allowed")
+
+	// a parent without a limit in between passes the root limit through per type
+	var mid, deep *Queue
+	mid, err = createManagedQueue(parent, "mid", true, nil)
+	assert.NilError(t, err, "failed to create mid queue")
+	deep, err = createManagedQueue(mid, "deep", false, map[string]string{"memory": "6"})
+	assert.NilError(t, err, "failed to create deep queue")
+	expected, err = resources.NewResourceFromConf(map[string]string{"vcore": "4", "memory": "6"})
+	assert.NilError(t, err, "failed to create resource")
+	maxRes = deep.GetMaxResource()
+	assert.Assert(t, resources.Equals(expected, maxRes), "deep queue max expected %v, got: %v", expected, maxRes)
+}
+
 func TestGetMaxQueueSet(t *testing.T) {
 	// create the root
 	root, err := createRootQueue(nil)
@@ -1481,6 +1689,78 @@ func TestAllocationCalcSub(t *testing.T) {
 	}
 }
 
+func TestDynamicQueueConcurrentCreate(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var parent *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+
+	// create each child twice concurrently in reverse order
+	const children = 10
+	created := make([]*Queue, 2*children)
+	var wg sync.WaitGroup
+	for i := 0; i < 2*children; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("child-%02d", children-1-i%children)
+			if queue, createErr := NewDynamicQueue(name, true, parent); createErr == nil {
+				created[i] = queue
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// only one creation per name succeeds and that queue is registered
+	var count int
+	for _, queue := range created {
+		if queue != nil {
+			count++
+			assert.Equal(t, parent.GetChildQueue(queue.Name), queue, "registered child is not the created queue")
+		}
+	}
+	assert.Equal(t, count, children, "unexpected number of created queues")
+
+	expected := make([]string, children)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("child-%02d", i)
+	}
+	names := make([]string, 0, children)
+	for _, child := range parent.GetSortedChildren() {
+		names = append(names, child.Name)
+	}
+	assert.DeepEqual(t, names, expected)
+	daoNames := make([]string, 0, children)
+	for _, child := range parent.GetPartitionQueueDAOInfo(true).Children {
+		daoNames = append(daoNames, child.QueueName)
+	}
+	for i := range expected {
+		expected[i] = "root.parent." + expected[i]
+	}
+	assert.DeepEqual(t, daoNames, expected)
+}
+
+func TestPathToRoot(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create queue")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create queue")
+
+	path := root.PathToRoot()
+	assert.Equal(t, len(path), 1, "root should only return itself")
+	assert.Equal(t, path[0], root, "unexpected queue in root path")
+
+	path = leaf.PathToRoot()
+	assert.Equal(t, len(path), 3, "unexpected path length for leaf")
+	assert.Equal(t, path[0], root, "path should start at the root")
+	assert.Equal(t, path[1], parent, "parent should be in the middle of the path")
+	assert.Equal(t, path[2], leaf, "path should end at the leaf")
+}
+
 func TestQueueProps(t *testing.T) {
 	// create the root
 	root, err := createRootQueue(nil)
@@ -1550,6 +1830,17 @@ func TestQueueProps(t *testing.T) {
 	leaf, err = createManagedQueueWithProps(parent, "leaf", false, nil, props)
 	assert.NilError(t, err, "failed to create leaf queue")
 	assert.Equal(t, leaf.preemptionPolicy, policies.DefaultPreemptionPolicy)
+	assert.Equal(t, leaf.tieBreakPolicy, policies.AppIDTieBreakPolicy)
+
+	props = map[string]string{"application.sort.tiebreak": "resource"}
+	leaf, err = createManagedQueueWithProps(parent, "leaf", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.tieBreakPolicy, policies.ResourceTieBreakPolicy)
+
+	props = map[string]string{"application.sort.tiebreak": "invalid"}
+	leaf, err = createManagedQueueWithProps(parent, "leaf", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.tieBreakPolicy, policies.AppIDTieBreakPolicy)
 }
 
 func TestInheritedQueueProps(t *testing.T) {
@@ -1773,7 +2064,7 @@ func TestSetResources(t *testing.T) {
 	err = queue.setResourcesFromConf(configs.Resources{
 		Guaranteed: guaranteedResource,
 		Max:        maxResource,
-	})
+	}, nil)
 	assert.NilError(t, err, "failed to set resources: %v", err)
 
 	expectedGuaranteedResource, err := resources.NewResourceFromConf(guaranteedResource)
@@ -1789,7 +2080,7 @@ func TestSetResources(t *testing.T) {
 	err = queue.setResourcesFromConf(configs.Resources{
 		Guaranteed: make(map[string]string),
 		Max:        make(map[string]string),
-	})
+	}, nil)
 	assert.NilError(t, err, "failed to set resources: %v", err)
 	assert.DeepEqual(t, queue.guaranteedResource, nilResource)
 	assert.DeepEqual(t, queue.maxResource, nilResource)
@@ -1798,7 +2089,7 @@ func TestSetResources(t *testing.T) {
 	err = queue.setResourcesFromConf(configs.Resources{
 		Guaranteed: getZeroResourceConf(),
 		Max:        getZeroResourceConf(),
-	})
+	}, nil)
 	assert.NilError(t, err, "failed to set resources: %v", err)
 	assert.DeepEqual(t, queue.guaranteedResource, nilResource)
 	assert.DeepEqual(t, queue.maxResource, nilResource)
@@ -1870,6 +2161,33 @@ func TestFindQueueByAppID(t *testing.T) {
 }
 
 // nolint: funlen
+func TestQueuePreemptableConfig(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create queue")
+	notPreemptable := false
+	parentConf := configs.QueueConfig{
+		Name:        "parent",
+		Parent:      true,
+		Preemptable: &notPreemptable,
+	}
+	var parent, leaf *Queue
+	parent, err = NewConfiguredQueue(parentConf, root, false)
+	assert.NilError(t, err, "failed to create queue")
+	preemptable := true
+	leaf, err = NewConfiguredQueue(configs.QueueConfig{Name: "leaf", Preemptable: &preemptable}, parent, false)
+	assert.NilError(t, err, "failed to create queue")
+	assert.Assert(t, root.IsPreemptable(), "root should be preemptable by default")
+	assert.Assert(t, !parent.IsPreemptable(), "parent should not be preemptable")
+	assert.Assert(t, !leaf.IsPreemptable(), "parent should force leaf to not preemptable")
+
+	// removing the setting from the config reverts to the default
+	parentConf.Preemptable = nil
+	err = parent.ApplyConf(parentConf)
+	assert.NilError(t, err, "failed to update queue")
+	assert.Assert(t, parent.IsPreemptable(), "parent should be preemptable after update")
+	assert.Assert(t, leaf.IsPreemptable(), "leaf should be preemptable after update")
+}
+
 func TestFindEligiblePreemptionVictims(t *testing.T) {
 	res := resources.NewResourceFromMap(map[string]resources.Quantity{siCommon.Memory: 100})
 	parentMax := map[string]string{siCommon.Memory: "200"}
@@ -1926,6 +2244,20 @@ func TestFindEligiblePreemptionVictims(t *testing.T) {
 	assert.Equal(t, 0, len(victims(snapshot)), "found victims")
 	leaf2.preemptionPolicy = policies.DefaultPreemptionPolicy
 
+	// a queue that is not preemptable should remove victims from consideration
+	leaf2.preemptable = false
+	snapshot = leaf1.FindEligiblePreemptionVictims(leaf1.QueuePath, ask)
+	assert.Equal(t, 0, len(victims(snapshot)), "found victims")
+	leaf2.preemptable = true
+
+	// an ancestor that is not preemptable protects the whole subtree
+	parent2.preemptable = false
+	assert.Assert(t, !leaf2.IsPreemptable(), "leaf should inherit not preemptable from parent")
+	snapshot = leaf1.FindEligiblePreemptionVictims(leaf1.QueuePath, ask)
+	assert.Equal(t, 0, len(victims(snapshot)), "found victims")
+	parent2.preemptable = true
+	assert.Assert(t, leaf2.IsPreemptable(), "leaf should be preemptable")
+
 	// fencing parent1 queue should limit scope
 	parent1.preemptionPolicy = policies.FencePreemptionPolicy
 	assert.Equal(t, leaf1.findPreemptionFenceRoot(make(map[string]int64), int64(ask.priority)).QueuePath, "root.parent1")
@@ -2346,11 +2678,11 @@ func TestResetRunningState(t *testing.T) {
 	parent.MarkQueueForRemoval()
 	assert.Assert(t, parent.IsDraining(), "parent should be marked as draining")
 	assert.Assert(t, leaf.IsDraining(), "leaf should be marked as draining")
-	err = parent.applyConf(emptyConf, false)
+	err = parent.applyConf(emptyConf, nil, false)
 	assert.NilError(t, err, "failed to update parent")
 	assert.Assert(t, parent.IsRunning(), "parent should be running again")
 	assert.Assert(t, leaf.IsDraining(), "leaf should still be marked as draining")
-	err = leaf.applyConf(emptyConf, false)
+	err = leaf.applyConf(emptyConf, leaf.getParentPercentageBase(), false)
 	assert.NilError(t, err, "failed to update leaf")
 	assert.Assert(t, leaf.IsRunning(), "leaf should be running again")
 }
@@ -2877,3 +3209,1297 @@ func TestQueue_allocatedResFits_Other(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSubmitAccessACLEnforcement(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	user := security.UserGroup{User: "testuser", Groups: []string{"testgroup"}}
+
+	// enforce mode is the default: no ACL set denies access
+	var enforced *Queue
+	enforced, err = createManagedQueue(root, "enforced", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	eventSystem := mock.NewEventSystem()
+	enforced.queueEvents = schedEvt.NewQueueEvents(eventSystem)
+	assert.Assert(t, !enforced.CheckSubmitAccess(user), "enforce mode should deny access")
+	assert.Equal(t, 0, len(eventSystem.Events), "enforce mode should not send an audit event")
+
+	// audit mode allows the denied access and sends an audit event
+	var audited *Queue
+	audited, err = createManagedQueueWithProps(root, "audited", false, nil, map[string]string{configs.ACLEnforcement: "audit"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, audited.getACLEnforcement(), policies.AuditACLPolicy, "acl enforcement property not set")
+	audited.queueEvents = schedEvt.NewQueueEvents(eventSystem)
+	assert.Assert(t, audited.CheckSubmitAccess(user), "audit mode should allow access")
+	assert.Equal(t, 1, len(eventSystem.Events), "audit mode should send an audit event")
+	assert.Equal(t, "root.audited", eventSystem.Events[0].ObjectID)
+	assert.Equal(t, "submit access denied in acl audit mode for user: testuser", eventSystem.Events[0].Message)
+
+	// the ACL passes: no audit event
+	audited.submitACL, err = security.NewACL("testuser", false)
+	assert.NilError(t, err, "failed to set ACL")
+	// the ACL is set directly: clear the denial recorded above
+	root.getACLDenials().clear()
+	assert.Assert(t, audited.CheckSubmitAccess(user), "ACL should allow access")
+	assert.Equal(t, 1, len(eventSystem.Events), "allowed access should not send an audit event")
+
+	// the mode is inherited from the parent: setting it on the root applies to the whole partition
+	var parent, child *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, map[string]string{configs.ACLEnforcement: "audit"})
+	assert.NilError(t, err, "failed to create parent queue")
+	child, err = createManagedQueue(parent, "child", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, child.getACLEnforcement(), policies.AuditACLPolicy, "acl enforcement should be inherited")
+	assert.Assert(t, child.CheckSubmitAccess(user), "inherited audit mode should allow access")
+
+	// the recovery queue is never accessible even in audit mode
+	var recovery *Queue
+	recovery, err = NewRecoveryQueue(root)
+	assert.NilError(t, err, "failed to create recovery queue")
+	recovery.aclEnforcement = policies.AuditACLPolicy
+	assert.Assert(t, !recovery.CheckSubmitAccess(user), "recovery queue should deny access")
+}
+
+func TestCheckAskSize(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"memory": "10"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, map[string]string{"vcore": "5"})
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	// types without a limit and asks that fit are accepted
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 5000, "gpu": 100})
+	assert.NilError(t, leaf.CheckAskSize(newAllocationAsk(aKey, appID1, res)), "ask that fits should be allowed")
+	// the limit set on the parent applies to the leaf
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 11, "vcore": 6000})
+	err = leaf.CheckAskSize(newAllocationAsk(aKey, appID1, res))
+	assert.Error(t, err, "queue root.parent.leaf cannot fit request alloc-1: memory requested 11 larger than max queue allocation 10")
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1, "vcore": 6000})
+	err = leaf.CheckAskSize(newAllocationAsk(aKey, appID1, res))
+	assert.Error(t, err, "queue root.parent.leaf cannot fit request alloc-1: vcore requested 6000 larger than max queue allocation 5000")
+	// no limit set in the hierarchy allows everything
+	var unlimited *Queue
+	unlimited, err = createManagedQueue(root, "unlimited", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.NilError(t, unlimited.CheckAskSize(newAllocationAsk(aKey, appID1, res)), "queue without limit should allow ask")
+
+	// warn mode is inherited and accepts the oversized ask
+	var warnParent, warnLeaf *Queue
+	warnParent, err = createManagedQueueWithProps(root, "warn", true, map[string]string{"memory": "10"}, map[string]string{configs.AskSizeEnforcement: "warn"})
+	assert.NilError(t, err, "failed to create parent queue")
+	warnLeaf, err = createManagedQueue(warnParent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, warnLeaf.getAskSizePolicy(), policies.WarnAskSizePolicy, "ask size enforcement should be inherited")
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 11})
+	assert.NilError(t, warnLeaf.CheckAskSize(newAllocationAsk(aKey, appID1, res)), "warn mode should allow oversized ask")
+}
+
+func TestDefaultSubmitACL(t *testing.T) {
+	root, err := NewConfiguredQueue(configs.QueueConfig{Name: "root", Parent: true, SubmitACL: " "}, nil, false)
+	assert.NilError(t, err, "queue create failed")
+	var explicit, child, implicit *Queue
+	explicit, err = NewConfiguredQueue(configs.QueueConfig{Name: "explicit", Parent: true, SubmitACL: "alice"}, root, false)
+	assert.NilError(t, err, "failed to create parent queue")
+	child, err = createManagedQueue(explicit, "child", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	implicit, err = createManagedQueue(root, "implicit", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	alice := security.UserGroup{User: "alice"}
+	bob := security.UserGroup{User: "bob"}
+
+	// no default set: only the explicit ACL allows access
+	assert.Assert(t, !implicit.CheckSubmitAccess(bob), "bob should not have access without a default")
+	assert.Assert(t, child.CheckSubmitAccess(alice), "alice should have access via the parent")
+	assert.Assert(t, !child.CheckSubmitAccess(bob), "bob should not have access without a default")
+
+	var acl security.ACL
+	acl, err = security.NewACL("bob", false)
+	assert.NilError(t, err, "failed to create ACL")
+	root.SetDefaultSubmitACL(acl)
+	// the default applies to queues without an explicit ACL only
+	assert.Assert(t, !root.CheckSubmitAccess(bob), "explicit root ACL should not be replaced by the default")
+	assert.Assert(t, !explicit.CheckSubmitAccess(bob), "explicit queue ACL should not be replaced by the default")
+	assert.Assert(t, explicit.CheckSubmitAccess(alice), "explicit queue ACL should still allow access")
+	assert.Assert(t, implicit.CheckSubmitAccess(bob), "default should apply to queue without an ACL")
+	assert.Assert(t, !implicit.CheckSubmitAccess(alice), "default should not allow other users")
+	assert.Assert(t, child.CheckSubmitAccess(bob), "default should apply to queue without an ACL below an explicit ACL")
+	assert.Assert(t, child.CheckSubmitAccess(alice), "parent ACL should still be inherited")
+	// dynamic queues do not have an explicit ACL
+	var dynamic *Queue
+	dynamic, err = createDynamicQueue(explicit, "dynamic", false)
+	assert.NilError(t, err, "failed to create dynamic queue")
+	assert.Assert(t, dynamic.CheckSubmitAccess(bob), "default should apply to a dynamic queue")
+
+	// a config update that sets an explicit ACL removes the default for the queue
+	err = implicit.ApplyConf(configs.QueueConfig{Name: "implicit", SubmitACL: "alice"})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Assert(t, !implicit.CheckSubmitAccess(bob), "explicit ACL should not be replaced by the default")
+	assert.Assert(t, implicit.CheckSubmitAccess(alice), "explicit ACL should allow access")
+}
+
+func TestGetEffectiveSubmitACL(t *testing.T) {
+	root, err := NewConfiguredQueue(configs.QueueConfig{Name: "root", Parent: true, SubmitACL: "admin", AdminACL: " admins"}, nil, false)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf, explicit *Queue
+	parent, err = NewConfiguredQueue(configs.QueueConfig{Name: "parent", Parent: true, SubmitACL: "alice devs"}, root, false)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	explicit, err = NewConfiguredQueue(configs.QueueConfig{Name: "explicit", SubmitACL: "carol"}, parent, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	assert.Equal(t, root.GetEffectiveSubmitACL().String(), "admin admins", "unexpected root ACL")
+	assert.Equal(t, parent.GetEffectiveSubmitACL().String(), "admin,alice admins,devs", "parent should inherit the root ACL")
+	assert.Equal(t, leaf.GetEffectiveSubmitACL().String(), "admin,alice admins,devs", "leaf without ACL should inherit all ACLs")
+	assert.Equal(t, explicit.GetEffectiveSubmitACL().String(), "admin,alice,carol admins,devs", "explicit ACL should be merged with the parents")
+
+	// the partition default only applies to queues without a submit ACL in the config
+	var acl security.ACL
+	acl, err = security.NewACL("bob", false)
+	assert.NilError(t, err, "failed to create ACL")
+	root.SetDefaultSubmitACL(acl)
+	assert.Equal(t, root.GetEffectiveSubmitACL().String(), "admin admins", "default should not apply to explicit root ACL")
+	assert.Equal(t, leaf.GetEffectiveSubmitACL().String(), "admin,alice,bob admins,devs", "default should apply to leaf without ACL")
+	assert.Equal(t, explicit.GetEffectiveSubmitACL().String(), "admin,alice,carol admins,devs", "default should not apply to explicit ACL")
+	bob := security.UserGroup{User: "bob"}
+	assert.Equal(t, leaf.GetEffectiveSubmitACL().CheckAccess(bob), leaf.CheckSubmitAccess(bob), "effective ACL should match the access check")
+	assert.Equal(t, explicit.GetEffectiveSubmitACL().CheckAccess(bob), explicit.CheckSubmitAccess(bob), "effective ACL should match the access check")
+
+	// the returned ACL is a copy
+	merged := leaf.GetEffectiveSubmitACL().Merge(acl)
+	assert.Equal(t, merged.String(), "admin,alice,bob admins,devs", "unexpected merged ACL")
+	assert.Equal(t, leaf.GetEffectiveSubmitACL().String(), "admin,alice,bob admins,devs", "merge should not change the queue ACL")
+
+	// a wildcard anywhere in the hierarchy allows all
+	err = parent.ApplyConf(configs.QueueConfig{Name: "parent", Parent: true, SubmitACL: "*"})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Equal(t, explicit.GetEffectiveSubmitACL().String(), "*", "wildcard on the parent should be inherited")
+	assert.Equal(t, root.GetEffectiveSubmitACL().String(), "admin admins", "wildcard should not be inherited upwards")
+}
+
+func TestGetSubmittableQueues(t *testing.T) {
+	root, err := NewConfiguredQueue(configs.QueueConfig{Name: "root", Parent: true, SubmitACL: "admin"}, nil, false)
+	assert.NilError(t, err, "queue create failed")
+	var parent, draining *Queue
+	parent, err = NewConfiguredQueue(configs.QueueConfig{Name: "parent", Parent: true, SubmitACL: "alice"}, root, false)
+	assert.NilError(t, err, "failed to create parent queue")
+	_, err = NewConfiguredQueue(configs.QueueConfig{Name: "leaf2", SubmitACL: "bob"}, parent, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	_, err = NewConfiguredQueue(configs.QueueConfig{Name: "leaf1", SubmitACL: "bob"}, parent, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	_, err = NewConfiguredQueue(configs.QueueConfig{Name: "other", SubmitACL: "bob"}, root, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	draining, err = NewConfiguredQueue(configs.QueueConfig{Name: "draining", SubmitACL: "bob"}, root, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	draining.MarkQueueForRemoval()
+	_, err = NewRecoveryQueue(root)
+	assert.NilError(t, err, "failed to create recovery queue")
+
+	bob := security.UserGroup{User: "bob"}
+	assert.DeepEqual(t, root.GetSubmittableQueues(bob, 0), []string{"root.other", "root.parent.leaf1", "root.parent.leaf2"})
+	assert.DeepEqual(t, root.GetSubmittableQueues(bob, 2), []string{"root.other", "root.parent.leaf1"})
+	assert.DeepEqual(t, parent.GetSubmittableQueues(bob, 0), []string{"root.parent.leaf1", "root.parent.leaf2"})
+	// access to the parent gives access to all its leaf queues
+	alice := security.UserGroup{User: "alice"}
+	assert.DeepEqual(t, root.GetSubmittableQueues(alice, 0), []string{"root.parent.leaf1", "root.parent.leaf2"})
+	assert.Equal(t, len(root.GetSubmittableQueues(security.UserGroup{User: "nobody"}, 0)), 0, "user should not reach any queue")
+}
+
+func TestDominantShare(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var tests = []struct {
+		name      string
+		allocated map[string]resources.Quantity
+		capacity  map[string]resources.Quantity
+		expected  float64
+	}{
+		{"nothing allocated", nil, map[string]resources.Quantity{"first": 10}, 0},
+		{"single type", map[string]resources.Quantity{"first": 5}, map[string]resources.Quantity{"first": 10}, 0.5},
+		{"first dominant", map[string]resources.Quantity{"first": 8, "second": 2}, map[string]resources.Quantity{"first": 10, "second": 10}, 0.8},
+		{"second dominant", map[string]resources.Quantity{"first": 8, "second": 6}, map[string]resources.Quantity{"first": 100, "second": 10}, 0.6},
+		{"type missing in capacity", map[string]resources.Quantity{"first": 2, "second": 60}, map[string]resources.Quantity{"first": 10}, 0.2},
+		{"zero capacity with allocation", map[string]resources.Quantity{"first": 2, "second": 1}, map[string]resources.Quantity{"first": 10, "second": 0}, 1},
+		{"zero capacity without allocation", map[string]resources.Quantity{"first": 2, "second": 0}, map[string]resources.Quantity{"first": 10, "second": 0}, 0.2},
+		{"over allocated", map[string]resources.Quantity{"first": 15}, map[string]resources.Quantity{"first": 10}, 1.5},
+		{"empty capacity", map[string]resources.Quantity{"first": 5}, map[string]resources.Quantity{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.allocatedResource = resources.NewResourceFromMap(tt.allocated)
+			assert.Equal(t, root.DominantShare(resources.NewResourceFromMap(tt.capacity)), tt.expected, "unexpected dominant share")
+		})
+	}
+	assert.Equal(t, root.DominantShare(nil), float64(0), "nil capacity should return zero share")
+}
+
+func TestTimeToFull(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, map[string]string{"first": "100", "second": "10"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	var tests = []struct {
+		name      string
+		allocated map[string]resources.Quantity
+		sample    map[string]resources.Quantity
+		expected  time.Duration
+		full      bool
+	}{
+		{"no sample", map[string]resources.Quantity{"first": 10}, nil, 0, false},
+		{"zero rate", map[string]resources.Quantity{"first": 10}, map[string]resources.Quantity{"first": 0}, 0, false},
+		{"negative rate", map[string]resources.Quantity{"first": 10}, map[string]resources.Quantity{"first": -5, "second": -1}, 0, false},
+		{"type without maximum", nil, map[string]resources.Quantity{"third": 5}, 0, false},
+		{"single type", map[string]resources.Quantity{"first": 50}, map[string]resources.Quantity{"first": 10}, 5 * time.Minute, true},
+		{"fractional", nil, map[string]resources.Quantity{"first": 40}, 150 * time.Second, true},
+		{"soonest type", map[string]resources.Quantity{"first": 20, "second": 4}, map[string]resources.Quantity{"first": 10, "second": 3}, 2 * time.Minute, true},
+		{"negative and positive rate", map[string]resources.Quantity{"first": 20, "second": 4}, map[string]resources.Quantity{"first": 10, "second": -3}, 8 * time.Minute, true},
+		{"already full", map[string]resources.Quantity{"first": 20, "second": 12}, map[string]resources.Quantity{"first": 10, "second": 1}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf.allocatedResource = resources.NewResourceFromMap(tt.allocated)
+			var sample *resources.Resource
+			if tt.sample != nil {
+				sample = resources.NewResourceFromMap(tt.sample)
+			}
+			duration, full := leaf.TimeToFull(sample, time.Minute)
+			assert.Equal(t, full, tt.full, "unexpected full projection")
+			assert.Equal(t, duration, tt.expected, "unexpected time to full")
+		})
+	}
+	sample := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	_, full := leaf.TimeToFull(sample, 0)
+	assert.Assert(t, !full, "zero interval should never be full")
+	_, full = root.TimeToFull(sample, time.Minute)
+	assert.Assert(t, !full, "queue without maximum should never be full")
+}
+
+func TestGetGuaranteedShareCandidates(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	create := func(parent *Queue, name string, isParent bool, guaranteed map[string]string, allocated map[string]resources.Quantity) *Queue {
+		queue, err := createManagedQueueGuaranteed(parent, name, isParent, nil, guaranteed)
+		assert.NilError(t, err, "failed to create queue %s", name)
+		queue.allocatedResource = resources.NewResourceFromMap(allocated)
+		return queue
+	}
+	parent := create(root, "parent", true, map[string]string{"first": "10"}, map[string]resources.Quantity{"first": 19})
+	create(parent, "over", false, map[string]string{"first": "5"}, map[string]resources.Quantity{"first": 8})
+	create(parent, "under", false, map[string]string{"first": "5"}, map[string]resources.Quantity{"first": 1})
+	create(parent, "none", false, nil, map[string]resources.Quantity{"first": 5})
+	create(parent, "equal", false, map[string]string{"first": "2"}, map[string]resources.Quantity{"first": 2})
+	create(root, "far-over", false, map[string]string{"first": "4", "second": "10"}, map[string]resources.Quantity{"first": 10, "second": 1})
+	create(root, "idle", false, map[string]string{"first": "3"}, nil)
+	create(root, "idle2", false, map[string]string{"second": "3"}, nil)
+
+	queuePaths := func(queues []*Queue) []string {
+		paths := make([]string, len(queues))
+		for i, queue := range queues {
+			paths[i] = queue.QueuePath
+		}
+		return paths
+	}
+	donors, needy := root.GetGuaranteedShareCandidates()
+	assert.DeepEqual(t, queuePaths(donors), []string{"root.far-over", "root.parent", "root.parent.over"})
+	assert.DeepEqual(t, queuePaths(needy), []string{"root.idle", "root.idle2", "root.parent.under"})
+
+	// the traversal starts at the queue it is called on
+	donors, needy = parent.GetGuaranteedShareCandidates()
+	assert.DeepEqual(t, queuePaths(donors), []string{"root.parent", "root.parent.over"})
+	assert.DeepEqual(t, queuePaths(needy), []string{"root.parent.under"})
+}
+
+func TestCheckFairShareConvergence(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	create := func(parent *Queue, name string, isParent bool, guaranteed map[string]string, allocated map[string]resources.Quantity) *Queue {
+		queue, err := createManagedQueueGuaranteed(parent, name, isParent, nil, guaranteed)
+		assert.NilError(t, err, "failed to create queue %s", name)
+		queue.allocatedResource = resources.NewResourceFromMap(allocated)
+		return queue
+	}
+	parent := create(root, "parent", true, map[string]string{"first": "8"}, map[string]resources.Quantity{"first": 8})
+	left := create(parent, "left", false, map[string]string{"first": "4"}, map[string]resources.Quantity{"first": 4})
+	right := create(parent, "right", false, map[string]string{"first": "4"}, map[string]resources.Quantity{"first": 4})
+	create(root, "none", false, nil, map[string]resources.Quantity{"first": 20})
+	idle := create(root, "idle", false, map[string]string{"first": "3"}, nil)
+
+	// all queues at their share, the idle queue has no demand and the queue without a guarantee is ignored
+	converged, worst, deviation := root.CheckFairShareConvergence(0)
+	assert.Assert(t, converged, "tree at fair share should have converged")
+	assert.Assert(t, worst == nil, "no offender expected on a converged tree")
+	assert.Equal(t, deviation, float64(0), "unexpected deviation")
+
+	// an idle queue with demand is under its share
+	idle.pending = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	converged, worst, deviation = root.CheckFairShareConvergence(0.1)
+	assert.Assert(t, !converged, "starved queue should not have converged")
+	assert.Equal(t, worst, idle, "idle queue with demand should be the worst offender")
+	assert.Equal(t, deviation, float64(1), "unexpected deviation")
+	idle.pending = nil
+
+	// one leaf borrows idle capacity from its sibling without demand
+	left.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	right.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3})
+	converged, worst, deviation = root.CheckFairShareConvergence(0)
+	assert.Assert(t, converged, "borrowing idle capacity should have converged")
+	assert.Assert(t, worst == nil, "no offender expected when borrowing idle capacity")
+	assert.Equal(t, deviation, float64(0), "unexpected deviation")
+
+	// the sibling has demand: within a loose tolerance, not within a strict one
+	right.pending = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	converged, worst, deviation = root.CheckFairShareConvergence(0.3)
+	assert.Assert(t, converged, "deviation within tolerance should have converged")
+	assert.Equal(t, worst, left, "deviations are equal, worst offender should be ordered on queue path")
+	assert.Equal(t, deviation, 0.25, "unexpected deviation")
+	converged, _, _ = root.CheckFairShareConvergence(0.1)
+	assert.Assert(t, !converged, "deviation outside tolerance should not have converged")
+
+	// the evaluation starts at the queue it is called on
+	converged, worst, _ = right.CheckFairShareConvergence(0.1)
+	assert.Assert(t, !converged, "starved leaf should not have converged")
+	assert.Equal(t, worst, right, "leaf should be the worst offender")
+}
+
+func TestCompletedAppRetention(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.CompletedAppRetention: "1h"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.completedRetention, time.Hour, "retention property not set")
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+
+	// completed and failed apps are retained, a removed running app is not
+	completed := newApplication(appID1, "default", leaf.QueuePath)
+	leaf.AddApplication(completed)
+	completed.SetState(Completed.String())
+	leaf.RemoveApplication(completed)
+	mockClock.Advance(time.Minute)
+	failed := newApplication(appID2, "default", leaf.QueuePath)
+	leaf.AddApplication(failed)
+	failed.SetState(Failed.String())
+	leaf.RemoveApplication(failed)
+	running := newApplication(appID3, "default", leaf.QueuePath)
+	leaf.AddApplication(running)
+	running.SetState(Running.String())
+	leaf.RemoveApplication(running)
+	assert.Equal(t, len(leaf.GetCopyOfApps()), 0, "apps should have been removed from the active list")
+	retained := leaf.GetRecentlyCompletedApps()
+	assert.Equal(t, len(retained), 2, "completed and failed apps should be retained")
+	assert.Equal(t, retained[0], completed, "oldest completed app should be first")
+	assert.Equal(t, retained[1], failed, "newest completed app should be last")
+
+	// before the retention passes nothing is purged
+	mockClock.Advance(30 * time.Minute)
+	assert.Equal(t, leaf.PurgeCompletedApps(), 0, "no apps should have been purged before the retention")
+	assert.Equal(t, len(leaf.GetRecentlyCompletedApps()), 2, "apps should still be retained")
+
+	// retention passed for the first app only
+	mockClock.Advance(29 * time.Minute)
+	assert.Equal(t, leaf.PurgeCompletedApps(), 1, "first app should have been purged")
+	retained = leaf.GetRecentlyCompletedApps()
+	assert.Equal(t, len(retained), 1, "one app should be retained")
+	assert.Equal(t, retained[0], failed, "failed app should still be retained")
+	mockClock.Advance(time.Minute)
+	assert.Equal(t, leaf.PurgeCompletedApps(), 1, "second app should have been purged")
+	assert.Equal(t, len(leaf.GetRecentlyCompletedApps()), 0, "no apps should be retained")
+
+	// no retention set: completed apps are not kept
+	var noRetention *Queue
+	noRetention, err = createManagedQueue(root, "noretention", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	completed = newApplication(appID1, "default", noRetention.QueuePath)
+	noRetention.AddApplication(completed)
+	completed.SetState(Completed.String())
+	noRetention.RemoveApplication(completed)
+	assert.Equal(t, len(noRetention.GetRecentlyCompletedApps()), 0, "app should not be retained without retention")
+}
+
+func TestPreviewAllocation(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"memory": "100", "vcores": "100"})
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"memory": "20"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, map[string]string{"vcores": "10"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	err = leaf.TryIncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 5}))
+	assert.NilError(t, err, "failed to set allocated resource")
+
+	// fits in the leaf, the parent and the root
+	fits, limiting := leaf.PreviewAllocation(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 5}))
+	assert.Assert(t, fits, "ask should fit in the queue hierarchy")
+	assert.Equal(t, limiting, "", "no limiting resource expected")
+
+	// blocked by the leaf max
+	fits, limiting = leaf.PreviewAllocation(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1, "vcores": 6}))
+	assert.Assert(t, !fits, "ask should be blocked by the leaf max")
+	assert.Equal(t, limiting, "vcores", "unexpected limiting resource for leaf max")
+
+	// blocked by the parent max
+	fits, limiting = leaf.PreviewAllocation(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 11, "vcores": 1}))
+	assert.Assert(t, !fits, "ask should be blocked by the parent max")
+	assert.Equal(t, limiting, "memory", "unexpected limiting resource for parent max")
+
+	// blocked by the root: type not registered
+	fits, limiting = leaf.PreviewAllocation(resources.NewResourceFromMap(map[string]resources.Quantity{"gpu": 1}))
+	assert.Assert(t, !fits, "ask should be blocked by the root")
+	assert.Equal(t, limiting, "gpu", "unexpected limiting resource for root")
+
+	// no state change
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 5})
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), expected), "leaf allocated changed by preview")
+	assert.Assert(t, resources.Equals(parent.GetAllocatedResource(), expected), "parent allocated changed by preview")
+	assert.Assert(t, resources.Equals(root.GetAllocatedResource(), expected), "root allocated changed by preview")
+}
+
+func TestReconcileAllocatedResource(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	eventSystem := mock.NewEventSystem()
+	leaf.queueEvents = schedEvt.NewQueueEvents(eventSystem)
+	parent.queueEvents = schedEvt.NewQueueEvents(eventSystem)
+	root.queueEvents = schedEvt.NewQueueEvents(eventSystem)
+
+	// empty hierarchy nothing to correct
+	assert.Assert(t, !root.ReconcileAllocatedResource(), "empty queues should not drift")
+	assert.Equal(t, 0, len(eventSystem.Events), "unexpected event")
+
+	app := newApplication(appID1, "default", "root.parent.leaf")
+	app.SetQueue(leaf)
+	leaf.AddApplication(app)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5})
+	app.AddAllocation(newAllocation(appID1, nodeID1, res))
+	leaf.IncAllocatedResource(res)
+	assert.Assert(t, !root.ReconcileAllocatedResource(), "consistent queues should not drift")
+	eventSystem.Reset()
+
+	// inject drifted counters: leaf too high, parent and root with a stale type
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	parent.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcore": 1})
+	root.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcore": 1})
+	assert.Assert(t, root.ReconcileAllocatedResource(), "drift should have been detected")
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), res), "leaf allocated not corrected")
+	assert.Assert(t, resources.Equals(parent.GetAllocatedResource(), res), "parent allocated not corrected")
+	assert.Assert(t, resources.Equals(root.GetAllocatedResource(), res), "root allocated not corrected")
+	assert.Equal(t, 3, len(eventSystem.Events), "expected one event per corrected queue")
+	assert.Equal(t, "root.parent.leaf", eventSystem.Events[0].ObjectID)
+	assert.Equal(t, "allocated resource drift detected: recorded map[memory:10], actual map[memory:5]", eventSystem.Events[0].Message)
+
+	// corrected hierarchy: second run finds nothing
+	assert.Assert(t, !root.ReconcileAllocatedResource(), "corrected queues should not drift")
+	assert.Equal(t, 3, len(eventSystem.Events), "unexpected event")
+}
+
+func TestGroupLimits(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = NewConfiguredQueue(configs.QueueConfig{
+		Name:                 "leaf",
+		MaxResourcesPerGroup: map[string]string{"memory": "10"},
+	}, root, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, resources.Equals(leaf.maxGroupResource, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})), "per group max not set")
+	assert.Assert(t, root.maxGroupResource == nil, "per group max should not be set on root")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5})
+	primary := security.UserGroup{User: "user1", Groups: []string{"group1", "group2"}}
+	leaf.incUserResourceUsage(primary, resources.Multiply(res, 2))
+	assert.Assert(t, resources.Equals(root.groupAllocated["group2"], resources.Multiply(res, 2)), "usage not tracked on the parent")
+
+	// primary group charge: group1 is full, group2 only used as a secondary group
+	assert.Equal(t, leaf.checkGroupLimits(security.UserGroup{User: "user2", Groups: []string{"group1"}}, res), "group1")
+	assert.Equal(t, leaf.checkGroupLimits(security.UserGroup{User: "user3", Groups: []string{"group3"}}, res), "")
+	secondary := security.UserGroup{User: "user4", Groups: []string{"group2", "group1"}}
+	assert.Equal(t, leaf.checkGroupLimits(secondary, res), "")
+	assert.Equal(t, leaf.checkGroupLimits(security.UserGroup{User: "user5"}, res), "", "user without groups is not limited")
+
+	// all groups charge: group2 is full
+	leaf.properties = map[string]string{configs.GroupLimitCharge: "all"}
+	leaf.UpdateQueueProperties()
+	assert.Equal(t, leaf.groupChargePolicy, policies.AllGroupsChargePolicy, "group charge property not set")
+	assert.Equal(t, leaf.checkGroupLimits(secondary, res), "group2")
+
+	// release frees the groups
+	leaf.decUserResourceUsage(primary, resources.Multiply(res, 2))
+	assert.Equal(t, leaf.checkGroupLimits(secondary, res), "")
+	assert.Equal(t, len(leaf.groupAllocated), 0, "released groups should be removed")
+	assert.Equal(t, len(root.primaryAllocated), 0, "released groups should be removed from the parent")
+}
+
+func TestGetUserShares(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"memory": "200"})
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, map[string]string{"memory": "120"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, len(leaf.GetUserShares(nil)), 0, "queue without users should not have shares")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	for i, user := range []string{"user1", "user2", "user3"} {
+		app := newApplicationWithUserGroup(fmt.Sprintf("app-%d", i), "default", "root.leaf", user, []string{"group1"})
+		app.SetQueue(leaf)
+		leaf.AddApplication(app)
+		// user1 has 3 allocations, user2 has 1 and user3 none
+		for j := 0; j < 3-2*i; j++ {
+			app.AddAllocation(newAllocationWithKey(fmt.Sprintf("alloc-%d-%d", i, j), app.ApplicationID, nodeID1, res))
+		}
+	}
+
+	// equal share of the queue maximum
+	shares := leaf.GetUserShares(nil)
+	assert.Equal(t, len(shares), 3, "unexpected number of users")
+	fair := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 40})
+	for user, allocated := range map[string]resources.Quantity{"user1": 30, "user2": 10, "user3": 0} {
+		assert.Equal(t, shares[user].Allocated.Resources["memory"], allocated, "unexpected allocation for %s", user)
+		assert.Assert(t, resources.Equals(shares[user].FairShare, fair), "unexpected fair share for %s: %s", user, shares[user].FairShare)
+	}
+
+	// weighted share: weights that are not positive count as 1
+	shares = leaf.GetUserShares(map[string]float64{"user1": 2, "user2": -1, "unknown": 5})
+	for user, share := range map[string]resources.Quantity{"user1": 60, "user2": 30, "user3": 30} {
+		assert.Equal(t, shares[user].FairShare.Resources["memory"], share, "unexpected weighted fair share for %s", user)
+	}
+
+	// the parent only tracks the users with allocations
+	shares = root.GetUserShares(nil)
+	assert.Equal(t, len(shares), 2, "unexpected number of users on the parent")
+	assert.Equal(t, shares["user1"].FairShare.Resources["memory"], resources.Quantity(100), "unexpected parent fair share")
+
+	// released allocations are removed from the user usage
+	for _, app := range leaf.GetCopyOfApps() {
+		for _, alloc := range app.GetAllAllocations() {
+			app.RemoveAllocation(alloc.GetAllocationKey(), si.TerminationType_STOPPED_BY_RM)
+		}
+	}
+	shares = leaf.GetUserShares(nil)
+	assert.Equal(t, len(shares), 3, "users with applications should have shares")
+	for user, share := range shares {
+		assert.Assert(t, resources.IsZero(share.Allocated), "allocation not released for %s", user)
+	}
+	assert.Equal(t, len(root.GetUserShares(nil)), 0, "released users should be removed from the parent")
+}
+
+func TestSortQueuesPriorityOffset(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf1, leaf2 *Queue
+	leaf1, err = createManagedQueue(root, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	leaf2, err = createManagedQueueWithProps(root, "leaf2", false, nil, map[string]string{configs.PriorityOffset: "10"})
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	// leaf1 has the higher app priority, leaf2 the lower app priority but a queue offset
+	for appID, queue := range map[string]*Queue{appID1: leaf1, appID2: leaf2} {
+		app := newApplication(appID, "default", queue.QueuePath)
+		app.SetQueue(queue)
+		queue.AddApplication(app)
+		priority := int32(5)
+		if queue == leaf2 {
+			priority = 0
+		}
+		err = app.AddAllocationAsk(newAllocationAskPriority("alloc-"+appID, appID, res, priority))
+		assert.NilError(t, err, "failed to add ask")
+	}
+	assert.Equal(t, leaf1.GetCurrentPriority(), int32(5), "leaf1 priority wrong")
+	assert.Equal(t, leaf2.GetCurrentPriority(), int32(10), "leaf2 priority should include the offset")
+	sorted := root.sortQueues()
+	assert.Equal(t, len(sorted), 2, "both queues should be sorted")
+	assert.Equal(t, sorted[0].QueuePath, "root.leaf2", "offset should move leaf2 first")
+
+	// a negative offset moves the queue behind
+	leaf2.properties = map[string]string{configs.PriorityOffset: "-10"}
+	leaf2.UpdateQueueProperties()
+	leaf2.UpdateApplicationPriority(appID2, 0)
+	sorted = root.sortQueues()
+	assert.Equal(t, sorted[0].QueuePath, "root.leaf1", "negative offset should move leaf2 last")
+}
+
+func TestSortQueuesSchedulingMode(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"first": "100"})
+	assert.NilError(t, err, "queue create failed")
+	var leaf1, leaf2 *Queue
+	leaf1, err = createManagedQueue(root, "leaf1", false, map[string]string{"first": "10"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	leaf2, err = createManagedQueue(root, "leaf2", false, map[string]string{"first": "10"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	// leaf1 has a guarantee above its maximum: fair mode measures against the guarantee, capacity mode against the max
+	leaf1.guaranteedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})
+	leaf1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	leaf2.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 4})
+	pending := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	leaf1.pending = pending
+	leaf2.pending = pending
+
+	sorted := root.sortQueues()
+	assert.Equal(t, len(sorted), 2, "both queues should be sorted")
+	assert.Equal(t, sorted[0].QueuePath, "root.leaf1", "fair mode should sort leaf1 first")
+
+	root.SetSchedulingMode(policies.CapacitySchedulingModePolicy)
+	sorted = root.sortQueues()
+	assert.Equal(t, len(sorted), 2, "both queues should be sorted")
+	assert.Equal(t, sorted[0].QueuePath, "root.leaf2", "capacity mode should sort leaf2 first")
+
+	// the fair share of the users follows the mode
+	leaf3, err := createManagedQueueGuaranteed(root, "leaf3", false, map[string]string{"first": "10"}, map[string]string{"first": "4"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	leaf3.userAllocated = map[string]*resources.Resource{"user1": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})}
+	shares := leaf3.GetUserShares(nil)
+	assert.Equal(t, shares["user1"].FairShare.Resources["first"], resources.Quantity(4), "capacity mode fair share should be the guarantee")
+	root.SetSchedulingMode(policies.FairSchedulingModePolicy)
+	shares = leaf3.GetUserShares(nil)
+	assert.Equal(t, shares["user1"].FairShare.Resources["first"], resources.Quantity(10), "fair mode fair share should be the fair max")
+}
+
+func TestTryAllocateAttemptBudget(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	iterator := getNodeIteratorFn(node)
+	getNode := func(nodeID string) *Node {
+		if nodeID == nodeID1 {
+			return node
+		}
+		return nil
+	}
+
+	root, err := createRootQueue(map[string]string{"first": "100"})
+	assert.NilError(t, err, "failed to create root queue")
+	leaf1, err := createManagedQueueWithProps(root, "leaf1", false, nil, map[string]string{configs.AppAttemptBudget: "1"})
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	assert.Equal(t, leaf1.getAttemptBudget(), uint64(1), "attempt budget property not set")
+	leaf2, err := createManagedQueue(root, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf2 queue")
+
+	// app-1 is larger than the node, app-2 fits
+	app1 := newApplication(appID1, "default", "root.leaf1")
+	app1.SetQueue(leaf1)
+	leaf1.AddApplication(app1)
+	err = app1.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	app2 := newApplication(appID2, "default", "root.leaf1")
+	app2.SetQueue(leaf1)
+	leaf1.AddApplication(app2)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-2", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	app3 := newApplication(appID3, "default", "root.leaf2")
+	app3.SetQueue(leaf2)
+	leaf2.AddApplication(app3)
+	err = app3.AddAllocationAsk(newAllocationAsk("alloc-3", appID3, res))
+	assert.NilError(t, err, "failed to add ask to app-3")
+
+	// the budget is used by app-1: leaf1 yields and the sibling is allocated
+	result := root.TryAllocate(iterator, iterator, getNode, false)
+	assert.Assert(t, result != nil, "sibling queue should have been allocated")
+	assert.Equal(t, result.Request.GetApplicationID(), appID3, "budget should stop leaf1 after one application")
+
+	// next cycle continues after app-1: app-2 is evaluated even though app-1 still fails
+	result = root.TryAllocate(iterator, iterator, getNode, false)
+	assert.Assert(t, result != nil, "app-2 should have been allocated")
+	assert.Equal(t, result.Request.GetApplicationID(), appID2, "budget should rotate to app-2")
+
+	// app-1 is evaluated again once all applications had their turn
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-4", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	assert.Assert(t, root.TryAllocate(iterator, iterator, getNode, false) == nil, "app-1 should use the budget")
+	result = root.TryAllocate(iterator, iterator, getNode, false)
+	assert.Assert(t, result != nil, "app-2 should have been allocated")
+	assert.Equal(t, result.Request.GetApplicationID(), appID2, "budget should rotate to app-2")
+
+	// without a budget all applications are evaluated
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-5", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	leaf1.attemptBudget = 0
+	result = root.TryAllocate(iterator, iterator, getNode, false)
+	assert.Assert(t, result != nil, "app-2 should have been allocated without a budget")
+	assert.Equal(t, result.Request.GetApplicationID(), appID2, "wrong application allocated")
+}
+
+func TestTryAllocateAttemptBudgetFIFO(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	iterator := getNodeIteratorFn(node)
+	getNode := func(nodeID string) *Node {
+		if nodeID == nodeID1 {
+			return node
+		}
+		return nil
+	}
+	root, err := createRootQueue(map[string]string{"first": "100"})
+	assert.NilError(t, err, "failed to create root queue")
+	props := map[string]string{configs.AppAttemptBudget: "2", configs.ApplicationSortPolicy: "fifo"}
+	leaf, err := createManagedQueueWithProps(root, "leaf", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	// app-1 is larger than the node, app-2 and app-3 fit
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	asks := map[string][]string{appID1: nil, appID2: {"alloc-2", "alloc-3"}, appID3: {"alloc-4"}}
+	for i, appID := range []string{appID1, appID2, appID3} {
+		app := newApplication(appID, "default", "root.leaf")
+		app.SubmissionTime = app.SubmissionTime.Add(time.Duration(i) * time.Second)
+		app.SetQueue(leaf)
+		leaf.AddApplication(app)
+		if appID == appID1 {
+			err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})))
+			assert.NilError(t, err, "failed to add ask to %s", appID)
+		}
+		for _, key := range asks[appID] {
+			err = app.AddAllocationAsk(newAllocationAsk(key, appID, res))
+			assert.NilError(t, err, "failed to add ask %s to %s", key, appID)
+		}
+	}
+
+	// an allocation within the budget keeps the FIFO order: app-2 is served until it has no pending requests
+	for _, expected := range []string{appID2, appID2, appID3} {
+		result := root.TryAllocate(iterator, iterator, getNode, false)
+		assert.Assert(t, result != nil, "allocation expected for %s", expected)
+		assert.Equal(t, result.Request.GetApplicationID(), expected, "FIFO order not kept with a budget")
+	}
+}
+
+func TestPreemptionCooldownMockClock(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	// no cooldown set: starting the cooldown has no effect
+	leaf.startPreemptionCooldown()
+	assert.Assert(t, !leaf.isInPreemptionCooldown(), "queue should not be in cooldown without a cooldown set")
+
+	root.SetPreemptionCooldown(time.Minute)
+	leaf.startPreemptionCooldown()
+	assert.Assert(t, leaf.isInPreemptionCooldown(), "queue should be in cooldown after start")
+	mockClock.Advance(59 * time.Second)
+	assert.Assert(t, leaf.isInPreemptionCooldown(), "queue should be in cooldown before it passes")
+	mockClock.Advance(time.Second)
+	assert.Assert(t, !leaf.isInPreemptionCooldown(), "queue should not be in cooldown after it passes")
+
+	// preempted users are forgotten after the preempted user memory
+	leaf.recordUserPreempted("user1")
+	mockClock.Advance(preemptedUserMemory)
+	assert.DeepEqual(t, leaf.getRecentlyPreemptedUsers(), map[string]bool{"user1": true})
+	mockClock.Advance(time.Nanosecond)
+	assert.Assert(t, leaf.getRecentlyPreemptedUsers() == nil, "preempted user should have been forgotten")
+}
+
+func TestCheckSubmitAccessDenialCache(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	user := security.UserGroup{User: "testuser", Groups: []string{"testgroup"}}
+	denials := leaf.getACLDenials()
+	assert.Equal(t, denials, root.aclDenials, "cache should be kept on the root")
+
+	// the denial is recorded for the queue and user
+	assert.Assert(t, !leaf.CheckSubmitAccess(user), "no ACL should deny access")
+	assert.Equal(t, denials.size(), 1, "denial should be cached")
+	assert.Assert(t, denials.isDenied("root.leaf", user), "denial should be cached for the queue and user")
+	assert.Assert(t, !denials.isDenied("root", user), "denial should not be cached for another queue")
+	assert.Assert(t, !denials.isDenied("root.leaf", security.UserGroup{User: "other", Groups: user.Groups}), "denial should not be cached for another user")
+	assert.Assert(t, !denials.isDenied("root.leaf", security.UserGroup{User: "testuser", Groups: []string{"othergroup"}}), "denial should not be cached for other groups")
+	// the groups are sorted in the key
+	multi := security.UserGroup{User: "testuser", Groups: []string{"b", "a"}}
+	denials.add("root.leaf", multi)
+	assert.Assert(t, denials.isDenied("root.leaf", security.UserGroup{User: "testuser", Groups: []string{"a", "b"}}), "group order should not matter")
+	assert.DeepEqual(t, multi.Groups, []string{"b", "a"})
+	denials.clear()
+	assert.Assert(t, !leaf.CheckSubmitAccess(user), "no ACL should deny access")
+
+	// a repeated denial hits the cache: the ACLs are not evaluated, changing the ACL directly has no effect
+	leaf.submitACL, err = security.NewACL("testuser", false)
+	assert.NilError(t, err, "failed to set ACL")
+	assert.Assert(t, !leaf.CheckSubmitAccess(user), "cached denial should deny access")
+	assert.Equal(t, denials.size(), 1, "repeated denial should not add an entry")
+
+	// the denial expires after the TTL
+	mockClock.Advance(aclDenialTTL)
+	assert.Assert(t, leaf.CheckSubmitAccess(user), "expired denial should evaluate the ACL")
+	assert.Equal(t, denials.size(), 0, "expired denial should be removed")
+
+	// an ACL change through the config clears the cache
+	err = leaf.ApplyConf(configs.QueueConfig{Name: "leaf"})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Assert(t, !leaf.CheckSubmitAccess(user), "no ACL should deny access")
+	assert.Equal(t, denials.size(), 1, "denial should be cached")
+	err = leaf.ApplyConf(configs.QueueConfig{Name: "leaf", SubmitACL: "testuser"})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Equal(t, denials.size(), 0, "config change should clear the cache")
+	assert.Assert(t, leaf.CheckSubmitAccess(user), "new ACL should allow access")
+
+	// a change on the parent or the partition default clears the cache for the whole hierarchy
+	other := security.UserGroup{User: "other", Groups: []string{"testgroup"}}
+	assert.Assert(t, !leaf.CheckSubmitAccess(other), "other user should be denied")
+	assert.Equal(t, denials.size(), 1, "denial should be cached")
+	err = root.ApplyConf(configs.QueueConfig{Name: "root", Parent: true, SubmitACL: "other"})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Equal(t, denials.size(), 0, "parent config change should clear the cache")
+	assert.Assert(t, leaf.CheckSubmitAccess(other), "parent ACL should allow access")
+	err = root.ApplyConf(configs.QueueConfig{Name: "root", Parent: true})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Assert(t, !leaf.CheckSubmitAccess(other), "other user should be denied")
+	var acl security.ACL
+	acl, err = security.NewACL("other", false)
+	assert.NilError(t, err, "failed to create ACL")
+	root.SetDefaultSubmitACL(acl)
+	assert.Equal(t, denials.size(), 0, "default ACL change should clear the cache")
+	assert.Assert(t, leaf.CheckSubmitAccess(other), "default ACL should allow access")
+}
+
+func TestGetBurstDemand(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 5})
+
+	// no quota: no demand
+	demand, ok := leaf.GetBurstDemand(res)
+	assert.Assert(t, ok, "no quota should always fit")
+	assert.Assert(t, resources.IsZero(demand), "no quota should have no demand")
+
+	// usage up to the quota has no demand, above the quota up to the burst has
+	leaf.setQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20}))
+	demand, ok = leaf.GetBurstDemand(res)
+	assert.Assert(t, ok, "usage within quota should fit")
+	assert.Assert(t, resources.IsZero(demand), "usage within quota should have no demand")
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	demand, ok = leaf.GetBurstDemand(res)
+	assert.Assert(t, ok, "usage within burst should fit")
+	assert.Assert(t, resources.Equals(demand, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3})), "only the part above quota is a demand")
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 12})
+	demand, ok = leaf.GetBurstDemand(res)
+	assert.Assert(t, ok, "usage within burst should fit")
+	assert.Assert(t, resources.Equals(demand, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})), "the whole allocation above quota is a demand")
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 16})
+	_, ok = leaf.GetBurstDemand(res)
+	assert.Assert(t, !ok, "usage above burst should not fit")
+
+	// no burst: the quota is the limit
+	leaf.setQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}), nil)
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	_, ok = leaf.GetBurstDemand(res)
+	assert.Assert(t, !ok, "usage above quota without burst should not fit")
+
+	// the demand of the parents is added
+	leaf.setQuota(nil, nil)
+	parent.setQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"second": 2}), resources.NewResourceFromMap(map[string]resources.Quantity{"second": 10}))
+	demand, ok = leaf.GetBurstDemand(res)
+	assert.Assert(t, ok, "usage within parent burst should fit")
+	assert.Assert(t, resources.Equals(demand, resources.NewResourceFromMap(map[string]resources.Quantity{"second": 3})), "parent demand should be included")
+}
+
+func TestPreemptionQuota(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+
+	// no quota: not limited
+	assert.Assert(t, leaf.GetPreemptionQuota() == nil, "no quota expected by default")
+	for i := 0; i < 5; i++ {
+		assert.Assert(t, leaf.usePreemptionQuota(res), "preemption without a quota should not be limited")
+	}
+
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+	root.SetPreemptionQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8}), 0)
+	assert.Assert(t, resources.Equals(leaf.GetPreemptionQuota(), root.GetPreemptionQuota()), "quota should be read from the root")
+	assert.Equal(t, leaf.GetPreemptionQuotaWindow(), defaultPreemptionQuotaWindow, "default window should be set")
+	// the first preemption in a window is always allowed, even if it is larger than the quota
+	large := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	assert.Assert(t, leaf.usePreemptionQuota(large), "first preemption in a window should always be allowed")
+	assert.Assert(t, !leaf.usePreemptionQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})), "quota should be exhausted")
+	mockClock.Advance(defaultPreemptionQuotaWindow)
+	assert.Assert(t, leaf.usePreemptionQuota(res), "first preemption within the quota should be allowed")
+	assert.Assert(t, !leaf.usePreemptionQuota(res), "preemption over the quota should be deferred")
+	assert.Assert(t, leaf.usePreemptionQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3})), "preemption up to the quota should be allowed")
+	assert.Assert(t, leaf.usePreemptionQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"second": 3})), "types not in the quota should not be limited")
+
+	// the quota is only restored when the preemptions leave the window, not on a new scheduling cycle
+	mockClock.Advance(defaultPreemptionQuotaWindow / 2)
+	assert.Assert(t, !leaf.usePreemptionQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})), "quota should be exhausted within the window")
+	mockClock.Advance(defaultPreemptionQuotaWindow / 2)
+	assert.Assert(t, leaf.usePreemptionQuota(res), "preemption should be allowed once earlier preemptions left the window")
+	assert.Assert(t, !leaf.usePreemptionQuota(res), "preemption over the quota should be deferred in the new window")
+
+	// a shorter window frees the quota earlier
+	root.SetPreemptionQuota(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8}), time.Second)
+	assert.Equal(t, leaf.GetPreemptionQuotaWindow(), time.Second, "window should be set")
+	mockClock.Advance(time.Second)
+	assert.Assert(t, leaf.usePreemptionQuota(res), "preemption should be allowed after the shorter window")
+
+	// zero quota removes the limit
+	root.SetPreemptionQuota(resources.NewResource(), 0)
+	assert.Assert(t, leaf.GetPreemptionQuota() == nil, "zero quota should remove the limit")
+	assert.Assert(t, leaf.usePreemptionQuota(large), "preemption without a quota should not be limited")
+}
+
+func TestQueueInfo(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	info := configs.QueueInfo{Owner: "alice", Team: "data-platform", Description: "batch jobs"}
+	conf := configs.QueueConfig{Name: "leaf", Info: info}
+	var leaf *Queue
+	leaf, err = NewConfiguredQueue(conf, root, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.DeepEqual(t, leaf.GetInfo(), info)
+	daoInfo := leaf.GetPartitionQueueDAOInfo(false)
+	assert.Equal(t, daoInfo.Owner, "alice", "owner not exposed")
+	assert.Equal(t, daoInfo.Team, "data-platform", "team not exposed")
+	assert.Equal(t, daoInfo.Description, "batch jobs", "description not exposed")
+
+	// the config update replaces the metadata
+	conf.Info = configs.QueueInfo{Owner: "bob"}
+	err = leaf.ApplyConf(conf)
+	assert.NilError(t, err, "failed to apply conf")
+	assert.DeepEqual(t, leaf.GetInfo(), configs.QueueInfo{Owner: "bob"})
+	daoInfo = leaf.GetPartitionQueueDAOInfo(false)
+	assert.Equal(t, daoInfo.Team, "", "team should be removed")
+
+	// dynamic queues have no metadata
+	var dynamic *Queue
+	dynamic, err = NewDynamicQueue("dynamic", true, root)
+	assert.NilError(t, err, "failed to create dynamic queue")
+	assert.DeepEqual(t, dynamic.GetInfo(), configs.QueueInfo{})
+}
+
+func TestQueueTags(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	conf := configs.QueueConfig{Name: "leaf", Tags: map[string]string{"team": "data", "tier": "gold"}}
+	var leaf *Queue
+	leaf, err = NewConfiguredQueue(conf, root, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.DeepEqual(t, leaf.GetTags(), map[string]string{"team": "data", "tier": "gold"})
+	assert.Equal(t, leaf.GetTag("team"), "data", "unexpected tag value")
+	assert.Equal(t, leaf.GetTag("unknown"), "", "unset tag should be empty")
+	assert.Assert(t, leaf.HasTags(map[string]string{"team": "data"}), "queue should carry the tag")
+	assert.Assert(t, !leaf.HasTags(map[string]string{"team": "web"}), "tag value should not match")
+	assert.Assert(t, !leaf.HasTags(map[string]string{"owner": "data"}), "tag key should not match")
+	daoInfo := leaf.GetPartitionQueueDAOInfo(false)
+	assert.DeepEqual(t, daoInfo.Tags, map[string]string{"team": "data", "tier": "gold"})
+
+	// the returned tags are a copy
+	tags := leaf.GetTags()
+	tags["team"] = "web"
+	assert.Equal(t, leaf.GetTag("team"), "data", "tags of the queue should not change")
+	// the config update replaces the tags
+	conf.Tags = map[string]string{"tier": "silver"}
+	err = leaf.ApplyConf(conf)
+	assert.NilError(t, err, "failed to apply conf")
+	assert.DeepEqual(t, leaf.GetTags(), map[string]string{"tier": "silver"})
+	conf.Tags = nil
+	err = leaf.ApplyConf(conf)
+	assert.NilError(t, err, "failed to apply conf")
+	assert.Equal(t, len(leaf.GetTags()), 0, "tags should be removed")
+	assert.Assert(t, leaf.GetPartitionQueueDAOInfo(false).Tags == nil, "empty tags should not be exposed")
+}
+
+func TestShareFloorInterval(t *testing.T) {
+	tests := []struct {
+		value    string
+		interval uint64
+		wantErr  bool
+	}{
+		{"1", 1, false},
+		{"0.5", 2, false},
+		{"0.1", 10, false},
+		{"0.3", 4, false},
+		{"0", 0, true},
+		{"1.5", 0, true},
+		{"-0.5", 0, true},
+		{"invalid", 0, true},
+	}
+	for _, tt := range tests {
+		interval, err := shareFloorInterval(tt.value)
+		assert.Equal(t, err != nil, tt.wantErr, "unexpected error result for %s: %v", tt.value, err)
+		assert.Equal(t, interval, tt.interval, "unexpected interval for %s", tt.value)
+	}
+}
+
+func TestApplyShareFloor(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var first, second, tiny *Queue
+	first, err = createManagedQueue(root, "first", false, nil)
+	assert.NilError(t, err, "failed to create queue")
+	second, err = createManagedQueue(root, "second", false, nil)
+	assert.NilError(t, err, "failed to create queue")
+	tiny, err = createManagedQueueWithProps(root, "tiny", false, nil, map[string]string{configs.FairShareFloor: "0.25"})
+	assert.NilError(t, err, "failed to create queue")
+	assert.Equal(t, tiny.floorInterval, uint64(4), "floor interval not set from the property")
+
+	// the tiny queue is moved to the front once in every four sorts, the order of the others is kept
+	for i := 1; i <= 8; i++ {
+		sorted := []*Queue{first, second, tiny}
+		applyShareFloor(sorted)
+		if i%4 == 0 {
+			assert.DeepEqual(t, []string{sorted[0].Name, sorted[1].Name, sorted[2].Name}, []string{"tiny", "first", "second"})
+		} else {
+			assert.DeepEqual(t, []string{sorted[0].Name, sorted[1].Name, sorted[2].Name}, []string{"first", "second", "tiny"})
+		}
+	}
+
+	// first on its own merit resets the tracking
+	applyShareFloor([]*Queue{first, tiny})
+	applyShareFloor([]*Queue{tiny, first})
+	assert.Equal(t, tiny.floorPassed, uint64(0), "tracking should be reset when the queue is first")
+	// queues without a floor are never moved
+	for i := 0; i < 10; i++ {
+		sorted := []*Queue{first, second}
+		applyShareFloor(sorted)
+		assert.Equal(t, sorted[0], first, "queue without a floor should not be moved")
+	}
+}
+
+func TestGuaranteedPercentage(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	root.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000, "vcore": 10000}))
+	parentConf := configs.QueueConfig{
+		Name:      "parent",
+		Parent:    true,
+		Resources: configs.Resources{Guaranteed: map[string]string{"memory": "50%"}},
+	}
+	var parent, leaf *Queue
+	parent, err = NewConfiguredQueue(parentConf, root, false)
+	assert.NilError(t, err, "failed to create parent queue")
+	leafConf := configs.QueueConfig{
+		Name:      "leaf",
+		Resources: configs.Resources{Guaranteed: map[string]string{"memory": "50%", "vcore": "10%", "pods": "5"}},
+	}
+	leaf, err = NewConfiguredQueue(leafConf, parent, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, resources.Equals(parent.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 500})), "parent guaranteed not resolved against the root: %s", parent.GetGuaranteedResource())
+	// memory from the parent guaranteed, vcore from the parent max as the parent has no guaranteed vcore
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 250, "vcore": 1000, "pods": 5})), "leaf guaranteed not resolved against the parent: %s", leaf.GetGuaranteedResource())
+
+	// a change of the cluster size is passed down
+	root.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 2000, "vcore": 20000}))
+	assert.Assert(t, resources.Equals(parent.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000})), "parent guaranteed not updated: %s", parent.GetGuaranteedResource())
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 500, "vcore": 2000, "pods": 5})), "leaf guaranteed not updated: %s", leaf.GetGuaranteedResource())
+
+	// a change of the parent config is passed down
+	parentConf.Resources.Guaranteed = map[string]string{"memory": "800"}
+	err = parent.ApplyConf(parentConf)
+	assert.NilError(t, err, "failed to apply parent conf")
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 400, "vcore": 2000, "pods": 5})), "leaf guaranteed not updated after parent change: %s", leaf.GetGuaranteedResource())
+
+	// a parent without the type does not set the type on the child
+	parentConf = configs.QueueConfig{Name: "other", Parent: true}
+	var other *Queue
+	other, err = NewConfiguredQueue(parentConf, root, false)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = NewConfiguredQueue(configs.QueueConfig{Name: "leaf", Resources: configs.Resources{Guaranteed: map[string]string{"gpu": "50%"}}}, other, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, leaf.GetGuaranteedResource() == nil, "guaranteed should not be set for a type the parent does not have")
+
+	// the resolved guaranteed is capped at the maximum of the queue
+	leafConf = configs.QueueConfig{
+		Name:      "capped",
+		Resources: configs.Resources{Guaranteed: map[string]string{"memory": "50%"}, Max: map[string]string{"memory": "300"}},
+	}
+	leaf, err = NewConfiguredQueue(leafConf, parent, false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300})), "leaf guaranteed not capped at the max: %s", leaf.GetGuaranteedResource())
+}
+
+func TestNodeIteratorRankResource(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	total := map[string]resources.Quantity{"memory": 100, "vcore": 100}
+	// node1 has little memory left, node2 has few vcores left
+	node1 := newNode(nodeID1, total)
+	node1.AddAllocation(newAllocation(appID1, nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 80, "vcore": 10})))
+	node2 := newNode(nodeID2, total)
+	node2.AddAllocation(newAllocation(appID1, nodeID2, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 70})))
+	iterator := getNodeIteratorFn(node1, node2)
+
+	tests := []struct {
+		name     string
+		props    map[string]string
+		expected []string
+	}{
+		{"no override", nil, []string{nodeID1, nodeID2}},
+		{"least leftover memory", map[string]string{configs.NodeSelectionResource: "memory"}, []string{nodeID1, nodeID2}},
+		{"least leftover vcore", map[string]string{configs.NodeSelectionResource: "vcore"}, []string{nodeID2, nodeID1}},
+		{"spread on memory", map[string]string{configs.NodeSelectionResource: "memory", configs.NodeSelectionPolicy: "spread"}, []string{nodeID2, nodeID1}},
+		{"spread on vcore", map[string]string{configs.NodeSelectionResource: "vcore", configs.NodeSelectionPolicy: "spread"}, []string{nodeID1, nodeID2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var leaf *Queue
+			leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, tt.props)
+			assert.NilError(t, err, "failed to create leaf queue")
+			nodes := make([]string, 0, 2)
+			leaf.nodeIterator(iterator)().ForEachNode(func(node *Node) bool {
+				nodes = append(nodes, node.NodeID)
+				return true
+			})
+			assert.DeepEqual(t, nodes, tt.expected)
+		})
+	}
+}
+
+func TestNodeIteratorPartitionPolicy(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	total := map[string]resources.Quantity{"memory": 100, "vcore": 100}
+	// node1 has little memory left, node2 has few vcores left
+	node1 := newNode(nodeID1, total)
+	node1.AddAllocation(newAllocation(appID1, nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 80, "vcore": 10})))
+	node2 := newNode(nodeID2, total)
+	node2.AddAllocation(newAllocation(appID1, nodeID2, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 70})))
+	iterator := getNodeIteratorFn(node1, node2)
+	order := func(it NodeIterator) []string {
+		nodes := make([]string, 0, 2)
+		it.ForEachNode(func(node *Node) bool {
+			nodes = append(nodes, node.NodeID)
+			return true
+		})
+		return nodes
+	}
+	// only vcore is weighted by the partition
+	root.SetNodeSortingPolicy(NewNodeSortingPolicy(policies.BinPackingPolicy.String(), map[string]float64{"vcore": 1.0}))
+	var leaf *Queue
+	// the partition uses the same policy: the partition order is used
+	leaf, err = createManagedQueueWithProps(root, "binpack", false, nil, map[string]string{configs.NodeSelectionPolicy: "binpack"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.DeepEqual(t, order(leaf.nodeIterator(iterator)()), []string{nodeID1, nodeID2})
+
+	// spread prefers the node with the most vcores left
+	leaf, err = createManagedQueueWithProps(root, "spread", false, nil, map[string]string{configs.NodeSelectionPolicy: "spread"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	cycle := leaf.nodeIterator(iterator)
+	assert.DeepEqual(t, order(cycle()), []string{nodeID1, nodeID2})
+	// the order is not changed within the cycle
+	node1.AddAllocation(newAllocation(appID1, nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 80})))
+	assert.DeepEqual(t, order(cycle()), []string{nodeID1, nodeID2})
+	assert.DeepEqual(t, order(leaf.nodeIterator(iterator)()), []string{nodeID2, nodeID1})
+}
+
+func TestGetEffectiveWeight(t *testing.T) {
+	mockClock := NewMockClock(time.Now())
+	defer SetClock(SetClock(mockClock))
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var leaf, plain *Queue
+	leaf, err = createManagedQueuePropsMaxApps(root, "leaf", false, nil, map[string]string{"first": "4"}, map[string]string{configs.SchedulingWeight: "3", configs.WeightHalfLife: "10m"}, 0)
+	assert.NilError(t, err, "failed to create leaf queue")
+	plain, err = createManagedQueue(root, "plain", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, plain.GetEffectiveWeight(), float64(1), "queue without weight should have the baseline weight")
+
+	// at its share the weight does not decay
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 4})
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(3), "queue at its share should have the full weight")
+	mockClock.Advance(time.Hour)
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(3), "queue at its share should have the full weight")
+
+	// sustained over share decays the weight toward the baseline
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(3), "weight should not decay at the start of the over share")
+	mockClock.Advance(10 * time.Minute)
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(2), "weight should have decayed one half life")
+	mockClock.Advance(10 * time.Minute)
+	assert.Equal(t, leaf.GetEffectiveWeight(), 1.5, "weight should have decayed two half lives")
+	mockClock.Advance(10 * time.Hour)
+	weight := leaf.GetEffectiveWeight()
+	assert.Assert(t, weight >= 1 && weight < 1.001, "weight should be close to the baseline: %f", weight)
+
+	// dropping back reverts the weight and restarts the over share period
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(3), "weight should revert when back under share")
+	leaf.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(3), "over share period should restart")
+	mockClock.Advance(10 * time.Minute)
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(2), "weight should have decayed one half life")
+
+	// without a half life the weight does not decay
+	leaf.weightHalfLife = 0
+	mockClock.Advance(time.Hour)
+	assert.Equal(t, leaf.GetEffectiveWeight(), float64(3), "weight should not decay without a half life")
+}
+
+func TestIsAllocationLogged(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var all, invalid, sampled *Queue
+	all, err = createManagedQueue(root, "all", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	invalid, err = createManagedQueueWithProps(root, "invalid", false, nil, map[string]string{configs.AllocationLogSample: "0"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	sampled, err = createManagedQueueWithProps(root, "sampled", false, nil, map[string]string{configs.AllocationLogSample: "10"})
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	const total = 10000
+	logged := 0
+	for i := 0; i < total; i++ {
+		key := fmt.Sprintf("alloc-%d", i)
+		assert.Assert(t, all.IsAllocationLogged(key), "queue without sample should log all allocations")
+		assert.Assert(t, invalid.IsAllocationLogged(key), "queue with an invalid sample should log all allocations")
+		if sampled.IsAllocationLogged(key) {
+			logged++
+			assert.Assert(t, sampled.IsAllocationLogged(key), "decision should be the same for the allocation")
+		}
+	}
+	// roughly one in ten: allow 10% deviation
+	assert.Assert(t, logged > 900 && logged < 1100, "expected roughly %d allocations logged, got %d", total/10, logged)
+}
+
+func TestPromoteToLeaf(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, map[string]string{configs.ApplicationSortPolicy: "fifo"})
+	assert.NilError(t, err, "failed to create parent queue")
+	assert.Equal(t, parent.getSortType(), policies.FairSortPolicy, "parent queue should use fair sorting")
+	_, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	err = parent.PromoteToLeaf()
+	assert.ErrorContains(t, err, "is a parent queue with child queues")
+
+	// promotion applies the leaf only properties
+	parent, err = createManagedQueueWithProps(root, "empty", true, nil, map[string]string{configs.ApplicationSortPolicy: "fifo"})
+	assert.NilError(t, err, "failed to create parent queue")
+	assert.NilError(t, parent.PromoteToLeaf(), "promotion of parent queue without children failed")
+	assert.Assert(t, parent.IsLeafQueue(), "queue should have been promoted")
+	assert.Equal(t, parent.getSortType(), policies.FifoSortPolicy, "promoted queue should use the configured sort policy")
+
+	// a promoted queue with applications stays a leaf on a config update
+	parent.AddApplication(newApplication("app-1", "default", "root.empty"))
+	conf := configs.QueueConfig{Name: "empty", Parent: true}
+	assert.NilError(t, parent.ApplyConf(conf), "failed to apply conf")
+	assert.Assert(t, parent.IsLeafQueue(), "promoted queue with applications should stay a leaf")
+
+	// a leaf queue that was not promoted follows the config
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	leaf.AddApplication(newApplication("app-2", "default", "root.leaf"))
+	conf = configs.QueueConfig{Name: "leaf", Parent: true}
+	assert.NilError(t, leaf.ApplyConf(conf), "failed to apply conf")
+	assert.Assert(t, !leaf.IsLeafQueue(), "configured leaf queue should have been changed into a parent")
+}
@@ -0,0 +1,249 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/yunikorn-core/pkg/common"
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
+	"github.com/apache/yunikorn-core/pkg/scheduler/ugm"
+)
+
+// BlockerReason is the category of the reason an application cannot be scheduled.
+type BlockerReason string
+
+const (
+	BlockerACL            BlockerReason = "ACLWaiting"
+	BlockerDependency     BlockerReason = "DependencyUnmet"
+	BlockerQueueFull      BlockerReason = "QueueFull"
+	BlockerGangIncomplete BlockerReason = "GangIncomplete"
+	BlockerNoFittingNode  BlockerReason = "NoFittingNode"
+)
+
+// SchedulingBlocker explains why the pending requests of an application are not scheduled.
+// LimitingResources lists the resource types that cause the blocker, if the blocker is resource based.
+type SchedulingBlocker struct {
+	Reason            BlockerReason
+	Message           string
+	LimitingResources []string
+}
+
+func (sb *SchedulingBlocker) String() string {
+	if sb == nil {
+		return "not blocked"
+	}
+	return fmt.Sprintf("%s: %s", sb.Reason, sb.Message)
+}
+
+// GetSchedulingBlocker returns the current reason the application cannot be scheduled, computed on demand.
+// The checks follow the order in which the scheduler evaluates the application: queue access, dependencies,
+// queue and user limits, gang placeholders and finally the nodes. The node check is skipped if the iterator is nil.
+// Returns nil if the application has no pending requests or no blocker was found.
+func (sa *Application) GetSchedulingBlocker(iterator NodeIterator) *SchedulingBlocker {
+	pending := sa.getPendingRequests()
+	if len(pending) == 0 {
+		return nil
+	}
+	queue := sa.GetQueue()
+	if queue != nil {
+		if blocker := sa.getQueueBlocker(queue, pending); blocker != nil {
+			return blocker
+		}
+	}
+	if blocker := sa.getGangBlocker(pending); blocker != nil {
+		return blocker
+	}
+	if iterator != nil {
+		return getNodeBlocker(pending, iterator)
+	}
+	return nil
+}
+
+// getPendingRequests returns the requests that are not allocated yet, sorted on the allocation key.
+func (sa *Application) getPendingRequests() []*Allocation {
+	var pending []*Allocation
+	for _, request := range sa.GetAllRequests() {
+		if !request.IsAllocated() {
+			pending = append(pending, request)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].GetAllocationKey() < pending[j].GetAllocationKey()
+	})
+	return pending
+}
+
+// getQueueBlocker checks the blockers that are linked to the queue of the application.
+func (sa *Application) getQueueBlocker(queue *Queue, pending []*Allocation) *SchedulingBlocker {
+	user := sa.GetUser()
+	if !queue.checkSubmitAccess(user) && queue.getACLEnforcement() != policies.AuditACLPolicy {
+		return &SchedulingBlocker{
+			Reason:  BlockerACL,
+			Message: fmt.Sprintf("user %s does not have submit access to queue %s", user.User, queue.QueuePath),
+		}
+	}
+	if waiting := sa.getUnmetDependencies(); len(waiting) != 0 {
+		return &SchedulingBlocker{
+			Reason:  BlockerDependency,
+			Message: fmt.Sprintf("waiting for applications to run: %s", strings.Join(waiting, common.Separator)),
+		}
+	}
+	if sa.IsAccepted() && !queue.canRunApp(sa.ApplicationID) {
+		return &SchedulingBlocker{
+			Reason:  BlockerQueueFull,
+			Message: fmt.Sprintf("maximum number of running applications reached in queue %s", queue.QueuePath),
+		}
+	}
+	if sa.IsAccepted() && !ugm.GetUserManager().CanRunApp(queue.QueuePath, sa.ApplicationID, user) {
+		return &SchedulingBlocker{
+			Reason:  BlockerQueueFull,
+			Message: fmt.Sprintf("maximum number of running applications reached for user %s in queue %s", user.User, queue.QueuePath),
+		}
+	}
+	headRoom := queue.getHeadRoom()
+	if request := firstNotFitting(pending, headRoom); request != nil {
+		limiting := getLimitingResources(request.GetAllocatedResource(), headRoom, false)
+		return &SchedulingBlocker{
+			Reason: BlockerQueueFull,
+			Message: fmt.Sprintf("queue %s does not have enough headroom for request %s: limited by %s",
+				queue.QueuePath, request.GetAllocationKey(), strings.Join(limiting, common.Separator)),
+			LimitingResources: limiting,
+		}
+	}
+	userHeadRoom := ugm.GetUserManager().Headroom(queue.QueuePath, sa.ApplicationID, user)
+	if request := firstNotFitting(pending, userHeadRoom); request != nil {
+		limiting := getLimitingResources(request.GetAllocatedResource(), userHeadRoom, false)
+		return &SchedulingBlocker{
+			Reason: BlockerQueueFull,
+			Message: fmt.Sprintf("user %s quota in queue %s is not enough for request %s: limited by %s",
+				user.User, queue.QueuePath, request.GetAllocationKey(), strings.Join(limiting, common.Separator)),
+			LimitingResources: limiting,
+		}
+	}
+	return nil
+}
+
+// getGangBlocker checks if the application is waiting for the placeholders of the gang to be allocated.
+func (sa *Application) getGangBlocker(pending []*Allocation) *SchedulingBlocker {
+	var waiting int
+	for _, request := range pending {
+		if request.IsPlaceholder() {
+			waiting++
+		}
+	}
+	if waiting == 0 || !(sa.IsNew() || sa.IsAccepted()) {
+		return nil
+	}
+	sa.RLock()
+	allocated := len(sa.getPlaceholderAllocations())
+	sa.RUnlock()
+	return &SchedulingBlocker{
+		Reason:  BlockerGangIncomplete,
+		Message: fmt.Sprintf("gang not complete: %d of %d placeholders allocated", allocated, allocated+waiting),
+	}
+}
+
+// getNodeBlocker checks if any of the pending requests fit on a schedulable node.
+// If no request fits the limiting resources are based on the first request and the node that is closest to fitting
+// the request: the node with the least resource types that are too small.
+func getNodeBlocker(pending []*Allocation, iterator NodeIterator) *SchedulingBlocker {
+	request := pending[0].GetAllocatedResource()
+	var capacityLimits, availableLimits []string
+	fits := false
+	checked := false
+	iterator.ForEachNode(func(node *Node) bool {
+		if !node.canSchedule() {
+			return true
+		}
+		for _, ask := range pending {
+			if node.CanAllocate(ask.GetAllocatedResource()) {
+				fits = true
+				return false
+			}
+		}
+		capacity := getLimitingResources(request, node.GetCapacity(), true)
+		available := getLimitingResources(request, node.GetAvailableResource(), true)
+		if !checked || len(capacity) < len(capacityLimits) {
+			capacityLimits = capacity
+		}
+		if len(capacity) == 0 && (len(availableLimits) == 0 || len(available) < len(availableLimits)) {
+			availableLimits = available
+		}
+		checked = true
+		return true
+	})
+	if fits {
+		return nil
+	}
+	message := "no node is large enough for request %s: limited by %s"
+	limiting := capacityLimits
+	if checked && len(capacityLimits) == 0 {
+		message = "no node has enough available resources for request %s: limited by %s"
+		limiting = availableLimits
+	}
+	if !checked {
+		limiting = getLimitingResources(request, nil, true)
+	}
+	return &SchedulingBlocker{
+		Reason:            BlockerNoFittingNode,
+		Message:           fmt.Sprintf(message, pending[0].GetAllocationKey(), strings.Join(limiting, common.Separator)),
+		LimitingResources: limiting,
+	}
+}
+
+// firstNotFitting returns the first request if none of the requests fit in the limit.
+// A nil limit or a resource type missing from the limit is seen as unlimited.
+func firstNotFitting(pending []*Allocation, limit *resources.Resource) *Allocation {
+	for _, request := range pending {
+		if limit.FitInMaxUndef(request.GetAllocatedResource()) {
+			return nil
+		}
+	}
+	return pending[0]
+}
+
+// getLimitingResources returns the sorted resource types for which the request is larger than the limit.
+// If undefinedIsZero is set a resource type missing from the limit is seen as zero, otherwise as unlimited.
+func getLimitingResources(request, limit *resources.Resource, undefinedIsZero bool) []string {
+	var limiting []string
+	if request == nil {
+		return limiting
+	}
+	for name, quantity := range request.Resources {
+		if quantity <= 0 {
+			continue
+		}
+		available, ok := resources.Quantity(0), false
+		if limit != nil {
+			available, ok = limit.Resources[name]
+		}
+		if !ok && !undefinedIsZero {
+			continue
+		}
+		if quantity > available {
+			limiting = append(limiting, name)
+		}
+	}
+	sort.Strings(limiting)
+	return limiting
+}
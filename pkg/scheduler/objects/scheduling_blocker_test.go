@@ -0,0 +1,180 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common"
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+)
+
+// create an application in a leaf queue below a root queue that allows everyone to submit
+func newBlockerApp(t *testing.T, maxRes map[string]string, maxApps uint64, tags map[string]string) (*Application, *Queue) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	root.submitACL, err = security.NewACL("*", false)
+	assert.NilError(t, err, "failed to set ACL")
+	var leaf *Queue
+	leaf, err = createManagedQueueMaxApps(root, "leaf", false, maxRes, maxApps)
+	assert.NilError(t, err, "failed to create leaf queue")
+	app := newApplicationWithTags(appID1, "default", "root.leaf", tags)
+	app.SetQueue(leaf)
+	leaf.AddApplication(app)
+	return app, leaf
+}
+
+func TestGetSchedulingBlockerNone(t *testing.T) {
+	app, _ := newBlockerApp(t, nil, 0, nil)
+	assert.Assert(t, app.GetSchedulingBlocker(nil) == nil, "no pending requests should not be blocked")
+	assert.Equal(t, app.GetSchedulingBlocker(nil).String(), "not blocked")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5})
+	err := app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+	node := newNode(nodeID1, map[string]resources.Quantity{"vcore": 10})
+	assert.Assert(t, app.GetSchedulingBlocker(getNodeIteratorFn(node)()) == nil, "request fits: should not be blocked")
+}
+
+func TestGetSchedulingBlockerACL(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	app := newApplication(appID1, "default", "root.leaf")
+	app.SetQueue(leaf)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5})
+	err = app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+
+	blocker := app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "app without access should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerACL)
+	assert.Equal(t, blocker.Message, "user testuser does not have submit access to queue root.leaf")
+	assert.Equal(t, blocker.String(), "ACLWaiting: user testuser does not have submit access to queue root.leaf")
+}
+
+func TestGetSchedulingBlockerDependency(t *testing.T) {
+	app, leaf := newBlockerApp(t, nil, 0, map[string]string{common.AppTagDependsOn: "app-2,app-3"})
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5})
+	err := app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+
+	blocker := app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "app with unmet dependencies should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerDependency)
+	assert.Equal(t, blocker.Message, "waiting for applications to run: app-2,app-3")
+
+	// one running dependency: only the other one is listed
+	app2 := newApplication("app-2", "default", "root.leaf")
+	app2.SetState(Running.String())
+	leaf.AddApplication(app2)
+	app.SetDependencyCallback(func(appID string) bool {
+		return appID == app2.ApplicationID && app2.HasRun()
+	})
+	blocker = app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "app with unmet dependencies should be blocked")
+	assert.Equal(t, blocker.Message, "waiting for applications to run: app-3")
+}
+
+func TestGetSchedulingBlockerQueueFull(t *testing.T) {
+	// the queue does not have enough headroom
+	app, leaf := newBlockerApp(t, map[string]string{"vcore": "10", "memory": "10"}, 1, nil)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5, "memory": 20, "gpu": 1})
+	err := app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+	blocker := app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "request larger than the headroom should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerQueueFull)
+	assert.DeepEqual(t, blocker.LimitingResources, []string{"memory"})
+	assert.Equal(t, blocker.Message, "queue root.leaf does not have enough headroom for request alloc-1: limited by memory")
+
+	// a second request that fits removes the blocker
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Assert(t, app.GetSchedulingBlocker(nil) == nil, "request fits: should not be blocked")
+
+	// the queue has reached the maximum number of running applications
+	assert.Assert(t, app.IsAccepted(), "app should be accepted after adding an ask")
+	leaf.incRunningApps("")
+	blocker = app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "app over the running limit should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerQueueFull)
+	assert.Equal(t, blocker.Message, "maximum number of running applications reached in queue root.leaf")
+	assert.Equal(t, len(blocker.LimitingResources), 0, "running limit should not have limiting resources")
+}
+
+func TestGetSchedulingBlockerGang(t *testing.T) {
+	app, _ := newBlockerApp(t, nil, 0, nil)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	for _, key := range []string{"ph-1", "ph-2", "ph-3"} {
+		err := app.AddAllocationAsk(newAllocationAskAll(key, appID1, "tg-1", res, true, 0))
+		assert.NilError(t, err, "placeholder ask should have been added to app")
+	}
+	blocker := app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "app with pending placeholders should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerGangIncomplete)
+	assert.Equal(t, blocker.Message, "gang not complete: 0 of 3 placeholders allocated")
+
+	ph := newAllocationAll("ph-0", appID1, nodeID1, "tg-1", res, true, 0)
+	app.AddAllocation(ph)
+	blocker = app.GetSchedulingBlocker(nil)
+	assert.Assert(t, blocker != nil, "app with pending placeholders should be blocked")
+	assert.Equal(t, blocker.Message, "gang not complete: 1 of 4 placeholders allocated")
+}
+
+func TestGetSchedulingBlockerNoFittingNode(t *testing.T) {
+	app, _ := newBlockerApp(t, nil, 0, nil)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5, "memory": 5, "gpu": 1})
+	err := app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+
+	// no node is large enough
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"vcore": 4, "memory": 10})
+	node2 := newNode(nodeID2, map[string]resources.Quantity{"vcore": 3, "memory": 10})
+	blocker := app.GetSchedulingBlocker(getNodeIteratorFn(node1, node2)())
+	assert.Assert(t, blocker != nil, "request larger than all nodes should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerNoFittingNode)
+	assert.DeepEqual(t, blocker.LimitingResources, []string{"gpu", "vcore"})
+	assert.Equal(t, blocker.Message, "no node is large enough for request alloc-1: limited by gpu,vcore")
+
+	// nodes are large enough but do not have enough resources available
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10, "memory": 10, "gpu": 1})
+	node1 = newNodeInternal(nodeID1, total, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 8}))
+	node2 = newNodeInternal(nodeID2, total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 8}))
+	blocker = app.GetSchedulingBlocker(getNodeIteratorFn(node1, node2)())
+	assert.Assert(t, blocker != nil, "request larger than the available resources should be blocked")
+	assert.Equal(t, blocker.Reason, BlockerNoFittingNode)
+	assert.DeepEqual(t, blocker.LimitingResources, []string{"vcore"})
+	assert.Equal(t, blocker.Message, "no node has enough available resources for request alloc-1: limited by vcore")
+
+	// unschedulable nodes are ignored
+	node3 := newNode("node-3", map[string]resources.Quantity{"vcore": 10, "memory": 10, "gpu": 1})
+	node3.SetSchedulable(false)
+	blocker = app.GetSchedulingBlocker(getNodeIteratorFn(node3)())
+	assert.Assert(t, blocker != nil, "unschedulable node should not be used")
+	assert.DeepEqual(t, blocker.LimitingResources, []string{"gpu", "memory", "vcore"})
+	node3.SetSchedulable(true)
+	assert.Assert(t, app.GetSchedulingBlocker(getNodeIteratorFn(node3)()) == nil, "request fits: should not be blocked")
+}
@@ -0,0 +1,135 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"sort"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+const (
+	SimulationQueueNotFound = "Queue not found"
+	SimulationNotLeafQueue  = "Queue is not a leaf queue"
+	SimulationNoNodeFits    = "No node has enough available resources"
+)
+
+// SimulationAsk is an ask used in an allocation simulation: the resource requested in a fully qualified queue.
+type SimulationAsk struct {
+	AllocationKey string
+	QueuePath     string
+	Resource      *resources.Resource
+}
+
+// SimulationResult is the outcome of the simulation for an ask. The node is set if the ask would be placed, the
+// reason if the ask would not be placed.
+type SimulationResult struct {
+	AllocationKey string
+	QueuePath     string
+	NodeID        string
+	Reason        string
+}
+
+// simQueue is the clone of a queue used in a simulation.
+type simQueue struct {
+	parent      *simQueue
+	leaf        bool
+	maxResource *resources.Resource
+	allocated   *resources.Resource
+}
+
+// simNode is the clone of a node used in a simulation.
+type simNode struct {
+	nodeID    string
+	available *resources.Resource
+}
+
+// SimulateAllocations places the asks, in the order passed in, on a clone of the queue tree and the schedulable nodes
+// and returns the result for each ask. An ask that is placed is added to the clone and is taken into account for the
+// asks that follow. Nodes are tried in node ID order. The queues, nodes and applications are not changed.
+func SimulateAllocations(root *Queue, nodes []*Node, asks []*SimulationAsk) []*SimulationResult {
+	queues := make(map[string]*simQueue)
+	cloneQueueTree(root, nil, queues)
+	simNodes := make([]*simNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.canSchedule() {
+			simNodes = append(simNodes, &simNode{nodeID: node.NodeID, available: node.GetAvailableResource()})
+		}
+	}
+	sort.Slice(simNodes, func(i, j int) bool {
+		return simNodes[i].nodeID < simNodes[j].nodeID
+	})
+	results := make([]*SimulationResult, 0, len(asks))
+	for _, ask := range asks {
+		results = append(results, simulateAsk(ask, queues, simNodes))
+	}
+	return results
+}
+
+// simulateAsk places a single ask on the cloned queues and nodes.
+func simulateAsk(ask *SimulationAsk, queues map[string]*simQueue, nodes []*simNode) *SimulationResult {
+	result := &SimulationResult{
+		AllocationKey: ask.AllocationKey,
+		QueuePath:     ask.QueuePath,
+	}
+	queue, ok := queues[ask.QueuePath]
+	if !ok {
+		result.Reason = SimulationQueueNotFound
+		return result
+	}
+	if !queue.leaf {
+		result.Reason = SimulationNotLeafQueue
+		return result
+	}
+	for current := queue; current != nil; current = current.parent {
+		if !current.maxResource.FitInMaxUndef(resources.Add(current.allocated, ask.Resource)) {
+			result.Reason = NotEnoughQueueQuota
+			return result
+		}
+	}
+	for _, node := range nodes {
+		if node.available.FitIn(ask.Resource) {
+			node.available.SubFrom(ask.Resource)
+			for current := queue; current != nil; current = current.parent {
+				current.allocated.AddTo(ask.Resource)
+			}
+			result.NodeID = node.nodeID
+			return result
+		}
+	}
+	result.Reason = SimulationNoNodeFits
+	return result
+}
+
+// cloneQueueTree adds a clone of the queue and all queues below it to the map keyed on the queue path.
+func cloneQueueTree(queue *Queue, parent *simQueue, queues map[string]*simQueue) {
+	clone := &simQueue{
+		parent:      parent,
+		leaf:        queue.IsLeafQueue(),
+		maxResource: queue.GetMaxResource(),
+		allocated:   queue.GetAllocatedResource(),
+	}
+	if clone.allocated == nil {
+		clone.allocated = resources.NewResource()
+	}
+	queues[queue.QueuePath] = clone
+	for _, child := range queue.GetCopyOfChildren() {
+		cloneQueueTree(child, clone, queues)
+	}
+}
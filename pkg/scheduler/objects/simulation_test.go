@@ -0,0 +1,83 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+func TestSimulateAllocations(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"first": "20"})
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf1, leaf2 *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"first": "10"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf1, err = createManagedQueue(parent, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	leaf2, err = createManagedQueue(root, "leaf2", false, map[string]string{"first": "8"})
+	assert.NilError(t, err, "failed to create leaf2 queue")
+	node1 := newNode("node1", map[string]resources.Quantity{"first": 6})
+	node2 := newNode("node2", map[string]resources.Quantity{"first": 10})
+	node3 := newNode("node3", map[string]resources.Quantity{"first": 100})
+	node3.SetSchedulable(false)
+
+	res := func(value resources.Quantity) *resources.Resource {
+		return resources.NewResourceFromMap(map[string]resources.Quantity{"first": value})
+	}
+	asks := []*SimulationAsk{
+		{AllocationKey: "fits-first-node", QueuePath: "root.parent.leaf1", Resource: res(5)},
+		{AllocationKey: "fits-second-node", QueuePath: "root.parent.leaf1", Resource: res(5)},
+		{AllocationKey: "parent-full", QueuePath: "root.parent.leaf1", Resource: res(1)},
+		{AllocationKey: "no-node", QueuePath: "root.leaf2", Resource: res(6)},
+		{AllocationKey: "parent-queue", QueuePath: "root.parent", Resource: res(1)},
+		{AllocationKey: "unknown-queue", QueuePath: "root.unknown", Resource: res(1)},
+		{AllocationKey: "fits-remaining", QueuePath: "root.leaf2", Resource: res(4)},
+	}
+	expected := []SimulationResult{
+		{AllocationKey: "fits-first-node", QueuePath: "root.parent.leaf1", NodeID: "node1"},
+		{AllocationKey: "fits-second-node", QueuePath: "root.parent.leaf1", NodeID: "node2"},
+		{AllocationKey: "parent-full", QueuePath: "root.parent.leaf1", Reason: NotEnoughQueueQuota},
+		{AllocationKey: "no-node", QueuePath: "root.leaf2", Reason: SimulationNoNodeFits},
+		{AllocationKey: "parent-queue", QueuePath: "root.parent", Reason: SimulationNotLeafQueue},
+		{AllocationKey: "unknown-queue", QueuePath: "root.unknown", Reason: SimulationQueueNotFound},
+		{AllocationKey: "fits-remaining", QueuePath: "root.leaf2", NodeID: "node2"},
+	}
+	results := SimulateAllocations(root, []*Node{node3, node2, node1}, asks)
+	assert.Equal(t, len(results), len(expected), "unexpected number of results")
+	for i, result := range results {
+		assert.DeepEqual(t, *result, expected[i])
+	}
+
+	// nothing changed on the live objects
+	assert.Assert(t, resources.IsZero(leaf1.GetAllocatedResource()), "leaf1 allocation should not change")
+	assert.Assert(t, resources.IsZero(leaf2.GetAllocatedResource()), "leaf2 allocation should not change")
+	assert.Assert(t, resources.IsZero(root.GetAllocatedResource()), "root allocation should not change")
+	assert.Assert(t, resources.Equals(node1.GetAvailableResource(), res(6)), "node1 available should not change")
+	assert.Assert(t, resources.Equals(node2.GetAvailableResource(), res(10)), "node2 available should not change")
+
+	// existing usage of the queue is taken into account
+	err = leaf1.TryIncAllocatedResource(res(8))
+	assert.NilError(t, err, "failed to set usage on leaf1")
+	results = SimulateAllocations(root, []*Node{node1, node2}, asks[:1])
+	assert.Equal(t, results[0].Reason, NotEnoughQueueQuota, "existing usage should block the ask")
+}
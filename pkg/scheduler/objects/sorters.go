@@ -20,20 +20,21 @@ package objects
 
 import (
 	"sort"
-	"time"
 
 	"github.com/apache/yunikorn-core/pkg/common/resources"
 	"github.com/apache/yunikorn-core/pkg/metrics"
 	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
 )
 
-func sortQueue(queues []*Queue, fairMaxResources []*resources.Resource, sortType policies.SortPolicy, considerPriority bool) {
-	sortingStart := time.Now()
+// sortQueue sorts the queues using the sort policy. In capacity mode the fair max resources already include the
+// guaranteed resources of the queues, the guaranteed resources are not used separately.
+func sortQueue(queues []*Queue, fairMaxResources []*resources.Resource, sortType policies.SortPolicy, considerPriority bool, capacity bool, comparator resources.ResourceComparator) {
+	sortingStart := getClock().Now()
 	if sortType == policies.FairSortPolicy {
 		if considerPriority {
-			sortQueuesByPriorityAndFairness(queues, fairMaxResources)
+			sortQueuesByPriorityAndFairness(queues, fairMaxResources, capacity, comparator)
 		} else {
-			sortQueuesByFairnessAndPriority(queues, fairMaxResources)
+			sortQueuesByFairnessAndPriority(queues, fairMaxResources, capacity, comparator)
 		}
 	} else {
 		if considerPriority {
@@ -43,6 +44,14 @@ func sortQueue(queues []*Queue, fairMaxResources []*resources.Resource, sortType
 	metrics.GetSchedulerMetrics().ObserveQueueSortingLatency(sortingStart)
 }
 
+// sortGuaranteed returns the guaranteed resource of the queue used in the fair comparison, nil in capacity mode.
+func sortGuaranteed(queue *Queue, capacity bool) *resources.Resource {
+	if capacity {
+		return nil
+	}
+	return queue.GetGuaranteedResource()
+}
+
 func sortQueuesByPriority(queues []*Queue) {
 	sort.SliceStable(queues, func(i, j int) bool {
 		l := queues[i]
@@ -53,7 +62,7 @@ func sortQueuesByPriority(queues []*Queue) {
 	})
 }
 
-func sortQueuesByPriorityAndFairness(queues []*Queue, fairMaxResources []*resources.Resource) {
+func sortQueuesByPriorityAndFairness(queues []*Queue, fairMaxResources []*resources.Resource, capacity bool, comparator resources.ResourceComparator) {
 	sort.SliceStable(queues, func(i, j int) bool {
 		l := queues[i]
 		r := queues[j]
@@ -66,8 +75,8 @@ func sortQueuesByPriorityAndFairness(queues []*Queue, fairMaxResources []*resour
 			return false
 		}
 
-		comp := resources.CompUsageRatioSeparately(l.GetAllocatedResource(), l.GetGuaranteedResource(), fairMaxResources[i],
-			r.GetAllocatedResource(), r.GetGuaranteedResource(), fairMaxResources[j])
+		comp := comparator.CompUsageRatioSeparately(l.GetAllocatedResource(), sortGuaranteed(l, capacity), fairMaxResources[i],
+			r.GetAllocatedResource(), sortGuaranteed(r, capacity), fairMaxResources[j])
 
 		if comp == 0 {
 			return resources.StrictlyGreaterThan(resources.Sub(l.GetPendingResource(), r.GetPendingResource()), resources.Zero)
@@ -76,13 +85,13 @@ func sortQueuesByPriorityAndFairness(queues []*Queue, fairMaxResources []*resour
 	})
 }
 
-func sortQueuesByFairnessAndPriority(queues []*Queue, fairMaxResources []*resources.Resource) {
+func sortQueuesByFairnessAndPriority(queues []*Queue, fairMaxResources []*resources.Resource, capacity bool, comparator resources.ResourceComparator) {
 	sort.SliceStable(queues, func(i, j int) bool {
 		l := queues[i]
 		r := queues[j]
 
-		comp := resources.CompUsageRatioSeparately(l.GetAllocatedResource(), l.GetGuaranteedResource(), fairMaxResources[i],
-			r.GetAllocatedResource(), r.GetGuaranteedResource(), fairMaxResources[j])
+		comp := comparator.CompUsageRatioSeparately(l.GetAllocatedResource(), sortGuaranteed(l, capacity), fairMaxResources[i],
+			r.GetAllocatedResource(), sortGuaranteed(r, capacity), fairMaxResources[j])
 		if comp == 0 {
 			lPriority := l.GetCurrentPriority()
 			rPriority := r.GetCurrentPriority()
@@ -98,39 +107,44 @@ func sortQueuesByFairnessAndPriority(queues []*Queue, fairMaxResources []*resour
 	})
 }
 
-func sortApplications(apps map[string]*Application, sortType policies.SortPolicy, considerPriority bool, globalResource *resources.Resource) []*Application {
-	sortingStart := time.Now()
+func sortApplications(apps map[string]*Application, sortType policies.SortPolicy, considerPriority bool, globalResource *resources.Resource, tieBreak policies.TieBreakPolicy, comparator resources.ResourceComparator) []*Application {
+	sortingStart := getClock().Now()
 	sortedApps := filterOnPendingResources(apps)
 	switch sortType {
 	case policies.FairSortPolicy:
 		if considerPriority {
-			sortApplicationsByPriorityAndFairness(sortedApps, globalResource)
+			sortApplicationsByPriorityAndFairness(sortedApps, globalResource, tieBreak, comparator)
 		} else {
-			sortApplicationsByFairnessAndPriority(sortedApps, globalResource)
+			sortApplicationsByFairnessAndPriority(sortedApps, globalResource, tieBreak, comparator)
 		}
 	case policies.FifoSortPolicy:
 		if considerPriority {
-			sortApplicationsByPriorityAndSubmissionTime(sortedApps)
+			sortApplicationsByPriorityAndSubmissionTime(sortedApps, tieBreak)
 		} else {
-			sortApplicationsBySubmissionTimeAndPriority(sortedApps)
+			sortApplicationsBySubmissionTimeAndPriority(sortedApps, tieBreak)
 		}
 	}
 	metrics.GetSchedulerMetrics().ObserveAppSortingLatency(sortingStart)
 	return sortedApps
 }
 
-func sortApplicationsByFairnessAndPriority(sortedApps []*Application, globalResource *resources.Resource) {
+func sortApplicationsByFairnessAndPriority(sortedApps []*Application, globalResource *resources.Resource, tieBreak policies.TieBreakPolicy, comparator resources.ResourceComparator) {
 	sort.SliceStable(sortedApps, func(i, j int) bool {
 		l := sortedApps[i]
 		r := sortedApps[j]
-		if comp := resources.CompUsageRatio(l.GetAllocatedResource(), r.GetAllocatedResource(), globalResource); comp != 0 {
+		if comp := comparator.CompUsageRatio(l.GetAllocatedResource(), r.GetAllocatedResource(), globalResource); comp != 0 {
 			return comp < 0
 		}
-		return l.GetAskMaxPriority() > r.GetAskMaxPriority()
+		leftPriority := l.GetAskMaxPriority()
+		rightPriority := r.GetAskMaxPriority()
+		if leftPriority != rightPriority {
+			return leftPriority > rightPriority
+		}
+		return breakApplicationTie(l, r, tieBreak)
 	})
 }
 
-func sortApplicationsByPriorityAndFairness(sortedApps []*Application, globalResource *resources.Resource) {
+func sortApplicationsByPriorityAndFairness(sortedApps []*Application, globalResource *resources.Resource, tieBreak policies.TieBreakPolicy, comparator resources.ResourceComparator) {
 	sort.SliceStable(sortedApps, func(i, j int) bool {
 		l := sortedApps[i]
 		r := sortedApps[j]
@@ -142,11 +156,14 @@ func sortApplicationsByPriorityAndFairness(sortedApps []*Application, globalReso
 		if leftPriority < rightPriority {
 			return false
 		}
-		return resources.CompUsageRatio(l.GetAllocatedResource(), r.GetAllocatedResource(), globalResource) < 0
+		if comp := comparator.CompUsageRatio(l.GetAllocatedResource(), r.GetAllocatedResource(), globalResource); comp != 0 {
+			return comp < 0
+		}
+		return breakApplicationTie(l, r, tieBreak)
 	})
 }
 
-func sortApplicationsBySubmissionTimeAndPriority(sortedApps []*Application) {
+func sortApplicationsBySubmissionTimeAndPriority(sortedApps []*Application, tieBreak policies.TieBreakPolicy) {
 	sort.SliceStable(sortedApps, func(i, j int) bool {
 		l := sortedApps[i]
 		r := sortedApps[j]
@@ -156,11 +173,16 @@ func sortApplicationsBySubmissionTimeAndPriority(sortedApps []*Application) {
 		if r.SubmissionTime.Before(l.SubmissionTime) {
 			return false
 		}
-		return l.GetAskMaxPriority() > r.GetAskMaxPriority()
+		leftPriority := l.GetAskMaxPriority()
+		rightPriority := r.GetAskMaxPriority()
+		if leftPriority != rightPriority {
+			return leftPriority > rightPriority
+		}
+		return breakApplicationTie(l, r, tieBreak)
 	})
 }
 
-func sortApplicationsByPriorityAndSubmissionTime(sortedApps []*Application) {
+func sortApplicationsByPriorityAndSubmissionTime(sortedApps []*Application, tieBreak policies.TieBreakPolicy) {
 	sort.SliceStable(sortedApps, func(i, j int) bool {
 		l := sortedApps[i]
 		r := sortedApps[j]
@@ -172,10 +194,27 @@ func sortApplicationsByPriorityAndSubmissionTime(sortedApps []*Application) {
 		if leftPriority < rightPriority {
 			return false
 		}
-		return l.SubmissionTime.Before(r.SubmissionTime)
+		if l.SubmissionTime.Before(r.SubmissionTime) {
+			return true
+		}
+		if r.SubmissionTime.Before(l.SubmissionTime) {
+			return false
+		}
+		return breakApplicationTie(l, r, tieBreak)
 	})
 }
 
+// breakApplicationTie returns true if the left application should be sorted before the right application.
+// Called when the applications are equal for the sort policy to make the order deterministic.
+func breakApplicationTie(l, r *Application, tieBreak policies.TieBreakPolicy) bool {
+	if tieBreak == policies.ResourceTieBreakPolicy {
+		if comp := resources.CompUsageRatio(l.GetPendingResource(), r.GetPendingResource(), nil); comp != 0 {
+			return comp > 0
+		}
+	}
+	return l.ApplicationID < r.ApplicationID
+}
+
 func filterOnPendingResources(apps map[string]*Application) []*Application {
 	filteredApps := make([]*Application, 0)
 	for _, app := range apps {
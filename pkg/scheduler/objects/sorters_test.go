@@ -26,6 +26,7 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/common/resources"
 	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
 )
@@ -70,49 +71,49 @@ func TestSortQueuesWithGuarantees(t *testing.T) {
 	// fifo
 	queues = []*Queue{q0, q1, q2, q3}
 
-	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q0, q1, q2, q3}), "fifo first")
 
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q0, q1, q2}), "fifo first - priority")
 
 	// fifo - different starting order
 	queues = []*Queue{q1, q3, q0, q2}
-	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q1, q3, q0, q2}), "fifo second")
 
 	queues = []*Queue{q1, q3, q0, q2}
-	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FifoSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q1, q0, q2}), "fifo second - priority")
 
 	// fairness ratios: q0:300/500=0.6, q1:200/300=0.67, q2:100/200=0.5, q3:100/200=0.5
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q2, q0, q1}), "fair first")
 
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q2, q0, q1}), "fair first - priority")
 
 	// fairness ratios: q0:200/500=0.4, q1:300/300=1, q2:100/200=0.5, q3:100/200=0.5
 	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200, "vcore": 200})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300, "vcore": 300})
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q0, q3, q2, q1}), "fair second")
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q0, q2, q1}), "fair second - priority")
 
 	// fairness ratios: q0:150/500=0.3, q1:120/300=0.4, q2:100/200=0.5, q3:100/200=0.5
 	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 150, "vcore": 150})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 120, "vcore": 120})
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q0, q1, q3, q2}), "fair third")
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q0, q1, q2}), "fair third - priority")
 
 	// fairness ratios: q0:400/800=0.5, q1:200/400= 0.5, q2:100/200=0.5, q3:100/200=0.5
@@ -121,7 +122,7 @@ func TestSortQueuesWithGuarantees(t *testing.T) {
 	q1.guaranteedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 400, "vcore": 300})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200, "vcore": 150})
 	queues = []*Queue{q0, q1, q2, q3}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q0, q1, q2}), "fair - pending resource")
 }
 
@@ -157,19 +158,19 @@ func TestSortQueuesNoGuarantees(t *testing.T) {
 		resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000, "vcore": 1000}),
 		resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000, "vcore": 1000}),
 	}
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q2, q1, q0}), "fair no gaurantees first")
 
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q2, q0, q1}), "fair no gaurantees first - priority")
 
 	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200, "vcore": 200})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300, "vcore": 300})
 
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q2, q0, q1}), "fair no gaurantees second")
 
-	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true)
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, true, false, resources.DefaultComparator())
 	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q3, q2, q0, q1}), "fair no limit second - priority")
 }
 
@@ -188,27 +189,27 @@ func TestSortAppsNoPending(t *testing.T) {
 	}
 
 	// no apps with pending resources should come back empty
-	list = sortApplications(input, policies.FairSortPolicy, false, nil)
+	list = sortApplications(input, policies.FairSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{}, "fair no pending")
-	list = sortApplications(input, policies.FairSortPolicy, true, nil)
+	list = sortApplications(input, policies.FairSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{}, "fair no pending - priority")
 
-	list = sortApplications(input, policies.FifoSortPolicy, false, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{}, "fifo no pending")
-	list = sortApplications(input, policies.FifoSortPolicy, true, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{}, "fifo no pending - priority")
 
 	// set one app with pending
 	appID := "app-1"
 	input[appID].pending = res
-	list = sortApplications(input, policies.FairSortPolicy, false, nil)
+	list = sortApplications(input, policies.FairSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{appID}, "fair one pending")
-	list = sortApplications(input, policies.FairSortPolicy, true, nil)
+	list = sortApplications(input, policies.FairSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{appID}, "fair one pending - priority")
 
-	list = sortApplications(input, policies.FifoSortPolicy, false, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{appID}, "fifo one pending")
-	list = sortApplications(input, policies.FifoSortPolicy, true, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppListLength(t, list, []string{appID}, "fifo one pending - priority")
 }
 
@@ -231,14 +232,14 @@ func TestSortAppsFifo(t *testing.T) {
 	}
 
 	// fifo - apps should come back in order created 0, 1, 2, 3
-	list = sortApplications(input, policies.FifoSortPolicy, false, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{0, 1, 2, 3}, "fifo simple")
 
 	input["app-1"].askMaxPriority = 3
 	input["app-3"].askMaxPriority = 5
 	input["app-2"].SubmissionTime = input["app-3"].SubmissionTime
 	input["app-1"].SubmissionTime = input["app-3"].SubmissionTime
-	list = sortApplications(input, policies.FifoSortPolicy, false, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	/*
 	* apps order: 0, 3, 1, 2
 	* the resultType of app index is [0, 2, 3, 1]
@@ -270,7 +271,7 @@ func TestSortAppsPriorityFifo(t *testing.T) {
 	input["app-3"].askMaxPriority = 4
 
 	// priority - apps should come back in order 1, 3, 0, 2
-	list = sortApplications(input, policies.FifoSortPolicy, true, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{2, 0, 3, 1}, "fifo simple")
 }
 
@@ -290,27 +291,27 @@ func TestSortAppsFair(t *testing.T) {
 	}
 	// nil resource: usage based sorting
 	// apps should come back in order: 0, 1, 2, 3
-	list := sortApplications(input, policies.FairSortPolicy, false, nil)
+	list := sortApplications(input, policies.FairSortPolicy, false, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{0, 1, 2, 3}, "nil total")
 
 	// apps should come back in order: 0, 1, 2, 3
-	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 0))
+	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 0), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{0, 1, 2, 3}, "zero total")
 
 	// apps should come back in order: 0, 1, 2, 3
-	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{0, 1, 2, 3}, "no alloc, set total")
 
 	// update allocated resource for app-1
 	input["app-1"].allocatedResource = resources.Multiply(res, 10)
 	// apps should come back in order: 0, 2, 3, 1
-	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{0, 3, 1, 2}, "app-1 allocated")
 
 	// update allocated resource for app-3 to negative (move to head of the list)
 	input["app-3"].allocatedResource = resources.Multiply(res, -10)
 	// apps should come back in order: 3, 0, 2, 1
-	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{1, 3, 2, 0}, "app-1 & app-3 allocated")
 
 	// update allocated resource for app-3 & app-1 where priority of app-3 is higher
@@ -318,7 +319,7 @@ func TestSortAppsFair(t *testing.T) {
 	input["app-1"].askMaxPriority = 2
 	input["app-3"].allocatedResource = resources.Multiply(res, 10)
 	input["app-3"].askMaxPriority = 3
-	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, false, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	/*
 	*  expected apps order: 0, 2, 3, 1 means
 	*  So resultType of apps indexs is [0, 3, 1, 2]
@@ -347,30 +348,111 @@ func TestSortAppsPriorityFair(t *testing.T) {
 
 	// nil resource: priority then usage based sorting
 	// apps should come back in order: 1, 0, 2, 3
-	list := sortApplications(input, policies.FairSortPolicy, true, nil)
+	list := sortApplications(input, policies.FairSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{1, 0, 2, 3}, "nil total")
 
 	// apps should come back in order: 1, 0, 2, 3
-	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 0))
+	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 0), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{1, 0, 2, 3}, "zero total")
 
 	// apps should come back in order: 1, 0, 2, 3
-	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{1, 0, 2, 3}, "no alloc, set total")
 
 	// update allocated resource for app-2
 	input["app-2"].allocatedResource = resources.Multiply(res, 10)
 	// apps should come back in order: 1, 0, 3, 2
-	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{1, 0, 3, 2}, "app-1 allocated")
 
 	// update allocated resource for app-3 to negative (move to head of the list within priority 0)
 	input["app-3"].allocatedResource = resources.Multiply(res, -10)
 	// apps should come back in order: 1, 3, 0, 2
-	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 5))
+	list = sortApplications(input, policies.FairSortPolicy, true, resources.Multiply(res, 5), policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{2, 0, 3, 1}, "app-1 & app-3 allocated")
 }
 
+func TestSortComparator(t *testing.T) {
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 10})
+	pending := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	// app-0 has the largest single share, app-1 the largest sum of shares
+	app0 := newApplication("app-0", "partition", "queue")
+	app0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 6})
+	app0.pending = pending
+	app1 := newApplication("app-1", "partition", "queue")
+	app1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 4, "vcore": 4})
+	app1.pending = pending
+	input := map[string]*Application{"app-0": app0, "app-1": app1}
+
+	totalComp, err := resources.GetComparator(resources.TotalComparator)
+	assert.NilError(t, err, "total comparator not found")
+	list := sortApplications(input, policies.FairSortPolicy, false, total, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
+	assertAppListLength(t, list, []string{"app-1", "app-0"}, "dominant comparator")
+	list = sortApplications(input, policies.FairSortPolicy, false, total, policies.AppIDTieBreakPolicy, totalComp)
+	assertAppListLength(t, list, []string{"app-0", "app-1"}, "total comparator")
+
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var q0, q1 *Queue
+	q0, err = createManagedQueue(root, "q0", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q0.allocatedResource = app0.allocatedResource
+	q1, err = createManagedQueue(root, "q1", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q1.allocatedResource = app1.allocatedResource
+	fairMaxResources := []*resources.Resource{total, total}
+	queues := []*Queue{q0, q1}
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, resources.DefaultComparator())
+	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q1, q0}), "dominant comparator")
+	queues = []*Queue{q0, q1}
+	sortQueue(queues, fairMaxResources, policies.FairSortPolicy, false, false, totalComp)
+	assert.Equal(t, queueNames(queues), queueNames([]*Queue{q0, q1}), "total comparator")
+
+	// comparator set via the queue property and inherited by the children
+	var parent, leaf *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, map[string]string{configs.ResourceComparator: resources.TotalComparator})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, root.getComparator(), resources.DefaultComparator(), "root should use the default comparator")
+	assert.Equal(t, parent.getComparator(), totalComp, "parent should use the configured comparator")
+	assert.Equal(t, leaf.getComparator(), totalComp, "leaf should inherit the comparator")
+}
+
+func TestSortAppsTieBreak(t *testing.T) {
+	small := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": resources.Quantity(100)})
+	large := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": resources.Quantity(200)})
+	submitted := time.Now()
+	// two apps equal on priority, submission time and usage: app-b has the larger request
+	input := make(map[string]*Application, 2)
+	appA := newApplication("app-a", "partition", "queue")
+	appA.pending = small
+	appA.SubmissionTime = submitted
+	input["app-a"] = appA
+	appB := newApplication("app-b", "partition", "queue")
+	appB.pending = large
+	appB.SubmissionTime = submitted
+	input["app-b"] = appB
+
+	sortTypes := []policies.SortPolicy{policies.FifoSortPolicy, policies.FairSortPolicy}
+	for _, sortType := range sortTypes {
+		for _, priority := range []bool{false, true} {
+			// repeat the sort: map iteration order must not influence the result
+			for i := 0; i < 10; i++ {
+				list := sortApplications(input, sortType, priority, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
+				assertAppListLength(t, list, []string{"app-a", "app-b"}, "app ID tie break "+sortType.String())
+				list = sortApplications(input, sortType, priority, nil, policies.ResourceTieBreakPolicy, resources.DefaultComparator())
+				assertAppListLength(t, list, []string{"app-b", "app-a"}, "resource tie break "+sortType.String())
+			}
+		}
+	}
+
+	// equal sized requests fall back to the application ID
+	appB.pending = small
+	list := sortApplications(input, policies.FifoSortPolicy, true, nil, policies.ResourceTieBreakPolicy, resources.DefaultComparator())
+	assertAppListLength(t, list, []string{"app-a", "app-b"}, "resource tie break equal size")
+}
+
 func queueNames(list []*Queue) string {
 	result := make([]string, 0)
 	for _, v := range list {
@@ -414,6 +496,6 @@ func TestSortBySubmissionTime(t *testing.T) {
 		input[appID] = app
 	}
 
-	list = sortApplications(input, policies.FifoSortPolicy, true, nil)
+	list = sortApplications(input, policies.FifoSortPolicy, true, nil, policies.AppIDTieBreakPolicy, resources.DefaultComparator())
 	assertAppList(t, list, []int{3, 2, 1, 0}, "sort by submission time")
 }
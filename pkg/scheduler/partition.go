@@ -20,9 +20,11 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -45,6 +47,11 @@ import (
 	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
 )
 
+const (
+	runAppRetention   = 24 * time.Hour   // time an application that has been running is remembered after it left the partition
+	dependencyTimeout = 15 * time.Minute // time an application waits for a dependency that is not known in the partition
+)
+
 type PartitionContext struct {
 	RmID string // the RM the partition belongs to
 	Name string // name of the partition
@@ -62,10 +69,28 @@ type PartitionContext struct {
 	userGroupCache         *security.UserGroupCache        // user cache per partition
 	totalPartitionResource *resources.Resource             // Total node resources
 	allocations            int                             // Number of allocations on the partition
+	maxAllocations         uint64                          // maximum number of allocations on the partition, 0 is unlimited
 	reservations           int                             // number of reservations
 	placeholderAllocations int                             // number of placeholder allocations
 	preemptionEnabled      bool                            // whether preemption is enabled or not
+	preemptionGracePeriod  time.Duration                   // time a preempted allocation is kept before it is released, zero waits for the RM
 	foreignAllocs          map[string]*objects.Allocation  // foreign (non-Yunikorn) allocations
+	overcommit             map[string]float64              // overcommit ratio per resource type applied to node capacity
+	nodeOverhead           *resources.Resource             // resources reserved on each node, removed from node capacity
+	overheadOverrides      []nodeOverheadOverride          // reserved node resources for nodes with matching attributes
+	granularity            *resources.Resource             // granularity per resource type new requests are rounded up to
+	allocationSinks        []AllocationSink                // sinks receiving the allocation lifecycle events
+	sandboxQueue           string                          // queue for applications from untrusted RMs, empty disables the sandbox
+	trustedRMs             map[string]bool                 // RMs whose applications go through the placement rules
+	zeroRequestPolicy      policies.ZeroRequestPolicy      // handling of applications that do not request resources on submit
+	zeroRequestQueue       string                          // queue for applications that do not request resources, route policy only
+	zeroRequestApps        map[string]string               // applications routed to the zero request queue, mapped to the requested queue
+	runApps                map[string]time.Time            // applications that have been running and left the partition with the time they left, resolves dependencies
+	parentPlacement        policies.ParentPlacementPolicy  // handling of applications placed in a parent queue
+	minRequest             *resources.Resource             // minimum per resource type a new request must ask for
+	minRequestPolicy       policies.MinRequestPolicy       // handling of new requests below the minimum
+	cordonedNodes          map[string]bool                 // nodes skipped during scheduling, their capacity is not schedulable
+	cordonFairShare        policies.CordonFairSharePolicy  // whether the capacity of cordoned nodes is part of the fair share
 
 	// The partition write lock must not be held while manipulating an application.
 	// Scheduling is running continuously as a lock free background task. Scheduling an application
@@ -96,6 +121,9 @@ func newPartitionContext(conf configs.PartitionConfig, rmID string, cc *ClusterC
 		completedApplications: make(map[string]*objects.Application),
 		nodes:                 objects.NewNodeCollection(conf.Name),
 		foreignAllocs:         make(map[string]*objects.Allocation),
+		cordonedNodes:         make(map[string]bool),
+		zeroRequestApps:       make(map[string]string),
+		runApps:               make(map[string]time.Time),
 	}
 	pc.partitionManager = newPartitionManager(pc, cc)
 	if err := pc.initialPartitionFromConfig(conf, silence); err != nil {
@@ -136,7 +164,19 @@ func (pc *PartitionContext) initialPartitionFromConfig(conf configs.PartitionCon
 	// get the user group cache for the partition
 	pc.userGroupCache = security.GetUserGroupCache("")
 	pc.updateNodeSortingPolicy(conf, silence)
+	pc.updateParentPlacement(conf)
 	pc.updatePreemption(conf)
+	pc.updateSchedulingMode(conf)
+	pc.updateOvercommit(conf)
+	pc.updateNodeOverhead(conf)
+	pc.updateGranularity(conf)
+	pc.updateMaxAllocations(conf)
+	pc.updateDefaultSubmitACL(conf)
+	pc.updateSandbox(conf)
+	pc.updateZeroRequest(conf)
+	pc.updateMinRequest(conf)
+	pc.updateBurstPool(conf)
+	pc.updateCordon(conf)
 
 	// update limit settings: start at the root
 	if !silence {
@@ -158,12 +198,425 @@ func (pc *PartitionContext) updateNodeSortingPolicy(conf configs.PartitionConfig
 		log.Log(log.SchedPartition).Info("NodeSorting policy set from config",
 			zap.Stringer("policyName", configuredPolicy))
 	}
-	pc.nodes.SetNodeSortingPolicy(objects.NewNodeSortingPolicy(conf.NodeSortPolicy.Type, conf.NodeSortPolicy.ResourceWeights))
+	policy := objects.NewNodeSortingPolicy(conf.NodeSortPolicy.Type, conf.NodeSortPolicy.ResourceWeights)
+	pc.nodes.SetNodeSortingPolicy(policy)
+	pc.root.SetNodeSortingPolicy(policy)
 }
 
 // NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
 func (pc *PartitionContext) updatePreemption(conf configs.PartitionConfig) {
 	pc.preemptionEnabled = conf.Preemption.Enabled == nil || *conf.Preemption.Enabled
+	var cooldown time.Duration
+	if conf.Preemption.Cooldown != "" {
+		var err error
+		if cooldown, err = time.ParseDuration(conf.Preemption.Cooldown); err != nil {
+			log.Log(log.SchedPartition).Debug("preemption cooldown incorrectly set, cooldown disabled",
+				zap.Error(err))
+			cooldown = 0
+		}
+	}
+	pc.root.SetPreemptionCooldown(cooldown)
+	var grace time.Duration
+	if conf.Preemption.GracePeriod != "" {
+		var err error
+		if grace, err = time.ParseDuration(conf.Preemption.GracePeriod); err != nil {
+			log.Log(log.SchedPartition).Debug("preemption grace period incorrectly set, grace period disabled",
+				zap.Error(err))
+			grace = 0
+		}
+	}
+	pc.preemptionGracePeriod = grace
+	quota, err := resources.NewResourceFromConf(conf.Preemption.Quota)
+	if err != nil {
+		log.Log(log.SchedPartition).Debug("preemption quota incorrectly set, quota disabled",
+			zap.Error(err))
+		quota = nil
+	}
+	var window time.Duration
+	if conf.Preemption.QuotaWindow != "" {
+		if window, err = time.ParseDuration(conf.Preemption.QuotaWindow); err != nil {
+			log.Log(log.SchedPartition).Debug("preemption quota window incorrectly set, default window used",
+				zap.Error(err))
+			window = 0
+		}
+	}
+	pc.root.SetPreemptionQuota(quota, window)
+}
+
+// updateOvercommit sets the overcommit ratios from the config. The new ratios are applied to node capacity
+// registered or updated after the change, the capacity of the existing nodes is not changed.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateOvercommit(conf configs.PartitionConfig) {
+	overcommit := make(map[string]float64, len(conf.Overcommit))
+	for k, v := range conf.Overcommit {
+		overcommit[k] = v
+	}
+	pc.overcommit = overcommit
+}
+
+// nodeOverheadOverride is the reserved resource for nodes with all labels set to the same value in the node attributes.
+type nodeOverheadOverride struct {
+	labels   map[string]string
+	reserved *resources.Resource
+}
+
+// updateNodeOverhead sets the reserved node overhead and overrides from the config. Like the overcommit the new
+// overhead is applied to node capacity registered or updated after the change.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateNodeOverhead(conf configs.PartitionConfig) {
+	reserved, err := resources.NewResourceFromConf(conf.NodeOverhead.Reserved)
+	if err != nil {
+		log.Log(log.SchedPartition).Debug("node overhead incorrectly set, overhead disabled",
+			zap.Error(err))
+		reserved = nil
+	}
+	pc.nodeOverhead = reserved
+	overrides := make([]nodeOverheadOverride, 0, len(conf.NodeOverhead.Overrides))
+	for _, override := range conf.NodeOverhead.Overrides {
+		reserved, err = resources.NewResourceFromConf(override.Reserved)
+		if err != nil {
+			log.Log(log.SchedPartition).Debug("node overhead override incorrectly set, override skipped",
+				zap.Any("labels", override.Labels),
+				zap.Error(err))
+			continue
+		}
+		labels := make(map[string]string, len(override.Labels))
+		for k, v := range override.Labels {
+			labels[k] = v
+		}
+		overrides = append(overrides, nodeOverheadOverride{labels: labels, reserved: reserved})
+	}
+	pc.overheadOverrides = overrides
+}
+
+// updateGranularity sets the allocation granularity from the config. The granularity is applied to requests added
+// after the change, existing requests and allocations are not changed.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateGranularity(conf configs.PartitionConfig) {
+	granularity, err := resources.NewResourceFromConf(conf.Granularity)
+	if err != nil {
+		log.Log(log.SchedPartition).Debug("allocation granularity incorrectly set, granularity disabled",
+			zap.Error(err))
+		granularity = nil
+	}
+	pc.granularity = granularity
+}
+
+// updateMaxAllocations sets the maximum number of allocations from the config. Lowering the limit below the current
+// number of allocations does not remove any allocations, new allocations are rejected until the count drops.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateMaxAllocations(conf configs.PartitionConfig) {
+	pc.maxAllocations = conf.MaxAllocations
+}
+
+// updateDefaultSubmitACL sets the default submit ACL from the config on the root queue. An incorrect ACL is rejected
+// by the config validation, if it still fails to parse no default is set.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateDefaultSubmitACL(conf configs.PartitionConfig) {
+	acl, err := security.NewACL(conf.DefaultSubmitACL, true)
+	if err != nil {
+		log.Log(log.SchedPartition).Debug("default submit ACL incorrectly set, no default used",
+			zap.Error(err))
+		acl = security.ACL{}
+	}
+	pc.root.SetDefaultSubmitACL(acl)
+}
+
+// updateSandbox sets the sandbox queue and the trusted RMs from the config.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateSandbox(conf configs.PartitionConfig) {
+	pc.sandboxQueue = conf.Sandbox.Queue
+	pc.trustedRMs = make(map[string]bool, len(conf.Sandbox.TrustedRMs))
+	for _, rmID := range conf.Sandbox.TrustedRMs {
+		pc.trustedRMs[rmID] = true
+	}
+}
+
+// getSandboxQueue returns the sandbox queue for an application from the RM. An empty string is returned if the
+// sandbox is not configured or the RM is trusted.
+func (pc *PartitionContext) getSandboxQueue(rmID string) string {
+	pc.RLock()
+	defer pc.RUnlock()
+	if pc.sandboxQueue == "" || pc.trustedRMs[rmID] {
+		return ""
+	}
+	return pc.sandboxQueue
+}
+
+// updateZeroRequest sets the handling of applications that do not request resources from the config.
+// The config has been validated, an unknown policy falls back to accepting the applications.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateZeroRequest(conf configs.PartitionConfig) {
+	policy, err := policies.ZeroRequestPolicyFromString(conf.ZeroRequest.Policy)
+	if err != nil {
+		log.Log(log.SchedPartition).Warn("zero request policy configuration error",
+			zap.Error(err))
+	}
+	pc.zeroRequestPolicy = policy
+	pc.zeroRequestQueue = conf.ZeroRequest.Queue
+}
+
+// updateSchedulingMode sets the scheduling mode from the config on the root queue.
+// The config has been validated, an unknown mode falls back to fair scheduling.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateSchedulingMode(conf configs.PartitionConfig) {
+	mode, err := policies.SchedulingModePolicyFromString(conf.SchedulingMode)
+	if err != nil {
+		log.Log(log.SchedPartition).Warn("scheduling mode configuration error",
+			zap.Error(err))
+	}
+	pc.root.SetSchedulingMode(mode)
+}
+
+// updateParentPlacement sets the handling of applications placed in a parent queue from the config on the partition
+// and the placement manager.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateParentPlacement(conf configs.PartitionConfig) {
+	policy, err := policies.ParentPlacementPolicyFromString(conf.ParentPlacement)
+	if err != nil {
+		log.Log(log.SchedPartition).Warn("parent placement policy configuration error",
+			zap.Error(err))
+	}
+	pc.getPlacementManager().SetParentPlacementPolicy(policy)
+	pc.Lock()
+	defer pc.Unlock()
+	pc.parentPlacement = policy
+}
+
+// updateCordon sets the fair share treatment of the capacity of cordoned nodes from the config.
+// The config has been validated, an unknown policy falls back to keeping the capacity in the fair share.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateCordon(conf configs.PartitionConfig) {
+	policy, err := policies.CordonFairSharePolicyFromString(conf.Cordon.FairShare)
+	if err != nil {
+		log.Log(log.SchedPartition).Warn("cordon fair share policy configuration error",
+			zap.Error(err))
+	}
+	pc.cordonFairShare = policy
+	pc.updateFairShareResource()
+}
+
+// updateMinRequest sets the minimum request and its policy from the config. The minimum is checked for requests
+// added after the change, existing requests are not changed.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateMinRequest(conf configs.PartitionConfig) {
+	policy, err := policies.MinRequestPolicyFromString(conf.MinRequest.Policy)
+	if err != nil {
+		log.Log(log.SchedPartition).Warn("minimum request policy configuration error",
+			zap.Error(err))
+	}
+	pc.minRequestPolicy = policy
+	minimum, err := resources.NewResourceFromConf(conf.MinRequest.Resources)
+	if err != nil {
+		log.Log(log.SchedPartition).Debug("minimum request incorrectly set, minimum disabled",
+			zap.Error(err))
+		minimum = nil
+	}
+	pc.minRequest = minimum
+}
+
+// updateBurstPool sets the burst pool shared by the queues from the config. The config has been validated, an
+// incorrect capacity removes the pool and an incorrect refill never refills the pool.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateBurstPool(conf configs.PartitionConfig) {
+	capacity, err := resources.NewResourceFromConf(conf.BurstPool.Capacity)
+	if err != nil {
+		log.Log(log.SchedPartition).Debug("burst pool capacity incorrectly set, burst pool disabled",
+			zap.Error(err))
+		capacity = nil
+	}
+	var refill time.Duration
+	if conf.BurstPool.Refill != "" {
+		refill, err = time.ParseDuration(conf.BurstPool.Refill)
+		if err != nil || refill < 0 {
+			log.Log(log.SchedPartition).Debug("burst pool refill incorrectly set, burst pool not refilled",
+				zap.String("refill", conf.BurstPool.Refill),
+				zap.Error(err))
+			refill = 0
+		}
+	}
+	pc.root.SetBurstPool(capacity, refill)
+}
+
+// checkMinRequest checks each resource type of a request against the minimum of the partition. Resource types that
+// are not part of the request or have no minimum are not checked. The error names the resource types below the
+// minimum, the policy defines if the request must be rejected.
+func (pc *PartitionContext) checkMinRequest(res *resources.Resource) (policies.MinRequestPolicy, error) {
+	pc.RLock()
+	defer pc.RUnlock()
+	if pc.minRequest == nil || res == nil {
+		return pc.minRequestPolicy, nil
+	}
+	var below []string
+	for name, minimum := range pc.minRequest.Resources {
+		if value, ok := res.Resources[name]; ok && value < minimum {
+			below = append(below, fmt.Sprintf("%s requested %d, minimum %d", name, value, minimum))
+		}
+	}
+	if len(below) == 0 {
+		return pc.minRequestPolicy, nil
+	}
+	sort.Strings(below)
+	return pc.minRequestPolicy, fmt.Errorf("request below minimum: %s", strings.Join(below, ", "))
+}
+
+// getZeroRequestHandling returns the zero request policy and the queue used by the route policy.
+func (pc *PartitionContext) getZeroRequestHandling() (policies.ZeroRequestPolicy, string) {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.zeroRequestPolicy, pc.zeroRequestQueue
+}
+
+// isZeroRequest returns true if the application does not request any resources: the sum of its asks and the
+// placeholder resource set on submit is zero.
+func isZeroRequest(app *objects.Application) bool {
+	return resources.IsZero(resources.Add(app.GetPendingResource(), app.GetPlaceholderAsk()))
+}
+
+var (
+	// errNoResourcesRequested is returned when an application is rejected by the zero request policy.
+	errNoResourcesRequested = errors.New("no resources requested")
+	// errAppPlacementFailed is returned when an application routed to the zero request queue cannot be placed on its
+	// first request, the application is rejected.
+	errAppPlacementFailed = errors.New("application placement failed")
+)
+
+// isAppRejected returns true if the error was returned for an application that was rejected and removed from the
+// partition while processing a request. The RM must be informed about the rejection.
+func isAppRejected(err error) bool {
+	return errors.Is(err, errNoResourcesRequested) || errors.Is(err, errAppPlacementFailed)
+}
+
+// rejectZeroRequestApp rejects the application if the reject zero request policy is set and the first request of
+// the application does not ask for any resources. An application stays in the New state until its first request is
+// added, applications are thus accepted on submit and the decision is made when the first request arrives.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) rejectZeroRequestApp(app *objects.Application) error {
+	if policy, _ := pc.getZeroRequestHandling(); policy != policies.RejectZeroRequestPolicy {
+		return nil
+	}
+	if !app.IsNew() || !isZeroRequest(app) {
+		return nil
+	}
+	err := fmt.Errorf("application %s rejected by partition %s: %w", app.ApplicationID, pc.Name, errNoResourcesRequested)
+	log.Log(log.SchedPartition).Info("Rejecting application without resource requests",
+		zap.String("appID", app.ApplicationID),
+		zap.String("partitionName", pc.Name))
+	pc.removeApplication(app.ApplicationID)
+	pc.AddRejectedApplication(app, err.Error())
+	return err
+}
+
+// placeZeroRequestApp runs the placement rules for an application that was routed to the zero request queue on
+// submit, now that the application requests resources or a recovered allocation arrives. The application is moved
+// out of the zero request queue into the queue the rules resolve for the originally requested queue. Applications
+// not routed on submit are left as is. An application that already has allocations is not moved: the allocated
+// resources are tracked on the zero request queue.
+// If the placement fails the application is rejected and an error wrapping errAppPlacementFailed is returned.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) placeZeroRequestApp(app *objects.Application) error {
+	appID := app.ApplicationID
+	pc.Lock()
+	requested, ok := pc.zeroRequestApps[appID]
+	delete(pc.zeroRequestApps, appID)
+	pc.Unlock()
+	if !ok {
+		return nil
+	}
+	if len(app.GetAllAllocations()) != 0 {
+		log.Log(log.SchedPartition).Warn("Application with allocations not moved from zero request queue",
+			zap.String("appID", appID),
+			zap.String("queueName", app.GetQueuePath()))
+		return nil
+	}
+	if queue := app.GetQueue(); queue != nil {
+		queue.RemoveApplication(app)
+	}
+	pc.removeAppInternal(appID)
+	app.SetQueuePath(requested)
+	if err := pc.addApplication(app, false); err != nil {
+		pc.AddRejectedApplication(app, err.Error())
+		return fmt.Errorf("%w: %w", errAppPlacementFailed, err)
+	}
+	log.Log(log.SchedPartition).Info("Placed application from zero request queue",
+		zap.String("appID", appID),
+		zap.String("queueName", app.GetQueuePath()))
+	return nil
+}
+
+// isAllocationLimitReached returns true if the partition has a maximum number of allocations set and the number of
+// allocations has reached that maximum.
+func (pc *PartitionContext) isAllocationLimitReached() bool {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.maxAllocations > 0 && pc.allocations >= 0 && uint64(pc.allocations) >= pc.maxAllocations
+}
+
+// applyOvercommit returns the schedulable capacity of a node based on the capacity reported by the RM.
+// Resource types with an overcommit ratio are multiplied by the ratio, all other types are returned as is.
+func (pc *PartitionContext) applyOvercommit(capacity *resources.Resource) *resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	if capacity == nil || len(pc.overcommit) == 0 {
+		return capacity
+	}
+	schedulable := capacity.Clone()
+	for k, ratio := range pc.overcommit {
+		if v, ok := schedulable.Resources[k]; ok {
+			schedulable.Resources[k] = resources.Quantity(float64(v) * ratio)
+		}
+	}
+	return schedulable
+}
+
+// applyNodeOverhead returns the capacity of the node with the overhead reserved for system daemons removed.
+// The reserved resource of the first override that matches the node attributes is used, if none matches the
+// partition wide overhead is used. A resource type never drops below zero.
+func (pc *PartitionContext) applyNodeOverhead(node *objects.Node, capacity *resources.Resource) *resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	if capacity == nil {
+		return capacity
+	}
+	reserved := pc.nodeOverhead
+	for _, override := range pc.overheadOverrides {
+		if override.matches(node) {
+			reserved = override.reserved
+			break
+		}
+	}
+	if resources.IsZero(reserved) {
+		return capacity
+	}
+	schedulable := capacity.Clone()
+	for k, v := range reserved.Resources {
+		if current, ok := schedulable.Resources[k]; ok {
+			schedulable.Resources[k] = max(current-v, 0)
+		}
+	}
+	return schedulable
+}
+
+// matches returns true if all labels of the override are set to the same value in the node attributes.
+func (o nodeOverheadOverride) matches(node *objects.Node) bool {
+	attributes := node.GetAttributes()
+	for k, v := range o.labels {
+		if value, ok := attributes[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// applyGranularity returns the resource of a request rounded up to the granularity of the partition.
+// Resource types without a granularity are returned as is.
+func (pc *PartitionContext) applyGranularity(res *resources.Resource) *resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	if pc.granularity == nil || len(pc.granularity.Resources) == 0 {
+		return res
+	}
+	return resources.RoundUp(res, pc.granularity)
 }
 
 func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig) error {
@@ -179,10 +632,22 @@ func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig)
 		return err
 	}
 	pc.updateNodeSortingPolicy(conf, false)
+	pc.updateParentPlacement(conf)
 
 	pc.Lock()
 	defer pc.Unlock()
 	pc.updatePreemption(conf)
+	pc.updateSchedulingMode(conf)
+	pc.updateOvercommit(conf)
+	pc.updateNodeOverhead(conf)
+	pc.updateGranularity(conf)
+	pc.updateMaxAllocations(conf)
+	pc.updateDefaultSubmitACL(conf)
+	pc.updateSandbox(conf)
+	pc.updateZeroRequest(conf)
+	pc.updateMinRequest(conf)
+	pc.updateBurstPool(conf)
+	pc.updateCordon(conf)
 	// start at the root: there is only one queue
 	queueConf := conf.Queues[0]
 	root := pc.root
@@ -315,10 +780,40 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 		return fmt.Errorf("adding application %s to partition %s, but application already existed", appID, pc.Name)
 	}
 
-	// Resolve the queue for this app using the placement rules
+	// Applications that do not request any resources on submit are routed based on the zero request policy.
+	// The reject policy is applied when the first request arrives, see rejectZeroRequestApp.
+	zeroPolicy, _ := pc.getZeroRequestHandling()
+	return pc.addApplication(app, zeroPolicy == policies.RouteZeroRequestPolicy && isZeroRequest(app))
+}
+
+// addApplication places the application and adds it to the partition. If routeZero is set the application is placed
+// in the zero request queue instead of running the placement rules, and tracked until its first request arrives.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) addApplication(app *objects.Application, routeZero bool) error { //nolint:funlen
+	appID := app.ApplicationID
+	requested := app.GetQueuePath()
+	_, zeroQueue := pc.getZeroRequestHandling()
+
+	// Applications from untrusted RMs are forced into the sandbox queue, bypassing the placement rules.
+	// Routed zero request applications are placed in the zero request queue.
+	// Otherwise resolve the queue for this app using the placement rules.
 	// We either have an error or a queue name is set on the application.
-	err := pc.getPlacementManager().PlaceApplication(app)
-	if err != nil {
+	var err error
+	routed := false
+	sandbox := pc.getSandboxQueue(app.GetRMID())
+	if sandbox != "" {
+		log.Log(log.SchedPartition).Info("Placing application from untrusted RM in sandbox queue",
+			zap.String("appID", appID),
+			zap.String("rmID", app.GetRMID()),
+			zap.String("queueName", sandbox))
+		app.SetQueuePath(sandbox)
+	} else if routeZero {
+		log.Log(log.SchedPartition).Info("Placing application without resource requests in zero request queue",
+			zap.String("appID", appID),
+			zap.String("queueName", zeroQueue))
+		app.SetQueuePath(zeroQueue)
+		routed = true
+	} else if err = pc.getPlacementManager().PlaceApplication(app); err != nil {
 		return fmt.Errorf("failed to place application %s: %v", appID, err)
 	}
 	queueName := app.GetQueuePath()
@@ -346,9 +841,19 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 		}
 	}
 
-	// check the queue: is a leaf queue
+	// the sandbox bypasses the placement rules, not the submit access of the queue
+	if sandbox != "" && !queue.CheckSubmitAccess(app.GetUser()) {
+		return fmt.Errorf("application %s rejected: submit access to sandbox queue %s denied", appID, queueName)
+	}
+
+	// check the queue: is a leaf queue, or a parent queue that can be changed into a leaf queue
 	if !queue.IsLeafQueue() {
-		return fmt.Errorf("failed to find queue %s for application %s", queueName, appID)
+		if pc.parentPlacement != policies.PromoteParentPlacementPolicy {
+			return fmt.Errorf("application %s rejected: queue %s is a parent queue", appID, queueName)
+		}
+		if err = queue.PromoteToLeaf(); err != nil {
+			return fmt.Errorf("application %s rejected: %w", appID, err)
+		}
 	}
 
 	guaranteedRes := app.GetGuaranteedResource()
@@ -381,15 +886,104 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 			}
 		}
 	}
+	// reject the app if the minimum resource can never be satisfied by the queue hierarchy
+	if minRes := app.GetMinResource(); !resources.IsZero(minRes) {
+		if maxQueue := queue.GetMaxQueueSet(); maxQueue != nil {
+			if !maxQueue.FitInMaxUndef(minRes) {
+				return fmt.Errorf("queue %s cannot fit application %s: minimum resource %s larger than max queue allocation %s", queueName, appID, minRes.String(), maxQueue.String())
+			}
+		}
+	}
+	// dependencies are resolved within the partition: reject the app if it would create a cycle
+	if err = pc.checkAppDependencies(app); err != nil {
+		return err
+	}
 	// all is OK update the app and add it to the partition
 	app.SetQueue(queue)
 	app.SetTerminatedCallback(pc.moveTerminatedApp)
+	app.SetDependencyCallback(pc.hasAppRun)
 	queue.AddApplication(app)
 	pc.applications[appID] = app
+	if routed {
+		pc.zeroRequestApps[appID] = requested
+	}
+
+	return nil
+}
 
+// checkAppDependencies checks that adding the application to the partition does not create a dependency cycle.
+// The applications in the partition never form a cycle, any new cycle must therefore pass through the application
+// that is added. Applications that left the partition cannot depend on the new application.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) checkAppDependencies(app *objects.Application) error {
+	dependencies := app.GetDependencies()
+	if len(dependencies) == 0 {
+		return nil
+	}
+	visited := make(map[string]bool)
+	toVisit := append([]string{}, dependencies...)
+	for len(toVisit) > 0 {
+		appID := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		if appID == app.ApplicationID {
+			return fmt.Errorf("application %s has a cyclic dependency in partition %s", app.ApplicationID, pc.Name)
+		}
+		if visited[appID] {
+			continue
+		}
+		visited[appID] = true
+		if dependency := pc.applications[appID]; dependency != nil {
+			toVisit = append(toVisit, dependency.GetDependencies()...)
+		}
+	}
 	return nil
 }
 
+// hasAppRun returns true if the application is, or has been, running in the partition. Applications that have been
+// running are remembered after they are removed from the partition or terminate.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) hasAppRun(appID string) bool {
+	pc.RLock()
+	app := pc.applications[appID]
+	_, hasRun := pc.runApps[appID]
+	pc.RUnlock()
+	return hasRun || (app != nil && app.HasRun())
+}
+
+// isAppKnown returns true if the application is in the partition, or has been running in the partition and is
+// still remembered.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) isAppKnown(appID string) bool {
+	pc.RLock()
+	defer pc.RUnlock()
+	_, hasRun := pc.runApps[appID]
+	return hasRun || pc.applications[appID] != nil
+}
+
+// recordAppRun remembers that the application has been running when it leaves the partition, the application
+// can still be a dependency of other applications. The application is remembered for the runAppRetention.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) recordAppRun(app *objects.Application) {
+	if !app.HasRun() {
+		return
+	}
+	pc.Lock()
+	defer pc.Unlock()
+	pc.runApps[app.ApplicationID] = objects.Now()
+}
+
+// pruneRunApps forgets the applications that left the partition longer than the runAppRetention before now.
+// An application that depends on a forgotten application is failed after the dependencyTimeout.
+func (pc *PartitionContext) pruneRunApps(now time.Time) {
+	pc.Lock()
+	defer pc.Unlock()
+	for appID, left := range pc.runApps {
+		if now.Sub(left) > runAppRetention {
+			delete(pc.runApps, appID)
+		}
+	}
+}
+
 // Remove the application from the partition.
 // This does not fail and handles missing app/queue/node/allocations internally
 func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocation {
@@ -398,6 +992,7 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 	if app == nil {
 		return nil
 	}
+	pc.recordAppRun(app)
 	// Remove all asks and thus all reservations and pending resources (queue included)
 	_ = app.RemoveAllocationAsk("")
 	// Remove app from queue
@@ -411,6 +1006,7 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 		// track the number of allocations
 		pc.updateAllocationCount(-len(allocations))
 		for _, alloc := range allocations {
+			pc.recordAllocationEvent(AllocationReleased, alloc, app.GetQueuePath())
 			currentAllocationKey := alloc.GetAllocationKey()
 			node := pc.GetNode(alloc.GetNodeID())
 			if node == nil {
@@ -426,6 +1022,8 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 					zap.String("nodeID", alloc.GetNodeID()))
 			}
 		}
+		// released resources might fit requests that failed to find a node before
+		pc.root.ResetPlacementBackoff()
 	}
 	return allocations
 }
@@ -442,6 +1040,7 @@ func (pc *PartitionContext) removeAppInternal(appID string) *objects.Application
 	}
 	// remove from partition then cleanup underlying objects
 	delete(pc.applications, appID)
+	delete(pc.zeroRequestApps, appID)
 	return app
 }
 
@@ -463,6 +1062,83 @@ func (pc *PartitionContext) getRejectedApplication(appID string) *objects.Applic
 	return pc.rejectedApplications[appID]
 }
 
+// queueStateSnapshot is the serialised queue state of a partition.
+type queueStateSnapshot struct {
+	Partition string                 `json:"partition"`
+	Root      *objects.QueueSnapshot `json:"root"`
+}
+
+// SnapshotQueues returns the queue accounting state of the partition: the queue paths, the allocated resources and
+// the applications in each leaf queue.
+func (pc *PartitionContext) SnapshotQueues() ([]byte, error) {
+	return json.Marshal(&queueStateSnapshot{
+		Partition: pc.Name,
+		Root:      pc.root.Snapshot(),
+	})
+}
+
+// RestoreQueues restores the queue accounting state of the partition from a snapshot created by SnapshotQueues.
+// The queues must have been created from the current configuration. Queues that were removed or changed type since
+// the snapshot was taken are skipped. Returns the application to queue mapping of the restored queues.
+func (pc *PartitionContext) RestoreQueues(blob []byte) (map[string]string, error) {
+	snapshot := &queueStateSnapshot{}
+	if err := json.Unmarshal(blob, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid queue snapshot for partition %s: %w", pc.Name, err)
+	}
+	if snapshot.Partition != pc.Name {
+		return nil, fmt.Errorf("queue snapshot for partition %s cannot be restored in partition %s", snapshot.Partition, pc.Name)
+	}
+	if snapshot.Root == nil {
+		return nil, fmt.Errorf("queue snapshot for partition %s has no root queue", pc.Name)
+	}
+	placement := pc.root.RestoreSnapshot(snapshot.Root)
+	log.Log(log.SchedPartition).Info("restored queue state from snapshot",
+		zap.String("partitionName", pc.Name),
+		zap.Int("applications", len(placement)))
+	return placement, nil
+}
+
+// ReconcileQueues recalculates the allocated resources of all queues in the partition from the applications.
+// Can be called on demand or periodically to correct any drift in the queue accounting.
+// Returns true if at least one queue was corrected.
+func (pc *PartitionContext) ReconcileQueues() bool {
+	return pc.root.ReconcileAllocatedResource()
+}
+
+// CheckQueueCapacity compares the maximum resources configured for the queues with the capacity of the nodes that are
+// registered in the partition. A queue maximum larger than the partition capacity never limits the queue, which is
+// most likely a configuration mistake. The diagnostics returned are non-fatal and are also logged as warnings.
+// Nothing is returned if there are no nodes registered.
+func (pc *PartitionContext) CheckQueueCapacity() []string {
+	capacity := pc.GetTotalPartitionResource()
+	if resources.IsZero(capacity) {
+		return nil
+	}
+	diagnostics := pc.root.CheckMaxResource(capacity)
+	for _, diagnostic := range diagnostics {
+		log.Log(log.SchedPartition).Warn("queue maximum exceeds partition capacity",
+			zap.String("partitionName", pc.Name),
+			zap.String("diagnostic", diagnostic))
+	}
+	return diagnostics
+}
+
+// GetGuaranteedShareCandidates returns the queues in the partition that use more than their guaranteed resource,
+// the donor candidates for preemption, and the queues that use less, the needy candidates.
+// See Queue.GetGuaranteedShareCandidates for the ordering of the lists.
+func (pc *PartitionContext) GetGuaranteedShareCandidates() ([]*objects.Queue, []*objects.Queue) {
+	return pc.root.GetGuaranteedShareCandidates()
+}
+
+// SetAdmissionHook registers the hook consulted before an allocation is committed in the partition, replacing any
+// hook registered before. A vetoed allocation is rejected with the reason returned by the hook. A nil hook removes
+// the registered hook and allows all allocations.
+// Requests backed off after a veto are retried in the next scheduling cycle.
+func (pc *PartitionContext) SetAdmissionHook(hook objects.AdmissionHook) {
+	pc.root.SetAdmissionHook(hook)
+	pc.root.ResetPlacementBackoff()
+}
+
 // GetQueue returns queue from the structure based on the fully qualified name.
 // Wrapper around the unlocked version getQueueInternal()
 // Visible by tests
@@ -495,6 +1171,28 @@ func (pc *PartitionContext) getQueueInternal(name string) *objects.Queue {
 	return queue
 }
 
+// GetDeepestQueue walks the dotted queue path down from the root as far as the queues exist. It returns the deepest
+// existing queue on the path and the part of the path below it that did not match, empty if the whole path matched.
+// A path that does not start at the root does not match: nil and the whole path are returned.
+func (pc *PartitionContext) GetDeepestQueue(path string) (*objects.Queue, string) {
+	pc.RLock()
+	defer pc.RUnlock()
+	part := strings.Split(path, configs.DOT)
+	if strings.ToLower(part[0]) != configs.RootQueue {
+		return nil, path
+	}
+	queue := pc.root
+	i := 1
+	for ; i < len(part); i++ {
+		child := queue.GetChildQueue(strings.ToLower(part[i]))
+		if child == nil {
+			break
+		}
+		queue = child
+	}
+	return queue, strings.Join(part[i:], configs.DOT)
+}
+
 // GetPartitionQueues builds the queue info for the whole queue structure to pass to the webservice
 func (pc *PartitionContext) GetPartitionQueues() dao.PartitionQueueDAOInfo {
 	partitionQueueDAOInfo := pc.root.GetPartitionQueueDAOInfo(true)
@@ -572,6 +1270,8 @@ func (pc *PartitionContext) AddNode(node *objects.Node) error {
 	if pc.isDraining() || pc.isStopped() {
 		return fmt.Errorf("partition %s is stopped cannot add a new node %s", pc.Name, node.NodeID)
 	}
+	// the node is not part of the partition yet: no need to track the capacity change
+	node.SetCapacity(pc.applyOvercommit(pc.applyNodeOverhead(node, node.GetCapacity())))
 	if err := pc.addNodeToList(node); err != nil {
 		return err
 	}
@@ -594,6 +1294,9 @@ func (pc *PartitionContext) updatePartitionResource(delta *resources.Resource) {
 		pc.totalPartitionResource.Prune()
 		// set the root queue size
 		pc.root.SetMaxResource(pc.totalPartitionResource)
+		pc.updateFairShareResource()
+		// requests that failed to find a node before might fit now
+		pc.root.ResetPlacementBackoff()
 	}
 }
 
@@ -625,6 +1328,9 @@ func (pc *PartitionContext) removeNodeFromList(nodeID string) *objects.Node {
 		return nil
 	}
 
+	pc.Lock()
+	delete(pc.cordonedNodes, nodeID)
+	pc.Unlock()
 	// Remove node from list of tracked nodes
 	metrics.GetSchedulerMetrics().DecActiveNodes()
 	log.Log(log.SchedPartition).Info("Removed node from available partition nodes",
@@ -733,6 +1439,8 @@ func (pc *PartitionContext) removeNodeAllocations(node *objects.Node) ([]*object
 					confirmed = append(confirmed, release)
 					// the allocation is removed so add it to the list that we return
 					released = append(released, alloc)
+					pc.recordAllocationEvent(AllocationReleased, alloc, queue.GetQueuePath())
+					pc.recordAllocationEvent(AllocationReplaced, release, queue.GetQueuePath())
 					log.Log(log.SchedPartition).Info("allocation removed from node and replacement confirmed",
 						zap.String("nodeID", node.NodeID),
 						zap.String("allocationKey", allocationKey),
@@ -784,6 +1492,7 @@ func (pc *PartitionContext) removeNodeAllocations(node *objects.Node) ([]*object
 
 		// the allocation is removed so add it to the list that we return
 		released = append(released, alloc)
+		pc.recordAllocationEvent(AllocationReleased, alloc, queue.GetQueuePath())
 		metrics.GetQueueMetrics(queue.GetQueuePath()).IncReleasedContainer()
 		log.Log(log.SchedPartition).Info("node removal: allocation removed",
 			zap.String("nodeID", node.NodeID),
@@ -796,6 +1505,65 @@ func (pc *PartitionContext) removeNodeAllocations(node *objects.Node) ([]*object
 	return released, confirmed
 }
 
+// FindOrphanedAllocations scans the partition for allocations that reference an application or node that no longer
+// exists. Allocations on a node without an application and allocations of an application without a node are returned.
+// If clean is set the orphaned allocations are removed from the node or application that still references them and
+// the queue usage is decreased for the allocations of an application.
+func (pc *PartitionContext) FindOrphanedAllocations(clean bool) []*objects.Allocation {
+	orphans := make([]*objects.Allocation, 0)
+	// allocations on a node for an application that does not exist
+	for _, node := range pc.GetNodes() {
+		for _, alloc := range node.GetYunikornAllocations() {
+			if pc.getApplication(alloc.GetApplicationID()) != nil {
+				continue
+			}
+			orphans = append(orphans, alloc)
+			log.Log(log.SchedPartition).Warn("orphaned allocation found: application does not exist",
+				zap.String("appID", alloc.GetApplicationID()),
+				zap.String("allocationKey", alloc.GetAllocationKey()),
+				zap.String("nodeID", node.NodeID),
+				zap.Bool("clean", clean))
+			if clean {
+				node.RemoveAllocation(alloc.GetAllocationKey())
+			}
+		}
+	}
+	// allocations of an application on a node that does not exist
+	for _, app := range pc.GetApplications() {
+		queue := app.GetQueue()
+		for _, alloc := range app.GetAllAllocations() {
+			if pc.GetNode(alloc.GetNodeID()) != nil {
+				continue
+			}
+			orphans = append(orphans, alloc)
+			log.Log(log.SchedPartition).Warn("orphaned allocation found: node does not exist",
+				zap.String("appID", app.ApplicationID),
+				zap.String("allocationKey", alloc.GetAllocationKey()),
+				zap.String("nodeID", alloc.GetNodeID()),
+				zap.Bool("clean", clean))
+			if !clean || app.RemoveAllocation(alloc.GetAllocationKey(), si.TerminationType_UNKNOWN_TERMINATION_TYPE) == nil {
+				continue
+			}
+			if queue != nil {
+				if err := queue.DecAllocatedResource(alloc.GetAllocatedResource()); err != nil {
+					log.Log(log.SchedPartition).Warn("failed to release resources from queue",
+						zap.String("appID", app.ApplicationID),
+						zap.Error(err))
+				}
+				if alloc.IsPreempted() {
+					queue.DecPreemptingResource(alloc.GetAllocatedResource())
+				}
+				pc.recordAllocationEvent(AllocationReleased, alloc, queue.GetQueuePath())
+			}
+			if alloc.IsPlaceholder() {
+				pc.decPhAllocationCount(1)
+			}
+			pc.updateAllocationCount(-1)
+		}
+	}
+	return orphans
+}
+
 func (pc *PartitionContext) calculateOutstandingRequests() []*objects.Allocation {
 	if !resources.StrictlyGreaterThanZero(pc.root.GetPendingResource()) {
 		return nil
@@ -805,6 +1573,36 @@ func (pc *PartitionContext) calculateOutstandingRequests() []*objects.Allocation
 	return outstanding
 }
 
+// PreemptionDryRun reports the victims preemption would select to place the pending ask of the application. Nothing
+// is preempted, the report only shows what would happen.
+func (pc *PartitionContext) PreemptionDryRun(appID, allocKey string) (*objects.PreemptionReport, error) {
+	app := pc.GetApplication(appID)
+	if app == nil {
+		return nil, fmt.Errorf("application %s not found in partition %s", appID, pc.Name)
+	}
+	return app.PreemptionDryRun(allocKey, pc.GetFullNodeIterator())
+}
+
+// GetPreemptionVictims reports the victims preemption would select to place the ask, the ask can be placed if the
+// report has no reason set. The application of the ask must exist in the partition, the ask itself does not need to
+// be added. See Application.GetPreemptionVictims for details. Nothing is preempted.
+func (pc *PartitionContext) GetPreemptionVictims(ask *objects.Allocation) (*objects.PreemptionReport, error) {
+	if ask == nil {
+		return nil, fmt.Errorf("no ask to report preemption victims for in partition %s", pc.Name)
+	}
+	app := pc.GetApplication(ask.GetApplicationID())
+	if app == nil {
+		return nil, fmt.Errorf("application %s not found in partition %s", ask.GetApplicationID(), pc.Name)
+	}
+	return app.GetPreemptionVictims(ask, pc.GetFullNodeIterator())
+}
+
+// SimulateAllocations reports for each ask where it would be placed in the partition, or why it would not be placed,
+// based on the current queue usage and node availability. The partition is not changed.
+func (pc *PartitionContext) SimulateAllocations(asks []*objects.SimulationAsk) []*objects.SimulationResult {
+	return objects.SimulateAllocations(pc.root, pc.GetNodes(), asks)
+}
+
 // Try regular allocation for the partition
 // Lock free call this all locks are taken when needed in called functions
 func (pc *PartitionContext) tryAllocate() *objects.AllocationResult {
@@ -812,6 +1610,11 @@ func (pc *PartitionContext) tryAllocate() *objects.AllocationResult {
 		// nothing to do just return
 		return nil
 	}
+	if pc.isAllocationLimitReached() {
+		log.Log(log.SchedPartition).Debug("partition allocation limit reached, skipping allocation",
+			zap.String("partitionName", pc.Name))
+		return nil
+	}
 	// try allocating from the root down
 	result := pc.root.TryAllocate(pc.GetNodeIterator, pc.GetFullNodeIterator, pc.GetNode, pc.IsPreemptionEnabled())
 	if result != nil {
@@ -830,6 +1633,11 @@ func (pc *PartitionContext) tryReservedAllocate() *objects.AllocationResult {
 		// nothing to do just return
 		return nil
 	}
+	if pc.isAllocationLimitReached() {
+		log.Log(log.SchedPartition).Debug("partition allocation limit reached, skipping reserved allocation",
+			zap.String("partitionName", pc.Name))
+		return nil
+	}
 	// try allocating from the root down
 	result := pc.root.TryReservedAllocate(pc.GetNodeIterator)
 	if result != nil {
@@ -934,7 +1742,7 @@ func (pc *PartitionContext) allocate(result *objects.AllocationResult) *objects.
 		result.ReservedNodeID = ""
 	}
 
-	alloc.SetBindTime(time.Now())
+	alloc.SetBindTime(objects.Now())
 	alloc.SetNodeID(targetNodeID)
 	alloc.SetInstanceType(targetNode.GetInstanceType())
 
@@ -943,17 +1751,26 @@ func (pc *PartitionContext) allocate(result *objects.AllocationResult) *objects.
 	if result.Request.IsPlaceholder() {
 		pc.incPhAllocationCount()
 	}
+	pc.recordAllocationEvent(AllocationAllocated, alloc, app.GetQueuePath())
 
-	log.Log(log.SchedPartition).Info("scheduler allocation processed",
-		zap.String("appID", result.Request.GetApplicationID()),
-		zap.String("allocationKey", result.Request.GetAllocationKey()),
-		zap.Stringer("allocatedResource", result.Request.GetAllocatedResource()),
-		zap.Bool("placeholder", result.Request.IsPlaceholder()),
-		zap.String("targetNode", targetNodeID))
+	if isAllocationLogged(app.GetQueue(), result.Request.GetAllocationKey()) {
+		log.Log(log.SchedPartition).Info("scheduler allocation processed",
+			zap.String("appID", result.Request.GetApplicationID()),
+			zap.String("allocationKey", result.Request.GetAllocationKey()),
+			zap.Stringer("allocatedResource", result.Request.GetAllocatedResource()),
+			zap.Bool("placeholder", result.Request.IsPlaceholder()),
+			zap.String("targetNode", targetNodeID))
+	}
 	// pass the allocation result back to the RM via the cluster context
 	return result
 }
 
+// isAllocationLogged returns true if the allocation must be logged at the allocation log points, based on the
+// allocation log sample of the queue. Allocations are always logged if the queue is not known.
+func isAllocationLogged(queue *objects.Queue, allocationKey string) bool {
+	return queue == nil || queue.IsAllocationLogged(allocationKey)
+}
+
 // Process the reservation in the scheduler
 // Lock free call this must be called holding the context lock
 func (pc *PartitionContext) reserve(app *objects.Application, node *objects.Node, ask *objects.Allocation) {
@@ -1116,7 +1933,8 @@ func (pc *PartitionContext) getCompletedAppsByState(state string) []string {
 	return pc.getAppsState(pc.completedApplications, state)
 }
 
-// cleanupExpiredApps cleans up applications in the Expired state from the three tracking maps
+// cleanupExpiredApps cleans up applications in the Expired state from the three tracking maps, and forgets the
+// applications that have been running and left the partition outside the retention
 func (pc *PartitionContext) cleanupExpiredApps() {
 	for _, appID := range pc.getAppsByState(objects.Expired.String()) {
 		pc.Lock()
@@ -1133,6 +1951,70 @@ func (pc *PartitionContext) cleanupExpiredApps() {
 		delete(pc.completedApplications, appID)
 		pc.Unlock()
 	}
+	pc.pruneRunApps(objects.Now())
+}
+
+// getPreemptionGracePeriod returns the time a preempted allocation is kept for a graceful shutdown.
+func (pc *PartitionContext) getPreemptionGracePeriod() time.Duration {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.preemptionGracePeriod
+}
+
+// releasePreemptedAllocations releases the allocations that were marked for preemption longer than the grace period
+// ago without the RM confirming the termination. The resources of a preempted allocation are not reused until the RM
+// confirms or the grace period has passed. Nothing is released if no grace period is set.
+func (pc *PartitionContext) releasePreemptedAllocations(now time.Time) []*objects.Allocation {
+	grace := pc.getPreemptionGracePeriod()
+	if grace <= 0 {
+		return nil
+	}
+	var released []*objects.Allocation
+	for _, app := range pc.GetApplications() {
+		for _, alloc := range app.GetAllAllocations() {
+			if !alloc.IsPreempted() || now.Sub(alloc.GetPreemptedTime()) < grace {
+				continue
+			}
+			log.Log(log.SchedPartition).Info("preemption grace period passed, releasing allocation",
+				zap.String("appID", alloc.GetApplicationID()),
+				zap.String("allocationKey", alloc.GetAllocationKey()),
+				zap.Stringer("gracePeriod", grace))
+			// the RM was notified when the allocation was preempted: the release is not sent again
+			pc.removeAllocation(&si.AllocationRelease{
+				PartitionName:   pc.Name,
+				ApplicationID:   alloc.GetApplicationID(),
+				AllocationKey:   alloc.GetAllocationKey(),
+				TerminationType: si.TerminationType_PREEMPTED_BY_SCHEDULER,
+				Message:         "preemption grace period passed",
+			})
+			released = append(released, alloc)
+		}
+	}
+	return released
+}
+
+// failDeadlineExceededApplications fails the applications in the partition that are not fully allocated when their
+// scheduling deadline passes. Returns the applications that were failed.
+func (pc *PartitionContext) failDeadlineExceededApplications() []*objects.Application {
+	var failed []*objects.Application
+	for _, app := range pc.GetApplications() {
+		if app.CheckDeadline() {
+			failed = append(failed, app)
+		}
+	}
+	return failed
+}
+
+// failUnresolvedDependencyApplications fails the applications in the partition that wait longer than the
+// dependencyTimeout for a dependency that is not known in the partition. Returns the applications that were failed.
+func (pc *PartitionContext) failUnresolvedDependencyApplications() []*objects.Application {
+	var failed []*objects.Application
+	for _, app := range pc.GetApplications() {
+		if app.CheckDependencyTimeout(pc.isAppKnown, dependencyTimeout) {
+			failed = append(failed, app)
+		}
+	}
+	return failed
 }
 
 // GetNodes returns a slice of all nodes unfiltered from the iterator
@@ -1140,6 +2022,74 @@ func (pc *PartitionContext) GetNodes() []*objects.Node {
 	return pc.nodes.GetNodes()
 }
 
+// CordonNode cordons the node, or removes the cordon if cordon is false. A cordoned node is skipped during scheduling
+// and its capacity is not schedulable. The allocations on the node are not changed. The cordon fair share policy
+// decides if the capacity of the node is part of the fair share of the queues.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) CordonNode(nodeID string, cordon bool) error {
+	node := pc.GetNode(nodeID)
+	if node == nil {
+		return fmt.Errorf("node %s not found in partition %s", nodeID, pc.Name)
+	}
+	node.SetCordoned(cordon)
+	pc.Lock()
+	defer pc.Unlock()
+	if cordon {
+		pc.cordonedNodes[nodeID] = true
+	} else {
+		delete(pc.cordonedNodes, nodeID)
+		// requests that failed to find a node before might fit now
+		pc.root.ResetPlacementBackoff()
+	}
+	pc.updateFairShareResource()
+	log.Log(log.SchedPartition).Info("node cordon state changed",
+		zap.String("partitionName", pc.Name),
+		zap.String("nodeID", nodeID),
+		zap.Bool("cordoned", cordon))
+	return nil
+}
+
+// GetSchedulableResource returns the total node resources of the partition without the capacity of cordoned nodes.
+func (pc *PartitionContext) GetSchedulableResource() *resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	return resources.SubEliminateNegative(pc.totalPartitionResource, pc.getCordonedResource())
+}
+
+// getCordonedResource returns the total capacity of the cordoned nodes.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) getCordonedResource() *resources.Resource {
+	cordoned := resources.NewResource()
+	for nodeID := range pc.cordonedNodes {
+		if node := pc.nodes.GetNode(nodeID); node != nil {
+			cordoned.AddTo(node.GetCapacity())
+		}
+	}
+	return cordoned
+}
+
+// updateFairShareResource sets the fair share base on the root queue. The capacity of cordoned nodes is only removed
+// from the base if the policy excludes it, otherwise the base is the root queue maximum.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateFairShareResource() {
+	if pc.cordonFairShare != policies.ExcludeCordonFairSharePolicy || len(pc.cordonedNodes) == 0 {
+		pc.root.SetFairShareResource(nil)
+		return
+	}
+	pc.root.SetFairShareResource(resources.SubEliminateNegative(pc.totalPartitionResource, pc.getCordonedResource()))
+}
+
+// GetReservedUnusedResource returns the resource reserved on the nodes of the partition for asks that have not been
+// allocated. The total follows the reservations: it increases when a node is reserved and decreases when the
+// reservation is released or allocated.
+func (pc *PartitionContext) GetReservedUnusedResource() *resources.Resource {
+	total := resources.NewResource()
+	for _, node := range pc.GetNodes() {
+		total.AddTo(node.GetReservedResource())
+	}
+	return total
+}
+
 // UpdateAllocation adds or updates an Allocation. If the Allocation has no NodeID specified, it is considered a
 // pending allocation and processed appropriate. This call is idempotent, and can be called multiple times with the
 // same allocation (such as on change updates from the shim)
@@ -1166,6 +2116,8 @@ func (pc *PartitionContext) UpdateAllocation(alloc *objects.Allocation) (request
 		zap.String("allocationKey", allocationKey))
 
 	if alloc.IsForeign() {
+		// round the foreign allocation up in the same way as allocations on the node that are tracked
+		alloc.SetAllocatedResource(pc.applyGranularity(alloc.GetAllocatedResource()))
 		return pc.handleForeignAllocation(allocationKey, applicationID, nodeID, node, alloc)
 	}
 
@@ -1189,12 +2141,20 @@ func (pc *PartitionContext) UpdateAllocation(alloc *objects.Allocation) (request
 	res := alloc.GetAllocatedResource()
 	if resources.IsZero(res) {
 		metrics.GetSchedulerMetrics().IncSchedulingError()
+		if err = pc.rejectZeroRequestApp(app); err != nil {
+			return false, false, err
+		}
 		return false, false, fmt.Errorf("allocation contains no resources")
 	}
 	if !resources.StrictlyGreaterThanZero(res) {
 		metrics.GetSchedulerMetrics().IncSchedulingError()
 		return false, false, fmt.Errorf("allocation contains negative resources")
 	}
+	// round up before any fit checks and accounting: new requests, recovered allocations and updates all use the
+	// rounded resource, an update thus never shrinks a rounded request or allocation back
+	submitted := res
+	res = pc.applyGranularity(res)
+	alloc.SetAllocatedResource(res)
 
 	// check to see if allocation exists already on app
 	existing := app.GetAllocationAsk(allocationKey)
@@ -1208,6 +2168,41 @@ func (pc *PartitionContext) UpdateAllocation(alloc *objects.Allocation) (request
 				zap.String("appID", applicationID),
 				zap.String("allocationKey", allocationKey))
 
+			// check the request as submitted, before rounding up
+			if policy, err := pc.checkMinRequest(submitted); err != nil {
+				if policy == policies.RejectMinRequestPolicy {
+					log.Log(log.SchedPartition).Info("rejecting request below minimum",
+						zap.String("partitionName", pc.Name),
+						zap.String("appID", applicationID),
+						zap.String("allocationKey", allocationKey),
+						zap.Error(err))
+					return false, false, err
+				}
+				log.Log(log.SchedPartition).Warn("request below minimum",
+					zap.String("partitionName", pc.Name),
+					zap.String("appID", applicationID),
+					zap.String("allocationKey", allocationKey),
+					zap.Error(err))
+			}
+			// the first request of an application routed to the zero request queue places the application
+			if err := pc.placeZeroRequestApp(app); err != nil {
+				log.Log(log.SchedPartition).Info("failed to place application on first request",
+					zap.String("partitionName", pc.Name),
+					zap.String("appID", applicationID),
+					zap.String("allocationKey", allocationKey),
+					zap.Error(err))
+				return false, false, err
+			}
+			queue = app.GetQueue()
+			// reject a request that can never be scheduled in the queue of the application
+			if err := queue.CheckAskSize(alloc); err != nil {
+				log.Log(log.SchedPartition).Info("rejecting request larger than queue maximum",
+					zap.String("partitionName", pc.Name),
+					zap.String("appID", applicationID),
+					zap.String("allocationKey", allocationKey),
+					zap.Error(err))
+				return false, false, err
+			}
 			if err := app.AddAllocationAsk(alloc); err != nil {
 				log.Log(log.SchedPartition).Info("failed to add request",
 					zap.String("partitionName", pc.Name),
@@ -1230,6 +2225,16 @@ func (pc *PartitionContext) UpdateAllocation(alloc *objects.Allocation) (request
 			zap.String("appID", applicationID),
 			zap.String("allocationKey", allocationKey))
 
+		// a recovered application routed to the zero request queue is placed before its first allocation is tracked
+		if err := pc.placeZeroRequestApp(app); err != nil {
+			log.Log(log.SchedPartition).Info("failed to place application on first allocation",
+				zap.String("partitionName", pc.Name),
+				zap.String("appID", applicationID),
+				zap.String("allocationKey", allocationKey),
+				zap.Error(err))
+			return false, false, err
+		}
+		queue = app.GetQueue()
 		queue.IncAllocatedResource(res)
 		metrics.GetQueueMetrics(queue.GetQueuePath()).IncAllocatedContainer()
 		node.AddAllocation(alloc)
@@ -1240,6 +2245,7 @@ func (pc *PartitionContext) UpdateAllocation(alloc *objects.Allocation) (request
 		if alloc.IsPlaceholder() {
 			pc.incPhAllocationCount()
 		}
+		pc.recordAllocationEvent(AllocationAllocated, alloc, queue.GetQueuePath())
 
 		log.Log(log.SchedPartition).Info("added existing allocation",
 			zap.String("partitionName", pc.Name),
@@ -1304,6 +2310,7 @@ func (pc *PartitionContext) UpdateAllocation(alloc *objects.Allocation) (request
 		if existing.IsPlaceholder() {
 			pc.incPhAllocationCount()
 		}
+		pc.recordAllocationEvent(AllocationAllocated, existing, queue.GetQueuePath())
 
 		log.Log(log.SchedPartition).Info("external allocation placed",
 			zap.String("partitionName", pc.Name),
@@ -1433,10 +2440,12 @@ func (pc *PartitionContext) processAllocationRelease(release *si.AllocationRelea
 				released = append(released, alloc)
 			}
 		} else {
-			log.Log(log.SchedPartition).Info("removing allocation from application",
-				zap.String("appID", app.ApplicationID),
-				zap.String("allocationKey", allocationKey),
-				zap.Stringer("terminationType", release.TerminationType))
+			if isAllocationLogged(app.GetQueue(), allocationKey) {
+				log.Log(log.SchedPartition).Info("removing allocation from application",
+					zap.String("appID", app.ApplicationID),
+					zap.String("allocationKey", allocationKey),
+					zap.Stringer("terminationType", release.TerminationType))
+			}
 			if alloc := app.RemoveAllocation(allocationKey, release.TerminationType); alloc != nil {
 				released = append(released, alloc)
 			}
@@ -1522,9 +2531,11 @@ func (pc *PartitionContext) removeAllocation(release *si.AllocationRelease) ([]*
 		} else if node.RemoveAllocation(alloc.GetAllocationKey()) != nil {
 			// all non replacement are real removes: must update the queue usage
 			total.AddTo(alloc.GetAllocatedResource())
-			log.Log(log.SchedPartition).Info("removing allocation from node",
-				zap.String("nodeID", alloc.GetNodeID()),
-				zap.String("allocationKey", alloc.GetAllocationKey()))
+			if isAllocationLogged(queue, alloc.GetAllocationKey()) {
+				log.Log(log.SchedPartition).Info("removing allocation from node",
+					zap.String("nodeID", alloc.GetNodeID()),
+					zap.String("allocationKey", alloc.GetAllocationKey()))
+			}
 		}
 		if alloc.IsPreempted() {
 			totalPreempting.AddTo(alloc.GetAllocatedResource())
@@ -1538,11 +2549,19 @@ func (pc *PartitionContext) removeAllocation(release *si.AllocationRelease) ([]*
 				zap.String("allocationKey", allocationKey),
 				zap.Error(err))
 		}
+		// released resources might fit requests that failed to find a node before
+		pc.root.ResetPlacementBackoff()
 	}
 	if resources.StrictlyGreaterThanZero(totalPreempting) {
 		queue.DecPreemptingResource(totalPreempting)
 	}
 
+	for _, alloc := range released {
+		pc.recordAllocationEvent(AllocationReleased, alloc, queue.GetQueuePath())
+	}
+	if confirmed != nil {
+		pc.recordAllocationEvent(AllocationReplaced, confirmed, queue.GetQueuePath())
+	}
 	// if confirmed is set we can assume there will just be one alloc in the released
 	// that allocation was already released by the shim, so clean up released
 	if confirmed != nil {
@@ -1650,6 +2669,7 @@ func (pc *PartitionContext) moveTerminatedApp(appID string) {
 		zap.String("appID", appID),
 		zap.String("app status", app.CurrentState()))
 	app.LogAppSummary(pc.RmID)
+	pc.recordAppRun(app)
 	pc.Lock()
 	defer pc.Unlock()
 	delete(pc.applications, appID)
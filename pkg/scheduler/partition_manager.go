@@ -53,11 +53,15 @@ func newPartitionManager(pc *PartitionContext, cc *ClusterContext) *partitionMan
 }
 
 // Run the manager for the partition.
-// The manager has four tasks:
+// The manager has seven tasks:
 // - clean up the managed queues that are empty and removed from the configuration
 // - remove empty unmanaged queues
+// - purge completed applications retained in the queues after the retention passed
 // - remove completed applications from the partition
 // - remove rejected applications from the partition
+// - release preempted allocations after the preemption grace period passed
+// - fail applications that are not fully allocated when their deadline passed
+// - fail applications that wait for a dependency that is not known in the partition
 // When the manager exits the partition is removed from the system and must be cleaned up
 func (manager *partitionManager) Run() {
 	log.Log(log.SchedPartition).Info("starting partition manager",
@@ -81,6 +85,9 @@ func (manager *partitionManager) cleanRoot() {
 		case <-time.After(cleanRootInterval):
 			runStart := time.Now()
 			manager.cleanQueues(manager.pc.root)
+			manager.pc.releasePreemptedAllocations(runStart)
+			manager.pc.failDeadlineExceededApplications()
+			manager.pc.failUnresolvedDependencyApplications()
 			log.Log(log.SchedPartition).Debug("time consumed for queue cleaner",
 				zap.Stringer("duration", time.Since(runStart)))
 		}
@@ -109,6 +116,8 @@ func (manager *partitionManager) cleanQueues(queue *objects.Queue) {
 			manager.cleanQueues(child)
 		}
 	}
+	// drop the completed applications that passed the retention
+	queue.PurgeCompletedApps()
 	// when we have done the children (or have none) this queue might be removable
 	if queue.IsDraining() || !queue.IsManaged() {
 		log.Log(log.SchedPartition).Debug("removing queue",
@@ -21,6 +21,7 @@ package scheduler
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -291,6 +292,61 @@ func TestRemoveNodeWithAllocations(t *testing.T) {
 	assert.NilError(t, err, "the event should have been processed")
 }
 
+func TestFindOrphanedAllocations(t *testing.T) {
+	setupUGM()
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000000})
+	node1 := newNodeMaxResource(nodeID1, nodeRes)
+	err = partition.AddNode(node1)
+	assert.NilError(t, err, "add node-1 to partition should not have failed")
+	node2 := newNodeMaxResource(nodeID2, nodeRes)
+	err = partition.AddNode(node2)
+	assert.NilError(t, err, "add node-2 to partition should not have failed")
+	appRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000})
+	for _, alloc := range []*objects.Allocation{newAllocation("alloc-1", appID1, nodeID1, appRes), newAllocation("alloc-2", appID1, nodeID2, appRes)} {
+		_, allocCreated, err := partition.UpdateAllocation(alloc)
+		assert.NilError(t, err, "failed to add allocation")
+		assert.Check(t, allocCreated)
+	}
+	assert.Equal(t, partition.GetTotalAllocationCount(), 2, "allocations not counted")
+
+	// consistent partition: nothing found
+	assert.Equal(t, len(partition.FindOrphanedAllocations(true)), 0, "no orphans expected")
+
+	// inject an allocation without an app and an allocation without a node
+	node2.AddAllocation(newAllocation("alloc-na", "not-an-app", nodeID2, appRes))
+	partition.removeNodeFromList(nodeID1)
+
+	// report only: nothing changes
+	orphans := partition.FindOrphanedAllocations(false)
+	assert.Equal(t, len(orphans), 2, "orphans not detected")
+	// nodes are scanned before applications
+	assert.Equal(t, orphans[0].GetAllocationKey(), "alloc-na", "orphan without app not detected")
+	assert.Equal(t, orphans[1].GetAllocationKey(), "alloc-1", "orphan without node not detected")
+	assert.Equal(t, len(node2.GetYunikornAllocations()), 2, "report should not remove from the node")
+	assert.Equal(t, len(app.GetAllAllocations()), 2, "report should not remove from the app")
+	queue := partition.GetQueue(defQueue)
+	assert.Assert(t, resources.Equals(queue.GetAllocatedResource(), resources.Multiply(appRes, 2)), "report should not change the queue")
+
+	// clean: removed from node, app, queue and user
+	orphans = partition.FindOrphanedAllocations(true)
+	assert.Equal(t, len(orphans), 2, "orphans not detected")
+	assert.Equal(t, len(node2.GetYunikornAllocations()), 1, "orphan without app not removed from the node")
+	allocs := app.GetAllAllocations()
+	assert.Equal(t, len(allocs), 1, "orphan without node not removed from the app")
+	assert.Equal(t, allocs[0].GetAllocationKey(), "alloc-2", "valid allocation should not be removed from the app")
+	assert.Assert(t, resources.Equals(queue.GetAllocatedResource(), appRes), "queue usage not decreased")
+	assert.Assert(t, resources.Equals(partition.root.GetAllocatedResource(), appRes), "root usage not decreased")
+	assert.Equal(t, partition.GetTotalAllocationCount(), 1, "allocation count not decreased")
+	assertLimits(t, getTestUserGroup(), appRes)
+	assert.Equal(t, len(partition.FindOrphanedAllocations(true)), 0, "no orphans expected after clean")
+}
+
 // test with a replacement of a placeholder: placeholder and real on the same node that gets removed
 func TestRemoveNodeWithPlaceholders(t *testing.T) {
 	setupUGM()
@@ -1538,205 +1594,1029 @@ func TestGetQueue(t *testing.T) {
 	assert.Equal(t, queue, parent, "partition returned nil for existing queue name request")
 }
 
-func TestTryAllocate(t *testing.T) {
-	setupUGM()
+func TestGetDeepestQueue(t *testing.T) {
 	partition := createQueuesNodes(t)
 	assert.Assert(t, partition != nil, "partition create failed")
-	if result := partition.tryAllocate(); result != nil {
-		t.Fatalf("empty cluster allocate returned allocation: %s", result)
+	tests := []struct {
+		name      string
+		path      string
+		queue     string
+		remainder string
+	}{
+		{"root only", "root", "root", ""},
+		{"full match leaf", "root.leaf", "root.leaf", ""},
+		{"full match nested", "root.parent.sub-leaf", "root.parent.sub-leaf", ""},
+		{"full match mixed case", "Root.Parent.Sub-Leaf", "root.parent.sub-leaf", ""},
+		{"partial match below root", "root.unknown", "root", "unknown"},
+		{"partial match below parent", "root.parent.new.child", "root.parent", "new.child"},
+		{"partial match below leaf", "root.leaf.child", "root.leaf", "child"},
+		{"no match unqualified", "parent.sub-leaf", "", "parent.sub-leaf"},
+		{"no match empty", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue, remainder := partition.GetDeepestQueue(tt.path)
+			if tt.queue == "" {
+				assert.Assert(t, queue == nil, "no queue expected for %s, got %v", tt.path, queue)
+			} else {
+				assert.Assert(t, queue != nil, "queue expected for %s", tt.path)
+				assert.Equal(t, queue.GetQueuePath(), tt.queue, "wrong deepest queue")
+			}
+			assert.Equal(t, remainder, tt.remainder, "wrong unmatched remainder")
+		})
 	}
+}
 
-	// create a set of queues and apps: app-1 2 asks; app-2 1 ask (same size)
-	// sub-leaf will have an app with 2 requests and thus more unconfirmed resources compared to leaf2
-	// this should filter up in the parent and the 1st allocate should show an app-1 allocation
-	// the ask with the higher priority is the second one added alloc-2 for app-1
-	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+func TestTryAllocateAppDependency(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
 
 	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
 	assert.NilError(t, err, "failed to create resource")
 
-	// add to the partition
-	err = partition.AddApplication(app)
-	assert.NilError(t, err, "failed to add app-1 to partition")
-	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
-	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
-	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-2", appID1, res, 2))
-	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
-
-	app = newApplication(appID2, "default", "root.leaf")
-	// add to the partition
-	err = partition.AddApplication(app)
+	// app-2 depends on app-1 which is not submitted yet
+	app2 := newApplicationTags(appID2, "default", "root.parent.sub-leaf", map[string]string{common.AppTagDependsOn: appID1})
+	err = partition.AddApplication(app2)
 	assert.NilError(t, err, "failed to add app-2 to partition")
-	err = app.AddAllocationAsk(newAllocationAskPriority(allocKey, appID2, res, 2))
+	err = app2.AddAllocationAsk(newAllocationAsk(allocKey, appID2, res))
 	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("app-2 should be held without its dependency, got allocation: %s", result)
+	}
 
-	expectedQueuesMaxLimits := make(map[string]map[string]interface{})
-	expectedQueuesMaxLimits["root"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root.leaf"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 10})
-	expectedQueuesMaxLimits["root.leaf"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcores": 5})
-	expectedQueuesMaxLimits["root"][maxapplications] = uint64(10)
-	expectedQueuesMaxLimits["root.leaf"][maxapplications] = uint64(1)
-	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), nil, expectedQueuesMaxLimits)
-
-	// first allocation should be app-1 and alloc-2
+	// app-1 is submitted but not running yet: app-2 is still held, app-1 gets the allocation
+	app1 := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
 	result := partition.tryAllocate()
 	if result == nil || result.Request == nil {
 		t.Fatal("allocation did not return any allocation")
 	}
-	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
-	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
 	assert.Equal(t, result.Request.GetApplicationID(), appID1, "expected application app-1 to be allocated")
-	assert.Equal(t, result.Request.GetAllocationKey(), allocKey2, "expected ask alloc-2 to be allocated")
-	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.Multiply(res, 1), expectedQueuesMaxLimits)
+	assert.Assert(t, app1.IsRunning(), "app-1 should be running after the allocation")
+	assert.Assert(t, app2.IsAccepted(), "app-2 should still be accepted")
 
-	// second allocation should be app-2 and alloc-1: higher up in the queue hierarchy
+	// app-1 is running: app-2 is released
 	result = partition.tryAllocate()
 	if result == nil || result.Request == nil {
 		t.Fatal("allocation did not return any allocation")
 	}
-	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
-	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
 	assert.Equal(t, result.Request.GetApplicationID(), appID2, "expected application app-2 to be allocated")
-	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
-	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.Multiply(res, 2), expectedQueuesMaxLimits)
+}
 
-	// third allocation should be app-1 and alloc-1
-	result = partition.tryAllocate()
+func TestTryAllocateMetadata(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	ask := objects.NewAllocationFromSI(&si.Allocation{
+		AllocationKey:    allocKey,
+		ApplicationID:    appID1,
+		PartitionName:    "test",
+		ResourcePerAlloc: res.ToProto(),
+		AllocationTags: map[string]string{
+			common.AllocTagMetadataPrefix + "costCenter": "cc-1",
+			common.AllocTagMetadataPrefix + "team":       "infra",
+			"other":                                      "ignored",
+		},
+	})
+	var created bool
+	created, _, err = partition.UpdateAllocation(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	assert.Assert(t, created, "ask should have been created")
+
+	result := partition.tryAllocate()
 	if result == nil || result.Request == nil {
 		t.Fatal("allocation did not return any allocation")
 	}
 	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
-	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
-	assert.Equal(t, result.Request.GetApplicationID(), appID1, "expected application app-1 to be allocated")
-	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
-	assert.Assert(t, resources.IsZero(partition.root.GetPendingResource()), "pending resources should be set to zero")
-	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.Multiply(res, 3), expectedQueuesMaxLimits)
+	expected := map[string]string{"costCenter": "cc-1", "team": "infra"}
+	assert.DeepEqual(t, result.Request.GetMetadata(), expected)
+	allocs := app.GetAllAllocations()
+	assert.Equal(t, len(allocs), 1, "expected one committed allocation")
+	assert.DeepEqual(t, allocs[0].GetMetadata(), expected)
 }
 
-// allocate ask request with required node
-func TestRequiredNodeReservation(t *testing.T) {
+func TestTryAllocateNodeSelectionPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected []string
+	}{
+		{"binpack", policies.BinpackNodeSelectionPolicy.String(), []string{nodeID1, nodeID1, nodeID1, nodeID1}},
+		{"spread", policies.SpreadNodeSelectionPolicy.String(), []string{nodeID1, nodeID2, nodeID1, nodeID2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupUGM()
+			conf := configs.PartitionConfig{
+				Name: "test",
+				Queues: []configs.QueueConfig{
+					{
+						Name:      "root",
+						Parent:    true,
+						SubmitACL: "*",
+						Queues: []configs.QueueConfig{
+							{
+								Name:       "leaf",
+								Parent:     false,
+								Properties: map[string]string{configs.NodeSelectionPolicy: tt.policy},
+							},
+						},
+					},
+				},
+			}
+			partition, err := newPartitionContext(conf, rmID, nil, false)
+			assert.NilError(t, err, "partition create failed")
+			var res *resources.Resource
+			res, err = resources.NewResourceFromConf(map[string]string{"vcore": "10"})
+			assert.NilError(t, err, "failed to create node resource")
+			err = partition.AddNode(newNodeMaxResource(nodeID1, res))
+			assert.NilError(t, err, "test node1 add failed unexpected")
+			err = partition.AddNode(newNodeMaxResource(nodeID2, res))
+			assert.NilError(t, err, "test node2 add failed unexpected")
+
+			app := newApplication(appID1, "default", "root.leaf")
+			err = partition.AddApplication(app)
+			assert.NilError(t, err, "failed to add app-1 to partition")
+			res, err = resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+			assert.NilError(t, err, "failed to create ask resource")
+			for i := range tt.expected {
+				err = app.AddAllocationAsk(newAllocationAsk(fmt.Sprintf("alloc-%d", i), appID1, res))
+				assert.NilError(t, err, "failed to add ask to app-1")
+			}
+			nodes := make([]string, 0, len(tt.expected))
+			for range tt.expected {
+				result := partition.tryAllocate()
+				if result == nil || result.Request == nil {
+					t.Fatal("allocation did not return any allocation")
+				}
+				assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+				nodes = append(nodes, result.NodeID)
+			}
+			assert.DeepEqual(t, nodes, tt.expected)
+		})
+	}
+}
+
+func TestTryAllocatePausedApp(t *testing.T) {
 	setupUGM()
 	partition := createQueuesNodes(t)
 	assert.Assert(t, partition != nil, "partition create failed")
-	node := partition.nodes.GetNode(nodeID1)
-	if node == nil {
-		t.Fatal("node-1 should have been created")
-	}
-
-	app := newApplication(appID1, "default", "root.parent.sub-leaf")
-	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "8"})
-	assert.NilError(t, err, "failed to create resource")
-
-	// add to the partition
-	err = partition.AddApplication(app)
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
 	assert.NilError(t, err, "failed to add app-1 to partition")
-	ask := newAllocationAsk(allocKey, appID1, res)
-	ask.SetRequiredNode(nodeID1)
-	err = app.AddAllocationAsk(ask)
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
 	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
-	assertLimits(t, getTestUserGroup(), nil)
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey2, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
 
-	// first allocation should be app-1 and alloc-1
 	result := partition.tryAllocate()
 	if result == nil || result.Request == nil {
 		t.Fatal("allocation did not return any allocation")
 	}
 	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
-	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
-	assert.Equal(t, result.Request.GetApplicationID(), appID1, "expected application app-1 to be allocated")
-	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
-	assertLimits(t, getTestUserGroup(), res)
 
-	ask2 := newAllocationAsk(allocKey2, appID1, res)
-	ask2.SetRequiredNode(nodeID1)
-	err = app.AddAllocationAsk(ask2)
-	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
-	result = partition.tryAllocate()
-	if result != nil {
-		t.Fatal("allocation attempt should not have returned an allocation")
+	// a paused app keeps its allocation and pending ask but does not get new allocations
+	app.Pause()
+	if result = partition.tryAllocate(); result != nil {
+		t.Fatalf("paused app should not get an allocation: %s", result)
 	}
-	// check if updated (must be after allocate call)
-	assert.Equal(t, 1, len(app.GetReservations()), "app should have one reserved ask")
-	assert.Equal(t, app.NodeReservedForAsk(allocKey2), nodeID1, "ask should have been reserved on node-1")
-	assert.Assert(t, node.IsReserved(), "node should have been reserved")
-	reservations := node.GetReservations()
-	assert.Equal(t, len(reservations), 1, "node should have two reservations")
-	_, _, resAsk := reservations[0].GetObjects()
-	assert.Equal(t, resAsk.GetAllocationKey(), allocKey2, "alloc-2 should have been reserved on the node")
-	assertLimits(t, getTestUserGroup(), res)
-
-	// allocation that fits on the node should not be allocated
-	var res2 *resources.Resource
-	res2, err = resources.NewResourceFromConf(map[string]string{"vcore": "1"})
-	assert.NilError(t, err, "failed to create resource")
+	assert.Equal(t, len(app.GetAllAllocations()), 1, "paused app should keep its allocation")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "paused app should keep its pending ask")
 
-	ask3 := newAllocationAsk(allocKey3, appID1, res2)
-	ask3.SetRequiredNode(nodeID1)
-	err = app.AddAllocationAsk(ask3)
-	assert.NilError(t, err, "failed to add ask alloc-3 to app-1")
+	app.Resume()
 	result = partition.tryAllocate()
-	if result != nil {
-		t.Fatal("allocation attempt should not have returned an allocation")
-	}
-
-	// reservation count should be updated as tryAllocate should have added a reservation for the 3rd ask
-	assert.Equal(t, 2, len(app.GetReservations()), "ask should not have been reserved, count changed")
-	assert.Equal(t, app.NodeReservedForAsk(allocKey2), nodeID1, "alloc-2 should have been reserved on node-1")
-	assert.Equal(t, app.NodeReservedForAsk(allocKey3), nodeID1, "alloc-3 should have been reserved on node-1")
-	assert.Assert(t, node.IsReserved(), "node should have been reserved")
-	reservations = node.GetReservations()
-	assert.Equal(t, len(reservations), 2, "node should have two reservations")
-	_, _, resAsk = reservations[0].GetObjects()
-	_, _, resAsk2 := reservations[1].GetObjects()
-	if !((resAsk.GetAllocationKey() == allocKey3 && resAsk2.GetAllocationKey() == allocKey2) ||
-		(resAsk2.GetAllocationKey() == allocKey3 && resAsk.GetAllocationKey() == allocKey2)) {
-		t.Fatal("missing reservation on the node")
+	if result == nil || result.Request == nil {
+		t.Fatal("resumed app did not get an allocation")
 	}
-	assertLimits(t, getTestUserGroup(), res)
+	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+	assert.Equal(t, len(app.GetAllAllocations()), 2, "resumed app should have both allocations")
 }
 
-// allocate ask request with required node having non daemon set reservations
-func TestRequiredNodeCancelOtherReservations(t *testing.T) {
+func TestUpdateAllocationGranularity(t *testing.T) {
+	setupUGM()
 	partition := createQueuesNodes(t)
 	assert.Assert(t, partition != nil, "partition create failed")
-	if result := partition.tryAllocate(); result != nil {
-		t.Fatalf("empty cluster allocate returned allocation: %s", result)
-	}
+	partition.updateGranularity(configs.PartitionConfig{Granularity: map[string]string{"vcore": "100m"}})
 
-	// override the reservation delay, and cleanup when done
-	objects.SetReservationDelay(10 * time.Nanosecond)
-	defer objects.SetReservationDelay(2 * time.Second)
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "250m"})
+	assert.NilError(t, err, "failed to create resource")
+	askCreated, _, err := partition.UpdateAllocation(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	assert.Assert(t, askCreated, "ask should have been created")
+
+	// the request is rounded up when it is added
+	rounded := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 300})
+	assert.Assert(t, resources.Equals(app.GetAllocationAsk(allocKey).GetAllocatedResource(), rounded), "request should be rounded up")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), rounded), "pending resource should be rounded up")
+	leaf := partition.GetQueue("root.leaf")
+	assert.Assert(t, resources.Equals(leaf.GetPendingResource(), rounded), "queue pending resource should be rounded up")
+
+	// the accounting uses the rounded value
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Assert(t, resources.Equals(app.GetAllocatedResource(), rounded), "app allocated resource should be rounded up")
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), rounded), "queue allocated resource should be rounded up")
+	node := partition.GetNode(result.NodeID)
+	assert.Assert(t, resources.Equals(node.GetAllocatedResource(), rounded), "node allocated resource should be rounded up")
+
+	// an update from the RM with the submitted resource does not shrink the rounded allocation
+	_, _, err = partition.UpdateAllocation(newAllocation(allocKey, appID1, result.NodeID, res))
+	assert.NilError(t, err, "failed to update allocation")
+	assert.Assert(t, resources.Equals(app.GetAllocatedResource(), rounded), "app allocated resource should stay rounded up")
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), rounded), "queue allocated resource should stay rounded up")
+	assert.Assert(t, resources.Equals(node.GetAllocatedResource(), rounded), "node allocated resource should stay rounded up")
+
+	// a recovered allocation is rounded up
+	twice := resources.Multiply(rounded, 2)
+	_, allocCreated, err := partition.UpdateAllocation(newAllocation(allocKey3, appID1, result.NodeID, res))
+	assert.NilError(t, err, "failed to add recovered allocation")
+	assert.Assert(t, allocCreated, "recovered allocation should have been created")
+	assert.Assert(t, resources.Equals(app.GetAllocatedResource(), twice), "recovered allocation should be rounded up on the app")
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), twice), "recovered allocation should be rounded up on the queue")
+	assert.Assert(t, resources.Equals(node.GetAllocatedResource(), twice), "recovered allocation should be rounded up on the node")
+
+	// a foreign allocation is rounded up
+	_, _, err = partition.UpdateAllocation(newForeignAllocation(foreignAlloc1, result.NodeID, res))
+	assert.NilError(t, err, "failed to add foreign allocation")
+	assert.Assert(t, resources.Equals(node.GetAllocation(foreignAlloc1).GetAllocatedResource(), rounded), "foreign allocation should be rounded up")
+
+	// removing the granularity leaves new requests unchanged
+	partition.updateGranularity(configs.PartitionConfig{})
+	_, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey2, appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	assert.Assert(t, resources.Equals(app.GetAllocationAsk(allocKey2).GetAllocatedResource(), res), "request should not be rounded without granularity")
+}
 
-	// turn off the second node
-	node2 := partition.GetNode(nodeID2)
-	node2.SetSchedulable(false)
+func TestUpdateAllocationMinRequest(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	below := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5, "memory": 10})
+	minimum := map[string]string{"vcore": "10m"}
+
+	// reject mode: the request is not added and the reason names the resource type
+	partition.updateMinRequest(configs.PartitionConfig{MinRequest: configs.PartitionMinRequestConfig{Resources: minimum, Policy: "reject"}})
+	askCreated, _, err := partition.UpdateAllocation(newAllocationAsk(allocKey, appID1, below))
+	assert.Error(t, err, "request below minimum: vcore requested 5, minimum 10")
+	assert.Assert(t, !askCreated, "ask should not have been created")
+	assert.Assert(t, app.GetAllocationAsk(allocKey) == nil, "rejected ask should not be added to the app")
+	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "rejected ask should not be pending")
+
+	// a request at the minimum is accepted
+	atMinimum := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10, "memory": 10})
+	askCreated, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey2, appID1, atMinimum))
+	assert.NilError(t, err, "request at the minimum should be accepted")
+	assert.Assert(t, askCreated, "ask should have been created")
+
+	// warn mode: the request is added unchanged
+	partition.updateMinRequest(configs.PartitionConfig{MinRequest: configs.PartitionMinRequestConfig{Resources: minimum, Policy: "warn"}})
+	askCreated, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey, appID1, below))
+	assert.NilError(t, err, "request below minimum should be accepted in warn mode")
+	assert.Assert(t, askCreated, "ask should have been created")
+	assert.Assert(t, resources.Equals(app.GetAllocationAsk(allocKey).GetAllocatedResource(), below), "request should not be changed")
+
+	// resource types not in the request are not checked
+	_, err = partition.checkMinRequest(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1}))
+	assert.NilError(t, err, "resource type without a minimum should not be checked")
+	var policy policies.MinRequestPolicy
+	policy, err = partition.checkMinRequest(resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1, "memory": 1}))
+	assert.Error(t, err, "request below minimum: vcore requested 1, minimum 10")
+	assert.Equal(t, policy, policies.WarnMinRequestPolicy, "wrong policy returned")
+
+	// no minimum set: nothing is checked
+	partition.updateMinRequest(configs.PartitionConfig{})
+	_, err = partition.checkMinRequest(below)
+	assert.NilError(t, err, "no minimum should not fail")
+}
 
-	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "7"})
+func TestTryAllocateDisabledQueue(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
 	assert.NilError(t, err, "failed to create resource")
-
-	// only one resource for alloc fits on a node
 	app := newApplication(appID1, "default", "root.parent.sub-leaf")
 	err = partition.AddApplication(app)
 	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
 
-	ask := newAllocationAsk("alloc-1", appID1, res)
-	err = app.AddAllocationAsk(ask)
-	assert.NilError(t, err, "failed to add ask to app")
-	ask = newAllocationAsk("alloc-2", appID1, res)
-	err = app.AddAllocationAsk(ask)
-	assert.NilError(t, err, "failed to add ask to app")
-	// calculate the resource size using the repeat request (reuse is possible using proto conversions in ask)
-	res.MultiplyTo(2)
-	assert.Assert(t, resources.Equals(res, app.GetPendingResource()), "pending resource not set as expected")
-	assert.Assert(t, resources.Equals(res, partition.root.GetPendingResource()), "pending resource not set as expected on root queue")
+	// disabling the parent disables all its children: the app stays in the queue
+	parent := partition.GetQueue("root.parent")
+	disabled := false
+	err = parent.ApplyConf(configs.QueueConfig{Name: "parent", Parent: true, Enabled: &disabled})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Assert(t, !parent.IsEnabled(), "parent should be disabled")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("disabled queue should not get an allocation: %s", result)
+	}
+	assert.Assert(t, partition.getApplication(appID1) != nil, "app should still be in the partition")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "app should keep its pending ask")
 
-	// the first one should be allocated
+	// re-enabling the queue resumes scheduling
+	err = parent.ApplyConf(configs.QueueConfig{Name: "parent", Parent: true})
+	assert.NilError(t, err, "failed to apply config")
+	assert.Assert(t, parent.IsEnabled(), "parent should be enabled by default")
 	result := partition.tryAllocate()
 	if result == nil || result.Request == nil {
-		t.Fatal("1st allocation did not return the correct allocation")
+		t.Fatal("re-enabled queue did not get an allocation")
 	}
-	assert.Equal(t, objects.Allocated, result.ResultType, "allocation result type should have been allocated")
+	assert.Equal(t, result.Request.GetApplicationID(), appID1, "wrong app allocated")
+}
+
+// admissionHook allows or vetoes all allocations and records the requests it was consulted for
+type admissionHook struct {
+	allow    bool
+	reason   string
+	asks     []string
+	requests []*objects.AdmissionRequest
+}
+
+func (h *admissionHook) Allow(request *objects.AdmissionRequest) (bool, string) {
+	h.asks = append(h.asks, request.AllocationKey)
+	h.requests = append(h.requests, request)
+	return h.allow, h.reason
+}
+
+func TestTryAllocateAdmissionHook(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	ask := newAllocationAsk(allocKey, appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+
+	// a vetoing hook rejects the allocation with its reason
+	veto := &admissionHook{allow: false, reason: "quota exhausted"}
+	partition.SetAdmissionHook(veto)
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("vetoed ask should not be allocated: %s", result)
+	}
+	assert.Assert(t, len(veto.asks) > 0, "vetoing hook should have been consulted")
+	assert.Equal(t, veto.asks[0], allocKey, "hook consulted for wrong ask")
+	assert.Assert(t, !ask.IsAllocated(), "vetoed ask should not be allocated")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "app should keep its pending ask")
+	assert.Assert(t, resources.IsZero(partition.root.GetAllocatedResource()), "nothing should be allocated on the root queue")
+	for _, node := range partition.GetNodes() {
+		assert.Equal(t, len(node.GetYunikornAllocations()), 0, "nothing should be allocated on node %s", node.NodeID)
+	}
+	vetoLogged := false
+	for _, entry := range ask.GetAllocationLog() {
+		if entry.Message == "allocation rejected by admission hook: quota exhausted" {
+			vetoLogged = true
+		}
+	}
+	assert.Assert(t, vetoLogged, "veto reason should be logged against the ask")
+
+	// an allowing hook lets the allocation through
+	allow := &admissionHook{allow: true}
+	partition.SetAdmissionHook(allow)
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allowed ask was not allocated")
+	}
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "wrong ask allocated")
+	assert.DeepEqual(t, allow.asks, []string{allocKey})
+	// the hook gets a snapshot of the application and the ask
+	request := allow.requests[0]
+	assert.Equal(t, request.ApplicationID, appID1, "unexpected application in snapshot")
+	assert.Equal(t, request.QueuePath, "root.leaf", "unexpected queue in snapshot")
+	assert.Equal(t, request.User.User, "testuser", "unexpected user in snapshot")
+	assert.Assert(t, resources.Equals(request.Resource, res), "unexpected resource in snapshot")
+	request.Resource.AddTo(res)
+	assert.Assert(t, resources.Equals(ask.GetAllocatedResource(), res), "changing the snapshot should not change the ask")
+
+	// a missing hook allows all
+	partition.SetAdmissionHook(nil)
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey2, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+	result = partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("ask was not allocated without a hook")
+	}
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey2, "wrong ask allocated")
+	assert.DeepEqual(t, allow.asks, []string{allocKey})
+}
+
+func TestTryAllocateBurst(t *testing.T) {
+	mockClock := objects.NewMockClock(time.Now())
+	defer objects.SetClock(objects.SetClock(mockClock))
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	leaf := partition.GetQueue("root.leaf")
+	assert.Assert(t, leaf != nil, "leaf queue not found")
+	err := leaf.ApplyConf(configs.QueueConfig{
+		Name:      "leaf",
+		Resources: configs.Resources{Quota: map[string]string{"vcore": "1"}, Burst: map[string]string{"vcore": "3"}},
+	})
+	assert.NilError(t, err, "failed to set quota on leaf queue")
+	partition.updateBurstPool(configs.PartitionConfig{
+		BurstPool: configs.PartitionBurstPoolConfig{Capacity: map[string]string{"vcore": "1"}, Refill: "10s"},
+	})
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	for i := 1; i <= 4; i++ {
+		err = app.AddAllocationAsk(newAllocationAsk(fmt.Sprintf("alloc-%d", i), appID1, res))
+		assert.NilError(t, err, "failed to add ask to app-1")
+	}
+
+	// within quota no tokens are needed, the first burst uses the pool
+	for i := 0; i < 2; i++ {
+		result := partition.tryAllocate()
+		if result == nil || result.Request == nil {
+			t.Fatalf("allocation %d within quota or burst was not allocated", i+1)
+		}
+	}
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), resources.Multiply(res, 2)), "leaf should be bursting")
+	// pool exhausted: no allocation above quota
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("allocation should be denied with an empty burst pool: %s", result)
+	}
+	// pool refilled: allocation up to the burst
+	mockClock.Advance(10 * time.Second)
+	partition.root.ResetPlacementBackoff()
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation was not allocated after the burst pool refilled")
+	}
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), resources.Multiply(res, 3)), "leaf should be bursting up to the limit")
+	// never above the burst limit even with tokens
+	mockClock.Advance(10 * time.Second)
+	partition.root.ResetPlacementBackoff()
+	if result = partition.tryAllocate(); result != nil {
+		t.Fatalf("allocation should be denied above the burst limit: %s", result)
+	}
+}
+
+func TestTryAllocateShareFloor(t *testing.T) {
+	setupUGM()
+	allocateTiny := func(floor string) int {
+		tiny := configs.QueueConfig{Name: "tiny"}
+		if floor != "" {
+			tiny.Properties = map[string]string{configs.FairShareFloor: floor}
+		}
+		conf := configs.PartitionConfig{
+			Name: "test",
+			Queues: []configs.QueueConfig{
+				{
+					Name:      "root",
+					Parent:    true,
+					SubmitACL: "*",
+					Queues:    []configs.QueueConfig{{Name: "big"}, tiny},
+				},
+			},
+		}
+		partition, err := newPartitionContext(conf, rmID, nil, false)
+		assert.NilError(t, err, "partition create failed")
+		err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 100})))
+		assert.NilError(t, err, "test node add failed")
+		res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+		// the big queue always has higher priority requests pending: the tiny queue is sorted last
+		for _, q := range []struct {
+			appID string
+			queue string
+			prio  int32
+		}{{appID1, "root.big", 10}, {appID2, "root.tiny", 1}} {
+			app := newApplication(q.appID, "default", q.queue)
+			err = partition.AddApplication(app)
+			assert.NilError(t, err, "failed to add app to partition")
+			for i := 0; i < 20; i++ {
+				err = app.AddAllocationAsk(newAllocationAskPriority(fmt.Sprintf("%s-%d", q.appID, i), q.appID, res, q.prio))
+				assert.NilError(t, err, "failed to add ask")
+			}
+		}
+		tinyAllocs := 0
+		for i := 0; i < 20; i++ {
+			result := partition.tryAllocate()
+			if result == nil || result.Request == nil {
+				t.Fatalf("allocation %d did not return any allocation", i)
+			}
+			if result.Request.GetApplicationID() == appID2 {
+				tinyAllocs++
+			}
+		}
+		return tinyAllocs
+	}
+	assert.Equal(t, allocateTiny(""), 0, "tiny queue should be starved without a floor")
+	assert.Equal(t, allocateTiny("0.25"), 5, "tiny queue should be first in one of every four cycles")
+	assert.Equal(t, allocateTiny("1"), 20, "tiny queue should always be first")
+}
+
+func TestTryAllocateMaxAllocations(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	partition.updateMaxAllocations(configs.PartitionConfig{MaxAllocations: 2})
+
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	for i := 0; i < 3; i++ {
+		err = app.AddAllocationAsk(newAllocationAsk(fmt.Sprintf("alloc-%d", i), appID1, res))
+		assert.NilError(t, err, "failed to add ask to app-1")
+	}
+
+	// allocate up to the limit
+	var allocKeys []string
+	for i := 0; i < 2; i++ {
+		result := partition.tryAllocate()
+		if result == nil || result.Request == nil {
+			t.Fatal("allocation did not return any allocation")
+		}
+		assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+		allocKeys = append(allocKeys, result.Request.GetAllocationKey())
+	}
+	assert.Equal(t, partition.GetTotalAllocationCount(), 2, "unexpected allocation count")
+	// limit reached: the last ask is not allocated
+	assert.Assert(t, partition.tryAllocate() == nil, "allocation should have been rejected over the limit")
+	assert.Equal(t, partition.GetTotalAllocationCount(), 2, "allocation count should not have changed")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "last ask should still be pending")
+
+	// releasing an allocation frees up space for the pending ask
+	release := &si.AllocationRelease{
+		PartitionName:   partition.Name,
+		ApplicationID:   appID1,
+		AllocationKey:   allocKeys[0],
+		TerminationType: si.TerminationType_STOPPED_BY_RM,
+	}
+	releases, _ := partition.removeAllocation(release)
+	assert.Equal(t, len(releases), 1, "unexpected number of released allocations")
+	assert.Equal(t, partition.GetTotalAllocationCount(), 1, "unexpected allocation count after release")
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation after release")
+	}
+	assert.Equal(t, partition.GetTotalAllocationCount(), 2, "unexpected allocation count after allocation")
+
+	// removing the limit allows allocations again
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-3", appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	assert.Assert(t, partition.tryAllocate() == nil, "allocation should have been rejected over the limit")
+	partition.updateMaxAllocations(configs.PartitionConfig{})
+	result = partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation without a limit")
+	}
+	assert.Equal(t, partition.GetTotalAllocationCount(), 3, "unexpected allocation count without a limit")
+}
+
+func TestTryAllocateAppDependencyRemoved(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// app-1 runs in a different queue than the app that depends on it
+	app1 := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Assert(t, app1.IsRunning(), "app-1 should be running after the allocation")
+	assert.Assert(t, partition.hasAppRun(appID1), "running app-1 should resolve a dependency")
+
+	// app-1 finishes and is removed before the applications depending on it are evaluated
+	partition.removeApplication(appID1)
+	assert.Assert(t, partition.getApplication(appID1) == nil, "app-1 should have been removed")
+	assert.Assert(t, partition.hasAppRun(appID1), "removed app-1 should still resolve a dependency")
+
+	// app-2 depends on the removed app-1, app-3 depends on app-4 which never ran
+	app2 := newApplicationTags(appID2, "default", "root.parent.sub-leaf", map[string]string{common.AppTagDependsOn: appID1})
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAsk(allocKey2, appID2, res))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-2")
+	app3 := newApplicationTags(appID3, "default", "root.parent.sub-leaf", map[string]string{common.AppTagDependsOn: "app-4"})
+	err = partition.AddApplication(app3)
+	assert.NilError(t, err, "failed to add app-3 to partition")
+	err = app3.AddAllocationAsk(newAllocationAsk(allocKey3, appID3, res))
+	assert.NilError(t, err, "failed to add ask alloc-3 to app-3")
+	result = partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, result.Request.GetApplicationID(), appID2, "expected application app-2 to be allocated")
+	if result = partition.tryAllocate(); result != nil {
+		t.Fatalf("app-3 should be held without its dependency, got allocation: %s", result)
+	}
+}
+
+func TestPruneRunApps(t *testing.T) {
+	setupUGM()
+	mockClock := objects.NewMockClock(time.Now().Add(-24 * time.Hour))
+	defer objects.SetClock(objects.SetClock(mockClock))
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// an app that never ran is not remembered
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	partition.removeApplication(appID1)
+	assert.Equal(t, len(partition.runApps), 0, "app that never ran should not be remembered")
+
+	// an app that ran is remembered after removal until the retention passed
+	app = newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, result.Request.GetBindTime(), mockClock.Now(), "bind time should come from the objects clock")
+	partition.removeApplication(appID2)
+	assert.Equal(t, len(partition.runApps), 1, "app that ran should be remembered")
+	partition.pruneRunApps(objects.Now())
+	assert.Equal(t, len(partition.runApps), 1, "app should be remembered within the retention")
+	assert.Assert(t, partition.hasAppRun(appID2), "remembered app should resolve a dependency")
+	mockClock.Advance(runAppRetention + time.Second)
+	partition.pruneRunApps(objects.Now())
+	assert.Equal(t, len(partition.runApps), 0, "app should be forgotten after the retention")
+	assert.Assert(t, !partition.hasAppRun(appID2), "forgotten app should not resolve a dependency")
+}
+
+func TestFailUnresolvedDependencyApplications(t *testing.T) {
+	setupUGM()
+	mockClock := objects.NewMockClock(time.Now())
+	defer objects.SetClock(objects.SetClock(mockClock))
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// app-1 depends on an unknown app, app-2 on app-3 which is known but not running
+	app1 := newApplicationTags(appID1, "default", "root.leaf", map[string]string{common.AppTagDependsOn: "unknown"})
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	app2 := newApplicationTags(appID2, "default", "root.leaf", map[string]string{common.AppTagDependsOn: appID3})
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAsk(allocKey2, appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	err = partition.AddApplication(newApplication(appID3, "default", "root.leaf"))
+	assert.NilError(t, err, "failed to add app-3 to partition")
+
+	// nothing fails before the timeout
+	mockClock.Advance(dependencyTimeout - time.Second)
+	assert.Equal(t, len(partition.failUnresolvedDependencyApplications()), 0, "no app should fail before the timeout")
+
+	// after the timeout only the app with the unknown dependency fails
+	mockClock.Advance(time.Second)
+	failed := partition.failUnresolvedDependencyApplications()
+	assert.Equal(t, len(failed), 1, "one app should have failed")
+	assert.Equal(t, failed[0], app1, "app with the unknown dependency should have failed")
+	assert.Assert(t, app1.IsFailing(), "app-1 should be failing")
+	assert.Assert(t, resources.IsZero(app1.GetPendingResource()), "pending requests of app-1 should be removed")
+	assert.Assert(t, !app2.IsFailing(), "app-2 waits for a known dependency")
+}
+
+func TestAddApplicationCyclicDependencyQueues(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+
+	// the cycle spans two queues: app-1 in root.leaf depends on app-2 in root.parent.sub-leaf
+	app := newApplicationTags(appID1, "default", "root.leaf", map[string]string{common.AppTagDependsOn: appID2})
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added")
+	app = newApplicationTags(appID2, "default", "root.parent.sub-leaf", map[string]string{common.AppTagDependsOn: appID1})
+	err = partition.AddApplication(app)
+	assert.ErrorContains(t, err, "cyclic dependency", "cycle over queues should have been rejected")
+}
+
+func TestAddApplicationCyclicDependency(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+
+	// self dependency
+	app := newApplicationTags(appID1, "default", defQueue, map[string]string{common.AppTagDependsOn: appID1})
+	err = partition.AddApplication(app)
+	assert.ErrorContains(t, err, "cyclic dependency", "self dependency should have been rejected")
+
+	// app-1 depends on app-2, app-2 depends on app-3 which depends on app-1
+	app = newApplicationTags(appID1, "default", defQueue, map[string]string{common.AppTagDependsOn: appID2})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added")
+	app = newApplicationTags(appID2, "default", defQueue, map[string]string{common.AppTagDependsOn: appID3})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-2 should have been added")
+	app = newApplicationTags(appID3, "default", defQueue, map[string]string{common.AppTagDependsOn: " , " + appID1})
+	err = partition.AddApplication(app)
+	assert.ErrorContains(t, err, "cyclic dependency", "cycle app-1 -> app-2 -> app-3 -> app-1 should have been rejected")
+	assert.Assert(t, partition.getApplication(appID3) == nil, "rejected app-3 should not be in the partition")
+}
+
+func TestTryAllocateBackoffNodeAdded(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+
+	// larger than any node but fits in the queue
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "15"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("oversized ask should not have been allocated: %s", result)
+	}
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("backed off ask should not have been allocated: %s", result)
+	}
+
+	// a new node that fits ends the backoff
+	err = partition.AddNode(newNodeMaxResource("node-3", resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 20000})))
+	assert.NilError(t, err, "failed to add node")
+	result := partition.tryAllocate()
+	assert.Assert(t, result != nil, "ask should have been allocated on the new node")
+	assert.Equal(t, result.NodeID, "node-3", "wrong node")
+}
+
+func TestTryAllocateWaitForCapacity(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "8"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// app-1 uses part of the partition
+	app1 := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	result := partition.tryAllocate()
+	assert.Assert(t, result != nil && result.Request.GetApplicationID() == appID1, "app-1 should have been allocated")
+
+	// app-2 needs more than is available in total: waits even though the ask fits on a node
+	tags := map[string]string{common.AppTagMinResource: "{\"resources\":{\"vcore\":{\"value\":15000}}}"}
+	app2 := newApplicationTags(appID2, "default", "root.parent.sub-leaf", tags)
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAsk(allocKey2, appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	if result = partition.tryAllocate(); result != nil {
+		t.Fatalf("app-2 should wait for capacity: %s", result)
+	}
+
+	// capacity appears: app-2 schedules
+	err = partition.AddNode(newNodeMaxResource("node-3", res))
+	assert.NilError(t, err, "failed to add node")
+	result = partition.tryAllocate()
+	assert.Assert(t, result != nil, "app-2 should have been allocated after capacity was added")
+	assert.Equal(t, result.Request.GetApplicationID(), appID2, "wrong app allocated")
+
+	// started application is no longer held
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-3", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	result = partition.tryAllocate()
+	assert.Assert(t, result != nil, "started app-2 should not wait for capacity")
+}
+
+func TestTryAllocate(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("empty cluster allocate returned allocation: %s", result)
+	}
+
+	// create a set of queues and apps: app-1 2 asks; app-2 1 ask (same size)
+	// sub-leaf will have an app with 2 requests and thus more unconfirmed resources compared to leaf2
+	// this should filter up in the parent and the 1st allocate should show an app-1 allocation
+	// the ask with the higher priority is the second one added alloc-2 for app-1
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-2", appID1, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+
+	app = newApplication(appID2, "default", "root.leaf")
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app.AddAllocationAsk(newAllocationAskPriority(allocKey, appID2, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+
+	expectedQueuesMaxLimits := make(map[string]map[string]interface{})
+	expectedQueuesMaxLimits["root"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root.leaf"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 10})
+	expectedQueuesMaxLimits["root.leaf"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcores": 5})
+	expectedQueuesMaxLimits["root"][maxapplications] = uint64(10)
+	expectedQueuesMaxLimits["root.leaf"][maxapplications] = uint64(1)
+	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), nil, expectedQueuesMaxLimits)
+
+	// first allocation should be app-1 and alloc-2
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
+	assert.Equal(t, result.Request.GetApplicationID(), appID1, "expected application app-1 to be allocated")
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey2, "expected ask alloc-2 to be allocated")
+	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.Multiply(res, 1), expectedQueuesMaxLimits)
+
+	// second allocation should be app-2 and alloc-1: higher up in the queue hierarchy
+	result = partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
+	assert.Equal(t, result.Request.GetApplicationID(), appID2, "expected application app-2 to be allocated")
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
+	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.Multiply(res, 2), expectedQueuesMaxLimits)
+
+	// third allocation should be app-1 and alloc-1
+	result = partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
+	assert.Equal(t, result.Request.GetApplicationID(), appID1, "expected application app-1 to be allocated")
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
+	assert.Assert(t, resources.IsZero(partition.root.GetPendingResource()), "pending resources should be set to zero")
+	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.Multiply(res, 3), expectedQueuesMaxLimits)
+}
+
+// allocate ask request with required node
+func TestRequiredNodeReservation(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	node := partition.nodes.GetNode(nodeID1)
+	if node == nil {
+		t.Fatal("node-1 should have been created")
+	}
+
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "8"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	ask := newAllocationAsk(allocKey, appID1, res)
+	ask.SetRequiredNode(nodeID1)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	assertLimits(t, getTestUserGroup(), nil)
+
+	// first allocation should be app-1 and alloc-1
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, result.ResultType, objects.Allocated, "result type is not the expected allocated")
+	assert.Check(t, !result.Request.HasRelease(), "released allocation should not exist")
+	assert.Equal(t, result.Request.GetApplicationID(), appID1, "expected application app-1 to be allocated")
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
+	assertLimits(t, getTestUserGroup(), res)
+
+	ask2 := newAllocationAsk(allocKey2, appID1, res)
+	ask2.SetRequiredNode(nodeID1)
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+	result = partition.tryAllocate()
+	if result != nil {
+		t.Fatal("allocation attempt should not have returned an allocation")
+	}
+	// check if updated (must be after allocate call)
+	assert.Equal(t, 1, len(app.GetReservations()), "app should have one reserved ask")
+	assert.Equal(t, app.NodeReservedForAsk(allocKey2), nodeID1, "ask should have been reserved on node-1")
+	assert.Assert(t, node.IsReserved(), "node should have been reserved")
+	reservations := node.GetReservations()
+	assert.Equal(t, len(reservations), 1, "node should have two reservations")
+	_, _, resAsk := reservations[0].GetObjects()
+	assert.Equal(t, resAsk.GetAllocationKey(), allocKey2, "alloc-2 should have been reserved on the node")
+	assertLimits(t, getTestUserGroup(), res)
+
+	// allocation that fits on the node should not be allocated
+	var res2 *resources.Resource
+	res2, err = resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	ask3 := newAllocationAsk(allocKey3, appID1, res2)
+	ask3.SetRequiredNode(nodeID1)
+	err = app.AddAllocationAsk(ask3)
+	assert.NilError(t, err, "failed to add ask alloc-3 to app-1")
+	result = partition.tryAllocate()
+	if result != nil {
+		t.Fatal("allocation attempt should not have returned an allocation")
+	}
+
+	// reservation count should be updated as tryAllocate should have added a reservation for the 3rd ask
+	assert.Equal(t, 2, len(app.GetReservations()), "ask should not have been reserved, count changed")
+	assert.Equal(t, app.NodeReservedForAsk(allocKey2), nodeID1, "alloc-2 should have been reserved on node-1")
+	assert.Equal(t, app.NodeReservedForAsk(allocKey3), nodeID1, "alloc-3 should have been reserved on node-1")
+	assert.Assert(t, node.IsReserved(), "node should have been reserved")
+	reservations = node.GetReservations()
+	assert.Equal(t, len(reservations), 2, "node should have two reservations")
+	_, _, resAsk = reservations[0].GetObjects()
+	_, _, resAsk2 := reservations[1].GetObjects()
+	if !((resAsk.GetAllocationKey() == allocKey3 && resAsk2.GetAllocationKey() == allocKey2) ||
+		(resAsk2.GetAllocationKey() == allocKey3 && resAsk.GetAllocationKey() == allocKey2)) {
+		t.Fatal("missing reservation on the node")
+	}
+	assertLimits(t, getTestUserGroup(), res)
+}
+
+// allocate ask request with required node having non daemon set reservations
+func TestRequiredNodeCancelOtherReservations(t *testing.T) {
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("empty cluster allocate returned allocation: %s", result)
+	}
+
+	// override the reservation delay, and cleanup when done
+	objects.SetReservationDelay(10 * time.Nanosecond)
+	defer objects.SetReservationDelay(2 * time.Second)
+
+	// turn off the second node
+	node2 := partition.GetNode(nodeID2)
+	node2.SetSchedulable(false)
+
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "7"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// only one resource for alloc fits on a node
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+
+	ask := newAllocationAsk("alloc-1", appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask to app")
+	ask = newAllocationAsk("alloc-2", appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask to app")
+	// calculate the resource size using the repeat request (reuse is possible using proto conversions in ask)
+	res.MultiplyTo(2)
+	assert.Assert(t, resources.Equals(res, app.GetPendingResource()), "pending resource not set as expected")
+	assert.Assert(t, resources.Equals(res, partition.root.GetPendingResource()), "pending resource not set as expected on root queue")
+
+	// the first one should be allocated
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("1st allocation did not return the correct allocation")
+	}
+	assert.Equal(t, objects.Allocated, result.ResultType, "allocation result type should have been allocated")
 
 	// the second one should be reserved as the 2nd node is not scheduling
 	result = partition.tryAllocate()
@@ -2026,6 +2906,44 @@ func TestPreemption(t *testing.T) {
 	assert.Assert(t, app2.GetApplicationSummary("default").PreemptedResource.TrackedResourceMap["UNKNOWN"] == nil)
 }
 
+// Preemption never crosses a partition: victims are only selected from the queues and nodes of the partition of the
+// preemptor, even if newer allocations exist in another partition.
+func TestPreemptionWithinPartition(t *testing.T) {
+	setupUGM()
+	partition, _, app2, alloc1, alloc2 := setupPreemption(t)
+
+	// a second partition with the same queues, fully used by newer allocations that are eligible victims
+	other := createPreemptionQueuesNodes(t)
+	app3, _ := newApplicationWithHandler(appID3, "default", "root.parent.leaf1")
+	err := other.AddApplication(app3)
+	assert.NilError(t, err, "failed to add app-3 to other partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "5"})
+	assert.NilError(t, err, "failed to create resource")
+	otherAllocs := make([]*objects.Allocation, 0, 2)
+	for _, key := range []string{"other-1", "other-2"} {
+		err = app3.AddAllocationAsk(newAllocationAskPreempt(key, appID3, 1, res))
+		assert.NilError(t, err, "failed to add ask %s to app-3", key)
+		result := other.tryAllocate()
+		if result == nil || result.Request == nil {
+			t.Fatalf("allocation of %s in other partition failed", key)
+		}
+		otherAllocs = append(otherAllocs, result.Request)
+	}
+
+	err = app2.AddAllocationAsk(newAllocationAskPreempt(allocKey3, appID2, 1, res))
+	assert.NilError(t, err, "failed to add ask alloc-3 to app-2")
+	time.Sleep(10 * time.Millisecond)
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatal("unexpected allocation")
+	}
+	assert.Assert(t, !alloc1.IsPreempted(), "alloc-1 is preempted")
+	assert.Assert(t, alloc2.IsPreempted(), "victim in the partition of the preemptor should have been preempted")
+	for _, alloc := range otherAllocs {
+		assert.Assert(t, !alloc.IsPreempted(), "allocation %s in other partition preempted", alloc.GetAllocationKey())
+	}
+	assert.Assert(t, resources.IsZero(other.GetQueue("root.parent.leaf1").GetPreemptingResource()), "other partition should not be preempting")
+}
+
 // Preemption followed by a normal allocation
 func TestPreemptionForRequiredNodeNormalAlloc(t *testing.T) {
 	setupUGM()
@@ -2041,101 +2959,506 @@ func TestPreemptionForRequiredNodeNormalAlloc(t *testing.T) {
 	assert.Equal(t, 0, partition.reservations, "no allocations on partition should have been reserved")
 }
 
-// Preemption followed by a reserved allocation
-func TestPreemptionForRequiredNodeReservedAlloc(t *testing.T) {
+// Preemption followed by a reserved allocation
+func TestPreemptionForRequiredNodeReservedAlloc(t *testing.T) {
+	setupUGM()
+	// setup the partition so we can try the real allocation
+	partition, app := setupPreemptionForRequiredNode(t)
+	// now try the allocation again: the reserved path
+	result := partition.tryReservedAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation attempt should have returned an allocation")
+	}
+	// check if updated (must be after allocate call)
+	assert.Equal(t, 0, len(app.GetReservations()), "ask should have no longer be reserved")
+	assert.Equal(t, result.ResultType, objects.AllocatedReserved, "result type is not the expected AllocatedReserved")
+	assert.Check(t, !result.Request.HasRelease(), "released allocation should not be present")
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey2, "expected ask alloc-2 to be allocated")
+	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 8000}), getExpectedQueuesLimitsForPreemption())
+}
+
+func TestPreemptionForRequiredNodeMultipleAttemptsAvoided(t *testing.T) {
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+
+	app, testHandler := newApplicationWithHandler(appID1, "default", "root.parent.sub-leaf")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "8"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+
+	// normal ask
+	ask := newAllocationAsk(allocKey, appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+
+	// first allocation should be app-1 and alloc-1
+	result := partition.tryAllocate()
+	assert.Assert(t, result != nil && result.Request != nil, "alloc expected")
+	alloc := result.Request
+
+	// required node set on ask
+	ask2 := newAllocationAsk(allocKey2, appID1, res)
+	ask2.SetRequiredNode(nodeID1)
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+	partition.tryAllocate()
+
+	// try multiple reserved allocation
+	partition.tryReservedAllocate()
+	partition.tryReservedAllocate()
+	partition.tryReservedAllocate()
+	partition.tryReservedAllocate()
+
+	var eventCount int
+	for _, event := range testHandler.GetEvents() {
+		if allocRelease, ok := event.(*rmevent.RMReleaseAllocationEvent); ok {
+			if allocRelease.ReleasedAllocations[0].AllocationKey == allocKey {
+				eventCount++
+			}
+		}
+	}
+	assert.Equal(t, 1, eventCount)
+	assert.Equal(t, true, ask2.HasTriggeredPreemption())
+	assert.Equal(t, true, alloc.IsPreempted())
+}
+
+func getExpectedQueuesLimitsForPreemption() map[string]map[string]interface{} {
+	expectedQueuesMaxLimits := make(map[string]map[string]interface{})
+	expectedQueuesMaxLimits["root"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root.parent"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root.parent.leaf1"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 10})
+	expectedQueuesMaxLimits["root.parent"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcores": 5})
+	expectedQueuesMaxLimits["root.parent.leaf1"][maxresources] = expectedQueuesMaxLimits["root.parent"][maxresources]
+	expectedQueuesMaxLimits["root"][maxapplications] = uint64(10)
+	expectedQueuesMaxLimits["root.parent"][maxapplications] = uint64(8)
+	expectedQueuesMaxLimits["root.parent.leaf1"][maxapplications] = uint64(8)
+	return expectedQueuesMaxLimits
+}
+
+func getExpectedQueuesLimitsForPreemptionWithRequiredNode() map[string]map[string]interface{} {
+	expectedQueuesMaxLimits := make(map[string]map[string]interface{})
+	expectedQueuesMaxLimits["root"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root.leaf"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root.parent"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root.parent.sub-leaf"] = make(map[string]interface{})
+	expectedQueuesMaxLimits["root"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 10})
+	expectedQueuesMaxLimits["root.leaf"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcores": 5})
+	expectedQueuesMaxLimits["root.parent"][maxresources] = expectedQueuesMaxLimits["root.leaf"][maxresources]
+	expectedQueuesMaxLimits["root.parent.sub-leaf"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 3, "vcores": 3})
+	expectedQueuesMaxLimits["root"][maxapplications] = uint64(10)
+	expectedQueuesMaxLimits["root.leaf"][maxapplications] = uint64(1)
+	expectedQueuesMaxLimits["root.parent"][maxapplications] = uint64(8)
+	expectedQueuesMaxLimits["root.parent.sub-leaf"][maxapplications] = uint64(2)
+	return expectedQueuesMaxLimits
+}
+
+func TestFailDeadlineExceededApplications(t *testing.T) {
+	setupUGM()
+	mockClock := objects.NewMockClock(time.Now())
+	defer objects.SetClock(objects.SetClock(mockClock))
+	partition := createQueuesNodes(t)
+	assert.Assert(t, partition != nil, "partition create failed")
+	tags := map[string]string{common.AppTagDeadline: "10s"}
+
+	// app-1 is scheduled before the deadline, app-2 asks for more than the nodes provide
+	app1 := newApplicationTags(appID1, "default", "root.leaf", tags)
+	err := partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app1.AddAllocationAsk(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	app2 := newApplicationTags(appID2, "default", "root.leaf", tags)
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	var large *resources.Resource
+	large, err = resources.NewResourceFromConf(map[string]string{"vcore": "50"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app2.AddAllocationAsk(newAllocationAsk(allocKey2, appID2, large))
+	assert.NilError(t, err, "failed to add ask to app-2")
+
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("app-1 did not get an allocation")
+	}
+	assert.Equal(t, result.Request.GetApplicationID(), appID1, "wrong app allocated")
+	assert.Assert(t, partition.tryAllocate() == nil, "app-2 should not fit")
+
+	// nothing fails before the deadline
+	mockClock.Advance(9 * time.Second)
+	assert.Equal(t, len(partition.failDeadlineExceededApplications()), 0, "no app should fail before the deadline")
+
+	// after the deadline only the unscheduled app fails
+	mockClock.Advance(time.Second)
+	failed := partition.failDeadlineExceededApplications()
+	assert.Equal(t, len(failed), 1, "one app should have failed")
+	assert.Equal(t, failed[0], app2, "unscheduled app should have failed")
+	assert.Equal(t, app2.CurrentState(), objects.Failing.String(), "unscheduled app should be failing")
+	assert.Assert(t, resources.IsZero(app2.GetPendingResource()), "failed app should have no pending requests")
+	assert.Equal(t, app1.CurrentState(), objects.Running.String(), "scheduled app should still be running")
+	assert.Assert(t, resources.Equals(app1.GetAllocatedResource(), res), "scheduled app should keep its allocation")
+}
+
+func TestPreemptionGracePeriod(t *testing.T) {
+	setupUGM()
+	partition, app1, app2, alloc1, alloc2 := setupPreemption(t)
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{GracePeriod: "1m"}})
+	assert.Equal(t, partition.getPreemptionGracePeriod(), time.Minute, "grace period not set")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "5"})
+	assert.NilError(t, err, "failed to create resource")
+	ask3 := newAllocationAskPreempt(allocKey3, appID2, 1, res)
+	err = app2.AddAllocationAsk(ask3)
+	assert.NilError(t, err, "failed to add ask alloc-3 to app-2")
+	// delay so that preemption delay passes
+	time.Sleep(time.Second)
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatal("unexpected allocation")
+	}
+	assert.Assert(t, !alloc1.IsPreempted(), "alloc-1 is preempted")
+	assert.Assert(t, alloc2.IsPreempted(), "alloc-2 is not preempted")
+	assert.Assert(t, !alloc2.GetPreemptedTime().IsZero(), "preempted time not set")
+
+	// within the grace period the capacity of the victim is not reused
+	assert.Equal(t, len(partition.releasePreemptedAllocations(time.Now())), 0, "victim released within the grace period")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("capacity reused within the grace period: %s", result)
+	}
+	assert.Equal(t, len(app1.GetAllAllocations()), 2, "victim should still be allocated")
+
+	// the grace period passed without the RM confirming: the victim is released
+	released := partition.releasePreemptedAllocations(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, len(released), 1, "victim should have been released after the grace period")
+	assert.Equal(t, released[0].GetAllocationKey(), allocKey2, "wrong allocation released")
+	assert.Equal(t, len(app1.GetAllAllocations()), 1, "victim should have been removed from the app")
+	assert.Assert(t, resources.IsZero(partition.GetQueue("root.parent.leaf1").GetPreemptingResource()), "preempting resources should be released")
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("missing allocation after the grace period")
+	}
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey3, "expected ask alloc-3 to be allocated")
+
+	// without a grace period victims are only released by the RM
+	partition.updatePreemption(configs.PartitionConfig{})
+	alloc1.MarkPreempted()
+	assert.Equal(t, len(partition.releasePreemptedAllocations(time.Now().Add(time.Hour))), 0, "victim released without a grace period")
+}
+
+func TestPreemptionDryRun(t *testing.T) {
+	setupUGM()
+	partition, err := newPreemptionConfiguredPartition(map[string]string{"vcore": "10"}, map[string]string{"vcore": "4"})
+	assert.NilError(t, err, "test partition create failed with error")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10000})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+	assert.NilError(t, err, "test node1 add failed unexpected")
+
+	// fill the parent queue from leaf1
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000})
+	app1, _ := newApplicationWithHandler(appID1, "default", "root.parent.leaf1")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	for i := 0; i < 5; i++ {
+		err = app1.AddAllocationAsk(newAllocationAskPreempt(fmt.Sprintf("alloc-%d", i), appID1, 1, res))
+		assert.NilError(t, err, "failed to add ask to app-1")
+		if result := partition.tryAllocate(); result == nil || result.Request == nil {
+			t.Fatal("allocation did not return any allocation")
+		}
+	}
+
+	app2, _ := newApplicationWithHandler(appID2, "default", "root.parent.leaf2")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	ask := newAllocationAskPreempt("preemptor", appID2, 2, res)
+	err = app2.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask preemptor to app-2")
+
+	// unknown application or ask
+	_, err = partition.PreemptionDryRun("unknown", "preemptor")
+	assert.ErrorContains(t, err, "application unknown not found")
+	_, err = partition.PreemptionDryRun(appID2, "unknown")
+	assert.ErrorContains(t, err, "pending ask unknown not found")
+
+	// the dry run reports the victim without changing anything
+	report, err := partition.PreemptionDryRun(appID2, "preemptor")
+	assert.NilError(t, err, "dry run failed")
+	assert.Equal(t, report.Reason, "", "dry run should have found victims")
+	assert.Equal(t, report.NodeID, nodeID1, "unexpected node")
+	assert.Equal(t, len(report.Victims), 1, "unexpected number of victims")
+	assert.Assert(t, resources.Equals(report.Freed, res), "unexpected freed resource: %s", report.Freed)
+	for _, alloc := range app1.GetAllAllocations() {
+		assert.Assert(t, !alloc.IsPreempted(), "dry run preempted %s", alloc.GetAllocationKey())
+	}
+	assert.Assert(t, !ask.HasTriggeredPreemption(), "dry run should not mark the ask")
+	leaf1 := partition.GetQueue("root.parent.leaf1")
+	assert.Assert(t, resources.IsZero(leaf1.GetPreemptingResource()), "dry run should not track preempting resources")
+	assert.Equal(t, len(ask.GetAllocationLog()), 0, "dry run should not log allocation failures")
+
+	// real preemption selects the same victim
+	time.Sleep(10 * time.Millisecond)
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("unexpected allocation: %s", result)
+	}
+	victim := report.Victims[0]
+	assert.Assert(t, victim.IsPreempted(), "dry run victim %s not preempted", victim.GetAllocationKey())
+	for _, alloc := range app1.GetAllAllocations() {
+		if alloc != victim {
+			assert.Assert(t, !alloc.IsPreempted(), "unexpected victim %s", alloc.GetAllocationKey())
+		}
+	}
+
+	// the ask has triggered preemption: a new dry run reports the precondition
+	report, err = partition.PreemptionDryRun(appID2, "preemptor")
+	assert.NilError(t, err, "dry run failed")
+	assert.Equal(t, len(report.Victims), 0, "no victims expected once preemption is triggered")
+	assert.Equal(t, report.Reason, common.PreemptionPreconditionsFailed, "unexpected reason")
+}
+
+func TestGetPreemptionVictims(t *testing.T) {
 	setupUGM()
-	// setup the partition so we can try the real allocation
-	partition, app := setupPreemptionForRequiredNode(t)
-	// now try the allocation again: the reserved path
-	result := partition.tryReservedAllocate()
-	if result == nil || result.Request == nil {
-		t.Fatal("allocation attempt should have returned an allocation")
+	partition, err := newPreemptionConfiguredPartition(map[string]string{"vcore": "10"}, map[string]string{"vcore": "4"})
+	assert.NilError(t, err, "test partition create failed with error")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10000})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+	assert.NilError(t, err, "test node1 add failed unexpected")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000})
+	app1, _ := newApplicationWithHandler(appID1, "default", "root.parent.leaf1")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	app2, _ := newApplicationWithHandler(appID2, "default", "root.parent.leaf2")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+
+	// no ask or unknown application
+	_, err = partition.GetPreemptionVictims(nil)
+	assert.ErrorContains(t, err, "no ask to report preemption victims")
+	_, err = partition.GetPreemptionVictims(newAllocationAskPreempt("incoming", "unknown", 2, res))
+	assert.ErrorContains(t, err, "application unknown not found")
+
+	// the ask fits without preemption
+	ask := newAllocationAskPreempt("incoming", appID2, 2, res)
+	report, err := partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.Reason, "", "ask should fit without preemption")
+	assert.Equal(t, report.NodeID, nodeID1, "unexpected node")
+	assert.Equal(t, len(report.Victims), 0, "no victims expected")
+
+	// fill the parent queue from leaf1: the ask needs preemption
+	for i := 0; i < 5; i++ {
+		err = app1.AddAllocationAsk(newAllocationAskPreempt(fmt.Sprintf("alloc-%d", i), appID1, 1, res))
+		assert.NilError(t, err, "failed to add ask to app-1")
+		if result := partition.tryAllocate(); result == nil || result.Request == nil {
+			t.Fatal("allocation did not return any allocation")
+		}
 	}
-	// check if updated (must be after allocate call)
-	assert.Equal(t, 0, len(app.GetReservations()), "ask should have no longer be reserved")
-	assert.Equal(t, result.ResultType, objects.AllocatedReserved, "result type is not the expected AllocatedReserved")
-	assert.Check(t, !result.Request.HasRelease(), "released allocation should not be present")
-	assert.Equal(t, result.Request.GetAllocationKey(), allocKey2, "expected ask alloc-2 to be allocated")
-	assertUserGroupResourceMaxLimits(t, getTestUserGroup(), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 8000}), getExpectedQueuesLimitsForPreemption())
+	report, err = partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.Reason, "", "preemption should succeed")
+	assert.Equal(t, report.NodeID, nodeID1, "unexpected node")
+	assert.Equal(t, len(report.Victims), 1, "unexpected number of victims")
+	assert.Equal(t, report.Victims[0].GetApplicationID(), appID1, "victim should be from app-1")
+	assert.Assert(t, resources.Equals(report.Freed, res), "unexpected freed resource")
+	for _, alloc := range app1.GetAllAllocations() {
+		assert.Assert(t, !alloc.IsPreempted(), "lookup preempted %s", alloc.GetAllocationKey())
+	}
+	assert.Assert(t, !ask.HasTriggeredPreemption(), "lookup should not mark the ask")
+	assert.Assert(t, app2.GetAllocationAsk("incoming") == nil, "lookup should not add the ask")
+
+	// an ask that is not allowed to preempt cannot be placed
+	ask = newAllocationAsk("incoming", appID2, res)
+	report, err = partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.Reason, common.PreemptionPreconditionsFailed, "ask without preemption should not be placed")
+	assert.Equal(t, len(report.Victims), 0, "no victims expected")
 }
 
-func TestPreemptionForRequiredNodeMultipleAttemptsAvoided(t *testing.T) {
-	partition := createQueuesNodes(t)
-	assert.Assert(t, partition != nil, "partition create failed")
+func TestGetPreemptionVictimsNodeChecks(t *testing.T) {
+	setupUGM()
+	partition, err := newPreemptionConfiguredPartition(map[string]string{"vcore": "10"}, map[string]string{"vcore": "4"})
+	assert.NilError(t, err, "test partition create failed with error")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10000})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+	assert.NilError(t, err, "test node1 add failed unexpected")
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes))
+	assert.NilError(t, err, "test node2 add failed unexpected")
+	app, _ := newApplicationWithHandler(appID1, "default", "root.parent.leaf1")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
 
-	app, testHandler := newApplicationWithHandler(appID1, "default", "root.parent.sub-leaf")
-	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "8"})
-	assert.NilError(t, err, "failed to create resource")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000})
+	ask := newAllocationAskPreempt("incoming", appID1, 1, res)
+	report, err := partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.Reason, "", "ask should fit without preemption")
+	assert.Equal(t, report.NodeID, nodeID1, "unexpected node")
+
+	// only the required node is considered
+	ask.SetRequiredNode(nodeID2)
+	report, err = partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.Reason, "", "ask should fit on the required node")
+	assert.Equal(t, report.NodeID, nodeID2, "required node should be reported")
+
+	// the predicates fail on the required node: no other node is used
+	plugin := mock.NewPredicatePlugin(false, map[string]int{nodeID2: 0})
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.RegisterSchedulerPlugin(mock.NewPredicatePlugin(false, nil))
+	report, err = partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.NodeID, "", "no node expected when the required node fails the predicates")
+	assert.Equal(t, report.Reason, common.PreemptionPreconditionsFailed, "required node ask cannot be placed by preemption")
+
+	// the predicates skip the failing node
+	ask.SetRequiredNode("")
+	report, err = partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.Reason, "", "ask should fit without preemption")
+	assert.Equal(t, report.NodeID, nodeID1, "node failing the predicates should be skipped")
+	plugins.RegisterSchedulerPlugin(mock.NewPredicatePlugin(false, map[string]int{nodeID1: 0}))
+	report, err = partition.GetPreemptionVictims(ask)
+	assert.NilError(t, err, "victim lookup failed")
+	assert.Equal(t, report.NodeID, nodeID2, "node failing the predicates should be skipped")
+}
 
-	// add to the partition
+func TestQueuePendingResource(t *testing.T) {
+	setupUGM()
+	partition, err := newPreemptionConfiguredPartition(map[string]string{"vcore": "10"}, map[string]string{"vcore": "4"})
+	assert.NilError(t, err, "test partition create failed with error")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10000})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+	assert.NilError(t, err, "test node1 add failed unexpected")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000})
+	app, _ := newApplicationWithHandler(appID1, "default", "root.parent.leaf1")
 	err = partition.AddApplication(app)
 	assert.NilError(t, err, "failed to add app-1 to partition")
+	leaf1 := partition.GetQueue("root.parent.leaf1")
+	leaf2 := partition.GetQueue("root.parent.leaf2")
+	parent := partition.GetQueue("root.parent")
+	assertPending := func(expected resources.Quantity, msg string) {
+		t.Helper()
+		pending := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": expected})
+		for _, queue := range []*objects.Queue{leaf1, parent, partition.root} {
+			assert.Assert(t, resources.EqualsOrEmpty(queue.GetPendingResource(), pending),
+				"%s: unexpected pending resource for %s: %s", msg, queue.GetQueuePath(), queue.GetPendingResource())
+		}
+		assert.Assert(t, resources.IsZero(leaf2.GetPendingResource()), "%s: sibling queue should have no pending resource", msg)
+	}
 
-	// normal ask
-	ask := newAllocationAsk(allocKey, appID1, res)
-	err = app.AddAllocationAsk(ask)
-	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	// adding asks increases the pending resource of the queue and its parents
+	keys := []string{allocKey, allocKey2, allocKey3}
+	for _, key := range keys {
+		err = app.AddAllocationAsk(newAllocationAsk(key, appID1, res))
+		assert.NilError(t, err, "failed to add ask %s", key)
+	}
+	assertPending(3000, "asks added")
 
-	// first allocation should be app-1 and alloc-1
-	result := partition.tryAllocate()
-	assert.Assert(t, result != nil && result.Request != nil, "alloc expected")
-	alloc := result.Request
+	// a satisfied ask is no longer pending, the allocated resource tracks it
+	if result := partition.tryAllocate(); result == nil || result.Request == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assertPending(2000, "ask allocated")
+	assert.Assert(t, resources.Equals(leaf1.GetAllocatedResource(), res), "unexpected allocated resource: %s", leaf1.GetAllocatedResource())
 
-	// required node set on ask
-	ask2 := newAllocationAsk(allocKey2, appID1, res)
-	ask2.SetRequiredNode(nodeID1)
-	err = app.AddAllocationAsk(ask2)
-	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
-	partition.tryAllocate()
+	// removing a pending ask removes its resource, the allocation is not affected
+	var pendingKeys []string
+	for _, key := range keys {
+		if ask := app.GetAllocationAsk(key); ask != nil && !ask.IsAllocated() {
+			pendingKeys = append(pendingKeys, key)
+		}
+	}
+	assert.Equal(t, len(pendingKeys), 2, "expected two pending asks")
+	app.RemoveAllocationAsk(pendingKeys[0])
+	assertPending(1000, "pending ask removed")
+	app.RemoveAllocationAsk(pendingKeys[1])
+	assertPending(0, "all pending asks removed")
+	assert.Assert(t, resources.Equals(leaf1.GetAllocatedResource(), res), "allocated resource should not change: %s", leaf1.GetAllocatedResource())
+}
 
-	// try multiple reserved allocation
-	partition.tryReservedAllocate()
-	partition.tryReservedAllocate()
-	partition.tryReservedAllocate()
-	partition.tryReservedAllocate()
+func TestPreemptionQuota(t *testing.T) {
+	setupUGM()
+	partition, err := newPreemptionConfiguredPartition(map[string]string{"vcore": "10"}, map[string]string{"vcore": "4"})
+	assert.NilError(t, err, "test partition create failed with error")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10000})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+	assert.NilError(t, err, "test node1 add failed unexpected")
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes))
+	assert.NilError(t, err, "test node2 add failed unexpected")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Quota: map[string]string{"vcore": "2"}}})
+	assert.Assert(t, resources.Equals(partition.root.GetPreemptionQuota(), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000})), "preemption quota not set")
 
-	var eventCount int
-	for _, event := range testHandler.GetEvents() {
-		if allocRelease, ok := event.(*rmevent.RMReleaseAllocationEvent); ok {
-			if allocRelease.ReleasedAllocations[0].AllocationKey == allocKey {
-				eventCount++
+	// fill the parent queue from leaf1
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000})
+	app1, _ := newApplicationWithHandler(appID1, "default", "root.parent.leaf1")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	for i := 0; i < 5; i++ {
+		err = app1.AddAllocationAsk(newAllocationAskPreempt(fmt.Sprintf("alloc-%d", i), appID1, 1, res))
+		assert.NilError(t, err, "failed to add ask to app-1")
+		if result := partition.tryAllocate(); result == nil || result.Request == nil {
+			t.Fatal("allocation did not return any allocation")
+		}
+	}
+	countPreempted := func() int {
+		count := 0
+		for _, alloc := range app1.GetAllAllocations() {
+			if alloc.IsPreempted() {
+				count++
 			}
 		}
+		return count
 	}
-	assert.Equal(t, 1, eventCount)
-	assert.Equal(t, true, ask2.HasTriggeredPreemption())
-	assert.Equal(t, true, alloc.IsPreempted())
-}
 
-func getExpectedQueuesLimitsForPreemption() map[string]map[string]interface{} {
-	expectedQueuesMaxLimits := make(map[string]map[string]interface{})
-	expectedQueuesMaxLimits["root"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root.parent"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root.parent.leaf1"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 10})
-	expectedQueuesMaxLimits["root.parent"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcores": 5})
-	expectedQueuesMaxLimits["root.parent.leaf1"][maxresources] = expectedQueuesMaxLimits["root.parent"][maxresources]
-	expectedQueuesMaxLimits["root"][maxapplications] = uint64(10)
-	expectedQueuesMaxLimits["root.parent"][maxapplications] = uint64(8)
-	expectedQueuesMaxLimits["root.parent.leaf1"][maxapplications] = uint64(8)
-	return expectedQueuesMaxLimits
-}
+	// two asks in leaf2 that each need a victim
+	app2, _ := newApplicationWithHandler(appID2, "default", "root.parent.leaf2")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	ask1 := newAllocationAskPreempt("preemptor-1", appID2, 2, res)
+	err = app2.AddAllocationAsk(ask1)
+	assert.NilError(t, err, "failed to add ask preemptor-1 to app-2")
+	ask2 := newAllocationAskPreempt("preemptor-2", appID2, 2, res)
+	err = app2.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "failed to add ask preemptor-2 to app-2")
+	// delay so that preemption delay passes
+	time.Sleep(10 * time.Millisecond)
 
-func getExpectedQueuesLimitsForPreemptionWithRequiredNode() map[string]map[string]interface{} {
-	expectedQueuesMaxLimits := make(map[string]map[string]interface{})
-	expectedQueuesMaxLimits["root"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root.leaf"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root.parent"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root.parent.sub-leaf"] = make(map[string]interface{})
-	expectedQueuesMaxLimits["root"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcores": 10})
-	expectedQueuesMaxLimits["root.leaf"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5, "vcores": 5})
-	expectedQueuesMaxLimits["root.parent"][maxresources] = expectedQueuesMaxLimits["root.leaf"][maxresources]
-	expectedQueuesMaxLimits["root.parent.sub-leaf"][maxresources] = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 3, "vcores": 3})
-	expectedQueuesMaxLimits["root"][maxapplications] = uint64(10)
-	expectedQueuesMaxLimits["root.leaf"][maxapplications] = uint64(1)
-	expectedQueuesMaxLimits["root.parent"][maxapplications] = uint64(8)
-	expectedQueuesMaxLimits["root.parent.sub-leaf"][maxapplications] = uint64(2)
-	return expectedQueuesMaxLimits
+	// the quota allows one victim within the window, the preemption clock starts after the preemption delay passed
+	mockClock := objects.NewMockClock(time.Now())
+	defer objects.SetClock(objects.SetClock(mockClock))
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("unexpected allocation: %s", result)
+	}
+	assert.Equal(t, countPreempted(), 1, "first preemption within the quota expected")
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("unexpected allocation: %s", result)
+	}
+	assert.Equal(t, countPreempted(), 1, "preemption over the quota should be deferred")
+	// a new scheduling cycle within the window does not restore the quota
+	mockClock.Advance(time.Second)
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("unexpected allocation: %s", result)
+	}
+	assert.Equal(t, countPreempted(), 1, "preemption over the quota should be deferred within the window")
+	var deferred int32
+	for _, entry := range ask2.GetAllocationLog() {
+		if entry.Message == common.PreemptionQuotaExhausted {
+			deferred = entry.Count
+		}
+	}
+	assert.Equal(t, deferred, int32(1), "deferred preemption should be logged once: the ask backs off")
+	assert.Assert(t, !ask2.HasTriggeredPreemption(), "deferred ask should not have triggered preemption")
+
+	// the first preemption leaves the window: preemption continues
+	mockClock.Advance(partition.root.GetPreemptionQuotaWindow())
+	if result := partition.tryAllocate(); result != nil {
+		t.Fatalf("unexpected allocation: %s", result)
+	}
+	assert.Equal(t, countPreempted(), 2, "deferred preemption should continue in the next window")
+	assert.Assert(t, ask2.HasTriggeredPreemption(), "ask should have triggered preemption")
 }
 
 // setup the partition with existing allocations so we can test preemption
@@ -2959,6 +4282,31 @@ func TestAddTGAppDynamic(t *testing.T) {
 	assert.Equal(t, queue.GetMaxApps(), uint64(1), "max running apps should be 1")
 }
 
+func TestAddApplicationMinResource(t *testing.T) {
+	limit := map[string]string{"vcore": "1"}
+	partition, err := newLimitedPartition(limit)
+	assert.NilError(t, err, "partition create failed")
+	// minimum larger than the queue max can never be satisfied
+	tags := map[string]string{common.AppTagMinResource: "{\"resources\":{\"vcore\":{\"value\":2000}}}"}
+	app := newApplicationTags(appID1, "default", "root.limited", tags)
+	err = partition.AddApplication(app)
+	assert.ErrorContains(t, err, "minimum resource", "app-1 should be rejected due to minimum resource")
+	assert.Assert(t, partition.getApplication(appID1) == nil, "rejected app should not be in the partition")
+
+	// minimum that fits in the queue max
+	tags = map[string]string{common.AppTagMinResource: "{\"resources\":{\"vcore\":{\"value\":1000}}}"}
+	app = newApplicationTags(appID2, "default", "root.limited", tags)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-2 should have been added to the partition")
+	assert.Equal(t, partition.getApplication(appID2), app, "partition failed to add app incorrect app returned")
+
+	// minimum for a type not limited in the queue
+	tags = map[string]string{common.AppTagMinResource: "{\"resources\":{\"memory\":{\"value\":1000}}}"}
+	app = newApplicationTags(appID3, "default", "root.limited", tags)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-3 should have been added to the partition")
+}
+
 func TestPlaceholderSmallerThanReal(t *testing.T) {
 	setupUGM()
 
@@ -3351,6 +4699,48 @@ func TestPreemptedPlaceholderSkip(t *testing.T) {
 // simple direct replace with one node
 //
 //nolint:funlen
+func TestTryPlaceholderAllocateAdmissionHook(t *testing.T) {
+	setupUGM()
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	var tgRes, res *resources.Resource
+	tgRes, err = resources.NewResourceFromConf(map[string]string{"vcore": "10"})
+	assert.NilError(t, err, "failed to create resource")
+	res, err = resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	setupNode(t, nodeID1, partition, tgRes)
+	app := newApplicationTG(appID1, "default", "root.default", tgRes)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added to the partition")
+	err = app.AddAllocationAsk(newAllocationAskTG(phID, appID1, taskGroup, res, true))
+	assert.NilError(t, err, "failed to add placeholder ask ph-1 to app")
+	if result := partition.tryAllocate(); result == nil || result.Request == nil {
+		t.Fatal("expected placeholder to be allocated")
+	}
+	err = app.AddAllocationAsk(newAllocationAskTG(allocKey, appID1, taskGroup, res, false))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+
+	// a vetoing hook blocks the replacement
+	veto := &admissionHook{allow: false, reason: "quota exhausted"}
+	partition.SetAdmissionHook(veto)
+	if result := partition.tryPlaceholderAllocate(); result != nil {
+		t.Fatalf("vetoed ask should not replace the placeholder: %s", result)
+	}
+	assert.Assert(t, len(veto.asks) > 0, "vetoing hook should have been consulted")
+	assert.Equal(t, veto.asks[0], allocKey, "hook consulted for wrong ask")
+	assert.Assert(t, !app.GetAllocationAsk(allocKey).IsAllocated(), "vetoed ask should not be allocated")
+
+	// an allowing hook lets the replacement through
+	allow := &admissionHook{allow: true}
+	partition.SetAdmissionHook(allow)
+	result := partition.tryPlaceholderAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allowed ask should have replaced the placeholder")
+	}
+	assert.Equal(t, result.ResultType, objects.Replaced, "result type is not the expected allocated replaced")
+	assert.DeepEqual(t, allow.asks, []string{allocKey})
+}
+
 func TestTryPlaceholderAllocate(t *testing.T) {
 	setupUGM()
 	partition, err := newBasePartition()
@@ -3644,6 +5034,63 @@ func TestUpdateAllocation(t *testing.T) {
 	assert.Check(t, !allocCreated, "alloc should not have been created")
 }
 
+func TestUpdateAllocationOversizedAsk(t *testing.T) {
+	setupUGM()
+	partition, err := newLimitedPartition(map[string]string{"vcore": "5"})
+	assert.NilError(t, err, "partition create failed")
+	app := newApplication(appID1, "default", "root.limited")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added to the partition")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 6000})
+	askCreated, _, err := partition.UpdateAllocation(newAllocationAsk(allocKey, appID1, res))
+	assert.Error(t, err, "queue root.limited cannot fit request alloc-1: vcore requested 6000 larger than max queue allocation 5000")
+	assert.Check(t, !askCreated, "oversized ask should not have been created")
+	assert.Assert(t, app.GetAllocationAsk(allocKey) == nil, "oversized ask should not have been added to the app")
+
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5000})
+	askCreated, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey, appID1, res))
+	assert.NilError(t, err, "ask that fits should have been added")
+	assert.Check(t, askCreated, "ask should have been created")
+}
+
+func TestCheckQueueCapacity(t *testing.T) {
+	partition, err := newPreemptionConfiguredPartition(map[string]string{"vcore": "30"}, map[string]string{"vcore": "5"})
+	assert.NilError(t, err, "test partition create failed with error")
+	assert.Assert(t, partition.CheckQueueCapacity() == nil, "no diagnostics expected without nodes")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "10"})
+	assert.NilError(t, err, "failed to create basic resource")
+	err = partition.AddNode(newNodeMaxResource("node-1", res))
+	assert.NilError(t, err, "test node1 add failed unexpected")
+	err = partition.AddNode(newNodeMaxResource("node-2", res))
+	assert.NilError(t, err, "test node2 add failed unexpected")
+	diagnostics := partition.CheckQueueCapacity()
+	assert.Equal(t, len(diagnostics), 1, "expected one over capacity queue: %v", diagnostics)
+	assert.Assert(t, strings.HasPrefix(diagnostics[0], "queue root.parent: "), "unexpected diagnostic: %s", diagnostics[0])
+	assert.Assert(t, strings.HasSuffix(diagnostics[0], "for vcore"), "unexpected diagnostic: %s", diagnostics[0])
+
+	err = partition.AddNode(newNodeMaxResource("node-3", res))
+	assert.NilError(t, err, "test node3 add failed unexpected")
+	assert.Assert(t, partition.CheckQueueCapacity() == nil, "no diagnostics expected when the max fits")
+}
+
+func TestReconcileQueues(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, !partition.ReconcileQueues(), "new partition should not drift")
+
+	// inject a drifted counter without any allocation
+	leaf := partition.GetQueue("root.leaf")
+	assert.Assert(t, leaf != nil, "leaf queue not found")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000})
+	leaf.IncAllocatedResource(res)
+	assert.Assert(t, partition.ReconcileQueues(), "drift should have been corrected")
+	assert.Assert(t, resources.IsZero(leaf.GetAllocatedResource()), "leaf allocated should be reset")
+	assert.Assert(t, resources.IsZero(partition.root.GetAllocatedResource()), "root allocated should be reset")
+	assert.Assert(t, !partition.ReconcileQueues(), "corrected partition should not drift")
+}
+
 func TestUpdateAllocationWithAsk(t *testing.T) {
 	setupUGM()
 	partition := createQueuesNodes(t)
@@ -3760,35 +5207,280 @@ func TestRemoveAllocationAsk(t *testing.T) {
 		t.Fatal("app updated removing timed out ask, should not have changed")
 	}
 
-	// correct remove of a known ask
-	release.TerminationType = si.TerminationType_STOPPED_BY_RM
-	partition.removeAllocation(release)
-	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "app should not have pending asks")
-	assertLimits(t, getTestUserGroup(), nil)
+	// correct remove of a known ask
+	release.TerminationType = si.TerminationType_STOPPED_BY_RM
+	partition.removeAllocation(release)
+	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "app should not have pending asks")
+	assertLimits(t, getTestUserGroup(), nil)
+}
+
+func TestUpdatePreemption(t *testing.T) {
+	var True = true
+	var False = false
+
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "Partition creation failed")
+	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by default")
+
+	partition.updatePreemption(configs.PartitionConfig{})
+	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by empty config")
+
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{}})
+	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by empty preemption section")
+
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Enabled: nil}})
+	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by explicit nil")
+
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Enabled: &True}})
+	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by explicit true")
+
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Enabled: &False}})
+	assert.Assert(t, !partition.IsPreemptionEnabled(), "preeemption should be disabled by explicit false")
+
+	assert.Equal(t, partition.root.GetPreemptionCooldown(), time.Duration(0), "preemption cooldown should be disabled by default")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Cooldown: "45s"}})
+	assert.Equal(t, partition.root.GetPreemptionCooldown(), 45*time.Second, "preemption cooldown not set on the root queue")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Cooldown: "invalid"}})
+	assert.Equal(t, partition.root.GetPreemptionCooldown(), time.Duration(0), "invalid preemption cooldown should disable the cooldown")
+
+	assert.Assert(t, partition.root.GetPreemptionQuota() == nil, "preemption quota should not be set by default")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Quota: map[string]string{"vcore": "5"}}})
+	assert.Assert(t, resources.Equals(partition.root.GetPreemptionQuota(), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5000})), "preemption quota not set on the root queue")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Quota: map[string]string{"vcore": "invalid"}}})
+	assert.Assert(t, partition.root.GetPreemptionQuota() == nil, "invalid preemption quota should disable the quota")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{QuotaWindow: "10s"}})
+	assert.Equal(t, partition.root.GetPreemptionQuotaWindow(), 10*time.Second, "preemption quota window not set on the root queue")
+	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{QuotaWindow: "invalid"}})
+	assert.Equal(t, partition.root.GetPreemptionQuotaWindow(), time.Minute, "invalid preemption quota window should use the default")
+}
+
+func TestUpdateDefaultSubmitACL(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name:             "test",
+		DefaultSubmitACL: "bob",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: " ",
+				Queues: []configs.QueueConfig{
+					{Name: "explicit", SubmitACL: "alice"},
+					{Name: "implicit"},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+	bob := security.UserGroup{User: "bob"}
+	explicit := partition.GetQueue("root.explicit")
+	implicit := partition.GetQueue("root.implicit")
+	assert.Assert(t, explicit != nil && implicit != nil, "queues not found")
+	assert.Assert(t, !explicit.CheckSubmitAccess(bob), "default should not apply to queue with an explicit ACL")
+	assert.Assert(t, implicit.CheckSubmitAccess(bob), "default should apply to queue without an ACL")
+
+	partition.updateDefaultSubmitACL(configs.PartitionConfig{})
+	assert.Assert(t, !implicit.CheckSubmitAccess(bob), "removed default submit ACL should not allow access")
+	partition.updateDefaultSubmitACL(conf)
+	assert.Assert(t, implicit.CheckSubmitAccess(bob), "default submit ACL should allow access")
+}
+
+func TestAddApplicationSandbox(t *testing.T) {
+	setupUGM()
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "default"},
+					{Name: "sandbox"},
+				},
+			},
+		},
+		PlacementRules: []configs.PlacementRule{{Name: "provided"}},
+		Sandbox: configs.PartitionSandboxConfig{
+			TrustedRMs: []string{rmID},
+			Queue:      "root.sandbox",
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+
+	// trusted RM: normal placement
+	trusted := newApplication(appID1, "default", "root.default")
+	err = partition.AddApplication(trusted)
+	assert.NilError(t, err, "app from trusted RM should have been added")
+	assert.Equal(t, trusted.GetQueuePath(), "root.default", "app from trusted RM not placed by the rules")
+
+	// untrusted RM: sandbox queue overrides the provided queue
+	siApp := &si.AddApplicationRequest{
+		ApplicationID: appID2,
+		QueueName:     "root.default",
+		PartitionName: "default",
+	}
+	untrusted := objects.NewApplication(siApp, security.UserGroup{User: "testuser"}, nil, "otherRM")
+	err = partition.AddApplication(untrusted)
+	assert.NilError(t, err, "app from untrusted RM should have been added")
+	assert.Equal(t, untrusted.GetQueuePath(), "root.sandbox", "app from untrusted RM not placed in sandbox")
+
+	// the submit access of the sandbox queue is checked for apps from untrusted RMs
+	conf.Queues[0].SubmitACL = ""
+	conf.Queues[0].Queues[1].SubmitACL = "alloweduser"
+	partition, err = newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+	siApp.ApplicationID = appID3
+	denied := objects.NewApplication(siApp, security.UserGroup{User: "testuser"}, nil, "otherRM")
+	err = partition.AddApplication(denied)
+	assert.ErrorContains(t, err, "submit access to sandbox queue root.sandbox denied", "app without sandbox access should have been rejected")
+	assert.Assert(t, partition.getApplication(appID3) == nil, "rejected app should not be in the partition")
+	allowed := objects.NewApplication(siApp, security.UserGroup{User: "alloweduser"}, nil, "otherRM")
+	err = partition.AddApplication(allowed)
+	assert.NilError(t, err, "app with sandbox access should have been added")
+	assert.Equal(t, allowed.GetQueuePath(), "root.sandbox", "app from untrusted RM not placed in sandbox")
+
+	// no sandbox queue: all RMs use the placement rules
+	partition.updateSandbox(configs.PartitionConfig{})
+	assert.Equal(t, partition.getSandboxQueue("otherRM"), "", "sandbox should be disabled")
 }
 
-func TestUpdatePreemption(t *testing.T) {
-	var True = true
-	var False = false
-
-	partition, err := newBasePartition()
-	assert.NilError(t, err, "Partition creation failed")
-	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by default")
+func TestAddApplicationZeroRequest(t *testing.T) {
+	setupUGM()
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "default"},
+					{Name: "empty"},
+				},
+			},
+		},
+		PlacementRules: []configs.PlacementRule{{Name: "provided"}},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+	gangApp := func(appID string) *objects.Application {
+		res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+		assert.NilError(t, err, "failed to create resource")
+		siApp := &si.AddApplicationRequest{
+			ApplicationID:  appID,
+			QueueName:      "root.default",
+			PartitionName:  "default",
+			PlaceholderAsk: res.ToProto(),
+		}
+		return objects.NewApplication(siApp, security.UserGroup{User: "testuser"}, nil, rmID)
+	}
 
-	partition.updatePreemption(configs.PartitionConfig{})
-	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by empty config")
+	// accept (default): no special handling
+	app := newApplication(appID1, "default", "root.default")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "zero request app should have been accepted")
+	assert.Equal(t, app.GetQueuePath(), "root.default", "accepted zero request app not placed by the rules")
 
-	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{}})
-	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by empty preemption section")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
 
-	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Enabled: nil}})
-	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by explicit nil")
+	// reject: apps are accepted on submit, an app whose first request does not ask for resources is rejected
+	conf.ZeroRequest = configs.PartitionZeroRequestConfig{Policy: "reject"}
+	partition.updateZeroRequest(conf)
+	app = newApplication(appID2, "default", "root.default")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app without requests on submit should have been accepted")
+	_, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey, appID2, res))
+	assert.NilError(t, err, "request should have been added")
+	assert.Assert(t, partition.getApplication(appID2) != nil, "app with a request should be in the partition")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "request not added to the app")
+	app = newApplication(appID3, "default", "root.default")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app without requests on submit should have been accepted")
+	_, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey2, appID3, resources.NewResource()))
+	assert.ErrorIs(t, err, errNoResourcesRequested, "zero request app should have been rejected")
+	assert.Assert(t, partition.getApplication(appID3) == nil, "rejected app should not be in the partition")
+	assert.Assert(t, partition.getRejectedApplication(appID3) != nil, "rejected app should be tracked")
+	assert.Assert(t, partition.GetQueue("root.default").GetApplication(appID3) == nil, "rejected app should be removed from the queue")
+	app = gangApp("app-4")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app with a request should have been added")
+	assert.Equal(t, app.GetQueuePath(), "root.default", "app with a request not placed by the rules")
+
+	// route: an app without requests is placed in the designated queue until its first request arrives,
+	// after that it is placed by the rules. An app with a request is placed by the rules on submit.
+	conf.ZeroRequest = configs.PartitionZeroRequestConfig{Policy: "route", Queue: "root.empty"}
+	partition.updateZeroRequest(conf)
+	app = newApplication("app-5", "default", "root.default")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "zero request app should have been routed")
+	assert.Equal(t, app.GetQueuePath(), "root.empty", "zero request app not placed in the zero request queue")
+	_, _, err = partition.UpdateAllocation(newAllocationAsk(allocKey3, "app-5", res))
+	assert.NilError(t, err, "request should have been added")
+	assert.Equal(t, app.GetQueuePath(), "root.default", "app not placed by the rules on its first request")
+	assert.Assert(t, partition.GetQueue("root.empty").GetApplication("app-5") == nil, "app should be removed from the zero request queue")
+	assert.Assert(t, partition.GetQueue("root.default").GetApplication("app-5") != nil, "app should be added to the placed queue")
+	assert.Assert(t, resources.Equals(partition.GetQueue("root.default").GetPendingResource(), resources.Multiply(res, 2)), "pending not tracked on the placed queue")
+	assert.Assert(t, resources.IsZero(partition.GetQueue("root.empty").GetPendingResource()), "zero request queue should not have pending resources")
+	assert.Equal(t, len(partition.zeroRequestApps), 0, "placed app should not be tracked")
+	app = gangApp("app-6")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app with a request should have been added")
+	assert.Equal(t, app.GetQueuePath(), "root.default", "app with a request should not be routed")
 
-	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Enabled: &True}})
-	assert.Assert(t, partition.IsPreemptionEnabled(), "preeemption should be enabled by explicit true")
+	// a recovered app is placed by the rules before its first allocation is tracked
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.Multiply(res, 10)))
+	assert.NilError(t, err, "failed to add node")
+	app = newApplication("app-7", "default", "root.default")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "zero request app should have been routed")
+	assert.Equal(t, app.GetQueuePath(), "root.empty", "zero request app not placed in the zero request queue")
+	_, _, err = partition.UpdateAllocation(newAllocation("alloc-7", "app-7", nodeID1, res))
+	assert.NilError(t, err, "recovered allocation should have been added")
+	assert.Equal(t, app.GetQueuePath(), "root.default", "recovered app not placed by the rules on its first allocation")
+	assert.Assert(t, resources.Equals(partition.GetQueue("root.default").GetAllocatedResource(), res), "allocation not tracked on the placed queue")
+	assert.Assert(t, resources.IsZero(partition.GetQueue("root.empty").GetAllocatedResource()), "zero request queue should not have allocations")
+
+	// a failed placement rejects the app
+	app = newApplication("app-8", "default", "root.in$valid")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "zero request app should have been routed")
+	_, _, err = partition.UpdateAllocation(newAllocationAsk("alloc-8", "app-8", res))
+	assert.ErrorIs(t, err, errAppPlacementFailed, "app should have been rejected on placement")
+	assert.Assert(t, isAppRejected(err), "placement failure should reject the app")
+	assert.Assert(t, partition.getApplication("app-8") == nil, "rejected app should not be in the partition")
+	assert.Assert(t, partition.getRejectedApplication("app-8") != nil, "rejected app should be tracked")
+}
 
-	partition.updatePreemption(configs.PartitionConfig{Preemption: configs.PartitionPreemptionConfig{Enabled: &False}})
-	assert.Assert(t, !partition.IsPreemptionEnabled(), "preeemption should be disabled by explicit false")
+func TestSnapshotRestoreQueues(t *testing.T) {
+	setupUGM()
+	partition := createQueuesNodes(t)
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000})
+	partition.GetQueue("root.parent.sub-leaf").IncAllocatedResource(res)
+
+	blob, err := partition.SnapshotQueues()
+	assert.NilError(t, err, "snapshot failed")
+
+	restored := createQueuesNodes(t)
+	placement, err := restored.RestoreQueues(blob)
+	assert.NilError(t, err, "restore failed")
+	assert.DeepEqual(t, placement, map[string]string{appID1: "root.parent.sub-leaf"})
+	for _, path := range []string{"root", "root.parent", "root.parent.sub-leaf"} {
+		assert.Assert(t, resources.Equals(restored.GetQueue(path).GetAllocatedResource(), res), "queue %s not restored", path)
+	}
+	assert.Assert(t, resources.IsZero(restored.GetQueue("root.leaf").GetAllocatedResource()), "unused queue should not have usage")
+
+	_, err = restored.RestoreQueues([]byte("not a snapshot"))
+	assert.ErrorContains(t, err, "invalid queue snapshot for partition test")
+	_, err = restored.RestoreQueues([]byte(`{"partition":"other"}`))
+	assert.ErrorContains(t, err, "queue snapshot for partition other cannot be restored in partition test")
+	_, err = restored.RestoreQueues([]byte(`{"partition":"test"}`))
+	assert.ErrorContains(t, err, "has no root queue")
 }
 
 func TestUpdateNodeSortingPolicy(t *testing.T) {
@@ -4251,6 +5943,54 @@ func TestReservationTracking(t *testing.T) {
 	assert.Equal(t, "alloc-2", result.Request.GetAllocationKey())
 }
 
+func TestReservedUnusedResource(t *testing.T) {
+	setupUGM()
+	defer setupUGM()
+	partition := createQueuesNodes(t)
+	assert.Assert(t, resources.IsZero(partition.GetReservedUnusedResource()), "no reservations should be reported")
+
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "10"})
+	assert.NilError(t, err, "failed to create resource")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	for _, key := range []string{allocKey, allocKey2, allocKey3} {
+		ask := newAllocationAsk(key, appID1, res)
+		ask.SetRequiredNode(nodeID1)
+		err = app.AddAllocationAsk(ask)
+		assert.NilError(t, err, "failed to add ask")
+	}
+
+	result := partition.tryAllocate() // ask1 occupies node1
+	assert.Assert(t, result != nil && result.ResultType == objects.Allocated, "first ask should have been allocated")
+	assert.Assert(t, resources.IsZero(partition.GetReservedUnusedResource()), "allocation should not be reported as reserved")
+	result = partition.tryAllocate() // ask2 and ask3 get reserved
+	assert.Assert(t, result == nil, "no allocation expected")
+	result = partition.tryAllocate()
+	assert.Assert(t, result == nil, "no allocation expected")
+	assert.Equal(t, 2, partition.getReservationCount())
+	assert.Assert(t, resources.Equals(partition.GetReservedUnusedResource(), resources.Multiply(res, 2)), "both reservations should be reported: %s", partition.GetReservedUnusedResource())
+
+	// release ask1: one of the reservations is allocated and no longer reported
+	partition.removeAllocation(&si.AllocationRelease{
+		AllocationKey:   allocKey,
+		ApplicationID:   appID1,
+		TerminationType: si.TerminationType_STOPPED_BY_RM,
+	})
+	result = partition.tryReservedAllocate()
+	assert.Assert(t, result != nil && result.Request != nil, "reservation should have been allocated")
+	assert.Assert(t, resources.Equals(partition.GetReservedUnusedResource(), res), "one reservation should be reported: %s", partition.GetReservedUnusedResource())
+
+	// release the last reservation
+	remaining := allocKey2
+	if result.Request.GetAllocationKey() == allocKey2 {
+		remaining = allocKey3
+	}
+	partition.unReserve(app, partition.GetNode(nodeID1), app.GetAllocationAsk(remaining))
+	assert.Equal(t, 0, partition.getReservationCount())
+	assert.Assert(t, resources.IsZero(partition.GetReservedUnusedResource()), "released reservation should not be reported")
+}
+
 //nolint:funlen
 func TestLimitMaxApplications(t *testing.T) {
 	testCases := []struct {
@@ -4800,3 +6540,385 @@ func TestForeignAllocation(t *testing.T) { //nolint:funlen
 	assert.Equal(t, 0, len(partition.foreignAllocs))
 	assert.Equal(t, 0, len(node.GetYunikornAllocations()))
 }
+
+func TestPartitionOvercommit(t *testing.T) {
+	setupUGM()
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "default"},
+				},
+			},
+		},
+		Overcommit: map[string]float64{"vcore": 2},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+
+	// vcore capacity is doubled, memory stays as reported
+	physical := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 4, "memory": 4})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, physical))
+	assert.NilError(t, err, "test node add failed")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 8, "memory": 4})
+	assert.Assert(t, resources.Equals(partition.GetNode(nodeID1).GetCapacity(), expected), "node capacity not overcommitted")
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource not overcommitted")
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+
+	// the vcore request is larger than the physical vcore on the node but fits the overcommitted capacity
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 6, "memory": 1})))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation over physical vcore should have been allowed")
+	}
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
+
+	// memory is not overcommitted: 4 memory does not fit in the remaining 3
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey2, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1, "memory": 4})))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app")
+	if result = partition.tryAllocate(); result != nil {
+		t.Fatalf("allocation over physical memory should not have been allowed: %s", result)
+	}
+
+	// capacity updates use the same ratios
+	updated := partition.applyOvercommit(resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5, "memory": 5, "pods": 10}))
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10, "memory": 5, "pods": 10})
+	assert.Assert(t, resources.Equals(updated, expected), "unexpected overcommitted capacity: %s", updated)
+}
+
+func TestPartitionNodeOverhead(t *testing.T) {
+	setupUGM()
+	defer setupUGM()
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "default"},
+				},
+			},
+		},
+		NodeOverhead: configs.NodeOverheadConfig{
+			Reserved: map[string]string{"vcore": "1", "memory": "2"},
+			Overrides: []configs.NodeOverheadOverride{
+				{Labels: map[string]string{"pool": "gpu", "zone": "a"}, Reserved: map[string]string{"vcore": "3"}},
+				{Labels: map[string]string{"pool": "gpu"}, Reserved: map[string]string{"vcore": "2"}},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+
+	// the partition wide overhead is removed from a node without matching labels, vcore is configured in milli cores
+	physical := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 4000, "memory": 8})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, physical))
+	assert.NilError(t, err, "test node add failed")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 3000, "memory": 6})
+	assert.Assert(t, resources.Equals(partition.GetNode(nodeID1).GetCapacity(), expected), "node capacity overhead not reserved")
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource overhead not reserved")
+
+	// the first matching override replaces the partition wide overhead
+	node := objects.NewNode(&si.NodeInfo{
+		NodeID:              nodeID2,
+		Attributes:          map[string]string{"pool": "gpu", "zone": "b"},
+		SchedulableResource: physical.ToProto(),
+	})
+	err = partition.AddNode(node)
+	assert.NilError(t, err, "test node add failed")
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000, "memory": 8})
+	assert.Assert(t, resources.Equals(node.GetCapacity(), expected), "override overhead not reserved: %s", node.GetCapacity())
+	node = objects.NewNode(&si.NodeInfo{
+		NodeID:              "node-3",
+		Attributes:          map[string]string{"pool": "gpu", "zone": "a"},
+		SchedulableResource: physical.ToProto(),
+	})
+	assert.Equal(t, partition.applyNodeOverhead(node, physical).Resources["vcore"], resources.Quantity(1000), "first override not used")
+	// overhead larger than the capacity does not go negative
+	small := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000, "memory": 1})
+	assert.Assert(t, resources.Equals(partition.applyNodeOverhead(node, small), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 0, "memory": 1})), "overhead should not go negative")
+	partition.removeNode(nodeID2)
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+
+	// the request fits the remaining capacity
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000, "memory": 4})))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+	result := partition.tryAllocate()
+	if result == nil || result.Request == nil {
+		t.Fatal("allocation within the schedulable capacity should have been allowed")
+	}
+	assert.Equal(t, result.Request.GetAllocationKey(), allocKey, "expected ask alloc-1 to be allocated")
+
+	// the node has vcore and memory left but only in the reserved overhead
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey2, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000, "memory": 3})))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app")
+	if result = partition.tryAllocate(); result != nil {
+		t.Fatalf("allocation using the reserved overhead should not have been allowed: %s", result)
+	}
+
+	// capacity updates reserve the same overhead
+	updated := partition.applyNodeOverhead(partition.GetNode(nodeID1), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 8000, "memory": 16}))
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 7000, "memory": 14})
+	assert.Assert(t, resources.Equals(updated, expected), "unexpected capacity after overhead: %s", updated)
+}
+
+func TestAddApplicationParentQueue(t *testing.T) {
+	setupUGM()
+	defer setupUGM()
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "empty", Parent: true},
+					{Name: "parent", Queues: []configs.QueueConfig{{Name: "leaf"}}},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+
+	// reject mode: a parent queue is rejected with a reason
+	err = partition.AddApplication(newApplication(appID1, "default", "root.empty"))
+	assert.ErrorContains(t, err, "queue root.empty is a parent queue")
+	assert.Assert(t, !partition.GetQueue("root.empty").IsLeafQueue(), "queue should not have been changed")
+
+	// promote mode: a parent queue without child queues is changed into a leaf queue
+	conf.ParentPlacement = "promote"
+	assert.NilError(t, partition.updatePartitionDetails(conf), "partition update failed")
+	err = partition.AddApplication(newApplication(appID1, "default", "root.empty"))
+	assert.NilError(t, err, "app in parent queue without children should have been added")
+	queue := partition.GetQueue("root.empty")
+	assert.Assert(t, queue.IsLeafQueue(), "queue should have been promoted to a leaf")
+	assert.Assert(t, queue.GetApplication(appID1) != nil, "app not added to the promoted queue")
+
+	// promote mode: a parent queue with child queues is rejected
+	err = partition.AddApplication(newApplication(appID2, "default", "root.parent"))
+	assert.ErrorContains(t, err, "queue root.parent is a parent queue")
+
+	// a config update keeps the promoted queue a leaf while it runs applications
+	assert.NilError(t, partition.updatePartitionDetails(conf), "partition update failed")
+	assert.Assert(t, queue.IsLeafQueue(), "promoted queue with applications should stay a leaf")
+}
+
+func TestUpdateQueueRemovedMaxResourceType(t *testing.T) {
+	setupUGM()
+	defer setupUGM()
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:      "parent",
+						Parent:    true,
+						Resources: configs.Resources{Max: map[string]string{"memory": "10", "vcore": "10"}},
+						Queues: []configs.QueueConfig{
+							{Name: "leaf", Resources: configs.Resources{Max: map[string]string{"memory": "10", "vcore": "4"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil, false)
+	assert.NilError(t, err, "partition create failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 100000})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+	assert.NilError(t, err, "test node add failed unexpected")
+	app := newApplication(appID1, "default", "root.parent.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 2, "vcore": 3000})
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, askRes))
+	assert.NilError(t, err, "failed to add ask to app")
+	result := partition.tryAllocate()
+	assert.Assert(t, result != nil && result.ResultType == objects.Allocated, "first allocation failed")
+	// the second ask does not fit in the vcore max of the leaf
+	err = app.AddAllocationAsk(newAllocationAsk(allocKey2, appID1, askRes))
+	assert.NilError(t, err, "failed to add ask to app")
+	assert.Assert(t, partition.tryAllocate() == nil, "second allocation should not fit in the vcore max")
+
+	// remove the vcore max: the usage must still be tracked and vcores are no longer constrained
+	conf.Queues[0].Queues[0].Resources.Max = map[string]string{"memory": "10"}
+	conf.Queues[0].Queues[0].Queues[0].Resources.Max = map[string]string{"memory": "10"}
+	assert.NilError(t, partition.updatePartitionDetails(conf), "partition update failed")
+	leaf := partition.GetQueue("root.parent.leaf")
+	parent := partition.GetQueue("root.parent")
+	assert.Equal(t, leaf.GetMaxResource().Resources["vcore"], resources.Quantity(100000), "vcore max should only be limited by the cluster")
+	for _, queue := range []*objects.Queue{leaf, parent, partition.root} {
+		assert.Assert(t, resources.Equals(queue.GetAllocatedResource(), askRes), "usage lost for queue %s: %s", queue.QueuePath, queue.GetAllocatedResource())
+	}
+	result = partition.tryAllocate()
+	assert.Assert(t, result != nil && result.ResultType == objects.Allocated, "second allocation should fit without the vcore max")
+	double := resources.Multiply(askRes, 2)
+	for _, queue := range []*objects.Queue{leaf, parent, partition.root} {
+		assert.Assert(t, resources.Equals(queue.GetAllocatedResource(), double), "usage not tracked for queue %s: %s", queue.QueuePath, queue.GetAllocatedResource())
+	}
+
+	// add the max back lower than the usage: the usage is kept and new allocations are blocked
+	conf.Queues[0].Queues[0].Queues[0].Resources.Max = map[string]string{"memory": "10", "vcore": "4"}
+	assert.NilError(t, partition.updatePartitionDetails(conf), "partition update failed")
+	assert.Assert(t, resources.Equals(leaf.GetAllocatedResource(), double), "usage lost after adding the max back: %s", leaf.GetAllocatedResource())
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-3", appID1, askRes))
+	assert.NilError(t, err, "failed to add ask to app")
+	assert.Assert(t, partition.tryAllocate() == nil, "allocation should not fit in the restored vcore max")
+
+	// releasing the allocations returns all usage
+	for _, alloc := range app.GetAllAllocations() {
+		partition.removeAllocation(&si.AllocationRelease{
+			PartitionName:   "test",
+			ApplicationID:   appID1,
+			AllocationKey:   alloc.GetAllocationKey(),
+			TerminationType: si.TerminationType_STOPPED_BY_RM,
+		})
+	}
+	for _, queue := range []*objects.Queue{leaf, parent, partition.root} {
+		assert.Assert(t, resources.IsZero(queue.GetAllocatedResource()), "usage not released for queue %s: %s", queue.QueuePath, queue.GetAllocatedResource())
+	}
+}
+
+func TestSchedulingModeBorrowing(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected int
+	}{
+		{"", 8},
+		{"fair", 8},
+		{"capacity", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			setupUGM()
+			defer setupUGM()
+			conf := configs.PartitionConfig{
+				Name:           "test",
+				SchedulingMode: tt.mode,
+				Queues: []configs.QueueConfig{
+					{
+						Name:      "root",
+						Parent:    true,
+						SubmitACL: "*",
+						Queues: []configs.QueueConfig{
+							{Name: "leaf1", Resources: configs.Resources{Guaranteed: map[string]string{"memory": "5"}}},
+							{Name: "leaf2", Resources: configs.Resources{Guaranteed: map[string]string{"memory": "5"}}},
+						},
+					},
+				},
+			}
+			partition, err := newPartitionContext(conf, rmID, nil, false)
+			assert.NilError(t, err, "partition create failed")
+			nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+			err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes))
+			assert.NilError(t, err, "test node add failed unexpected")
+			app := newApplication(appID1, "default", "root.leaf1")
+			err = partition.AddApplication(app)
+			assert.NilError(t, err, "failed to add app to partition")
+			askRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1})
+			for i := 0; i < 8; i++ {
+				err = app.AddAllocationAsk(newAllocationAsk(fmt.Sprintf("alloc-%d", i), appID1, askRes))
+				assert.NilError(t, err, "failed to add ask to app")
+			}
+			allocated := 0
+			for partition.tryAllocate() != nil {
+				allocated++
+			}
+			assert.Equal(t, allocated, tt.expected, "unexpected number of allocations")
+			assert.Equal(t, partition.GetQueue("root.leaf1").GetAllocatedResource().Resources["memory"], resources.Quantity(tt.expected), "unexpected queue usage")
+		})
+	}
+}
+
+func TestCordonNode(t *testing.T) {
+	tests := []struct {
+		policy    string
+		fairShare resources.Quantity
+	}{
+		{"", 20},
+		{"keep", 20},
+		{"exclude", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			setupUGM()
+			defer setupUGM()
+			conf := configs.PartitionConfig{
+				Name:   "test",
+				Cordon: configs.PartitionCordonConfig{FairShare: tt.policy},
+				Queues: []configs.QueueConfig{
+					{
+						Name:      "root",
+						Parent:    true,
+						SubmitACL: "*",
+						Queues:    []configs.QueueConfig{{Name: "leaf"}},
+					},
+				},
+			}
+			partition, err := newPartitionContext(conf, rmID, nil, false)
+			assert.NilError(t, err, "partition create failed")
+			nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+			for _, nodeID := range []string{nodeID1, nodeID2} {
+				err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes))
+				assert.NilError(t, err, "test node add failed unexpected")
+			}
+			assert.ErrorContains(t, partition.CordonNode("unknown", true), "node unknown not found")
+
+			// cordon: the capacity is not schedulable, the fair share follows the policy
+			assert.NilError(t, partition.CordonNode(nodeID1, true), "cordon failed")
+			assert.Assert(t, partition.GetNode(nodeID1).IsCordoned(), "node should be cordoned")
+			assert.Equal(t, partition.GetSchedulableResource().Resources["memory"], resources.Quantity(10), "cordoned capacity should not be schedulable")
+			assert.Equal(t, partition.GetTotalPartitionResource().Resources["memory"], resources.Quantity(20), "total capacity should not change")
+			assert.Equal(t, partition.root.GetFairMaxResource().Resources["memory"], tt.fairShare, "unexpected fair share base")
+			leaf := partition.GetQueue("root.leaf")
+			assert.Equal(t, leaf.GetFairMaxResource().Resources["memory"], tt.fairShare, "unexpected fair share base for the leaf")
+
+			// allocations are only placed on the node that is not cordoned
+			app := newApplication(appID1, "default", "root.leaf")
+			err = partition.AddApplication(app)
+			assert.NilError(t, err, "failed to add app to partition")
+			askRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 6})
+			err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, askRes))
+			assert.NilError(t, err, "failed to add ask to app")
+			err = app.AddAllocationAsk(newAllocationAsk(allocKey2, appID1, askRes))
+			assert.NilError(t, err, "failed to add ask to app")
+			result := partition.tryAllocate()
+			assert.Assert(t, result != nil && result.ResultType == objects.Allocated, "allocation failed")
+			assert.Equal(t, result.Request.GetNodeID(), nodeID2, "allocation should be on the node that is not cordoned")
+			assert.Assert(t, partition.tryAllocate() == nil, "cordoned node should not be used")
+
+			// remove the cordon: the node is used again and the capacity is schedulable
+			assert.NilError(t, partition.CordonNode(nodeID1, false), "cordon removal failed")
+			assert.Equal(t, partition.GetSchedulableResource().Resources["memory"], resources.Quantity(20), "capacity should be schedulable")
+			assert.Equal(t, partition.root.GetFairMaxResource().Resources["memory"], resources.Quantity(20), "fair share should include all nodes")
+			result = partition.tryAllocate()
+			assert.Assert(t, result != nil && result.ResultType == objects.Allocated, "allocation failed")
+			assert.Equal(t, result.Request.GetNodeID(), nodeID1, "allocation should be on the node without cordon")
+
+			// removing a cordoned node removes the cordon
+			assert.NilError(t, partition.CordonNode(nodeID1, true), "cordon failed")
+			partition.removeNode(nodeID1)
+			assert.Equal(t, len(partition.cordonedNodes), 0, "removed node should not be cordoned")
+			assert.Equal(t, partition.root.GetFairMaxResource().Resources["memory"], resources.Quantity(10), "fair share should follow the remaining node")
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+// A wrapper around a rule that adds a static prefix and or suffix to the last queue name in the path the rule resolves.
+// The wrapped rule validates the dynamic part of the name, the combined name is validated again after adding the static
+// segments. The wrapped rule is always created with the create flag set: the create flag of the configuration is
+// checked against the queue with the static segments added.
+type affixRule struct {
+	rule
+	create bool
+	prefix string
+	suffix string
+}
+
+// newAffixRule wraps the rule for the configuration if a queue prefix or suffix is configured.
+func newAffixRule(conf configs.PlacementRule, r rule) rule {
+	if conf.QueuePrefix == "" && conf.QueueSuffix == "" {
+		return r
+	}
+	return &affixRule{
+		rule:   r,
+		create: conf.Create,
+		prefix: conf.QueuePrefix,
+		suffix: conf.QueueSuffix,
+	}
+}
+
+func (ar *affixRule) ruleDAO() *dao.RuleDAO {
+	ruleDAO := ar.rule.ruleDAO()
+	params := make(map[string]string, len(ruleDAO.Parameters)+2)
+	for k, v := range ruleDAO.Parameters {
+		params[k] = v
+	}
+	// the wrapped rule always creates: show the configured flag
+	if _, ok := params["create"]; ok {
+		params["create"] = strconv.FormatBool(ar.create)
+	}
+	if ar.prefix != "" {
+		params["queuePrefix"] = ar.prefix
+	}
+	if ar.suffix != "" {
+		params["queueSuffix"] = ar.suffix
+	}
+	ruleDAO.Parameters = params
+	return ruleDAO
+}
+
+func (ar *affixRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	queueName, err := ar.rule.placeApplication(app, queueFn)
+	if err != nil || queueName == "" {
+		return queueName, err
+	}
+	last := strings.LastIndex(queueName, configs.DOT) + 1
+	childQueueName := ar.prefix + queueName[last:] + ar.suffix
+	if err = configs.IsQueueNameValid(childQueueName); err != nil {
+		return "", err
+	}
+	queueName = queueName[:last] + childQueueName
+	// if we cannot create the queue it must exist, rule does not match otherwise
+	if !ar.create && queueFn(queueName) == nil {
+		return "", nil
+	}
+	log.Log(log.SchedApplication).Debug("Queue prefix and suffix added",
+		zap.String("application", app.ApplicationID),
+		zap.String("ruleName", ar.getName()),
+		zap.String("queue", queueName))
+	return queueName, nil
+}
@@ -0,0 +1,103 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+func TestAffixRule(t *testing.T) {
+	// invalid static segments fail the rule create
+	conf := configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: ".apps"}
+	ar, err := newRule(conf)
+	assert.ErrorContains(t, err, "invalid queue prefix or suffix .apps")
+	assert.Assert(t, ar == nil, "rule should not have been created")
+	conf = configs.PlacementRule{Name: "tag", Value: "namespace", QueuePrefix: "a b"}
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "invalid queue prefix or suffix a b")
+	// group rules set the static segments on the rules in the group
+	conf = configs.PlacementRule{Name: "group", QueueSuffix: "-apps", Rules: []configs.PlacementRule{{Name: "user"}}}
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "a group rule cannot have a queue prefix or suffix")
+	// no static segments: the rule is not wrapped
+	ar, err = newRule(configs.PlacementRule{Name: "tag", Value: "namespace"})
+	assert.NilError(t, err, "rule create failed")
+	_, ok := ar.(*affixRule)
+	assert.Assert(t, !ok, "rule without prefix or suffix should not be wrapped")
+}
+
+func TestAffixRulePlace(t *testing.T) {
+	err := initQueueStructure([]byte(confTestQueue))
+	assert.NilError(t, err, "setting up the queue config failed")
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+
+	var tests = []struct {
+		name      string
+		namespace string
+		conf      configs.PlacementRule
+		expected  string
+		errMsg    string
+	}{
+		{"namespace suffix", "dev", configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: "-apps", Create: true}, "root.dev-apps", ""},
+		{"namespace prefix and suffix", "dev", configs.PlacementRule{Name: "tag", Value: "namespace", QueuePrefix: "ns-", QueueSuffix: "-apps", Create: true}, "root.ns-dev-apps", ""},
+		{"existing queue", "test", configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: "queue"}, "root.testqueue", ""},
+		{"non existing queue", "dev", configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: "-apps"}, "", ""},
+		{"no namespace", "", configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: "-apps", Create: true}, "", ""},
+		{"parent rule", "test", configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: "child", Parent: &configs.PlacementRule{Name: "fixed", Value: "testparent"}}, "root.testparent.testchild", ""},
+		{"combined name too long", strings.Repeat("a", 62), configs.PlacementRule{Name: "tag", Value: "namespace", QueueSuffix: "-apps", Create: true}, "", "invalid queue name"},
+		{"user prefix", "", configs.PlacementRule{Name: "user", QueuePrefix: "u-", Create: true}, "root.u-testuser", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := make(map[string]string)
+			if tt.namespace != "" {
+				tags["namespace"] = tt.namespace
+			}
+			app := newApplication("app1", "default", "ignored", user, tags, nil, "")
+			var ar rule
+			ar, err = newRule(tt.conf)
+			assert.NilError(t, err, "rule create failed")
+			var queue string
+			queue, err = ar.placeApplication(app, queueFunc)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+			} else {
+				assert.NilError(t, err, "unexpected placement failure")
+			}
+			assert.Equal(t, queue, tt.expected, "unexpected queue")
+		})
+	}
+}
+
+func Test_affixRule_ruleDAO(t *testing.T) {
+	ar, err := newRule(configs.PlacementRule{Name: "tag", Value: "namespace", QueuePrefix: "ns-", QueueSuffix: "-apps"})
+	assert.NilError(t, err, "setting up the rule failed")
+	want := &dao.RuleDAO{Name: "tag", Parameters: map[string]string{"tagName": "namespace", "create": "false", "queuePrefix": "ns-", "queueSuffix": "-apps"}}
+	assert.DeepEqual(t, want, ar.ruleDAO())
+}
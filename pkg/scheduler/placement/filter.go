@@ -42,6 +42,8 @@ type Filter struct {
 	groupList map[string]bool
 	userExp   *regexp.Regexp
 	groupExp  *regexp.Regexp
+	userExps  []*regexp.Regexp // slash delimited expressions from the user list
+	groupExps []*regexp.Regexp // slash delimited expressions from the group list
 }
 
 // Check if the user is allowed by the filter
@@ -78,8 +80,8 @@ func (filter Filter) filterUser(user string) bool {
 	if filter.userExp != nil {
 		return filter.userExp.MatchString(user)
 	}
-	// check the members
-	return filter.userList[user]
+	// check the members and then the expressions from the list
+	return filter.userList[user] || matchAny(filter.userExps, user)
 }
 
 // Filter the user based on group getName: return true if the group is in the list or regexp and false if not
@@ -88,8 +90,18 @@ func (filter Filter) filterGroup(group string) bool {
 	if filter.groupExp != nil {
 		return filter.groupExp.MatchString(group)
 	}
-	// check the members
-	return filter.groupList[group]
+	// check the members and then the expressions from the list
+	return filter.groupList[group] || matchAny(filter.groupExps, group)
+}
+
+// matchAny returns true if the name matches at least one of the expressions
+func matchAny(exps []*regexp.Regexp, name string) bool {
+	for _, exp := range exps {
+		if exp.MatchString(name) {
+			return true
+		}
+	}
+	return false
 }
 
 // filterDAO returns the DAO object for the filter.
@@ -107,9 +119,15 @@ func (filter Filter) filterDAO() *dao.FilterDAO {
 	if len(filter.userList) != 0 {
 		userList = maps.Keys(filter.userList)
 	}
+	for _, exp := range filter.userExps {
+		userList = append(userList, configs.FilterExpDelimiter+exp.String()+configs.FilterExpDelimiter)
+	}
 	if len(filter.groupList) != 0 {
 		groupList = maps.Keys(filter.groupList)
 	}
+	for _, exp := range filter.groupExps {
+		groupList = append(groupList, configs.FilterExpDelimiter+exp.String()+configs.FilterExpDelimiter)
+	}
 	var userExp, groupExp string
 	if filter.userExp != nil {
 		userExp = filter.userExp.String()
@@ -142,7 +160,9 @@ func newFilter(conf configs.Filter) Filter {
 	if len(conf.Users) == 1 {
 		user := conf.Users[0]
 		// check for regexp characters that cannot be in a user
-		if configs.SpecialRegExp.MatchString(user) {
+		if exp, ok := configs.GetFilterExp(user); ok {
+			filter.userExps = appendFilterExp(filter.userExps, exp, "user")
+		} else if configs.SpecialRegExp.MatchString(user) {
 			filter.userExp, err = regexp.Compile(user)
 			if err != nil {
 				log.Log(log.Config).Debug("Filter user expression does not compile", zap.Any("userFilter", conf.Users))
@@ -157,19 +177,21 @@ func newFilter(conf configs.Filter) Filter {
 	// if there are 2 or more users create a list
 	if len(conf.Users) >= 2 {
 		for _, user := range conf.Users {
-			// sanity check the entry, do not add if it does not comply
-			if configs.UserRegExp.MatchString(user) {
+			// a slash delimited entry is an expression, otherwise sanity check the entry, do not add if it does not comply
+			if exp, ok := configs.GetFilterExp(user); ok {
+				filter.userExps = appendFilterExp(filter.userExps, exp, "user")
+			} else if configs.UserRegExp.MatchString(user) {
 				filter.userList[user] = true
 			}
 		}
-		if len(filter.userList) != len(conf.Users) {
+		if len(filter.userList)+len(filter.userExps) != len(conf.Users) {
 			log.Log(log.Config).Info("Filter creation duplicate or invalid users found", zap.Any("userFilter", conf.Users))
 		}
 		filter.empty = false
 	}
 
 	// check what we have created
-	if len(conf.Users) > 0 && filter.userExp == nil && len(filter.userList) == 0 {
+	if len(conf.Users) > 0 && filter.userExp == nil && len(filter.userList) == 0 && len(filter.userExps) == 0 {
 		log.Log(log.Config).Info("Filter creation partially failed (user)", zap.Any("userFilter", conf.Users))
 	}
 
@@ -177,7 +199,9 @@ func newFilter(conf configs.Filter) Filter {
 	if len(conf.Groups) == 1 {
 		group := conf.Groups[0]
 		// check for regexp characters that cannot be in a group
-		if configs.SpecialRegExp.MatchString(group) {
+		if exp, ok := configs.GetFilterExp(group); ok {
+			filter.groupExps = appendFilterExp(filter.groupExps, exp, "group")
+		} else if configs.SpecialRegExp.MatchString(group) {
 			filter.groupExp, err = regexp.Compile(group)
 			if err != nil {
 				log.Log(log.Config).Debug("Filter group expression does not compile", zap.Any("groupFilter", conf.Groups))
@@ -192,19 +216,21 @@ func newFilter(conf configs.Filter) Filter {
 	// if there are 2 or more groups create a list
 	if len(conf.Groups) >= 2 {
 		for _, group := range conf.Groups {
-			// sanity check the entry, do not add if it does not comply
-			if configs.GroupRegExp.MatchString(group) {
+			// a slash delimited entry is an expression, otherwise sanity check the entry, do not add if it does not comply
+			if exp, ok := configs.GetFilterExp(group); ok {
+				filter.groupExps = appendFilterExp(filter.groupExps, exp, "group")
+			} else if configs.GroupRegExp.MatchString(group) {
 				filter.groupList[group] = true
 			}
 		}
-		if len(filter.groupList) != len(conf.Groups) {
+		if len(filter.groupList)+len(filter.groupExps) != len(conf.Groups) {
 			log.Log(log.Config).Info("Filter creation duplicate or invalid groups found", zap.Any("groupFilter", conf.Groups))
 		}
 		filter.empty = false
 	}
 
 	// check what we have created
-	if len(conf.Groups) > 0 && filter.groupExp == nil && len(filter.groupList) == 0 {
+	if len(conf.Groups) > 0 && filter.groupExp == nil && len(filter.groupList) == 0 && len(filter.groupExps) == 0 {
 		log.Log(log.Config).Info("Filter creation partially failed (groups)", zap.Any("groupFilter", conf.Groups))
 	}
 
@@ -213,6 +239,20 @@ func newFilter(conf configs.Filter) Filter {
 	return filter
 }
 
+// appendFilterExp compiles the expression and adds it to the list. An expression that does not compile is logged and
+// not added.
+func appendFilterExp(exps []*regexp.Regexp, exp, entryType string) []*regexp.Regexp {
+	compiled, err := regexp.Compile(exp)
+	if err != nil {
+		log.Log(log.Config).Info("Filter expression does not compile, entry skipped",
+			zap.String("type", entryType),
+			zap.String("expression", exp),
+			zap.Error(err))
+		return exps
+	}
+	return append(exps, compiled)
+}
+
 func logFilter(filter *Filter) {
 	var userfilter, groupfilter string
 	if filter.userExp == nil {
@@ -231,5 +271,7 @@ func logFilter(filter *Filter) {
 		zap.Any("userList", filter.userList),
 		zap.Any("groupList", filter.groupList),
 		zap.String("userFilter", userfilter),
-		zap.String("groupFilter", groupfilter))
+		zap.String("groupFilter", groupfilter),
+		zap.Int("userExpressions", len(filter.userExps)),
+		zap.Int("groupExpressions", len(filter.groupExps)))
 }
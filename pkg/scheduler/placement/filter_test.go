@@ -21,8 +21,11 @@ package placement
 import (
 	"reflect"
 	"regexp"
+	"sort"
 	"testing"
 
+	"gotest.tools/v3/assert"
+
 	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/common/security"
 	"github.com/apache/yunikorn-core/pkg/webservice/dao"
@@ -273,6 +276,48 @@ func TestFilterUser(t *testing.T) {
 	}
 }
 
+func TestFilterListExpressions(t *testing.T) {
+	conf := configs.Filter{
+		Users:  []string{"alice", "/^dev-[a-z]+$/", "/dev[a-z/"},
+		Groups: []string{"/^team-.*$/"},
+	}
+	filter := newFilter(conf)
+	assert.Equal(t, len(filter.userList), 1, "user list not set correctly")
+	assert.Equal(t, len(filter.userExps), 1, "invalid expression should have been skipped")
+	assert.Assert(t, filter.userExp == nil, "list expression should not set the single user expression")
+	assert.Equal(t, len(filter.groupExps), 1, "single group expression not set correctly")
+	assert.Assert(t, !filter.empty, "filter should not be empty")
+
+	assert.Assert(t, filter.filterUser("alice"), "filter did not match user 'alice' while in list")
+	assert.Assert(t, filter.filterUser("dev-bob"), "filter did not match user 'dev-bob' while in expression")
+	assert.Assert(t, !filter.filterUser("dev-Bob"), "filter did match user 'dev-Bob' while not in expression")
+	assert.Assert(t, !filter.filterUser("eve"), "filter did match user 'eve' while not in list or expression")
+	assert.Assert(t, !filter.filterUser("dev[a-z"), "filter did match the invalid expression as a user")
+	assert.Assert(t, filter.filterGroup("team-a"), "filter did not match group 'team-a' while in expression")
+	assert.Assert(t, !filter.filterGroup("other"), "filter did match group 'other' while not in expression")
+
+	// allow and deny based on the expression match
+	assert.Assert(t, filter.allowUser(security.UserGroup{User: "dev-carol"}), "allow filter should allow matching user")
+	assert.Assert(t, filter.allowUser(security.UserGroup{User: "eve", Groups: []string{"team-b"}}), "allow filter should allow matching group")
+	assert.Assert(t, !filter.allowUser(security.UserGroup{User: "eve", Groups: []string{"other"}}), "allow filter should not allow user without a match")
+	conf.Type = filterDeny
+	filter = newFilter(conf)
+	assert.Assert(t, !filter.allowUser(security.UserGroup{User: "dev-carol"}), "deny filter should deny matching user")
+	assert.Assert(t, filter.allowUser(security.UserGroup{User: "eve"}), "deny filter should allow user without a match")
+
+	// only invalid expressions: nothing matches
+	filter = newFilter(configs.Filter{Users: []string{"/[a-z/"}})
+	assert.Equal(t, len(filter.userExps), 0, "invalid expression should have been skipped")
+	assert.Assert(t, !filter.filterUser("a"), "filter without valid entries should not match")
+	assert.DeepEqual(t, filter.filterDAO(), &dao.FilterDAO{Type: filterAllow})
+
+	// the expressions are shown in the list in the DAO
+	filter = newFilter(configs.Filter{Users: []string{"/^dev-[a-z]+$/"}, Groups: []string{"admin", "/^team-.*$/"}})
+	filterDAO := filter.filterDAO()
+	sort.Strings(filterDAO.GroupList)
+	assert.DeepEqual(t, filterDAO, &dao.FilterDAO{Type: filterAllow, UserList: []string{"/^dev-[a-z]+$/"}, GroupList: []string{"/^team-.*$/", "admin"}})
+}
+
 // test complex expression
 func TestComplexExpression(t *testing.T) {
 	// expression user list (case insensitive)
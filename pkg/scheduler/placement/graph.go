@@ -0,0 +1,122 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/yunikorn-core/pkg/common"
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+)
+
+const (
+	dotStart    = "start"
+	dotPlaced   = "placed"
+	dotDefault  = "default"
+	dotRejected = "rejected"
+)
+
+// RulesToDOT renders the placement rules from a parsed config as a Graphviz DOT graph. The graph follows the order in
+// which the placement manager executes the rules: each rule either places the application or falls through to the
+// next rule. After the last rule the default queue is tried before the application is rejected. Parent rules are
+// linked to the rule they generate the parent queue for.
+// The rules are not validated, an empty list is rendered as the implicit provided rule.
+func RulesToDOT(rules []configs.PlacementRule) string {
+	if len(rules) == 0 {
+		rules = []configs.PlacementRule{{Name: types.Provided}}
+	}
+	var sb strings.Builder
+	sb.WriteString("digraph placement {\n")
+	sb.WriteString("\trankdir=TB;\n")
+	writeDOTNode(&sb, dotStart, "start", "circle")
+	writeDOTNode(&sb, dotPlaced, "placed", "doublecircle")
+	writeDOTNode(&sb, dotDefault, "default queue\n"+common.DefaultPlacementQueue, "diamond")
+	writeDOTNode(&sb, dotRejected, "rejected", "octagon")
+	previous := dotStart
+	edge := ""
+	for i := range rules {
+		id := fmt.Sprintf("rule%d", i)
+		writeDOTRule(&sb, id, &rules[i])
+		writeDOTEdge(&sb, previous, id, edge)
+		writeDOTEdge(&sb, id, dotPlaced, "match")
+		previous = id
+		edge = "no match"
+	}
+	writeDOTEdge(&sb, previous, dotDefault, edge)
+	writeDOTEdge(&sb, dotDefault, dotPlaced, "exists")
+	writeDOTEdge(&sb, dotDefault, dotRejected, "missing")
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeDOTRule writes the node for the rule and the chain of parent rules linked to it.
+func writeDOTRule(sb *strings.Builder, id string, conf *configs.PlacementRule) {
+	writeDOTNode(sb, id, ruleLabel(conf), "box")
+	if conf.Parent != nil {
+		parentID := id + "_parent"
+		writeDOTRule(sb, parentID, conf.Parent)
+		writeDOTEdge(sb, id, parentID, "parent")
+	}
+}
+
+// ruleLabel returns the multi line label describing the rule config.
+func ruleLabel(conf *configs.PlacementRule) string {
+	lines := []string{conf.Name}
+	if conf.Value != "" {
+		lines = append(lines, "value: "+conf.Value)
+	}
+	lines = append(lines, fmt.Sprintf("create: %t", conf.Create))
+	if filter := conf.Filter; filter.Type != "" || len(filter.Users) != 0 || len(filter.Groups) != 0 {
+		filterType := filterAllow
+		if filter.Type == filterDeny {
+			filterType = filterDeny
+		}
+		line := "filter: " + filterType
+		if len(filter.Users) != 0 {
+			line += " users " + strings.Join(filter.Users, ",")
+		}
+		if len(filter.Groups) != 0 {
+			line += " groups " + strings.Join(filter.Groups, ",")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeDOTNode(sb *strings.Builder, id, label, shape string) {
+	fmt.Fprintf(sb, "\t%s [label=%s, shape=%s];\n", id, dotQuote(label), shape)
+}
+
+func writeDOTEdge(sb *strings.Builder, from, to, label string) {
+	if label == "" {
+		fmt.Fprintf(sb, "\t%s -> %s;\n", from, to)
+		return
+	}
+	fmt.Fprintf(sb, "\t%s -> %s [label=%s];\n", from, to, dotQuote(label))
+}
+
+// dotQuote returns the text as a quoted DOT string with line breaks converted to DOT line breaks.
+func dotQuote(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	text = strings.ReplaceAll(text, "\n", `\n`)
+	return `"` + text + `"`
+}
@@ -0,0 +1,75 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+)
+
+func TestRulesToDOT(t *testing.T) {
+	rules := []configs.PlacementRule{
+		{
+			Name:   "tag",
+			Value:  "namespace",
+			Create: true,
+			Parent: &configs.PlacementRule{
+				Name:  "fixed",
+				Value: "root.namespaces",
+			},
+			Filter: configs.Filter{Type: filterDeny, Users: []string{"admin"}, Groups: []string{"ops", "dev"}},
+		},
+		{Name: "user"},
+	}
+	dot := RulesToDOT(rules)
+	assert.Assert(t, strings.HasPrefix(dot, "digraph placement {\n"), "graph header missing: %s", dot)
+	assert.Assert(t, strings.HasSuffix(dot, "}\n"), "graph not closed: %s", dot)
+	for _, want := range []string{
+		`rule0 [label="tag\nvalue: namespace\ncreate: true\nfilter: deny users admin groups ops,dev", shape=box];`,
+		`rule0_parent [label="fixed\nvalue: root.namespaces\ncreate: false", shape=box];`,
+		`rule1 [label="user\ncreate: false", shape=box];`,
+		`start -> rule0;`,
+		`rule0 -> rule0_parent [label="parent"];`,
+		`rule0 -> placed [label="match"];`,
+		`rule0 -> rule1 [label="no match"];`,
+		`rule1 -> placed [label="match"];`,
+		`rule1 -> default [label="no match"];`,
+		`default -> placed [label="exists"];`,
+		`default -> rejected [label="missing"];`,
+	} {
+		assert.Assert(t, strings.Contains(dot, want), "expected %s in graph: %s", want, dot)
+	}
+	assert.Assert(t, !strings.Contains(dot, "rule1_parent"), "rule without parent should not have a parent node: %s", dot)
+
+	// no rules renders the implicit provided rule
+	dot = RulesToDOT(nil)
+	assert.Assert(t, strings.Contains(dot, `rule0 [label="provided\ncreate: false", shape=box];`), "implicit provided rule missing: %s", dot)
+	assert.Assert(t, strings.Contains(dot, `rule0 -> default [label="no match"];`), "fall through to default missing: %s", dot)
+	assert.Assert(t, !strings.Contains(dot, "rule1"), "only one rule expected: %s", dot)
+}
+
+func TestDOTQuote(t *testing.T) {
+	assert.Equal(t, dotQuote("plain"), `"plain"`)
+	assert.Equal(t, dotQuote("two\nlines"), `"two\nlines"`)
+	assert.Equal(t, dotQuote(`say "hi" \o/`), `"say \"hi\" \\o/"`)
+}
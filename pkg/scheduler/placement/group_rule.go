@@ -0,0 +1,117 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+// A rule that groups a chain of rules. The rules in the group are executed in order and the queue returned by the first
+// rule that matches is the result of the group. If none of the rules in the group match the group does not match and
+// the next rule after the group is executed. A failure of a rule in the group fails the group.
+// The group has no parent rule: the rules in the group can each have their own parent rule.
+type groupRule struct {
+	basicRule
+	rules []rule
+}
+
+func (gr *groupRule) getName() string {
+	return types.Group
+}
+
+func (gr *groupRule) ruleDAO() *dao.RuleDAO {
+	rulesDAO := make([]*dao.RuleDAO, len(gr.rules))
+	for i, r := range gr.rules {
+		rulesDAO[i] = r.ruleDAO()
+	}
+	return &dao.RuleDAO{
+		Name:   gr.getName(),
+		Filter: gr.filter.filterDAO(),
+		Rules:  rulesDAO,
+	}
+}
+
+func (gr *groupRule) initialise(conf configs.PlacementRule) error {
+	if len(conf.Rules) == 0 {
+		return fmt.Errorf("a group rule must have at least one rule")
+	}
+	if conf.Parent != nil {
+		return fmt.Errorf("a group rule cannot have a parent rule")
+	}
+	if conf.QueuePrefix != "" || conf.QueueSuffix != "" {
+		return fmt.Errorf("a group rule cannot have a queue prefix or suffix, set it on the rules in the group")
+	}
+	gr.filter = newFilter(conf.Filter)
+	gr.rules = make([]rule, 0, len(conf.Rules))
+	for _, ruleConf := range conf.Rules {
+		r, err := newRule(ruleConf)
+		if err != nil {
+			return err
+		}
+		gr.rules = append(gr.rules, r)
+	}
+	return nil
+}
+
+func (gr *groupRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	return gr.placeChecked(app, queueFn, func(string, string) bool { return true })
+}
+
+// placeChecked executes the rules in the group and returns the queue of the first rule that matches and passes the
+// check. A queue returned by a rule that fails the check is skipped and the next rule in the group is executed.
+func (gr *groupRule) placeChecked(app *objects.Application, queueFn func(string) *objects.Queue, check func(queueName, ruleName string) bool) (string, error) {
+	// before anything run the filter
+	if !gr.filter.allowUser(app.GetUser()) {
+		log.Log(log.SchedApplication).Debug("Group rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()))
+		return "", nil
+	}
+	for _, r := range gr.rules {
+		var queueName string
+		var err error
+		if group, ok := r.(*groupRule); ok {
+			queueName, err = group.placeChecked(app, queueFn, check)
+		} else {
+			queueName, err = r.placeApplication(app, queueFn)
+			if err == nil && queueName != "" && !check(queueName, r.getName()) {
+				queueName = ""
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		if queueName != "" {
+			log.Log(log.SchedApplication).Debug("Group rule matched",
+				zap.String("application", app.ApplicationID),
+				zap.String("ruleName", r.getName()),
+				zap.String("queue", queueName))
+			return queueName, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,198 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+func TestGroupRule(t *testing.T) {
+	var tests = []struct {
+		name  string
+		conf  configs.PlacementRule
+		valid bool
+	}{
+		{"no rules", configs.PlacementRule{Name: "group"}, false},
+		{"parent rule", configs.PlacementRule{Name: "group", Rules: []configs.PlacementRule{{Name: "user"}}, Parent: &configs.PlacementRule{Name: "user"}}, false},
+		{"invalid rule in group", configs.PlacementRule{Name: "group", Rules: []configs.PlacementRule{{Name: "user"}, {Name: "fixed"}}}, false},
+		{"recovery rule in group", configs.PlacementRule{Name: "group", Rules: []configs.PlacementRule{{Name: "recovery"}}}, false},
+		{"single rule", configs.PlacementRule{Name: "group", Rules: []configs.PlacementRule{{Name: "user"}}}, true},
+		{"nested group", configs.PlacementRule{Name: "group", Rules: []configs.PlacementRule{{Name: "group", Rules: []configs.PlacementRule{{Name: "user"}}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gr, err := newRule(tt.conf)
+			if tt.valid {
+				assert.NilError(t, err, "group rule create failed")
+				assert.Assert(t, gr != nil, "group rule create returned nil rule")
+			} else {
+				assert.Assert(t, err != nil, "group rule create should have failed")
+				assert.Assert(t, gr == nil, "group rule create should not return a rule")
+			}
+		})
+	}
+}
+
+func TestGroupRulePlace(t *testing.T) {
+	err := initQueueStructure([]byte(confTestQueue))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	app := newApplication("app1", "default", "ignored", user, map[string]string{}, nil, "")
+
+	// the second rule in the group matches
+	conf := configs.PlacementRule{
+		Name: "group",
+		Rules: []configs.PlacementRule{
+			{Name: "fixed", Value: "nonexist"},
+			{Name: "fixed", Value: "testchild", Parent: &configs.PlacementRule{Name: "fixed", Value: "testparent"}},
+			{Name: "fixed", Value: "testqueue"},
+		},
+	}
+	var gr rule
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	var queue string
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "root.testparent.testchild", "second rule of the group should have matched")
+
+	// no rule in the group matches
+	conf.Rules = []configs.PlacementRule{{Name: "fixed", Value: "nonexist"}, {Name: "user"}}
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "", "group rule should not have matched")
+
+	// a failing rule fails the group
+	conf.Rules = []configs.PlacementRule{{Name: "fixed", Value: "nonexist", Create: true, Parent: &configs.PlacementRule{Name: "fixed", Value: "testqueue"}}}
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	_, err = gr.placeApplication(app, queueFunc)
+	assert.Assert(t, err != nil, "group rule should have failed with a leaf parent")
+
+	// a filtered group does not run its rules
+	conf = configs.PlacementRule{
+		Name:   "group",
+		Rules:  []configs.PlacementRule{{Name: "fixed", Value: "testqueue"}},
+		Filter: configs.Filter{Type: filterDeny, Users: []string{"testuser"}},
+	}
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "", "filtered group rule should not have matched")
+}
+
+func TestManagerPlaceAppGroupRule(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: testqueue
+          - name: other
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+	rules := []configs.PlacementRule{
+		{Name: "group", Rules: []configs.PlacementRule{
+			{Name: "fixed", Value: "nonexist"},
+			{Name: "user"},
+		}},
+		{Name: "fixed", Value: "other"},
+	}
+	man := NewPlacementManager(rules, queueFunc, false)
+	user := security.UserGroup{
+		User:   "testqueue",
+		Groups: []string{},
+	}
+	// the second rule in the group matches the user queue
+	app := newApplication("app1", "default", "", user, map[string]string{}, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err, "application should have been placed")
+	assert.Equal(t, app.GetQueuePath(), "root.testqueue", "application should be placed by the group")
+
+	// none of the rules in the group match: the rule after the group places the application
+	user.User = "unknown"
+	app = newApplication("app2", "default", "", user, map[string]string{}, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err, "application should have been placed")
+	assert.Equal(t, app.GetQueuePath(), "root.other", "application should be placed by the rule after the group")
+}
+
+func TestManagerPlaceAppGroupRuleDenied(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: denied
+            submitacl: "other"
+          - name: allowed
+            submitacl: "*"
+          - name: last
+            submitacl: "*"
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+	rules := []configs.PlacementRule{
+		{Name: "group", Rules: []configs.PlacementRule{
+			{Name: "fixed", Value: "denied"},
+			{Name: "fixed", Value: "allowed"},
+		}},
+		{Name: "fixed", Value: "last"},
+	}
+	man := NewPlacementManager(rules, queueFunc, false)
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	// access to the queue of the first rule in the group is denied: the second rule in the group places the application
+	app := newApplication("app1", "default", "", user, map[string]string{}, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err, "application should have been placed")
+	assert.Equal(t, app.GetQueuePath(), "root.allowed", "application should be placed by the second rule in the group")
+}
+
+func Test_groupRule_ruleDAO(t *testing.T) {
+	gr, err := newRule(configs.PlacementRule{Name: "group", Rules: []configs.PlacementRule{{Name: "test", Create: true}, {Name: "fixed", Value: "default"}}})
+	assert.NilError(t, err, "setting up the rule failed")
+	want := &dao.RuleDAO{
+		Name: "group",
+		Rules: []*dao.RuleDAO{
+			{Name: "test", Parameters: map[string]string{"create": "true"}},
+			{Name: "fixed", Parameters: map[string]string{"queue": "default", "qualified": "false", "create": "false"}},
+		},
+	}
+	assert.DeepEqual(t, want, gr.ruleDAO())
+}
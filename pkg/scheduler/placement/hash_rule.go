@@ -0,0 +1,158 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+// A rule to spread applications over a fixed set of queues. The value of the rule is a comma separated list of
+// target queues. The target queue is selected using a hash of the application ID modulo the number of targets: the
+// same application ID is always placed in the same queue as long as the list does not change.
+// If the selected queue is fully qualified, starts with "root.", the parent rule is skipped. If the queue is not
+// qualified the parent rule is run before making the queue name fully qualified.
+// NOTE: queue names are normalised to lower case.
+type hashRule struct {
+	basicRule
+	targets []string
+}
+
+func (hr *hashRule) getName() string {
+	return types.Hash
+}
+
+func (hr *hashRule) ruleDAO() *dao.RuleDAO {
+	var pDAO *dao.RuleDAO
+	if hr.parent != nil {
+		pDAO = hr.parent.ruleDAO()
+	}
+	return &dao.RuleDAO{
+		Name: hr.getName(),
+		Parameters: map[string]string{
+			"queues": strings.Join(hr.targets, ","),
+			"create": strconv.FormatBool(hr.create),
+		},
+		ParentRule: pDAO,
+		Filter:     hr.filter.filterDAO(),
+	}
+}
+
+func (hr *hashRule) initialise(conf configs.PlacementRule) error {
+	if strings.TrimSpace(conf.Value) == "" {
+		return fmt.Errorf("a hash rule must have target queues set")
+	}
+	seen := make(map[string]bool)
+	hr.targets = make([]string, 0)
+	for _, entry := range strings.Split(conf.Value, ",") {
+		queue := normalise(strings.TrimSpace(entry))
+		if queue == "" {
+			return fmt.Errorf("empty target queue in hash rule value '%s'", conf.Value)
+		}
+		if seen[queue] {
+			return fmt.Errorf("duplicate target queue '%s' in hash rule", queue)
+		}
+		for _, part := range strings.Split(queue, configs.DOT) {
+			if err := configs.IsQueueNameValid(part); err != nil {
+				return fmt.Errorf("invalid target queue name '%s' in hash rule: %w", queue, err)
+			}
+		}
+		seen[queue] = true
+		hr.targets = append(hr.targets, queue)
+	}
+	hr.create = conf.Create
+	hr.filter = newFilter(conf.Filter)
+	var err = error(nil)
+	if conf.Parent != nil {
+		hr.parent, err = newRule(*conf.Parent)
+	}
+	return err
+}
+
+// selectTarget returns the target queue for the application ID.
+func (hr *hashRule) selectTarget(appID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(appID))
+	return hr.targets[h.Sum32()%uint32(len(hr.targets))]
+}
+
+func (hr *hashRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	// before anything run the filter
+	if !hr.filter.allowUser(app.GetUser()) {
+		log.Log(log.SchedApplication).Debug("Hash rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()))
+		return "", nil
+	}
+	queueName := hr.selectTarget(app.ApplicationID)
+	// not fully qualified queue, run the parent rule if set
+	if !strings.HasPrefix(queueName, configs.RootQueue+configs.DOT) {
+		var parentName string
+		var err error
+		if hr.parent != nil {
+			parentName, err = hr.parent.placeApplication(app, queueFn)
+			// failed parent rule, fail this rule
+			if err != nil {
+				return "", err
+			}
+			// rule did not return a parent: this could be filter or create flag related
+			if parentName == "" {
+				return "", nil
+			}
+			// check if this is a parent queue and qualify it
+			if !strings.HasPrefix(parentName, configs.RootQueue+configs.DOT) {
+				parentName = configs.RootQueue + configs.DOT + parentName
+			}
+			// if the parent queue exists it cannot be a leaf
+			parentQueue := queueFn(parentName)
+			if parentQueue != nil && parentQueue.IsLeafQueue() {
+				return "", fmt.Errorf("parent rule returned a leaf queue: %s", parentName)
+			}
+		}
+		// the parent is set from the rule otherwise set it to the root
+		if parentName == "" {
+			parentName = configs.RootQueue
+		}
+		queueName = parentName + configs.DOT + queueName
+	}
+	// Log the result before we check the create flag
+	log.Log(log.SchedApplication).Debug("Hash rule intermediate result",
+		zap.String("application", app.ApplicationID),
+		zap.String("queue", queueName))
+	// get the queue object
+	queue := queueFn(queueName)
+	// if we cannot create the queue must exist
+	if !hr.create && queue == nil {
+		return "", nil
+	}
+	log.Log(log.SchedApplication).Info("Hash rule application placed",
+		zap.String("application", app.ApplicationID),
+		zap.String("queue", queueName))
+	return queueName, nil
+}
@@ -0,0 +1,192 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+func TestHashRule(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"no targets", "", false},
+		{"single target", "testqueue", true},
+		{"multiple targets", "testqueue, root.testparent.testchild", true},
+		{"empty target", "testqueue,,other", false},
+		{"duplicate target", "testqueue,TestQueue", false},
+		{"invalid queue name", "test!>queue", false},
+		{"invalid queue name in hierarchy", "root.test!>parent.testqueue", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hr, err := newRule(configs.PlacementRule{Name: "hash", Value: tt.value})
+			if tt.valid {
+				assert.NilError(t, err, "hash rule create failed")
+				assert.Assert(t, hr != nil, "hash rule create returned nil rule")
+			} else {
+				assert.Assert(t, err != nil, "hash rule create should have failed")
+				assert.Assert(t, hr == nil, "hash rule create should not return a rule")
+			}
+		})
+	}
+}
+
+func TestHashRulePlace(t *testing.T) {
+	err := initQueueStructure([]byte(confTestQueue))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	tags := make(map[string]string)
+	conf := configs.PlacementRule{
+		Name:   "hash",
+		Value:  "spread1,spread2,spread3,spread4",
+		Create: true,
+	}
+	hr, err := newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+
+	// the same application is always placed in the same queue, applications are spread roughly evenly
+	const total = 4000
+	placed := make(map[string]int)
+	for i := 0; i < total; i++ {
+		app := newApplication(fmt.Sprintf("app-%d", i), "default", "ignored", user, tags, nil, "")
+		var queue string
+		queue, err = hr.placeApplication(app, queueFunc)
+		assert.NilError(t, err, "hash rule placement failed")
+		for j := 0; j < 3; j++ {
+			var again string
+			again, err = hr.placeApplication(app, queueFunc)
+			assert.NilError(t, err, "hash rule placement failed")
+			assert.Equal(t, again, queue, "hash rule placement is not deterministic for %s", app.ApplicationID)
+		}
+		placed[queue]++
+	}
+	assert.Equal(t, len(placed), 4, "all target queues should be used: %v", placed)
+	for _, queue := range []string{"root.spread1", "root.spread2", "root.spread3", "root.spread4"} {
+		// roughly a quarter: allow 10% deviation
+		assert.Assert(t, placed[queue] > 900 && placed[queue] < 1100, "expected roughly %d applications in %s, got %d", total/4, queue, placed[queue])
+	}
+
+	// a single existing target without create
+	conf = configs.PlacementRule{Name: "hash", Value: "root.testparent.testchild"}
+	hr, err = newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+	app := newApplication("app1", "default", "ignored", user, tags, nil, "")
+	queue, err := hr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "hash rule placement failed")
+	assert.Equal(t, queue, "root.testparent.testchild", "hash rule placed in wrong queue")
+
+	// a non existing target without create does not place
+	conf = configs.PlacementRule{Name: "hash", Value: "newqueue"}
+	hr, err = newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+	queue, err = hr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "hash rule placement failed")
+	assert.Equal(t, queue, "", "hash rule without create should not place in a non existing queue")
+
+	// deny filter should not place the application
+	conf = configs.PlacementRule{
+		Name:   "hash",
+		Value:  "testqueue",
+		Filter: configs.Filter{Type: filterDeny},
+	}
+	hr, err = newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+	queue, err = hr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "hash rule placement failed")
+	assert.Equal(t, queue, "", "hash rule with deny filter should not place the application")
+}
+
+func TestHashRuleParent(t *testing.T) {
+	err := initQueueStructure([]byte(confParentChild))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	tags := make(map[string]string)
+	app := newApplication("app1", "default", "ignored", user, tags, nil, "")
+
+	// unqualified queue uses the parent
+	conf := configs.PlacementRule{
+		Name:   "hash",
+		Value:  "testchild",
+		Create: true,
+		Parent: &configs.PlacementRule{
+			Name:   "fixed",
+			Value:  "testparentnew",
+			Create: true,
+		},
+	}
+	hr, err := newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+	queue, err := hr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "hash rule placement failed")
+	assert.Equal(t, queue, nameParentChild, "hash rule with parent placed in wrong queue")
+
+	// parent is a leaf queue
+	conf.Parent = &configs.PlacementRule{
+		Name:  "fixed",
+		Value: "testchild",
+	}
+	hr, err = newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+	queue, err = hr.placeApplication(app, queueFunc)
+	assert.Assert(t, err != nil, "hash rule with leaf parent should have failed")
+	assert.Equal(t, queue, "", "hash rule with leaf parent should not place the application")
+
+	// qualified queue skips the parent
+	conf.Value = "root.testparent.newchild"
+	hr, err = newRule(conf)
+	assert.NilError(t, err, "hash rule create failed")
+	queue, err = hr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "hash rule placement failed")
+	assert.Equal(t, queue, "root.testparent.newchild", "hash rule with qualified queue placed in wrong queue")
+}
+
+func Test_hashRule_ruleDAO(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name:   "hash",
+		Value:  "Other, root.default",
+		Create: true,
+		Parent: &configs.PlacementRule{Name: "test", Create: true},
+	}
+	hr, err := newRule(conf)
+	assert.NilError(t, err, "setting up the rule failed")
+	want := &dao.RuleDAO{
+		Name:       "hash",
+		Parameters: map[string]string{"queues": "other,root.default", "create": "true"},
+		ParentRule: &dao.RuleDAO{Name: "test", Parameters: map[string]string{"create": "true"}},
+	}
+	assert.DeepEqual(t, want, hr.ruleDAO())
+}
@@ -20,25 +20,33 @@ package placement
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"go.uber.org/zap"
 
 	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
 	"github.com/apache/yunikorn-core/pkg/locking"
 	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/metrics"
 	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/yunikorn-core/pkg/webservice/dao"
 )
 
 // RejectedError is the standard error returned if placement has failed
 var RejectedError = errors.New("application rejected: no placement rule matched")
 
+// maxSuggestedQueues is the maximum number of queues the user can submit to that are added to a rejection
+const maxSuggestedQueues = 3
+
 type AppPlacementManager struct {
-	rules   []rule
-	queueFn func(string) *objects.Queue
+	rules         []rule
+	queueFn       func(string) *objects.Queue
+	promoteParent bool // a parent queue without child queues is accepted and changed into a leaf queue
 
 	locking.RWMutex
 }
@@ -73,6 +81,19 @@ func (m *AppPlacementManager) UpdateRules(rules []configs.PlacementRule) error {
 	return nil
 }
 
+// SetParentPlacementPolicy sets the handling of a rule that returns an existing parent queue.
+func (m *AppPlacementManager) SetParentPlacementPolicy(policy policies.ParentPlacementPolicy) {
+	m.Lock()
+	defer m.Unlock()
+	m.promoteParent = policy == policies.PromoteParentPlacementPolicy
+}
+
+// ValidateRules checks that the rules from a parsed config can be built without changing any placement manager.
+func ValidateRules(rules []configs.PlacementRule) error {
+	_, err := buildRules(rules, true)
+	return err
+}
+
 // initialise the rules from a parsed config.
 // If the silence flag is set to true, the function will not log.
 func (m *AppPlacementManager) initialise(rules []configs.PlacementRule, silence bool) error {
@@ -107,15 +128,38 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 	m.RLock()
 	defer m.RUnlock()
 
+	// an override by a queue admin bypasses the rules
+	if queueName := m.overrideQueue(app); queueName != "" {
+		app.SetQueuePath(queueName)
+		return nil
+	}
+
 	var queueName string
 	var err error
 	var remainingRules = len(m.rules)
+	var fallThrough bool
+	var parentQueue string
+	check := func(queueName, ruleName string) bool {
+		valid, parent := m.checkQueue(app, queueName, ruleName)
+		if parent {
+			// remember the queue for the rejection reason
+			parentQueue = queueName
+		}
+		return valid
+	}
 	for _, checkRule := range m.rules {
 		remainingRules--
 		log.Log(log.SchedApplication).Debug("Executing rule for placing application",
 			zap.String("ruleName", checkRule.getName()),
 			zap.String("application", app.ApplicationID))
-		queueName, err = checkRule.placeApplication(app, m.queueFn)
+		// the rules in a group are checked one by one: a denied queue moves on to the next rule in the group
+		var checked bool
+		if group, ok := checkRule.(*groupRule); ok {
+			queueName, err = group.placeChecked(app, m.queueFn, check)
+			checked = true
+		} else {
+			queueName, err = checkRule.placeApplication(app, m.queueFn)
+		}
 		if err != nil {
 			log.Log(log.SchedApplication).Error("rule execution failed",
 				zap.String("ruleName", checkRule.getName()),
@@ -133,6 +177,8 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 			if queue != nil {
 				// default queue exist
 				queueName = common.DefaultPlacementQueue
+				fallThrough = true
+				checked = false
 			}
 		}
 		// no queue name next rule
@@ -145,77 +191,142 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 		if queueName == common.RecoveryQueueFull && app.IsCreateForced() {
 			log.Log(log.SchedApplication).Info("Placing application in recovery queue",
 				zap.String("application", app.ApplicationID))
+			metrics.GetSchedulerMetrics().IncPlacementRuleMatch(checkRule.getName())
 			break
 		}
-		// queueName returned make sure ACL allows access and set the queueName in the app
-		queue := m.queueFn(queueName)
-		// walk up the tree if the queue does not exist
-		if queue == nil {
-			current := queueName
-			for queue == nil {
-				current = current[0:strings.LastIndex(current, configs.DOT)]
-				// check if the queue exist
-				queue = m.queueFn(current)
-			}
-			// Check if the user is allowed to submit to this queueName, if not next rule
-			if !queue.CheckSubmitAccess(app.GetUser()) {
-				log.Log(log.SchedApplication).Debug("Submit access denied on queue",
-					zap.String("queueName", queue.GetQueuePath()),
-					zap.String("ruleName", checkRule.getName()),
-					zap.String("application", app.ApplicationID))
-				// reset the queue name for the last rule in the chain
-				queueName = ""
-				continue
-			}
-		} else {
-			// Check if this final queue is a leaf queue, if not next rule
-			if !queue.IsLeafQueue() {
-				log.Log(log.SchedApplication).Debug("Rule returned parent queue",
-					zap.String("queueName", queueName),
-					zap.String("ruleName", checkRule.getName()),
-					zap.String("application", app.ApplicationID))
-				// reset the queue name for the last rule in the chain
-				queueName = ""
-				continue
-			}
-			// Check if the user is allowed to submit to this queueName, if not next rule
-			if !queue.CheckSubmitAccess(app.GetUser()) {
-				log.Log(log.SchedApplication).Debug("Submit access denied on queue",
-					zap.String("queueName", queueName),
-					zap.String("ruleName", checkRule.getName()),
-					zap.String("application", app.ApplicationID))
-				// reset the queue name for the last rule in the chain
-				queueName = ""
-				continue
-			}
-			// Check if the queue in Draining state, and if so, proceed to the next rule
-			if queue.IsDraining() {
-				log.Log(log.SchedApplication).Debug("Cannot place application in draining queue",
-					zap.String("queueName", queueName),
-					zap.String("ruleName", checkRule.getName()),
-					zap.String("application", app.ApplicationID))
-				// reset the queue name for the last rule in the chain
-				queueName = ""
-				continue
-			}
+		// queueName returned make sure the queue can be used, if not next rule
+		if !checked && !check(queueName, checkRule.getName()) {
+			// reset the queue name for the last rule in the chain
+			queueName = ""
+			continue
 		}
 		// we have a queue that allows submitting and can be created: app placed
 		log.Log(log.SchedApplication).Info("Rule result for placing application",
 			zap.String("application", app.ApplicationID),
 			zap.String("ruleName", checkRule.getName()),
 			zap.String("queueName", queueName))
+		if fallThrough {
+			metrics.GetSchedulerMetrics().IncPlacementFallThrough(metrics.PlacementDefault)
+		} else {
+			metrics.GetSchedulerMetrics().IncPlacementRuleMatch(checkRule.getName())
+		}
 		break
 	}
 	// no more rules to check no queueName found reject placement
 	if queueName == "" {
+		metrics.GetSchedulerMetrics().IncPlacementFallThrough(metrics.PlacementRejected)
 		app.SetQueuePath("")
-		return RejectedError
+		if parentQueue != "" {
+			return m.suggestQueues(fmt.Errorf("%w: queue %s is a parent queue", RejectedError, parentQueue), app.GetUser())
+		}
+		return m.suggestQueues(RejectedError, app.GetUser())
 	}
 	// Add the queue into the application, overriding what was submitted
 	app.SetQueuePath(queueName)
 	return nil
 }
 
+// checkQueue checks the queue returned by a rule: the user must be allowed to submit to the queue, an existing queue
+// must be a leaf queue, or a parent queue that can be changed into one, and must not be draining. A queue that does not
+// exist is checked against the first existing queue up the hierarchy. Returns true if the queue can be used and true as
+// the second value if the queue was rejected because it is a parent queue.
+// Lock free call, must be called holding the placement manager lock.
+func (m *AppPlacementManager) checkQueue(app *objects.Application, queueName, ruleName string) (bool, bool) {
+	// the recovery queue is not checked for a forced placement
+	if queueName == common.RecoveryQueueFull && app.IsCreateForced() {
+		return true, false
+	}
+	queue := m.queueFn(queueName)
+	// walk up the tree if the queue does not exist
+	if queue == nil {
+		current := queueName
+		for queue == nil {
+			current = current[0:strings.LastIndex(current, configs.DOT)]
+			// check if the queue exist
+			queue = m.queueFn(current)
+		}
+		// Check if the user is allowed to submit to this queueName
+		if !queue.CheckSubmitAccess(app.GetUser()) {
+			log.Log(log.SchedApplication).Debug("Submit access denied on queue",
+				zap.String("queueName", queue.GetQueuePath()),
+				zap.String("ruleName", ruleName),
+				zap.String("application", app.ApplicationID))
+			return false, false
+		}
+		return true, false
+	}
+	// Check if this final queue is a leaf queue, or can be changed into one
+	if !queue.IsLeafQueue() && (!m.promoteParent || len(queue.GetCopyOfChildren()) != 0) {
+		log.Log(log.SchedApplication).Debug("Rule returned parent queue",
+			zap.String("queueName", queueName),
+			zap.String("ruleName", ruleName),
+			zap.String("application", app.ApplicationID))
+		return false, true
+	}
+	// Check if the user is allowed to submit to this queueName
+	if !queue.CheckSubmitAccess(app.GetUser()) {
+		log.Log(log.SchedApplication).Debug("Submit access denied on queue",
+			zap.String("queueName", queueName),
+			zap.String("ruleName", ruleName),
+			zap.String("application", app.ApplicationID))
+		return false, false
+	}
+	// Check if the queue in Draining state
+	if queue.IsDraining() {
+		log.Log(log.SchedApplication).Debug("Cannot place application in draining queue",
+			zap.String("queueName", queueName),
+			zap.String("ruleName", ruleName),
+			zap.String("application", app.ApplicationID))
+		return false, false
+	}
+	return true, false
+}
+
+// suggestQueues adds the leaf queues the user can submit to, up to maxSuggestedQueues, to the rejection error.
+// The error is returned unchanged if the user cannot submit to any queue.
+func (m *AppPlacementManager) suggestQueues(err error, user security.UserGroup) error {
+	root := m.queueFn(configs.RootQueue)
+	if root == nil {
+		return err
+	}
+	suggested := root.GetSubmittableQueues(user, maxSuggestedQueues)
+	if len(suggested) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w, suggested queues: %s", err, strings.Join(suggested, common.Separator))
+}
+
+// overrideQueue returns the queue set in the queue override tag of the application.
+// The override is only honoured if the queue exists, is a leaf queue that is not draining, and the user has admin access
+// to the queue. In all other cases an empty string is returned and the normal rules must be applied.
+func (m *AppPlacementManager) overrideQueue(app *objects.Application) string {
+	queueName := app.GetTag(common.AppTagQueueOverride)
+	if queueName == "" {
+		return ""
+	}
+	if !strings.HasPrefix(queueName, configs.RootQueue+configs.DOT) {
+		queueName = configs.RootQueue + configs.DOT + queueName
+	}
+	queue := m.queueFn(queueName)
+	if queue == nil || !queue.IsLeafQueue() || queue.IsDraining() || common.IsRecoveryQueue(queueName) {
+		log.Log(log.SchedApplication).Info("Queue override ignored, queue is not an active leaf queue",
+			zap.String("application", app.ApplicationID),
+			zap.String("queueName", queueName))
+		return ""
+	}
+	if !queue.CheckAdminAccess(app.GetUser()) {
+		log.Log(log.SchedApplication).Info("Queue override ignored, user is not a queue admin",
+			zap.String("application", app.ApplicationID),
+			zap.String("queueName", queueName),
+			zap.String("user", app.GetUser().User))
+		return ""
+	}
+	log.Log(log.SchedApplication).Info("Placing application in override queue",
+		zap.String("application", app.ApplicationID),
+		zap.String("queueName", queueName))
+	return queueName
+}
+
 // buildRules builds a new rule set based on the config.
 // If the rule set is correct and can be used the new set is returned.
 // If any error is encountered a nil array is returned and the error set.
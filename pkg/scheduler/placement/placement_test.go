@@ -27,7 +27,10 @@ import (
 	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/configs"
 	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/metrics"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/scheduler/policies"
 	siCommon "github.com/apache/yunikorn-scheduler-interface/lib/go/common"
 )
 
@@ -334,6 +337,234 @@ partitions:
 	}
 }
 
+func TestManagerPlaceAppOverride(t *testing.T) {
+	// Create the structure for the test
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+          - name: secure
+            adminacl: "admin-user"
+            queues:
+              - name: batch
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+	rules := []configs.PlacementRule{
+		{Name: "fixed",
+			Value: "root.default"},
+	}
+	man := NewPlacementManager(rules, queueFunc, false)
+	if man == nil {
+		t.Fatal("placement manager create failed")
+	}
+	admin := security.UserGroup{
+		User:   "admin-user",
+		Groups: []string{},
+	}
+	other := security.UserGroup{
+		User:   "other-user",
+		Groups: []string{},
+	}
+
+	var tests = []struct {
+		name          string
+		user          security.UserGroup
+		override      string
+		expectedQueue string
+	}{
+		{"admin override honoured", admin, "root.secure.batch", "root.secure.batch"},
+		{"admin override not qualified", admin, "secure.batch", "root.secure.batch"},
+		{"non admin override ignored", other, "root.secure.batch", "root.default"},
+		{"admin override parent ignored", admin, "root.secure", "root.default"},
+		{"admin override non existing queue ignored", admin, "root.secure.unknown", "root.default"},
+		{"no override", admin, "", "root.default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := map[string]string{common.AppTagQueueOverride: tt.override}
+			app := newApplication("app1", "default", "", tt.user, tags, nil, "")
+			err = man.PlaceApplication(app)
+			assert.NilError(t, err, "placement should not have failed")
+			assert.Equal(t, app.GetQueuePath(), tt.expectedQueue, "app placed in unexpected queue")
+		})
+	}
+}
+
+func TestManagerPlaceAppMetrics(t *testing.T) {
+	// Create the structure for the test
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+          - name: testparent
+            queues:
+              - name: testchild
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+	rules := []configs.PlacementRule{
+		{Name: "user",
+			Create: false,
+			Parent: &configs.PlacementRule{
+				Name:  "fixed",
+				Value: "testparent"},
+		},
+		{Name: "provided",
+			Create: false},
+	}
+	man := NewPlacementManager(rules, queueFunc, false)
+	sm := metrics.GetSchedulerMetrics()
+	userStart, err := sm.GetPlacementRuleMatch(types.User)
+	assert.NilError(t, err)
+	providedStart, err := sm.GetPlacementRuleMatch(types.Provided)
+	assert.NilError(t, err)
+	fallStart, err := sm.GetPlacementFallThrough(metrics.PlacementDefault)
+	assert.NilError(t, err)
+	rejectStart, err := sm.GetPlacementFallThrough(metrics.PlacementRejected)
+	assert.NilError(t, err)
+
+	assertCounts := func(user, provided, fallThrough, rejected int) {
+		t.Helper()
+		count, err := sm.GetPlacementRuleMatch(types.User)
+		assert.NilError(t, err)
+		assert.Equal(t, count-userStart, user, "unexpected user rule matches")
+		count, err = sm.GetPlacementRuleMatch(types.Provided)
+		assert.NilError(t, err)
+		assert.Equal(t, count-providedStart, provided, "unexpected provided rule matches")
+		count, err = sm.GetPlacementFallThrough(metrics.PlacementDefault)
+		assert.NilError(t, err)
+		assert.Equal(t, count-fallStart, fallThrough, "unexpected fall-throughs")
+		count, err = sm.GetPlacementFallThrough(metrics.PlacementRejected)
+		assert.NilError(t, err)
+		assert.Equal(t, count-rejectStart, rejected, "unexpected rejections")
+	}
+	tags := make(map[string]string)
+
+	// first rule places the app
+	app := newApplication("app1", "default", "", security.UserGroup{User: "testchild"}, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err)
+	assert.Equal(t, app.GetQueuePath(), "root.testparent.testchild")
+	assertCounts(1, 0, 0, 0)
+
+	// first rule does not match, second rule places the app
+	app = newApplication("app1", "default", "root.testparent.testchild", security.UserGroup{User: "other"}, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err)
+	assertCounts(1, 1, 0, 0)
+
+	// no rule matches: app ends up in the default queue
+	app = newApplication("app1", "default", "", security.UserGroup{User: "other"}, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err)
+	assert.Equal(t, app.GetQueuePath(), common.DefaultPlacementQueue)
+	assertCounts(1, 1, 1, 0)
+
+	// recovery rule not used for a non forced app: rejected
+	man = NewPlacementManager(rules, func(name string) *objects.Queue {
+		if name == common.DefaultPlacementQueue {
+			return nil
+		}
+		return queueFunc(name)
+	}, false)
+	app = newApplication("app1", "default", "", security.UserGroup{User: "other"}, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, errors.Is(err, RejectedError), "app should have been rejected")
+	assertCounts(1, 1, 1, 1)
+}
+
+func TestManagerPlaceAppParentQueue(t *testing.T) {
+	const conf = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: testchild
+          - name: testparent
+            parent: true
+          - name: other
+            queues:
+              - name: testchild
+`
+	err := initQueueStructure([]byte(conf))
+	assert.NilError(t, err, "setting up the queue config failed")
+	man := NewPlacementManager(nil, queueFunc, false)
+	user := security.UserGroup{User: "testuser"}
+	tags := make(map[string]string)
+
+	// reject mode: a parent queue is rejected with the reason
+	app := newApplication("app1", "default", "root.testparent", user, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, errors.Is(err, RejectedError), "app in parent queue should have been rejected")
+	assert.ErrorContains(t, err, "queue root.testparent is a parent queue")
+	assert.Equal(t, app.GetQueuePath(), "", "rejected app should not have a queue")
+
+	// promote mode: a parent queue without child queues is accepted
+	man.SetParentPlacementPolicy(policies.PromoteParentPlacementPolicy)
+	app = newApplication("app1", "default", "root.testparent", user, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.NilError(t, err, "app in parent queue without children should have been placed")
+	assert.Equal(t, app.GetQueuePath(), "root.testparent", "app placed in wrong queue")
+
+	// promote mode: a parent queue with child queues is still rejected
+	app = newApplication("app1", "default", "root.other", user, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, errors.Is(err, RejectedError), "app in parent queue with children should have been rejected")
+	assert.ErrorContains(t, err, "queue root.other is a parent queue")
+
+	// back to reject mode
+	man.SetParentPlacementPolicy(policies.RejectParentPlacementPolicy)
+	app = newApplication("app1", "default", "root.testparent", user, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, errors.Is(err, RejectedError), "app in parent queue should have been rejected")
+}
+
+func TestManagerPlaceAppSuggestQueues(t *testing.T) {
+	const conf = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: alpha
+            submitacl: "testuser"
+          - name: beta
+            submitacl: "testuser"
+          - name: denied
+          - name: gamma
+            submitacl: "testuser"
+          - name: zeta
+            submitacl: "testuser"
+`
+	err := initQueueStructure([]byte(conf))
+	assert.NilError(t, err, "setting up the queue config failed")
+	man := NewPlacementManager(nil, queueFunc, false)
+	tags := make(map[string]string)
+
+	// acl denied: the rejection suggests the first queues the user can submit to
+	app := newApplication("app1", "default", "root.denied", security.UserGroup{User: "testuser"}, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, errors.Is(err, RejectedError), "app in denied queue should have been rejected")
+	assert.ErrorContains(t, err, "suggested queues: root.alpha,root.beta,root.gamma")
+
+	// user without access to any queue: no suggestions
+	app = newApplication("app1", "default", "root.denied", security.UserGroup{User: "other"}, tags, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, errors.Is(err, RejectedError), "app in denied queue should have been rejected")
+	assert.Equal(t, err.Error(), RejectedError.Error(), "rejection should not have suggestions")
+}
+
 //nolint:funlen
 func TestForcePlaceApp(t *testing.T) {
 	const (
@@ -0,0 +1,131 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+// A rule to select an existing parent queue based on the tags set on the queue in the configuration.
+// The first parent queue that carries all the configured tags with the same value is returned. The queue hierarchy is
+// searched level by level with the queues on each level checked in name order. The root queue is never selected.
+// The rule is meant to be used as the parent rule of another rule. It never creates queues and has no parent rule.
+type queueTagRule struct {
+	basicRule
+	tags map[string]string
+}
+
+func (qr *queueTagRule) getName() string {
+	return types.QueueTag
+}
+
+func (qr *queueTagRule) ruleDAO() *dao.RuleDAO {
+	return &dao.RuleDAO{
+		Name: qr.getName(),
+		Parameters: map[string]string{
+			"tags": qr.tagString(),
+		},
+		Filter: qr.filter.filterDAO(),
+	}
+}
+
+// tagString returns the tags in the form "key=value" sorted on the key.
+func (qr *queueTagRule) tagString() string {
+	entries := make([]string, 0, len(qr.tags))
+	for key, value := range qr.tags {
+		entries = append(entries, key+"="+value)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func (qr *queueTagRule) initialise(conf configs.PlacementRule) error {
+	if len(conf.QueueTags) == 0 {
+		return fmt.Errorf("a queuetag rule must have queue tags set")
+	}
+	if conf.Parent != nil {
+		return fmt.Errorf("a queuetag rule cannot have a parent rule")
+	}
+	qr.tags = make(map[string]string, len(conf.QueueTags))
+	for key, value := range conf.QueueTags {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("a queuetag rule cannot have an empty tag key")
+		}
+		qr.tags[key] = value
+	}
+	qr.filter = newFilter(conf.Filter)
+	return nil
+}
+
+func (qr *queueTagRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	// before anything run the filter
+	if !qr.filter.allowUser(app.GetUser()) {
+		log.Log(log.SchedApplication).Debug("Queue tag rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()),
+			zap.String("tags", qr.tagString()))
+		return "", nil
+	}
+	root := queueFn(configs.RootQueue)
+	if root == nil {
+		return "", nil
+	}
+	candidates := sortedChildren(root)
+	for len(candidates) > 0 {
+		var next []*objects.Queue
+		for _, queue := range candidates {
+			if queue.IsLeafQueue() {
+				continue
+			}
+			if queue.HasTags(qr.tags) {
+				log.Log(log.SchedApplication).Info("Queue tag rule application placed",
+					zap.String("application", app.ApplicationID),
+					zap.String("tags", qr.tagString()),
+					zap.String("queue", queue.GetQueuePath()))
+				return queue.GetQueuePath(), nil
+			}
+			next = append(next, sortedChildren(queue)...)
+		}
+		candidates = next
+	}
+	return "", nil
+}
+
+// sortedChildren returns the child queues of the queue sorted on the queue name.
+func sortedChildren(queue *objects.Queue) []*objects.Queue {
+	children := queue.GetCopyOfChildren()
+	sorted := make([]*objects.Queue, 0, len(children))
+	for _, child := range children {
+		sorted = append(sorted, child)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
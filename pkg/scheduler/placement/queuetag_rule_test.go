@@ -0,0 +1,149 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+const confQueueTags = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: analytics
+            parent: true
+            tags:
+              team: data
+              tier: gold
+          - name: batch
+            parent: true
+            tags:
+              team: data
+            queues:
+              - name: nested
+                parent: true
+                tags:
+                  team: ml
+          - name: leaf
+            tags:
+              team: ml
+`
+
+func TestQueueTagRule(t *testing.T) {
+	var tests = []struct {
+		name  string
+		conf  configs.PlacementRule
+		valid bool
+	}{
+		{"no tags", configs.PlacementRule{Name: "queuetag"}, false},
+		{"empty tag key", configs.PlacementRule{Name: "queuetag", QueueTags: map[string]string{" ": "data"}}, false},
+		{"parent rule", configs.PlacementRule{Name: "queuetag", QueueTags: map[string]string{"team": "data"}, Parent: &configs.PlacementRule{Name: "user"}}, false},
+		{"single tag", configs.PlacementRule{Name: "queuetag", QueueTags: map[string]string{"team": "data"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr, err := newRule(tt.conf)
+			if tt.valid {
+				assert.NilError(t, err, "queuetag rule create failed")
+				assert.Assert(t, qr != nil, "queuetag rule create returned nil rule")
+			} else {
+				assert.Assert(t, err != nil, "queuetag rule create should have failed")
+				assert.Assert(t, qr == nil, "queuetag rule create should not return a rule")
+			}
+		})
+	}
+}
+
+func TestQueueTagRulePlace(t *testing.T) {
+	err := initQueueStructure([]byte(confQueueTags))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	app := newApplication("app1", "default", "ignored", user, map[string]string{}, nil, "")
+
+	var tests = []struct {
+		name          string
+		tags          map[string]string
+		expectedQueue string
+	}{
+		{"first parent in name order", map[string]string{"team": "data"}, "root.analytics"},
+		{"all tags must match", map[string]string{"team": "data", "tier": "gold"}, "root.analytics"},
+		{"nested parent, leaf skipped", map[string]string{"team": "ml"}, "root.batch.nested"},
+		{"no matching parent", map[string]string{"team": "web"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var qr rule
+			qr, err = newRule(configs.PlacementRule{Name: "queuetag", QueueTags: tt.tags})
+			assert.NilError(t, err, "queuetag rule create failed")
+			var queue string
+			queue, err = qr.placeApplication(app, queueFunc)
+			assert.NilError(t, err, "queuetag rule place failed")
+			assert.Equal(t, queue, tt.expectedQueue, "unexpected queue selected")
+		})
+	}
+
+	// a user rule that selects its parent based on the queue tags
+	conf := configs.PlacementRule{
+		Name:   "user",
+		Create: true,
+		Parent: &configs.PlacementRule{
+			Name:      "queuetag",
+			QueueTags: map[string]string{"team": "ml"},
+		},
+	}
+	var ur rule
+	ur, err = newRule(conf)
+	assert.NilError(t, err, "user rule create failed")
+	var queue string
+	queue, err = ur.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "user rule place failed")
+	assert.Equal(t, queue, "root.batch.nested.testuser", "user rule with queuetag parent placed incorrectly")
+
+	// a filtered rule does not select a parent
+	conf = configs.PlacementRule{
+		Name:      "queuetag",
+		QueueTags: map[string]string{"team": "data"},
+		Filter:    configs.Filter{Type: filterDeny, Users: []string{"testuser"}},
+	}
+	var qr rule
+	qr, err = newRule(conf)
+	assert.NilError(t, err, "queuetag rule create failed")
+	queue, err = qr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "queuetag rule place failed")
+	assert.Equal(t, queue, "", "filtered rule should not select a parent")
+}
+
+func Test_queueTagRule_ruleDAO(t *testing.T) {
+	qr, err := newRule(configs.PlacementRule{Name: "queuetag", QueueTags: map[string]string{"tier": "gold", "team": "data"}})
+	assert.NilError(t, err, "setting up the rule failed")
+	want := &dao.RuleDAO{Name: "queuetag", Parameters: map[string]string{"tags": "team=data,tier=gold"}}
+	assert.DeepEqual(t, want, qr.ruleDAO())
+}
@@ -0,0 +1,169 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+// A rule to place an application based on the ID of the resource manager that submitted the application.
+// The value of the rule is a comma separated list of mappings in the form "rmID=queue".
+// An application from a resource manager that is not part of the mapping is not placed by this rule.
+// If the mapped queue is fully qualified, starts with "root.", the parent rule is skipped. If the queue is not
+// qualified the parent rule is run before making the queue name fully qualified.
+// NOTE: resource manager IDs are case sensitive, queue names are normalised to lower case.
+type rmIDRule struct {
+	basicRule
+	mapping map[string]string
+}
+
+func (rr *rmIDRule) getName() string {
+	return types.RMID
+}
+
+func (rr *rmIDRule) ruleDAO() *dao.RuleDAO {
+	var pDAO *dao.RuleDAO
+	if rr.parent != nil {
+		pDAO = rr.parent.ruleDAO()
+	}
+	return &dao.RuleDAO{
+		Name: rr.getName(),
+		Parameters: map[string]string{
+			"mapping": rr.mappingString(),
+			"create":  strconv.FormatBool(rr.create),
+		},
+		ParentRule: pDAO,
+		Filter:     rr.filter.filterDAO(),
+	}
+}
+
+// mappingString returns the mapping in its config form sorted on the resource manager ID.
+func (rr *rmIDRule) mappingString() string {
+	entries := make([]string, 0, len(rr.mapping))
+	for rmID, queue := range rr.mapping {
+		entries = append(entries, rmID+"="+queue)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func (rr *rmIDRule) initialise(conf configs.PlacementRule) error {
+	if strings.TrimSpace(conf.Value) == "" {
+		return fmt.Errorf("a rmid rule must have a mapping set")
+	}
+	rr.mapping = make(map[string]string)
+	for _, entry := range strings.Split(conf.Value, ",") {
+		rmID, queue, found := strings.Cut(entry, "=")
+		rmID = strings.TrimSpace(rmID)
+		queue = normalise(strings.TrimSpace(queue))
+		if !found || rmID == "" || queue == "" {
+			return fmt.Errorf("invalid rmid rule mapping entry '%s', expected 'rmID=queue'", entry)
+		}
+		if _, ok := rr.mapping[rmID]; ok {
+			return fmt.Errorf("duplicate resource manager ID '%s' in rmid rule mapping", rmID)
+		}
+		for _, part := range strings.Split(queue, configs.DOT) {
+			if err := configs.IsQueueNameValid(part); err != nil {
+				return fmt.Errorf("invalid queue name '%s' mapped for resource manager ID '%s': %w", queue, rmID, err)
+			}
+		}
+		rr.mapping[rmID] = queue
+	}
+	rr.create = conf.Create
+	rr.filter = newFilter(conf.Filter)
+	var err = error(nil)
+	if conf.Parent != nil {
+		rr.parent, err = newRule(*conf.Parent)
+	}
+	return err
+}
+
+func (rr *rmIDRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	// if the resource manager is not mapped we can skip all other processing
+	rmID := app.GetRMID()
+	queueName, ok := rr.mapping[rmID]
+	if !ok {
+		return "", nil
+	}
+	// before anything run the filter
+	if !rr.filter.allowUser(app.GetUser()) {
+		log.Log(log.SchedApplication).Debug("RMID rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()),
+			zap.String("rmID", rmID))
+		return "", nil
+	}
+	// not fully qualified queue, run the parent rule if set
+	if !strings.HasPrefix(queueName, configs.RootQueue+configs.DOT) {
+		var parentName string
+		var err error
+		if rr.parent != nil {
+			parentName, err = rr.parent.placeApplication(app, queueFn)
+			// failed parent rule, fail this rule
+			if err != nil {
+				return "", err
+			}
+			// rule did not return a parent: this could be filter or create flag related
+			if parentName == "" {
+				return "", nil
+			}
+			// check if this is a parent queue and qualify it
+			if !strings.HasPrefix(parentName, configs.RootQueue+configs.DOT) {
+				parentName = configs.RootQueue + configs.DOT + parentName
+			}
+			// if the parent queue exists it cannot be a leaf
+			parentQueue := queueFn(parentName)
+			if parentQueue != nil && parentQueue.IsLeafQueue() {
+				return "", fmt.Errorf("parent rule returned a leaf queue: %s", parentName)
+			}
+		}
+		// the parent is set from the rule otherwise set it to the root
+		if parentName == "" {
+			parentName = configs.RootQueue
+		}
+		queueName = parentName + configs.DOT + queueName
+	}
+	// Log the result before we check the create flag
+	log.Log(log.SchedApplication).Debug("RMID rule intermediate result",
+		zap.String("application", app.ApplicationID),
+		zap.String("rmID", rmID),
+		zap.String("queue", queueName))
+	// get the queue object
+	queue := queueFn(queueName)
+	// if we cannot create the queue must exist
+	if !rr.create && queue == nil {
+		return "", nil
+	}
+	log.Log(log.SchedApplication).Info("RMID rule application placed",
+		zap.String("application", app.ApplicationID),
+		zap.String("rmID", rmID),
+		zap.String("queue", queueName))
+	return queueName, nil
+}
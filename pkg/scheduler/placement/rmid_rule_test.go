@@ -0,0 +1,176 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+func TestRMIDRule(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"no mapping", "", false},
+		{"single mapping", "rm-1=testqueue", true},
+		{"multiple mappings", "rm-1=testqueue, rm-2=root.testparent.testchild", true},
+		{"missing separator", "rm-1", false},
+		{"missing queue", "rm-1=", false},
+		{"missing rm ID", "=testqueue", false},
+		{"duplicate rm ID", "rm-1=testqueue,rm-1=other", false},
+		{"invalid queue name", "rm-1=test!>queue", false},
+		{"invalid queue name in hierarchy", "rm-1=root.test!>parent.testqueue", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr, err := newRule(configs.PlacementRule{Name: "rmid", Value: tt.value})
+			if tt.valid {
+				assert.NilError(t, err, "rmid rule create failed")
+				assert.Assert(t, rr != nil, "rmid rule create returned nil rule")
+			} else {
+				assert.Assert(t, err != nil, "rmid rule create should have failed")
+				assert.Assert(t, rr == nil, "rmid rule create should not return a rule")
+			}
+		})
+	}
+}
+
+func TestRMIDRulePlace(t *testing.T) {
+	err := initQueueStructure([]byte(confTestQueue))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	tags := make(map[string]string)
+	conf := configs.PlacementRule{
+		Name:  "rmid",
+		Value: "rm-1=testqueue,rm-2=root.testparent.testchild,rm-3=newqueue",
+	}
+	var tests = []struct {
+		name          string
+		rmID          string
+		create        bool
+		expectedQueue string
+	}{
+		{"mapped queue under root", "rm-1", false, "root.testqueue"},
+		{"mapped qualified queue", "rm-2", false, "root.testparent.testchild"},
+		{"mapped non existing queue", "rm-3", false, ""},
+		{"mapped non existing queue with create", "rm-3", true, "root.newqueue"},
+		{"unmapped rm falls through", "rm-unknown", true, ""},
+		{"rm ID is case sensitive", "RM-1", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.Create = tt.create
+			rr, err := newRule(conf)
+			assert.NilError(t, err, "rmid rule create failed")
+			app := newApplication("app1", "default", "ignored", user, tags, nil, tt.rmID)
+			queue, err := rr.placeApplication(app, queueFunc)
+			assert.NilError(t, err, "rmid rule placement failed")
+			assert.Equal(t, tt.expectedQueue, queue, "rmid rule placed in wrong queue")
+		})
+	}
+
+	// deny filter should not place the application
+	conf = configs.PlacementRule{
+		Name:   "rmid",
+		Value:  "rm-1=testqueue",
+		Filter: configs.Filter{Type: filterDeny},
+	}
+	rr, err := newRule(conf)
+	assert.NilError(t, err, "rmid rule create failed")
+	app := newApplication("app1", "default", "ignored", user, tags, nil, "rm-1")
+	queue, err := rr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rmid rule placement failed")
+	assert.Equal(t, "", queue, "rmid rule with deny filter should not place the application")
+}
+
+func TestRMIDRuleParent(t *testing.T) {
+	err := initQueueStructure([]byte(confParentChild))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	tags := make(map[string]string)
+	app := newApplication("app1", "default", "ignored", user, tags, nil, "rm-1")
+
+	// unqualified queue uses the parent
+	conf := configs.PlacementRule{
+		Name:   "rmid",
+		Value:  "rm-1=testchild",
+		Create: true,
+		Parent: &configs.PlacementRule{
+			Name:   "fixed",
+			Value:  "testparentnew",
+			Create: true,
+		},
+	}
+	rr, err := newRule(conf)
+	assert.NilError(t, err, "rmid rule create failed")
+	queue, err := rr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rmid rule placement failed")
+	assert.Equal(t, nameParentChild, queue, "rmid rule with parent placed in wrong queue")
+
+	// parent is a leaf queue
+	conf.Parent = &configs.PlacementRule{
+		Name:  "fixed",
+		Value: "testchild",
+	}
+	rr, err = newRule(conf)
+	assert.NilError(t, err, "rmid rule create failed")
+	queue, err = rr.placeApplication(app, queueFunc)
+	assert.Assert(t, err != nil, "rmid rule with leaf parent should have failed")
+	assert.Equal(t, "", queue, "rmid rule with leaf parent should not place the application")
+
+	// qualified queue skips the parent
+	conf.Value = "rm-1=root.testparent.newchild"
+	rr, err = newRule(conf)
+	assert.NilError(t, err, "rmid rule create failed")
+	queue, err = rr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rmid rule placement failed")
+	assert.Equal(t, "root.testparent.newchild", queue, "rmid rule with qualified queue placed in wrong queue")
+}
+
+func Test_rmIDRule_ruleDAO(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name:   "rmid",
+		Value:  "rm-2=Other, rm-1=root.default",
+		Create: true,
+		Parent: &configs.PlacementRule{Name: "test", Create: true},
+	}
+	rr, err := newRule(conf)
+	assert.NilError(t, err, "setting up the rule failed")
+	want := &dao.RuleDAO{
+		Name:       "rmid",
+		Parameters: map[string]string{"mapping": "rm-1=root.default,rm-2=other", "create": "true"},
+		ParentRule: &dao.RuleDAO{Name: "test", Parameters: map[string]string{"create": "true"}},
+	}
+	assert.DeepEqual(t, want, rr.ruleDAO())
+}
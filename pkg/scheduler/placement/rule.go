@@ -114,6 +114,21 @@ func newRule(conf configs.PlacementRule) (rule, error) {
 	// rule that uses a tag from the application (like namespace)
 	case types.Tag:
 		r = &tagRule{}
+	// rule that maps the resource manager the application was submitted from to a queue
+	case types.RMID:
+		r = &rmIDRule{}
+	// rule that uses the resources requested by the application to pick a queue
+	case types.Size:
+		r = &sizeRule{}
+	// rule that spreads applications over a set of queues based on a hash of the application ID
+	case types.Hash:
+		r = &hashRule{}
+	// rule that selects an existing parent queue based on the queue tags
+	case types.QueueTag:
+		r = &queueTagRule{}
+	// rule that runs a chain of rules and uses the first that matches
+	case types.Group:
+		r = &groupRule{}
 	// recovery rule must not be specified in the config
 	case types.Recovery:
 		return nil, fmt.Errorf("recovery rule cannot be part of the config, failing placement rule config")
@@ -124,14 +139,26 @@ func newRule(conf configs.PlacementRule) (rule, error) {
 		return nil, fmt.Errorf("unknown rule name specified %s, failing placement rule config", conf.Name)
 	}
 
+	// a static queue prefix or suffix wraps the rule: the create flag is checked by the wrapper
+	initConf := conf
+	if conf.QueuePrefix != "" || conf.QueueSuffix != "" {
+		for _, affix := range []string{conf.QueuePrefix, conf.QueueSuffix} {
+			if affix != "" {
+				if err = configs.IsQueueNameValid(affix); err != nil {
+					return nil, fmt.Errorf("invalid queue prefix or suffix %s for rule %s: %w", affix, conf.Name, err)
+				}
+			}
+		}
+		initConf.Create = true
+	}
 	// initialise the rule: do not expect the rule to log errors
-	err = r.initialise(conf)
+	err = r.initialise(initConf)
 	if err != nil {
 		log.Log(log.Config).Error("Rule init failed", zap.Error(err))
 		return nil, err
 	}
 	log.Log(log.Config).Debug("New rule created", zap.Any("ruleConf", conf))
-	return r, nil
+	return newAffixRule(conf, r), nil
 }
 
 // Normalise the rule name from the config.
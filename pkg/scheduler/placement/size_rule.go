@@ -0,0 +1,179 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/log"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/scheduler/placement/types"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+// A rule to place an application based on the resources it requests.
+// The requested resource is the placeholder resource set when the application was submitted, if that is not set the
+// pending resource of the application is used. An application that requests more than the threshold for at least one
+// of the resource types in the threshold is placed in the above queue, any other application in the below queue.
+// An application without a requested resource, or for which the matching queue is not set, is not placed by this rule.
+// If the queue is fully qualified, starts with "root.", the parent rule is skipped. If the queue is not qualified the
+// parent rule is run before making the queue name fully qualified.
+type sizeRule struct {
+	basicRule
+	threshold  *resources.Resource
+	aboveQueue string
+	belowQueue string
+}
+
+func (sr *sizeRule) getName() string {
+	return types.Size
+}
+
+func (sr *sizeRule) ruleDAO() *dao.RuleDAO {
+	var pDAO *dao.RuleDAO
+	if sr.parent != nil {
+		pDAO = sr.parent.ruleDAO()
+	}
+	return &dao.RuleDAO{
+		Name: sr.getName(),
+		Parameters: map[string]string{
+			"threshold":  sr.threshold.String(),
+			"aboveQueue": sr.aboveQueue,
+			"belowQueue": sr.belowQueue,
+			"create":     strconv.FormatBool(sr.create),
+		},
+		ParentRule: pDAO,
+		Filter:     sr.filter.filterDAO(),
+	}
+}
+
+func (sr *sizeRule) initialise(conf configs.PlacementRule) error {
+	threshold, err := resources.NewResourceFromConf(conf.Threshold)
+	if err != nil {
+		return fmt.Errorf("invalid size rule threshold: %w", err)
+	}
+	if !resources.StrictlyGreaterThanZero(threshold) {
+		return fmt.Errorf("a size rule must have a threshold larger than zero set")
+	}
+	sr.threshold = threshold
+	sr.aboveQueue = normalise(strings.TrimSpace(conf.AboveQueue))
+	sr.belowQueue = normalise(strings.TrimSpace(conf.BelowQueue))
+	if sr.aboveQueue == "" && sr.belowQueue == "" {
+		return fmt.Errorf("a size rule must have an above or below queue set")
+	}
+	for _, queue := range []string{sr.aboveQueue, sr.belowQueue} {
+		if queue == "" {
+			continue
+		}
+		for _, part := range strings.Split(queue, configs.DOT) {
+			if err = configs.IsQueueNameValid(part); err != nil {
+				return fmt.Errorf("invalid queue name '%s' in size rule: %w", queue, err)
+			}
+		}
+	}
+	sr.create = conf.Create
+	sr.filter = newFilter(conf.Filter)
+	if conf.Parent != nil {
+		sr.parent, err = newRule(*conf.Parent)
+	}
+	return err
+}
+
+// requestedResource returns the resource requested by the application used to compare with the threshold.
+func requestedResource(app *objects.Application) *resources.Resource {
+	if requested := app.GetPlaceholderAsk(); !resources.IsZero(requested) {
+		return requested
+	}
+	return app.GetPendingResource()
+}
+
+func (sr *sizeRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	// without a request there is nothing to compare: skip all other processing
+	requested := requestedResource(app)
+	if resources.IsZero(requested) {
+		return "", nil
+	}
+	queueName := sr.belowQueue
+	if !sr.threshold.FitInMaxUndef(requested) {
+		queueName = sr.aboveQueue
+	}
+	if queueName == "" {
+		return "", nil
+	}
+	// before anything run the filter
+	if !sr.filter.allowUser(app.GetUser()) {
+		log.Log(log.SchedApplication).Debug("Size rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()),
+			zap.Stringer("requested", requested))
+		return "", nil
+	}
+	// not fully qualified queue, run the parent rule if set
+	if !strings.HasPrefix(queueName, configs.RootQueue+configs.DOT) {
+		var parentName string
+		var err error
+		if sr.parent != nil {
+			parentName, err = sr.parent.placeApplication(app, queueFn)
+			// failed parent rule, fail this rule
+			if err != nil {
+				return "", err
+			}
+			// rule did not return a parent: this could be filter or create flag related
+			if parentName == "" {
+				return "", nil
+			}
+			// check if this is a parent queue and qualify it
+			if !strings.HasPrefix(parentName, configs.RootQueue+configs.DOT) {
+				parentName = configs.RootQueue + configs.DOT + parentName
+			}
+			// if the parent queue exists it cannot be a leaf
+			parentQueue := queueFn(parentName)
+			if parentQueue != nil && parentQueue.IsLeafQueue() {
+				return "", fmt.Errorf("parent rule returned a leaf queue: %s", parentName)
+			}
+		}
+		// the parent is set from the rule otherwise set it to the root
+		if parentName == "" {
+			parentName = configs.RootQueue
+		}
+		queueName = parentName + configs.DOT + queueName
+	}
+	// Log the result before we check the create flag
+	log.Log(log.SchedApplication).Debug("Size rule intermediate result",
+		zap.String("application", app.ApplicationID),
+		zap.Stringer("requested", requested),
+		zap.String("queue", queueName))
+	// get the queue object
+	queue := queueFn(queueName)
+	// if we cannot create the queue must exist
+	if !sr.create && queue == nil {
+		return "", nil
+	}
+	log.Log(log.SchedApplication).Info("Size rule application placed",
+		zap.String("application", app.ApplicationID),
+		zap.Stringer("requested", requested),
+		zap.String("queue", queueName))
+	return queueName, nil
+}
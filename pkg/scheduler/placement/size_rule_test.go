@@ -0,0 +1,188 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"github.com/apache/yunikorn-core/pkg/common/security"
+	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
+)
+
+// newSizedApplication creates an application with the requested resource set as the placeholder ask.
+func newSizedApplication(requested map[string]resources.Quantity) *objects.Application {
+	siApp := &si.AddApplicationRequest{
+		ApplicationID: "app1",
+		QueueName:     "ignored",
+		PartitionName: "default",
+	}
+	if requested != nil {
+		siApp.PlaceholderAsk = resources.NewResourceFromMap(requested).ToProto()
+	}
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	return objects.NewApplication(siApp, user, nil, "")
+}
+
+func TestSizeRule(t *testing.T) {
+	var tests = []struct {
+		name  string
+		conf  configs.PlacementRule
+		valid bool
+	}{
+		{"no threshold", configs.PlacementRule{Name: "size", AboveQueue: "big"}, false},
+		{"zero threshold", configs.PlacementRule{Name: "size", Threshold: map[string]string{"vcore": "0"}, AboveQueue: "big"}, false},
+		{"invalid threshold", configs.PlacementRule{Name: "size", Threshold: map[string]string{"vcore": "x"}, AboveQueue: "big"}, false},
+		{"no queues", configs.PlacementRule{Name: "size", Threshold: map[string]string{"vcore": "10"}}, false},
+		{"invalid queue name", configs.PlacementRule{Name: "size", Threshold: map[string]string{"vcore": "10"}, AboveQueue: "big!>queue"}, false},
+		{"above queue only", configs.PlacementRule{Name: "size", Threshold: map[string]string{"vcore": "10"}, AboveQueue: "big"}, true},
+		{"both queues", configs.PlacementRule{Name: "size", Threshold: map[string]string{"vcore": "10"}, AboveQueue: "big", BelowQueue: "root.small"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, err := newRule(tt.conf)
+			if tt.valid {
+				assert.NilError(t, err, "size rule create failed")
+				assert.Assert(t, sr != nil, "size rule create returned nil rule")
+			} else {
+				assert.Assert(t, err != nil, "size rule create should have failed")
+				assert.Assert(t, sr == nil, "size rule create should not return a rule")
+			}
+		})
+	}
+}
+
+func TestSizeRulePlace(t *testing.T) {
+	err := initQueueStructure([]byte(confTestQueue))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	conf := configs.PlacementRule{
+		Name:       "size",
+		Threshold:  map[string]string{"vcore": "10", "memory": "100"},
+		AboveQueue: "testqueue",
+		BelowQueue: "root.testparent.testchild",
+	}
+	var tests = []struct {
+		name          string
+		requested     map[string]resources.Quantity
+		expectedQueue string
+	}{
+		{"above threshold", map[string]resources.Quantity{"vcore": 20000, "memory": 50}, "root.testqueue"},
+		{"below threshold", map[string]resources.Quantity{"vcore": 5000, "memory": 50}, "root.testparent.testchild"},
+		{"equal to threshold", map[string]resources.Quantity{"vcore": 10000, "memory": 100}, "root.testparent.testchild"},
+		{"type not in threshold", map[string]resources.Quantity{"gpu": 8}, "root.testparent.testchild"},
+		{"missing request", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, err := newRule(conf)
+			assert.NilError(t, err, "size rule create failed")
+			queue, err := sr.placeApplication(newSizedApplication(tt.requested), queueFunc)
+			assert.NilError(t, err, "size rule placement failed")
+			assert.Equal(t, tt.expectedQueue, queue, "size rule placed in wrong queue")
+		})
+	}
+
+	// no below queue: small applications fall through
+	conf.BelowQueue = ""
+	sr, err := newRule(conf)
+	assert.NilError(t, err, "size rule create failed")
+	queue, err := sr.placeApplication(newSizedApplication(map[string]resources.Quantity{"vcore": 5000}), queueFunc)
+	assert.NilError(t, err, "size rule placement failed")
+	assert.Equal(t, "", queue, "size rule without below queue should not place the application")
+
+	// non existing queue without create falls through, with create is placed
+	conf.AboveQueue = "bigjobs"
+	sr, err = newRule(conf)
+	assert.NilError(t, err, "size rule create failed")
+	app := newSizedApplication(map[string]resources.Quantity{"vcore": 50000})
+	queue, err = sr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "size rule placement failed")
+	assert.Equal(t, "", queue, "size rule should not place in non existing queue")
+	conf.Create = true
+	sr, err = newRule(conf)
+	assert.NilError(t, err, "size rule create failed")
+	queue, err = sr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "size rule placement failed")
+	assert.Equal(t, "root.bigjobs", queue, "size rule with create placed in wrong queue")
+
+	// deny filter should not place the application
+	conf.Filter = configs.Filter{Type: filterDeny}
+	sr, err = newRule(conf)
+	assert.NilError(t, err, "size rule create failed")
+	queue, err = sr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "size rule placement failed")
+	assert.Equal(t, "", queue, "size rule with deny filter should not place the application")
+}
+
+func TestSizeRuleParent(t *testing.T) {
+	err := initQueueStructure([]byte(confParentChild))
+	assert.NilError(t, err, "setting up the queue config failed")
+	app := newSizedApplication(map[string]resources.Quantity{"vcore": 50000})
+
+	// unqualified queue uses the parent
+	conf := configs.PlacementRule{
+		Name:       "size",
+		Threshold:  map[string]string{"vcore": "10"},
+		AboveQueue: "testchild",
+		Create:     true,
+		Parent: &configs.PlacementRule{
+			Name:   "fixed",
+			Value:  "testparentnew",
+			Create: true,
+		},
+	}
+	sr, err := newRule(conf)
+	assert.NilError(t, err, "size rule create failed")
+	queue, err := sr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "size rule placement failed")
+	assert.Equal(t, nameParentChild, queue, "size rule with parent placed in wrong queue")
+
+	// parent is a leaf queue
+	conf.Parent = &configs.PlacementRule{
+		Name:  "fixed",
+		Value: "testchild",
+	}
+	sr, err = newRule(conf)
+	assert.NilError(t, err, "size rule create failed")
+	queue, err = sr.placeApplication(app, queueFunc)
+	assert.Assert(t, err != nil, "size rule with leaf parent should have failed")
+	assert.Equal(t, "", queue, "size rule with leaf parent should not place the application")
+}
+
+func Test_sizeRule_ruleDAO(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name:       "size",
+		Threshold:  map[string]string{"vcore": "10"},
+		AboveQueue: "big",
+		BelowQueue: "small",
+	}
+	sr, err := newRule(conf)
+	assert.NilError(t, err, "setting up the rule failed")
+	want := &dao.RuleDAO{Name: "size", Parameters: map[string]string{"threshold": "map[vcore:10000]", "aboveQueue": "big", "belowQueue": "small", "create": "false"}}
+	assert.DeepEqual(t, want, sr.ruleDAO())
+}
@@ -35,10 +35,13 @@ import (
 // A rule to place an application based on the a tag on the application.
 // The tag will be part of the application that is submitted. An application can have 0 or more tags.
 // If the tag is present the value will be used as the queue name.
+// A configured prefix or suffix is removed from a not fully qualified value before it is used as the queue name.
 // NOTE: tags are normalised and only use lower case (not case sensitive)
 type tagRule struct {
 	basicRule
-	tagName string
+	tagName  string
+	prefixes []string
+	suffixes []string
 }
 
 func (tr *tagRule) getName() string {
@@ -50,12 +53,19 @@ func (tr *tagRule) ruleDAO() *dao.RuleDAO {
 	if tr.parent != nil {
 		pDAO = tr.parent.ruleDAO()
 	}
+	params := map[string]string{
+		"tagName": tr.tagName,
+		"create":  strconv.FormatBool(tr.create),
+	}
+	if len(tr.prefixes) > 0 {
+		params["stripPrefixes"] = strings.Join(tr.prefixes, ",")
+	}
+	if len(tr.suffixes) > 0 {
+		params["stripSuffixes"] = strings.Join(tr.suffixes, ",")
+	}
 	return &dao.RuleDAO{
-		Name: tr.getName(),
-		Parameters: map[string]string{
-			"tagName": tr.tagName,
-			"create":  strconv.FormatBool(tr.create),
-		},
+		Name:       tr.getName(),
+		Parameters: params,
 		ParentRule: pDAO,
 		Filter:     tr.filter.filterDAO(),
 	}
@@ -66,6 +76,8 @@ func (tr *tagRule) initialise(conf configs.PlacementRule) error {
 	if tr.tagName == "" {
 		return fmt.Errorf("a tag queue rule must have a tag name set")
 	}
+	tr.prefixes = nonEmpty(conf.StripPrefixes)
+	tr.suffixes = nonEmpty(conf.StripSuffixes)
 	tr.create = conf.Create
 	tr.filter = newFilter(conf.Filter)
 	var err = error(nil)
@@ -102,7 +114,7 @@ func (tr *tagRule) placeApplication(app *objects.Application, queueFn func(strin
 		}
 	} else {
 		// not fully qualified queue
-		childQueueName := replaceDot(tagVal)
+		childQueueName := replaceDot(tr.strip(tagVal))
 		if err = configs.IsQueueNameValid(childQueueName); err != nil {
 			return "", err
 		}
@@ -148,3 +160,32 @@ func (tr *tagRule) placeApplication(app *objects.Application, queueFn func(strin
 		zap.String("queue", queueName))
 	return queueName, nil
 }
+
+// strip removes the first matching prefix and the first matching suffix from the value.
+// A prefix or suffix that would leave an empty value is not removed.
+func (tr *tagRule) strip(value string) string {
+	for _, prefix := range tr.prefixes {
+		if stripped, ok := strings.CutPrefix(value, prefix); ok && stripped != "" {
+			value = stripped
+			break
+		}
+	}
+	for _, suffix := range tr.suffixes {
+		if stripped, ok := strings.CutSuffix(value, suffix); ok && stripped != "" {
+			value = stripped
+			break
+		}
+	}
+	return value
+}
+
+// nonEmpty returns the values that are not empty.
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
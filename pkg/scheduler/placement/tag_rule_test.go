@@ -335,6 +335,59 @@ func TestTagRuleParent(t *testing.T) {
 	}
 }
 
+func TestTagRuleStrip(t *testing.T) {
+	err := initQueueStructure([]byte(confTestQueue))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	conf := configs.PlacementRule{
+		Name:          "tag",
+		Value:         "namespace",
+		Create:        true,
+		StripPrefixes: []string{"ci-", "prod-", ""},
+		StripSuffixes: []string{"-ns"},
+	}
+	tr, err := newRule(conf)
+	assert.NilError(t, err, "tag rule create failed")
+
+	var tests = []struct {
+		name          string
+		value         string
+		expectedQueue string
+	}{
+		{"first prefix", "ci-testqueue", "root.testqueue"},
+		{"second prefix", "prod-testqueue", "root.testqueue"},
+		{"prefix and suffix", "ci-team-ns", "root.team"},
+		{"prefix absent", "testqueue", "root.testqueue"},
+		{"prefix not at the start", "team-ci-x", "root.team-ci-x"},
+		{"prefix only", "ci-", "root.ci-"},
+		{"qualified value not stripped", "root.ci-testqueue", "root.ci-testqueue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApplication("app1", "default", "ignored", user, map[string]string{"namespace": tt.value}, nil, "")
+			queue, err := tr.placeApplication(app, queueFunc)
+			assert.NilError(t, err, "tag rule placement failed")
+			assert.Equal(t, queue, tt.expectedQueue, "tag rule placed in unexpected queue")
+		})
+	}
+
+	// stripping happens before the validation of the queue name
+	conf.StripPrefixes = []string{"ci!"}
+	tr, err = newRule(conf)
+	assert.NilError(t, err, "tag rule create failed")
+	app := newApplication("app1", "default", "ignored", user, map[string]string{"namespace": "ci!testqueue"}, nil, "")
+	queue, err := tr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "stripped name should be valid")
+	assert.Equal(t, queue, "root.testqueue", "tag rule placed in unexpected queue")
+	app = newApplication("app1", "default", "ignored", user, map[string]string{"namespace": "test!queue"}, nil, "")
+	_, err = tr.placeApplication(app, queueFunc)
+	assert.Assert(t, err != nil, "tag rule should have failed on an invalid name")
+}
+
 func Test_tagRule_ruleDAO(t *testing.T) {
 	tests := []struct {
 		name string
@@ -361,6 +414,11 @@ func Test_tagRule_ruleDAO(t *testing.T) {
 			configs.PlacementRule{Name: "tag", Value: "two", Create: true, Filter: configs.Filter{Type: filterDeny, Groups: []string{"group[0-9]"}}},
 			&dao.RuleDAO{Name: "tag", Parameters: map[string]string{"tagName": "two", "create": "true"}, Filter: &dao.FilterDAO{Type: filterDeny, GroupExp: "group[0-9]"}},
 		},
+		{
+			"strip",
+			configs.PlacementRule{Name: "tag", Value: "namespace", StripPrefixes: []string{"ci-", "prod-"}, StripSuffixes: []string{"-ns"}},
+			&dao.RuleDAO{Name: "tag", Parameters: map[string]string{"tagName": "namespace", "create": "false", "stripPrefixes": "ci-,prod-", "stripSuffixes": "-ns"}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -23,6 +23,11 @@ const (
 	User     = "user"
 	Provided = "provided"
 	Tag      = "tag"
+	RMID     = "rmid"
+	Size     = "size"
+	Hash     = "hash"
+	QueueTag = "queuetag"
+	Group    = "group"
 	Test     = "test"
 	Recovery = "recovery"
 )
@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ACLEnforcementPolicy defines what happens when a queue ACL check fails.
+type ACLEnforcementPolicy int
+
+const (
+	EnforceACLPolicy ACLEnforcementPolicy = iota // deny access on a failed ACL check
+	AuditACLPolicy                               // log and allow access on a failed ACL check
+)
+
+func (a ACLEnforcementPolicy) String() string {
+	return [...]string{"enforce", "audit"}[a]
+}
+
+func ACLEnforcementPolicyFromString(str string) (ACLEnforcementPolicy, error) {
+	switch strings.ToLower(str) {
+	case EnforceACLPolicy.String(), "":
+		return EnforceACLPolicy, nil
+	case AuditACLPolicy.String():
+		return AuditACLPolicy, nil
+	default:
+		return EnforceACLPolicy, fmt.Errorf("undefined acl.enforcement: %s", str)
+	}
+}
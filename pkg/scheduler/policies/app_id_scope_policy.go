@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppIDScopePolicy defines the scope in which an application ID must be unique.
+type AppIDScopePolicy int
+
+const (
+	PartitionAppIDScope AppIDScopePolicy = iota // unique within a partition, reuse across partitions allowed
+	GlobalAppIDScope                            // unique across all partitions
+)
+
+func (a AppIDScopePolicy) String() string {
+	return [...]string{"partition", "global"}[a]
+}
+
+func AppIDScopePolicyFromString(str string) (AppIDScopePolicy, error) {
+	switch strings.ToLower(str) {
+	case PartitionAppIDScope.String(), "":
+		return PartitionAppIDScope, nil
+	case GlobalAppIDScope.String():
+		return GlobalAppIDScope, nil
+	default:
+		return PartitionAppIDScope, fmt.Errorf("undefined application ID scope: %s", str)
+	}
+}
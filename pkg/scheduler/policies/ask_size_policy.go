@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AskSizePolicy defines what happens when a single ask is larger than the maximum resource of its queue.
+type AskSizePolicy int
+
+const (
+	RejectAskSizePolicy AskSizePolicy = iota // reject an ask that can never fit in the queue
+	WarnAskSizePolicy                        // log and accept an ask that can never fit in the queue
+)
+
+func (a AskSizePolicy) String() string {
+	return [...]string{"reject", "warn"}[a]
+}
+
+func AskSizePolicyFromString(str string) (AskSizePolicy, error) {
+	switch strings.ToLower(str) {
+	case RejectAskSizePolicy.String(), "":
+		return RejectAskSizePolicy, nil
+	case WarnAskSizePolicy.String():
+		return WarnAskSizePolicy, nil
+	default:
+		return RejectAskSizePolicy, fmt.Errorf("undefined ask.size.enforcement: %s", str)
+	}
+}
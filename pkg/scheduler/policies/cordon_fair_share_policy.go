@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CordonFairSharePolicy defines if the capacity of cordoned nodes is part of the fair share of the queues.
+type CordonFairSharePolicy int
+
+const (
+	KeepCordonFairSharePolicy    CordonFairSharePolicy = iota // the capacity of cordoned nodes is part of the fair share
+	ExcludeCordonFairSharePolicy                              // the capacity of cordoned nodes is removed from the fair share
+)
+
+func (p CordonFairSharePolicy) String() string {
+	return [...]string{"keep", "exclude"}[p]
+}
+
+func CordonFairSharePolicyFromString(str string) (CordonFairSharePolicy, error) {
+	switch strings.ToLower(str) {
+	case KeepCordonFairSharePolicy.String(), "":
+		return KeepCordonFairSharePolicy, nil
+	case ExcludeCordonFairSharePolicy.String():
+		return ExcludeCordonFairSharePolicy, nil
+	default:
+		return KeepCordonFairSharePolicy, fmt.Errorf("undefined cordon fair share policy: %s", str)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupChargePolicy defines which groups of a user are charged against the per group resource limit of a queue.
+type GroupChargePolicy int
+
+const (
+	PrimaryGroupChargePolicy GroupChargePolicy = iota // charge only the primary (first) group of the user
+	AllGroupsChargePolicy                             // charge all groups of the user
+)
+
+func (g GroupChargePolicy) String() string {
+	return [...]string{"primary", "all"}[g]
+}
+
+func GroupChargePolicyFromString(str string) (GroupChargePolicy, error) {
+	switch strings.ToLower(str) {
+	case PrimaryGroupChargePolicy.String(), "":
+		return PrimaryGroupChargePolicy, nil
+	case AllGroupsChargePolicy.String():
+		return AllGroupsChargePolicy, nil
+	default:
+		return PrimaryGroupChargePolicy, fmt.Errorf("undefined group.limit.charge: %s", str)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinRequestPolicy defines what happens when a new request asks for less than the configured minimum.
+type MinRequestPolicy int
+
+const (
+	WarnMinRequestPolicy   MinRequestPolicy = iota // log a warning and accept the request
+	RejectMinRequestPolicy                         // reject the request
+)
+
+func (m MinRequestPolicy) String() string {
+	return [...]string{"warn", "reject"}[m]
+}
+
+func MinRequestPolicyFromString(str string) (MinRequestPolicy, error) {
+	switch strings.ToLower(str) {
+	case WarnMinRequestPolicy.String(), "":
+		return WarnMinRequestPolicy, nil
+	case RejectMinRequestPolicy.String():
+		return RejectMinRequestPolicy, nil
+	default:
+		return WarnMinRequestPolicy, fmt.Errorf("undefined minimum request policy: %s", str)
+	}
+}
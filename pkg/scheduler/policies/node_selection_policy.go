@@ -0,0 +1,50 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NodeSelectionPolicy defines how a queue orders the nodes when allocating.
+type NodeSelectionPolicy int
+
+const (
+	DefaultNodeSelectionPolicy NodeSelectionPolicy = iota // use the node sorting policy of the partition
+	BinpackNodeSelectionPolicy                            // prefer the most loaded node
+	SpreadNodeSelectionPolicy                             // prefer the least loaded node
+)
+
+func (n NodeSelectionPolicy) String() string {
+	return [...]string{"default", "binpack", "spread"}[n]
+}
+
+func NodeSelectionPolicyFromString(str string) (NodeSelectionPolicy, error) {
+	switch strings.ToLower(str) {
+	case DefaultNodeSelectionPolicy.String(), "":
+		return DefaultNodeSelectionPolicy, nil
+	case BinpackNodeSelectionPolicy.String():
+		return BinpackNodeSelectionPolicy, nil
+	case SpreadNodeSelectionPolicy.String():
+		return SpreadNodeSelectionPolicy, nil
+	default:
+		return DefaultNodeSelectionPolicy, fmt.Errorf("undefined node selection policy: %s", str)
+	}
+}
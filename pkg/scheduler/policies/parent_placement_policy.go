@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParentPlacementPolicy defines what happens when an application is placed in a parent queue.
+type ParentPlacementPolicy int
+
+const (
+	RejectParentPlacementPolicy  ParentPlacementPolicy = iota // reject the placement in the parent queue
+	PromoteParentPlacementPolicy                              // change a parent queue without child queues into a leaf queue
+)
+
+func (p ParentPlacementPolicy) String() string {
+	return [...]string{"reject", "promote"}[p]
+}
+
+func ParentPlacementPolicyFromString(str string) (ParentPlacementPolicy, error) {
+	switch strings.ToLower(str) {
+	case RejectParentPlacementPolicy.String(), "":
+		return RejectParentPlacementPolicy, nil
+	case PromoteParentPlacementPolicy.String():
+		return PromoteParentPlacementPolicy, nil
+	default:
+		return RejectParentPlacementPolicy, fmt.Errorf("undefined parent placement policy: %s", str)
+	}
+}
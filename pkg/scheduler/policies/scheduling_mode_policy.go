@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchedulingModePolicy defines how the queues of a partition share the resources.
+type SchedulingModePolicy int
+
+const (
+	FairSchedulingModePolicy     SchedulingModePolicy = iota // queues can borrow unused resources up to their maximum
+	CapacitySchedulingModePolicy                             // queues with a guaranteed resource cannot allocate above it
+)
+
+func (p SchedulingModePolicy) String() string {
+	return [...]string{"fair", "capacity"}[p]
+}
+
+func SchedulingModePolicyFromString(str string) (SchedulingModePolicy, error) {
+	switch strings.ToLower(str) {
+	case FairSchedulingModePolicy.String(), "":
+		return FairSchedulingModePolicy, nil
+	case CapacitySchedulingModePolicy.String():
+		return CapacitySchedulingModePolicy, nil
+	default:
+		return FairSchedulingModePolicy, fmt.Errorf("undefined scheduling mode: %s", str)
+	}
+}
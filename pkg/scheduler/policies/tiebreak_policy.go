@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TieBreakPolicy defines how applications that compare equal under the sort policy are ordered.
+type TieBreakPolicy int
+
+const (
+	AppIDTieBreakPolicy    TieBreakPolicy = iota // lexicographic order of the application ID
+	ResourceTieBreakPolicy                       // largest pending resource first, application ID second
+)
+
+func (t TieBreakPolicy) String() string {
+	return [...]string{"appid", "resource"}[t]
+}
+
+func TieBreakPolicyFromString(str string) (TieBreakPolicy, error) {
+	switch strings.ToLower(str) {
+	case AppIDTieBreakPolicy.String(), "":
+		return AppIDTieBreakPolicy, nil
+	case ResourceTieBreakPolicy.String():
+		return ResourceTieBreakPolicy, nil
+	default:
+		return AppIDTieBreakPolicy, fmt.Errorf("undefined application.sort.tiebreak: %s", str)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ZeroRequestPolicy defines what happens when an application that does not request any resources is submitted.
+type ZeroRequestPolicy int
+
+const (
+	AcceptZeroRequestPolicy ZeroRequestPolicy = iota // accept the application without special handling
+	RejectZeroRequestPolicy                          // reject the application if its first request does not ask for resources
+	RouteZeroRequestPolicy                           // place the application in the designated queue until its first request
+)
+
+func (z ZeroRequestPolicy) String() string {
+	return [...]string{"accept", "reject", "route"}[z]
+}
+
+func ZeroRequestPolicyFromString(str string) (ZeroRequestPolicy, error) {
+	switch strings.ToLower(str) {
+	case AcceptZeroRequestPolicy.String(), "":
+		return AcceptZeroRequestPolicy, nil
+	case RejectZeroRequestPolicy.String():
+		return RejectZeroRequestPolicy, nil
+	case RouteZeroRequestPolicy.String():
+		return RouteZeroRequestPolicy, nil
+	default:
+		return AcceptZeroRequestPolicy, fmt.Errorf("undefined zero request policy: %s", str)
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/apache/yunikorn-core/pkg/common"
 	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/common/security"
 	"github.com/apache/yunikorn-core/pkg/scheduler/objects"
 	siCommon "github.com/apache/yunikorn-scheduler-interface/lib/go/common"
 	"github.com/apache/yunikorn-scheduler-interface/lib/go/si"
@@ -159,6 +160,44 @@ partitions:
 	assert.Assert(t, queue != nil, "New partition: queue root.production is not found")
 }
 
+// Test a reconfiguration that only changes the ACL wildcard policy
+func TestConfigWildcardPolicy(t *testing.T) {
+	configData := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+            submitacl: "user1 *"
+`
+	ms := &mockScheduler{}
+	defer ms.Stop()
+	defer security.SetWildcardPolicy(security.AnyFieldWildcard)
+
+	err := ms.Init(configData, false, false)
+	assert.NilError(t, err, "RegisterResourceManager failed")
+	queue := ms.getPartitionQueue(leafName, partition)
+	noGroups := security.UserGroup{User: "user2"}
+	assert.Assert(t, queue.CheckSubmitAccess(noGroups), "group wildcard should allow all access using the default policy")
+
+	request := si.UpdateConfigurationRequest{
+		RmID:        "rm:123",
+		PolicyGroup: "policygroup",
+		Config:      configData,
+		ExtraConfig: map[string]string{configs.CMACLWildcardPolicy: "field"},
+	}
+	err = ms.proxy.UpdateConfiguration(&request)
+	assert.NilError(t, err, "configuration reload failed")
+	assert.Assert(t, !queue.CheckSubmitAccess(noGroups), "group wildcard should only allow users with a group")
+
+	// the denial is remembered: changing the policy back must allow access again
+	request.ExtraConfig = map[string]string{configs.CMACLWildcardPolicy: "any"}
+	err = ms.proxy.UpdateConfiguration(&request)
+	assert.NilError(t, err, "configuration reload failed")
+	assert.Assert(t, queue.CheckSubmitAccess(noGroups), "group wildcard should allow all access after the policy change")
+}
+
 // Test basic interactions from rm proxy to cache and to scheduler.
 //
 //nolint:funlen
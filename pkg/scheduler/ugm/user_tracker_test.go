@@ -100,12 +100,12 @@ func TestIncreaseTrackedResource(t *testing.T) {
 	userTracker.setGroupForApp(TestApp4, groupTracker)
 
 	actualResources := userTracker.getUsedResources()
-	assert.Equal(t, "map[mem:80000000 vcore:80000]", actualResources["root"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:80000000 vcore:80000]", actualResources["root.parent"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:40000000 vcore:40000]", actualResources["root.parent.child1"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:30000000 vcore:30000]", actualResources["root.parent.child1.child12"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:20000000 vcore:20000]", actualResources["root.parent.child2"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:20000000 vcore:20000]", actualResources["root.parent.child12"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:80000 mem:80000000]", actualResources["root"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:80000 mem:80000000]", actualResources["root.parent"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:40000 mem:40000000]", actualResources["root.parent.child1"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:30000 mem:30000000]", actualResources["root.parent.child1.child12"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:20000 mem:20000000]", actualResources["root.parent.child2"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:20000 mem:20000000]", actualResources["root.parent.child12"].String(), "wrong resource")
 	assert.Equal(t, 4, len(userTracker.getTrackedApplications()))
 }
 
@@ -137,10 +137,10 @@ func TestDecreaseTrackedResource(t *testing.T) {
 
 	actualResources := userTracker.getUsedResources()
 	assert.Equal(t, 2, len(userTracker.getTrackedApplications()))
-	assert.Equal(t, "map[mem:90000000 vcore:90000]", actualResources["root"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:90000000 vcore:90000]", actualResources["root.parent"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:70000000 vcore:70000]", actualResources["root.parent.child1"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:20000000 vcore:20000]", actualResources["root.parent.child2"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:90000 mem:90000000]", actualResources["root"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:90000 mem:90000000]", actualResources["root.parent"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:70000 mem:70000000]", actualResources["root.parent.child1"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:20000 mem:20000000]", actualResources["root.parent.child2"].String(), "wrong resource")
 
 	usage3, err := resources.NewResourceFromConf(map[string]string{"mem": "10M", "vcore": "10"})
 	if err != nil {
@@ -156,10 +156,10 @@ func TestDecreaseTrackedResource(t *testing.T) {
 	assert.Equal(t, removeQT, false, "wrong remove queue tracker value")
 
 	actualResources1 := userTracker.getUsedResources()
-	assert.Equal(t, "map[mem:70000000 vcore:70000]", actualResources1["root"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:70000000 vcore:70000]", actualResources1["root.parent"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:60000000 vcore:60000]", actualResources1["root.parent.child1"].String(), "wrong resource")
-	assert.Equal(t, "map[mem:10000000 vcore:10000]", actualResources1["root.parent.child2"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:70000 mem:70000000]", actualResources1["root"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:70000 mem:70000000]", actualResources1["root.parent"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:60000 mem:60000000]", actualResources1["root.parent.child1"].String(), "wrong resource")
+	assert.Equal(t, "map[vcore:10000 mem:10000000]", actualResources1["root.parent.child2"].String(), "wrong resource")
 
 	usage4, err := resources.NewResourceFromConf(map[string]string{"mem": "60M", "vcore": "60"})
 	if err != nil {
@@ -27,6 +27,7 @@ type AllocationAskLogDAOInfo struct {
 type AllocationAskDAOInfo struct {
 	AllocationKey       string                     `json:"allocationKey"` // no omitempty, allocation key should not be empty
 	AllocationTags      map[string]string          `json:"allocationTags,omitempty"`
+	Metadata            map[string]string          `json:"metadata,omitempty"`
 	RequestTime         int64                      `json:"requestTime,omitempty"`
 	ResourcePerAlloc    map[string]int64           `json:"resource,omitempty"`
 	Priority            string                     `json:"priority,omitempty"`
@@ -21,6 +21,7 @@ package dao
 type AllocationDAOInfo struct {
 	AllocationKey    string            `json:"allocationKey"` // no omitempty, allocation key should not be empty
 	AllocationTags   map[string]string `json:"allocationTags,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
 	RequestTime      int64             `json:"requestTime,omitempty"`     // Allocation ask's createTime if PlaceholderUsed is false, otherwise equivalent to placeholder allocation's createTime
 	AllocationTime   int64             `json:"allocationTime,omitempty"`  // Allocation's createTime
 	AllocationDelay  int64             `json:"allocationDelay,omitempty"` // Difference between AllocationTime and RequestTime
@@ -54,4 +54,8 @@ type PartitionQueueDAOInfo struct {
 	PreemptionDelay        string                  `json:"preemptionDelay,omitempty"`
 	IsPriorityFence        bool                    `json:"isPriorityFence"` // no omitempty, a false value gives a quick way to understand whether it's fenced.
 	PriorityOffset         int32                   `json:"priorityOffset,omitempty"`
+	Owner                  string                  `json:"owner,omitempty"`
+	Team                   string                  `json:"team,omitempty"`
+	Description            string                  `json:"description,omitempty"`
+	Tags                   map[string]string       `json:"tags,omitempty"`
 }
@@ -36,4 +36,5 @@ type RuleDAO struct {
 	Parameters map[string]string `json:"parameters,omitempty"`
 	Filter     *FilterDAO        `json:"filter,omitempty"`
 	ParentRule *RuleDAO          `json:"parentRule,omitempty"`
+	Rules      []*RuleDAO        `json:"rules,omitempty"`
 }
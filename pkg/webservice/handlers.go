@@ -259,6 +259,7 @@ func getAllocationDAO(alloc *objects.Allocation) *dao.AllocationDAOInfo {
 	allocDAO := &dao.AllocationDAOInfo{
 		AllocationKey:    alloc.GetAllocationKey(),
 		AllocationTags:   alloc.GetTagsClone(),
+		Metadata:         alloc.GetMetadata(),
 		RequestTime:      requestTime,
 		AllocationTime:   allocTime,
 		AllocationDelay:  allocTime - requestTime,
@@ -396,6 +397,7 @@ func getAllocationAskDAO(ask *objects.Allocation) *dao.AllocationAskDAOInfo {
 	return &dao.AllocationAskDAOInfo{
 		AllocationKey:       ask.GetAllocationKey(),
 		AllocationTags:      ask.GetTagsClone(),
+		Metadata:            ask.GetMetadata(),
 		RequestTime:         ask.GetCreateTime().UnixNano(),
 		ResourcePerAlloc:    ask.GetAllocatedResource().DAOMap(),
 		Priority:            strconv.Itoa(int(ask.GetPriority())),
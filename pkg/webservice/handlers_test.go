@@ -1629,6 +1629,34 @@ func TestGetQueueApplicationsHandler(t *testing.T) {
 	assert.Equal(t, errInfo.StatusCode, http.StatusBadRequest)
 }
 
+func TestGetQueueApplicationsAllocationMetadata(t *testing.T) {
+	defaultQueue := "root.default"
+	part := setup(t, configDefault, 1)
+	app := addApp(t, "app-1", part, defaultQueue, false)
+	alloc := objects.NewAllocationFromSI(&si.Allocation{
+		AllocationKey:    "alloc-1",
+		ApplicationID:    "app-1",
+		PartitionName:    part.Name,
+		NodeID:           "node-1",
+		ResourcePerAlloc: &si.Resource{Resources: map[string]*si.Quantity{"vcore": {Value: 1}}},
+		AllocationTags:   map[string]string{common.AllocTagMetadataPrefix + "costCenter": "cc-1"},
+	})
+	app.AddAllocation(alloc)
+
+	NewWebApp(schedulerContext.Load(), nil)
+
+	req, err := createRequest(t, "/ws/v1/partition/default/queue/"+defaultQueue+"/applications", map[string]string{"partition": partitionNameWithoutClusterID, "queue": defaultQueue})
+	assert.NilError(t, err)
+	resp := &MockResponseWriter{}
+	var appsDao []*dao.ApplicationDAOInfo
+	getQueueApplications(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &appsDao)
+	assert.NilError(t, err, unmarshalError)
+	assert.Equal(t, len(appsDao), 1)
+	assert.Equal(t, len(appsDao[0].Allocations), 1)
+	assert.DeepEqual(t, appsDao[0].Allocations[0].Metadata, map[string]string{"costCenter": "cc-1"})
+}
+
 func createRequest(t *testing.T, url string, paramsMap map[string]string) (*http.Request, error) {
 	var err error
 	var req *http.Request